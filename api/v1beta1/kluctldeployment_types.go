@@ -180,6 +180,18 @@ type KluctlDeploymentSpec struct {
 	// +optional
 	ForceReplaceOnError bool `json:"forceReplaceOnError,omitempty"`
 
+	// ForceReplaceOnErrorKinds restricts ForceReplaceOnError to objects of the given kinds. Kinds must be specified in
+	// the format 'Kind.group', e.g. 'Job.batch'. The group must be omitted for core kinds.
+	// Equivalent to using '--force-replace-on-error-kind' when calling kluctl.
+	// +optional
+	ForceReplaceOnErrorKinds []string `json:"forceReplaceOnErrorKinds,omitempty"`
+
+	// ReplacePreserveMetadata is a list of glob patterns for annotation and label keys which are carried over
+	// from the remote object when an object is replaced.
+	// Equivalent to using '--replace-preserve-metadata' when calling kluctl.
+	// +optional
+	ReplacePreserveMetadata []string `json:"replacePreserveMetadata,omitempty"`
+
 	// ForceReplaceOnError instructs kluctl to abort deployments immediately when something fails.
 	// Equivalent to using '--abort-on-error' when calling kluctl.
 	// +kubebuilder:default:=false
@@ -196,6 +196,16 @@ func (in *KluctlDeploymentSpec) DeepCopyInto(out *KluctlDeploymentSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ForceReplaceOnErrorKinds != nil {
+		in, out := &in.ForceReplaceOnErrorKinds, &out.ForceReplaceOnErrorKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReplacePreserveMetadata != nil {
+		in, out := &in.ReplacePreserveMetadata, &out.ReplacePreserveMetadata
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.IncludeTags != nil {
 		in, out := &in.IncludeTags, &out.IncludeTags
 		*out = make([]string, len(*in))
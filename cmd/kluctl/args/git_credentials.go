@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/gobwas/glob"
 	git_auth "github.com/kluctl/kluctl/lib/git/auth"
+	"github.com/kluctl/kluctl/lib/git/messages"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"os"
 	"strings"
@@ -16,6 +17,20 @@ type GitCredentials struct {
 	GitSshKeyFile        []string `group:"git" skipenv:"true" help:"Specify SSH key to use for Git authentication. Must be in the form --git-ssh-key-file=<host>/<path>=<filePath>."`
 	GitSshKnownHostsFile []string `group:"git" skipenv:"true" help:"Specify known_hosts file to use for Git authentication. Must be in the form --git-ssh-known-hosts-file=<host>/<path>=<filePath>."`
 	GitCAFile            []string `group:"git" skipenv:"true" help:"Specify CA bundle to use for https verification. Must be in the form --git-ca-file=<registry>/<repo>=<filePath>."`
+
+	GitCredentialsFile ExistingFileType `group:"git" help:"Specify a yaml file which maps repo key globs (e.g. github.com/my-org/**) to Git credentials (token, username/password or ssh key). Credentials from environment variables take precedence over credentials from this file." exts:"yml,yaml"`
+}
+
+// BuildCredentialsFileAuthProvider returns an auth provider for --git-credentials-file or nil if it was not specified.
+// The returned provider must be registered after the env based auth provider.
+func (c *GitCredentials) BuildCredentialsFileAuthProvider(messageCallbacks *messages.MessageCallbacks) git_auth.GitAuthProvider {
+	if c == nil || c.GitCredentialsFile == "" {
+		return nil
+	}
+	return &git_auth.GitCredentialsConfigAuthProvider{
+		MessageCallbacks: *messageCallbacks,
+		Path:             c.GitCredentialsFile.String(),
+	}
 }
 
 func (c *GitCredentials) BuildAuthProvider(ctx context.Context) (git_auth.GitAuthProvider, error) {
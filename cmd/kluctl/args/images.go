@@ -10,6 +10,7 @@ import (
 type ImageFlags struct {
 	FixedImage      []string         `group:"images" short:"F" help:"Pin an image to a given version. Expects '--fixed-image=image<:namespace:deployment:container>=result'"`
 	FixedImagesFile ExistingFileType `group:"images" help:"Use .yaml file to pin image versions. See output of list-images sub-command or read the documentation for details about the output format" exts:"yml,yaml"`
+	OverrideImage   []string         `group:"images" help:"Rewrite all images matching a regex. Expects '--override-image=regex=replacement', where replacement can reference capture groups via $1 or ${1}. Fixed images take precedence."`
 }
 
 func (args *ImageFlags) LoadFixedImagesFromArgs() ([]types.FixedImage, error) {
@@ -33,6 +34,22 @@ func (args *ImageFlags) LoadFixedImagesFromArgs() ([]types.FixedImage, error) {
 	return ret.Images, nil
 }
 
+func (args *ImageFlags) LoadImageOverridesFromArgs() ([]types.ImageOverride, error) {
+	var ret []types.ImageOverride
+	for _, arg := range args.OverrideImage {
+		// the replacement is less likely to contain a '=' than the regex
+		i := strings.LastIndex(arg, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("--override-image expects 'regex=replacement'")
+		}
+		ret = append(ret, types.ImageOverride{
+			Regex:       arg[:i],
+			Replacement: arg[i+1:],
+		})
+	}
+	return ret, nil
+}
+
 func buildFixedImageEntryFromArg(arg string) (*types.FixedImage, error) {
 	s := strings.Split(arg, "=")
 	if len(s) != 2 {
@@ -2,19 +2,28 @@ package args
 
 import (
 	"fmt"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"path/filepath"
 )
 
 type InclusionFlags struct {
-	IncludeTag           []string `group:"inclusion" short:"I" help:"Include deployments with given tag."`
-	ExcludeTag           []string `group:"inclusion" short:"E" help:"Exclude deployments with given tag. Exclusion has precedence over inclusion, meaning that explicitly excluded deployments will always be excluded even if an inclusion rule would match the same deployment."`
+	IncludeTag           []string `group:"inclusion" short:"I" help:"Include deployments with given tag. Can also be a jinja2 template that renders to a comma separated list of tags."`
+	ExcludeTag           []string `group:"inclusion" short:"E" help:"Exclude deployments with given tag. Exclusion has precedence over inclusion, meaning that explicitly excluded deployments will always be excluded even if an inclusion rule would match the same deployment. See --inclusion-order to change this behaviour."`
 	IncludeDeploymentDir []string `group:"inclusion" help:"Include deployment dir. The path must be relative to the root deployment project."`
 	ExcludeDeploymentDir []string `group:"inclusion" help:"Exclude deployment dir. The path must be relative to the root deployment project. Exclusion has precedence over inclusion, same as in --exclude-tag"`
+	InclusionOrder       string   `group:"inclusion" help:"Specify the order in which inclusion and exclusion rules are evaluated. Can be 'include-exclude', meaning that exclusions have precedence over inclusions, or 'exclude-include', meaning that inclusions can re-include deployments that were excluded before. In the latter case, deployments not matching any rule are deployed if any exclusion rule was specified." default:"include-exclude"`
 }
 
 func (args *InclusionFlags) ParseInclusionFromArgs() (*utils.Inclusion, error) {
 	inclusion := utils.NewInclusion()
+	switch utils.InclusionOrder(args.InclusionOrder) {
+	case "", utils.InclusionOrderIncludeExclude:
+	case utils.InclusionOrderExcludeInclude:
+		inclusion.SetOrder(utils.InclusionOrderExcludeInclude)
+	default:
+		return nil, fmt.Errorf("invalid --inclusion-order %s", args.InclusionOrder)
+	}
 	for _, tag := range args.IncludeTag {
 		inclusion.AddInclude("tag", tag)
 	}
@@ -35,3 +44,53 @@ func (args *InclusionFlags) ParseInclusionFromArgs() (*utils.Inclusion, error) {
 	}
 	return inclusion, nil
 }
+
+type PruneInclusionFlags struct {
+	PruneIncludeTag           []string `group:"misc" help:"Only prune orphaned objects with the given tag. Pruning is always limited to objects that also match the deployment inclusion rules."`
+	PruneExcludeTag           []string `group:"misc" help:"Never prune orphaned objects with the given tag. Exclusion has precedence over inclusion."`
+	PruneIncludeDeploymentDir []string `group:"misc" help:"Only prune orphaned objects from the given deployment dir. The path must be relative to the root deployment project."`
+	PruneExcludeDeploymentDir []string `group:"misc" help:"Never prune orphaned objects from the given deployment dir. The path must be relative to the root deployment project. Exclusion has precedence over inclusion."`
+
+	PruneAllowlist        []string `group:"misc" help:"Only prune orphaned objects of the given kinds, in the format 'Kind.group' (e.g. 'Deployment.apps' or 'ConfigMap'). Skipped objects are reported in the result."`
+	PruneDenylist         []string `group:"misc" help:"Never prune orphaned objects of the given kinds, in the format 'Kind.group'. The denylist has precedence over the allowlist."`
+	PruneDenylistDefaults bool     `group:"misc" help:"Add Namespace and CustomResourceDefinition.apiextensions.k8s.io to the prune denylist."`
+}
+
+// GetPruneDenylist returns the prune denylist, including the defaults if requested
+func (args *PruneInclusionFlags) GetPruneDenylist() []string {
+	ret := append([]string{}, args.PruneDenylist...)
+	if args.PruneDenylistDefaults {
+		for _, gk := range utils2.DefaultPruneDenyKinds {
+			ret = append(ret, gk.String())
+		}
+	}
+	return ret
+}
+
+// ParsePruneInclusionFromArgs returns nil if no prune inclusion/exclusion flags were specified
+func (args *PruneInclusionFlags) ParsePruneInclusionFromArgs() (*utils.Inclusion, error) {
+	if len(args.PruneIncludeTag) == 0 && len(args.PruneExcludeTag) == 0 && len(args.PruneIncludeDeploymentDir) == 0 && len(args.PruneExcludeDeploymentDir) == 0 {
+		return nil, nil
+	}
+
+	inclusion := utils.NewInclusion()
+	for _, tag := range args.PruneIncludeTag {
+		inclusion.AddInclude("tag", tag)
+	}
+	for _, tag := range args.PruneExcludeTag {
+		inclusion.AddExclude("tag", tag)
+	}
+	for _, dir := range args.PruneIncludeDeploymentDir {
+		if filepath.IsAbs(dir) {
+			return nil, fmt.Errorf("--prune-include-deployment-dir path must be relative")
+		}
+		inclusion.AddInclude("deploymentItemDir", filepath.ToSlash(dir))
+	}
+	for _, dir := range args.PruneExcludeDeploymentDir {
+		if filepath.IsAbs(dir) {
+			return nil, fmt.Errorf("--prune-exclude-deployment-dir path must be relative")
+		}
+		inclusion.AddExclude("deploymentItemDir", filepath.ToSlash(dir))
+	}
+	return inclusion, nil
+}
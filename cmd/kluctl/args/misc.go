@@ -9,8 +9,14 @@ type YesFlags struct {
 }
 
 type OfflineKubernetesFlags struct {
-	OfflineKubernetes bool   `group:"misc" help:"Run command in offline mode, meaning that it will not try to connect the target cluster"`
-	KubernetesVersion string `group:"misc" help:"Specify the Kubernetes version that will be assumed. This will also override the kubeVersion used when rendering Helm Charts."`
+	OfflineKubernetes   bool   `group:"misc" help:"Run command in offline mode, meaning that it will not try to connect the target cluster"`
+	KubernetesVersion   string `group:"misc" help:"Specify the Kubernetes version that will be assumed. This will also override the kubeVersion used when rendering Helm Charts."`
+	OfflineApiResources string `group:"misc" help:"Load the API resources of the offline cluster from the given file, which must contain the output of 'kubectl api-resources' (optionally with '-o wide'). This allows to resolve namespaced/cluster-scoped custom resources and passes the available API versions to Helm. Only used with --offline-kubernetes."`
+}
+
+type ClusterFixtureFlags struct {
+	RecordCluster string `group:"misc" help:"Record all requests sent to the cluster and their responses into the given directory. The recorded fixture can later be used with --replay-cluster."`
+	ReplayCluster string `group:"misc" help:"Replay a cluster fixture recorded via --record-cluster instead of connecting to the cluster. Requests that were not recorded will fail. Implies --dry-run."`
 }
 
 type DryRunFlags struct {
@@ -22,8 +28,10 @@ type ForceApplyFlags struct {
 }
 
 type ReplaceOnErrorFlags struct {
-	ReplaceOnError      bool `group:"misc" help:"When patching an object fails, try to replace it. See documentation for more details."`
-	ForceReplaceOnError bool `group:"misc" help:"Same as --replace-on-error, but also try to delete and re-create objects. See documentation for more details."`
+	ReplaceOnError          bool     `group:"misc" help:"When patching an object fails, try to replace it. See documentation for more details."`
+	ForceReplaceOnError     bool     `group:"misc" help:"Same as --replace-on-error, but also try to delete and re-create objects. See documentation for more details."`
+	ForceReplaceOnErrorKind []string `group:"misc" help:"Only delete and re-create objects of the given kind when a replace fails. The kind must be specified in the format 'Kind.group', e.g. 'Job.batch'. Omit the group for core kinds. Implies --force-replace-on-error. Can be specified multiple times."`
+	ReplacePreserveMetadata []string `group:"misc" help:"When replacing an object, carry over remote annotations and labels with keys matching the given glob pattern, e.g. 'autoscaling.alpha.kubernetes.io/*'. Can be specified multiple times."`
 }
 
 type HookFlags struct {
@@ -37,6 +45,32 @@ type IgnoreFlags struct {
 	IgnoreKluctlMetadata bool `group:"misc" help:"Ignores changes in Kluctl related metadata (e.g. tags, discriminators, ...)"`
 }
 
+type HelmValuesDiffFlags struct {
+	HelmValuesDiff bool `group:"misc" help:"Compare the rendered Helm values of all Helm releases with the values found in the last command result of the same target. Values originating from sops encrypted values files are redacted."`
+}
+
+type ApplyFlags struct {
+	ApplyParallelism int           `group:"misc" help:"Maximum number of deployment items to apply in parallel. Barriers are still respected. If not specified or 0, a default of 8 is used."`
+	ApplyTimeout     time.Duration `group:"misc" help:"Maximum time a single apply/replace request for an object may take. A timed out request is recorded as an error for the affected object. Timeouts are in the duration format (1s, 1m, 1h, ...). Defaults to no timeout."`
+	BarrierTimeout   time.Duration `group:"misc" help:"Maximum time to wait at a barrier for the preceding deployment items to finish. Stalled deployment items are cancelled and an error is recorded. Deploying continues afterwards, unless --abort-on-error is passed. Defaults to no timeout."`
+	AbortAfter       int           `group:"misc" help:"Abort deploying after the given number of errors occurred, instead of trying the remaining deployments. A value of 1 is equivalent to --abort-on-error. Defaults to 0, meaning that deploying is never aborted early."`
+
+	HookPollInterval    time.Duration `group:"misc" help:"Initial interval used to poll hooks while waiting for them to finish. The interval is doubled on every poll until --hook-poll-max-interval is reached." default:"500ms"`
+	HookPollMaxInterval time.Duration `group:"misc" help:"Maximum interval used to poll hooks while waiting for them to finish." default:"5s"`
+
+	ApplyRetryBackoff    time.Duration `group:"misc" help:"Initial delay before retrying a failed apply with conflict resolution, replace or force-replace. The delay is doubled for every further retry of the same object until --apply-retry-max-backoff is reached and is randomized by +/- 20%. Defaults to retrying immediately."`
+	ApplyRetryMaxBackoff time.Duration `group:"misc" help:"Maximum delay between apply retries when --apply-retry-backoff is used." default:"5s"`
+
+	ApplyLabelSelector string `group:"misc" help:"Only apply objects matching the given label selector (e.g. 'tier=critical'). Non-matching objects are skipped, but are not pruned. Hooks are only run for deployment items with at least one matching object, unless the hook itself matches."`
+}
+
+type ApiDeprecationFlags struct {
+	FailOnApiDeprecation      bool     `group:"misc" help:"Treat API deprecation warnings returned by the cluster as errors."`
+	FailOnApiDeprecationGroup []string `group:"misc" help:"Only treat API deprecation warnings for the given API group as errors. Use 'core' for the core API group. Implies --fail-on-api-deprecation. Can be specified multiple times."`
+	WarningsAsErrors          bool     `group:"misc" help:"Treat all warnings returned by the cluster as errors. Together with --abort-on-error, deploying is aborted on the first warning."`
+	WarningsAsErrorsPattern   []string `group:"misc" help:"Only treat warnings returned by the cluster that match the given regular expression as errors. Non-matching warnings are still reported as warnings. Implies --warnings-as-errors. Can be specified multiple times."`
+}
+
 type AbortOnErrorFlags struct {
 	AbortOnError bool `group:"misc" help:"Abort deploying when an error occurs instead of trying the remaining deployments"`
 }
@@ -45,6 +79,7 @@ type OutputFormatFlags struct {
 	OutputFormat []string `group:"misc" short:"o" help:"Specify output format and target file, in the format 'format=path'. Format can either be 'text' or 'yaml'. Can be specified multiple times. The actual format for yaml is currently not documented and subject to change."`
 	NoObfuscate  bool     `group:"misc" help:"Disable obfuscation of sensitive/secret data"`
 	ShortOutput  bool     `group:"misc" help:"When using the 'text' output format (which is the default), only names of changes objects are shown instead of showing all changes."`
+	DiffFormat   string   `group:"misc" help:"Specify how changes are shown when using the 'text' output format. Can be 'text' to show the changes of each object as a table, or 'unified' to only show per-object unified diffs compatible with 'git diff'." default:"text"`
 }
 
 type OutputFlags struct {
@@ -52,5 +87,8 @@ type OutputFlags struct {
 }
 
 type RenderOutputDirFlags struct {
-	RenderOutputDir string `group:"misc" help:"Specifies the target directory to render the project into. If omitted, a temporary directory is used."`
+	RenderOutputDir    string `group:"misc" help:"Specifies the target directory to render the project into. If omitted, a temporary directory is used."`
+	RenderOutputFormat string `group:"misc" help:"Specifies the format of the render output. Can be 'dir' to write the rendered tree into --render-output-dir, 'single-yaml' to write all rendered objects into a single multi-document 'rendered.yaml' or 'json' to write them as a json array into 'rendered.json'." default:"dir"`
+	RenderExcludeHooks bool   `group:"misc" help:"Omit hooks from the render output. This only affects what is written to the render output, not what is deployed."`
+	RenderOnlyHooks    bool   `group:"misc" help:"Only write hooks to the render output. Useful to debug the rendering of hooks in isolation."`
 }
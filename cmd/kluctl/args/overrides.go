@@ -10,7 +10,7 @@ import (
 )
 
 type SourceOverrides struct {
-	LocalGitOverride      []string `group:"project" help:"Specify a single repository local git override in the form of 'github.com/my-org/my-repo=/local/path/to/override'. This will cause kluctl to not use git to clone for the specified repository but instead use the local directory. This is useful in case you need to test out changes in external git repositories without pushing them."`
+	LocalGitOverride      []string `group:"project" help:"Specify a single repository local git override in the form of 'github.com/my-org/my-repo=/local/path/to/override'. This will cause kluctl to not use git to clone for the specified repository but instead use the local directory. This is useful in case you need to test out changes in external git repositories without pushing them. A wildcard in the form of 'github.com/*=/local/path/to/mirror' or 'github.com/my-org/*=/local/path' is treated as a group override (see --local-git-group-override). If multiple overrides match, the most specific one is used."`
 	LocalGitGroupOverride []string `group:"project" help:"Same as --local-git-override, but for a whole group prefix instead of a single repository. All repositories that have the given prefix will be overridden with the given local path and the repository suffix appended. For example, 'gitlab.com/some-org/sub-org=/local/path/to/my-forks' will override all repositories below 'gitlab.com/some-org/sub-org/' with the repositories found in '/local/path/to/my-forks'. It will however only perform an override if the given repository actually exists locally and otherwise revert to the actual (non-overridden) repository."`
 	LocalOciOverride      []string `group:"project" help:"Same as --local-git-override, but for OCI repositories."`
 	LocalOciGroupOverride []string `group:"project" help:"Same as --local-git-group-override, but for OCI repositories."`
@@ -56,6 +56,23 @@ func (a *SourceOverrides) parseRepoOverride(ctx context.Context, s string, isGro
 		return sourceoverride.RepoOverride{}, fmt.Errorf("%s", s)
 	}
 
+	// the wildcard form 'example.com/*' or 'example.com/org/*' is a group override for everything below the prefix
+	if strings.HasSuffix(sp[0], "/*") {
+		repoKey, err := types.ParseRepoKey(strings.TrimSuffix(sp[0], "*"), type_)
+		if err != nil {
+			return sourceoverride.RepoOverride{}, err
+		}
+		repoKey.Path = strings.TrimSuffix(repoKey.Path, "/")
+		if repoKey.Type != type_ {
+			return sourceoverride.RepoOverride{}, fmt.Errorf("%s is not a repo key of type %s", sp[0], type_)
+		}
+		return sourceoverride.RepoOverride{
+			RepoKey:  repoKey,
+			IsGroup:  true,
+			Override: sp[1],
+		}, nil
+	}
+
 	repoKey, err := types.ParseRepoKey(sp[0], type_)
 	if err != nil {
 		if !allowLegacy {
@@ -82,6 +99,14 @@ func (a *SourceOverrides) parseRepoOverride(ctx context.Context, s string, isGro
 
 		status.Deprecation(ctx, "old-repo-override", "Passing --local-git-override/--local-git-override-group in the example.com:path form is deprecated and will not be supported in future versions of Kluctl. Please use the example.com/path form.")
 	}
+	if repoKey.Type != type_ {
+		return sourceoverride.RepoOverride{}, fmt.Errorf("%s is not a repo key of type %s", sp[0], type_)
+	}
+
+	// group overrides match by prefix, so a trailing slash would prevent any match
+	if isGroup {
+		repoKey.Path = strings.TrimSuffix(repoKey.Path, "/")
+	}
 
 	return sourceoverride.RepoOverride{
 		RepoKey:  repoKey,
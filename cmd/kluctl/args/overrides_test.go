@@ -0,0 +1,72 @@
+package args
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/lib/git/types"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOciGroupOverride(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "app"), 0o700))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sub", "app"), 0o700))
+
+	for _, key := range []string{"myregistry.io/team", "myregistry.io/team/", "oci://myregistry.io/team", "myregistry.io/team/*"} {
+		t.Run(key, func(t *testing.T) {
+			so := SourceOverrides{
+				LocalOciGroupOverride: []string{key + "=" + dir},
+			}
+			m, err := so.ParseOverrides(context.Background())
+			assert.NoError(t, err)
+
+			resolve := func(path string) string {
+				p, err := m.ResolveOverride(context.Background(), types.NewRepoKey("oci", "myregistry.io", path))
+				assert.NoError(t, err)
+				return p
+			}
+
+			assert.Equal(t, filepath.Join(dir, "app"), resolve("team/app"))
+			assert.Equal(t, filepath.Join(dir, "sub", "app"), resolve("team/sub/app"))
+			assert.Equal(t, "", resolve("team"))
+			assert.Equal(t, "", resolve("other/app"))
+			assert.Equal(t, "", resolve("teammate/app"))
+
+			p, err := m.ResolveOverride(context.Background(), types.NewRepoKey("git", "myregistry.io", "team/app"))
+			assert.NoError(t, err)
+			assert.Equal(t, "", p)
+		})
+	}
+}
+
+func TestOciOverrideInvalidType(t *testing.T) {
+	so := SourceOverrides{
+		LocalOciGroupOverride: []string{"git://myregistry.io/team=/tmp"},
+	}
+	_, err := so.ParseOverrides(context.Background())
+	assert.ErrorContains(t, err, "is not a repo key of type oci")
+}
+
+func TestOciOverridePrecedence(t *testing.T) {
+	groupDir := t.TempDir()
+	exactDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(groupDir, "app1"), 0o700))
+	assert.NoError(t, os.MkdirAll(filepath.Join(groupDir, "app2"), 0o700))
+
+	so := SourceOverrides{
+		LocalOciGroupOverride: []string{"myregistry.io/team=" + groupDir},
+		LocalOciOverride:      []string{"myregistry.io/team/app2=" + exactDir},
+	}
+	m, err := so.ParseOverrides(context.Background())
+	assert.NoError(t, err)
+
+	p, err := m.ResolveOverride(context.Background(), types.NewRepoKey("oci", "myregistry.io", "team/app1"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(groupDir, "app1"), p)
+
+	p, err = m.ResolveOverride(context.Background(), types.NewRepoKey("oci", "myregistry.io", "team/app2"))
+	assert.NoError(t, err)
+	assert.Equal(t, exactDir, p)
+}
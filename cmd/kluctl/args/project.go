@@ -1,10 +1,17 @@
 package args
 
 import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/client-go/rest"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -31,11 +38,19 @@ type ProjectFlags struct {
 
 	Timeout                time.Duration `group:"project" help:"Specify timeout for all operations, including loading of the project, all external api calls and waiting for readiness." default:"10m"`
 	GitCacheUpdateInterval time.Duration `group:"project" help:"Specify the time to wait between git cache updates. Defaults to not wait at all and always updating caches."`
+	GitTimeout             time.Duration `group:"project" help:"Specify the timeout for individual git operations (e.g. clone or fetch). The overall --timeout still applies as an outer bound. Defaults to no separate timeout."`
+	NoGitFetch             bool          `group:"project" help:"Never fetch git repositories or pull OCI artifacts. Only repositories and refs already present in the local cache are used, and a descriptive error is returned for everything else. Useful for fully offline runs, e.g. in combination with --offline-kubernetes."`
+
+	AllowMissingSopsKeys bool     `group:"project" help:"Skip sops encrypted vars files which can't be decrypted due to missing keys instead of failing. Vars from skipped files will be missing, which is only useful for local development."`
+	SopsAgeKeyFile       []string `group:"project" help:"Specify an additional age key file to be used for sops decryption. Keys from SOPS_AGE_KEY_FILE and the default locations are still used. Can be specified multiple times."`
+	NoIgnoreMissingVars  bool     `group:"project" help:"Treat all vars sources as required, overriding 'ignoreMissing: true' of individual vars sources. Useful to ensure that production deployments never silently miss variables."`
+	StrictArgs           bool     `group:"project" help:"Validate all passed args against the args declared in .kluctl.yaml. Unknown args and args with a type not matching the declared type (or the type of the default value) cause an error."`
 }
 
 type ArgsFlags struct {
 	Arg          []string `group:"project" short:"a" help:"Passes a template argument in the form of name=value. Nested args can be set with the '-a my.nested.arg=value' syntax. Values are interpreted as yaml values, meaning that 'true' and 'false' will lead to boolean values and numbers will be treated as numbers. Use quotes if you want these to be treated as strings. If the value starts with @, it is treated as a file, meaning that the contents of the file will be loaded and treated as yaml."`
 	ArgsFromFile []string `group:"project" help:"Loads a yaml file and makes it available as arguments, meaning that they will be available thought the global 'args' variable."`
+	ArgsFromEnv  []string `group:"project" help:"Loads all environment variables with the given prefix as arguments. The prefix is stripped and the remaining name is lowercased, with '__' being converted to '.' to allow nested args. For example, '--args-from-env=MY_ARGS_' turns MY_ARGS_FOO__BAR=1 into the arg 'foo.bar'. Values are interpreted the same way as with --arg. Args from the environment have the lowest precedence, followed by --arg and then --args-from-file."`
 }
 
 func (a *ArgsFlags) LoadArgs() (*uo.UnstructuredObject, error) {
@@ -43,15 +58,24 @@ func (a *ArgsFlags) LoadArgs() (*uo.UnstructuredObject, error) {
 		return uo.New(), nil
 	}
 
-	var args *uo.UnstructuredObject
+	args := uo.New()
+	for _, prefix := range a.ArgsFromEnv {
+		envArgs, err := kluctl_project.ConvertArgsToVars(collectArgsFromEnv(prefix, os.Environ()), true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load args from environment variables with prefix %s: %w", prefix, err)
+		}
+		args.Merge(envArgs)
+	}
+
 	optionArgs, err := kluctl_project.ParseArgs(a.Arg)
 	if err != nil {
 		return nil, err
 	}
-	args, err = kluctl_project.ConvertArgsToVars(optionArgs, true)
+	inlineArgs, err := kluctl_project.ConvertArgsToVars(optionArgs, true)
 	if err != nil {
 		return nil, err
 	}
+	args.Merge(inlineArgs)
 	for _, a := range a.ArgsFromFile {
 		optionArgs2, err := uo.FromFile(a)
 		if err != nil {
@@ -62,6 +86,24 @@ func (a *ArgsFlags) LoadArgs() (*uo.UnstructuredObject, error) {
 	return args, nil
 }
 
+// collectArgsFromEnv returns all environment variables with the given prefix, using the normalized arg names as keys.
+func collectArgsFromEnv(prefix string, environ []string) map[string]string {
+	ret := map[string]string{}
+	for _, e := range environ {
+		s := strings.SplitN(e, "=", 2)
+		if len(s) != 2 || !strings.HasPrefix(s[0], prefix) {
+			continue
+		}
+		name := strings.TrimPrefix(s[0], prefix)
+		if name == "" {
+			continue
+		}
+		name = strings.ToLower(strings.ReplaceAll(name, "__", "."))
+		ret[name] = s[1]
+	}
+	return ret
+}
+
 type TargetFlagsBase struct {
 	Target             string `group:"project" short:"t" help:"Target name to run command for. Target must exist in .kluctl.yaml."`
 	TargetNameOverride string `group:"project" short:"T" help:"Overrides the target name. If -t is used at the same time, then the target will be looked up based on -t <name> and then renamed to the value of -T. If no target is specified via -t, then the no-name target is renamed to the value of -T."`
@@ -69,15 +111,85 @@ type TargetFlagsBase struct {
 
 type TargetFlags struct {
 	TargetFlagsBase
-	Context string `group:"project" help:"Overrides the context name specified in the target. If the selected target does not specify a context or the no-name target is used, --context will override the currently active context."`
+	Context             []string `group:"project" help:"Overrides the context name specified in the target. If the selected target does not specify a context or the no-name target is used, --context will override the currently active context. Can be specified multiple times to run the command for the same target on multiple clusters, once per context."`
+	AllContextsMatching string   `group:"project" help:"Run the command for the same target on all kubeconfig contexts matching the given regex, once per context. Can not be combined with --context."`
+	ContextsParallelism int      `group:"project" help:"Maximum number of contexts to run the command for in parallel when multiple contexts are selected via --context or --all-contexts-matching." default:"1"`
 }
 
 type KubeconfigFlags struct {
-	Kubeconfig ExistingFileType `group:"project" help:"Overrides the kubeconfig to use."`
+	Kubeconfig  ExistingFileType `group:"project" help:"Overrides the kubeconfig to use."`
+	K8sCaFile   ExistingFileType `group:"project" help:"Overrides the CA bundle used to verify the Kubernetes API server certificate, e.g. when connecting through a TLS intercepting proxy. Takes precedence over the CA configured in the kubeconfig."`
+	K8sProxyUrl string           `group:"project" help:"Overrides the proxy used to connect to the Kubernetes API server, e.g. 'http://proxy.example.com:3128'. Takes precedence over the proxy configured in the kubeconfig."`
+
+	As      string   `group:"project" help:"Username to impersonate for all Kubernetes API calls. Can also be a service account in the form 'system:serviceaccount:<namespace>:<name>'."`
+	AsGroup []string `group:"project" help:"Group to impersonate for all Kubernetes API calls. Can be specified multiple times."`
+	AsUid   string   `group:"project" help:"UID to impersonate for all Kubernetes API calls."`
+
+	KubeApiQps   float32 `group:"project" help:"Maximum queries per second of the client-side rate limiter used for Kubernetes API calls. Kluctl uses up to 16 clients in parallel, each with its own rate limiter. Defaults to 10."`
+	KubeApiBurst int     `group:"project" help:"Maximum burst of the client-side rate limiter used for Kubernetes API calls. Defaults to 20."`
+}
+
+// kubeApiQpsWarnThreshold and kubeApiBurstWarnThreshold are the values above which a warning is printed, as the
+// configured rate limits are multiplied by the number of parallel clients
+const kubeApiQpsWarnThreshold = 50
+const kubeApiBurstWarnThreshold = 100
+
+// ApplyToRestConfig injects --k8s-ca-file, --k8s-proxy-url, the impersonation flags and the rate limiting flags into
+// the given rest.Config, overriding the values loaded from the kubeconfig
+func (args *KubeconfigFlags) ApplyToRestConfig(restConfig *rest.Config) error {
+	if args.KubeApiQps < 0 {
+		return fmt.Errorf("--kube-api-qps must not be negative")
+	}
+	if args.KubeApiBurst < 0 {
+		return fmt.Errorf("--kube-api-burst must not be negative")
+	}
+	if args.KubeApiQps != 0 {
+		restConfig.QPS = args.KubeApiQps
+	}
+	if args.KubeApiBurst != 0 {
+		restConfig.Burst = args.KubeApiBurst
+	}
+	if args.K8sCaFile != "" {
+		restConfig.TLSClientConfig.CAFile = args.K8sCaFile.String()
+		restConfig.TLSClientConfig.CAData = nil
+		restConfig.TLSClientConfig.Insecure = false
+	}
+	if args.K8sProxyUrl != "" {
+		u, err := url.Parse(args.K8sProxyUrl)
+		if err != nil {
+			return fmt.Errorf("invalid --k8s-proxy-url: %w", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid --k8s-proxy-url '%s', expected a url in the form 'http://host:port'", args.K8sProxyUrl)
+		}
+		restConfig.Proxy = http.ProxyURL(u)
+	}
+	if args.As != "" || len(args.AsGroup) != 0 || args.AsUid != "" {
+		if args.As == "" {
+			return fmt.Errorf("--as-group and --as-uid can only be used together with --as")
+		}
+		restConfig.Impersonate = rest.ImpersonationConfig{
+			UserName: args.As,
+			Groups:   args.AsGroup,
+			UID:      args.AsUid,
+		}
+	}
+	return nil
+}
+
+// WarnRateLimits prints a warning if --kube-api-qps or --kube-api-burst are set to values that might overload the
+// Kubernetes API server, which is especially problematic on shared clusters
+func (args *KubeconfigFlags) WarnRateLimits(ctx context.Context) {
+	if args.KubeApiQps > kubeApiQpsWarnThreshold || args.KubeApiBurst > kubeApiBurstWarnThreshold {
+		status.Warningf(ctx, "--kube-api-qps=%v and --kube-api-burst=%d apply to each of the parallel Kubernetes clients. High values might overload the Kubernetes API server, especially on shared clusters.", args.KubeApiQps, args.KubeApiBurst)
+	}
 }
 
 type CommandResultReadOnlyFlags struct {
+	CommandResultStore     string `group:"results" help:"Specify where command results are stored. Can either be 'secrets' to store them as Kubernetes secrets in the cluster, 'fs' to store them in the local directory specified via --command-result-path or 'oci' to push them as OCI artifacts into the repository specified via --command-result-oci-url." default:"secrets"`
 	CommandResultNamespace string `group:"results" help:"Override the namespace to be used when writing command results." default:"kluctl-results"`
+	CommandResultPath      string `group:"results" help:"Specify the directory to be used when --command-result-store=fs is used."`
+	CommandResultOciUrl    string `group:"results" help:"Specify the OCI repository to be used when --command-result-store=oci is used, in the format 'oci://<registry>/<repo>'."`
 }
 
 type CommandResultWriteFlags struct {
@@ -85,6 +197,9 @@ type CommandResultWriteFlags struct {
 	ForceWriteCommandResult  bool `group:"results" help:"Force writing of command results, even if the command is run in dry-run mode."`
 	KeepCommandResultsCount  int  `group:"results" help:"Configure how many old command results to keep." default:"5"`
 	KeepValidateResultsCount int  `group:"results" help:"Configure how many old validate results to keep." default:"2"`
+	CommandResultBestEffort  bool `group:"results" help:"Don't fail the command if writing the command result into the result store fails, e.g. because the OCI registry is unreachable. A warning is printed instead."`
+
+	CommandResultOutput []string `group:"results" help:"Write the command result in the same structure as it is stored in the cluster, in the format 'format=path'. Format can either be 'json' or 'yaml'. If the path is omitted or '-', the result is written to stdout. Can be specified multiple times. This works independently of --write-command-result."`
 }
 
 type CommandResultFlags struct {
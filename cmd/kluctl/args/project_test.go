@@ -0,0 +1,115 @@
+package args
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCollectArgsFromEnv(t *testing.T) {
+	args := collectArgsFromEnv("MY_ARGS_", []string{
+		"MY_ARGS_FOO=1",
+		"MY_ARGS_NESTED__VALUE=a=b",
+		"MY_ARGS_=ignored",
+		"OTHER_FOO=2",
+	})
+	assert.Equal(t, map[string]string{
+		"foo":          "1",
+		"nested.value": "a=b",
+	}, args)
+}
+
+func TestArgsFromEnvPrecedence(t *testing.T) {
+	t.Setenv("TEST_ARGS_A", "env")
+	t.Setenv("TEST_ARGS_B", "env")
+	t.Setenv("TEST_ARGS_C", "env")
+	t.Setenv("TEST_ARGS_NESTED__FLAG", "true")
+
+	f := filepath.Join(t.TempDir(), "args.yaml")
+	assert.NoError(t, os.WriteFile(f, []byte("c: file\n"), 0o600))
+
+	a := ArgsFlags{
+		Arg:          []string{"b=inline", "c=inline"},
+		ArgsFromFile: []string{f},
+		ArgsFromEnv:  []string{"TEST_ARGS_"},
+	}
+	args, err := a.LoadArgs()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"a": "env",
+		"b": "inline",
+		"c": "file",
+		"nested": map[string]any{
+			"flag": true,
+		},
+	}, args.Object)
+}
+
+func TestKubeApiRateLimits(t *testing.T) {
+	restConfig := &rest.Config{}
+	assert.NoError(t, (&KubeconfigFlags{}).ApplyToRestConfig(restConfig))
+	assert.Equal(t, float32(0), restConfig.QPS)
+	assert.Equal(t, 0, restConfig.Burst)
+
+	assert.NoError(t, (&KubeconfigFlags{KubeApiQps: 50, KubeApiBurst: 100}).ApplyToRestConfig(restConfig))
+	assert.Equal(t, float32(50), restConfig.QPS)
+	assert.Equal(t, 100, restConfig.Burst)
+
+	assert.ErrorContains(t, (&KubeconfigFlags{KubeApiQps: -1}).ApplyToRestConfig(restConfig), "--kube-api-qps must not be negative")
+	assert.ErrorContains(t, (&KubeconfigFlags{KubeApiBurst: -1}).ApplyToRestConfig(restConfig), "--kube-api-burst must not be negative")
+}
+
+func TestKubeconfigCaFileAndProxy(t *testing.T) {
+	restConfig := &rest.Config{
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData:   []byte("from-kubeconfig"),
+			Insecure: true,
+		},
+	}
+	assert.NoError(t, (&KubeconfigFlags{}).ApplyToRestConfig(restConfig))
+	assert.Equal(t, []byte("from-kubeconfig"), restConfig.TLSClientConfig.CAData)
+	assert.True(t, restConfig.TLSClientConfig.Insecure)
+	assert.Nil(t, restConfig.Proxy)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, (&KubeconfigFlags{
+		K8sCaFile:   ExistingFileType(caFile),
+		K8sProxyUrl: "http://proxy.example.com:3128",
+	}).ApplyToRestConfig(restConfig))
+	assert.Equal(t, caFile, restConfig.TLSClientConfig.CAFile)
+	assert.Nil(t, restConfig.TLSClientConfig.CAData)
+	assert.False(t, restConfig.TLSClientConfig.Insecure)
+
+	if assert.NotNil(t, restConfig.Proxy) {
+		req, err := http.NewRequest(http.MethodGet, "https://kubernetes.example.com", nil)
+		assert.NoError(t, err)
+		u, err := restConfig.Proxy(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "http://proxy.example.com:3128", u.String())
+	}
+
+	assert.ErrorContains(t, (&KubeconfigFlags{K8sProxyUrl: "proxy.example.com"}).ApplyToRestConfig(restConfig), "invalid --k8s-proxy-url 'proxy.example.com'")
+}
+
+func TestKubeconfigImpersonation(t *testing.T) {
+	restConfig := &rest.Config{}
+	assert.NoError(t, (&KubeconfigFlags{}).ApplyToRestConfig(restConfig))
+	assert.Equal(t, rest.ImpersonationConfig{}, restConfig.Impersonate)
+
+	assert.NoError(t, (&KubeconfigFlags{
+		As:      "system:serviceaccount:ns:sa",
+		AsGroup: []string{"g1", "g2"},
+		AsUid:   "uid",
+	}).ApplyToRestConfig(restConfig))
+	assert.Equal(t, rest.ImpersonationConfig{
+		UserName: "system:serviceaccount:ns:sa",
+		Groups:   []string{"g1", "g2"},
+		UID:      "uid",
+	}, restConfig.Impersonate)
+
+	assert.ErrorContains(t, (&KubeconfigFlags{AsGroup: []string{"g1"}}).ApplyToRestConfig(&rest.Config{}), "--as-group and --as-uid can only be used together with --as")
+	assert.ErrorContains(t, (&KubeconfigFlags{AsUid: "uid"}).ApplyToRestConfig(&rest.Config{}), "--as-group and --as-uid can only be used together with --as")
+}
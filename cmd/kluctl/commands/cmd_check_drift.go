@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+)
+
+type checkDriftCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.InclusionFlags
+	args.ImageFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.IgnoreFlags
+	args.OutputFormatFlags
+	args.RenderOutputDirFlags
+
+	Discriminator string `group:"misc" help:"Override the target discriminator."`
+}
+
+func (cmd *checkDriftCmd) Help() string {
+	return `Compares deployed objects against the locally rendered target and reports objects that were
+modified on the cluster since they were last deployed.
+Objects are only considered drifted when the rendered desired state did not change since the
+last deployment, which is determined by the 'kluctl.io/rendered-checksum' annotation. Fields
+which are only present on the cluster (e.g. defaults set by the api server) are ignored.
+The command fails if any drift is detected.`
+}
+
+func (cmd *checkDriftCmd) Run(ctx context.Context) error {
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		imageFlags:           cmd.ImageFlags,
+		inclusionFlags:       cmd.InclusionFlags,
+		gitCredentials:       cmd.GitCredentials,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		discriminator:        cmd.Discriminator,
+		outputPaths:          outputFilePaths(cmd.OutputFormat, true),
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		cmd2 := commands.NewCheckDriftCommand(cmdCtx.targetCtx)
+		cmd2.IgnoreTags = cmd.IgnoreTags
+		cmd2.IgnoreLabels = cmd.IgnoreLabels
+		cmd2.IgnoreAnnotations = cmd.IgnoreAnnotations
+		cmd2.IgnoreKluctlMetadata = cmd.IgnoreKluctlMetadata
+		result := cmd2.Run()
+		err := outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, false)
+		if err != nil {
+			return err
+		}
+		if len(result.Errors) != 0 {
+			return fmt.Errorf("command failed")
+		}
+		if len(result.Objects) != 0 {
+			return fmt.Errorf("drift detected for %d objects", len(result.Objects))
+		}
+		return nil
+	})
+}
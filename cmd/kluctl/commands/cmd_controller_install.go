@@ -34,6 +34,11 @@ func (cmd *controllerInstallCmd) Run(ctx context.Context) error {
 		deployArgs = append(deployArgs, fmt.Sprintf("kluctl_version=%s", cmd.KluctlVersion))
 	}
 
+	var contexts []string
+	if cmd.Context != "" {
+		contexts = append(contexts, cmd.Context)
+	}
+
 	cmd2 := deployCmd{
 		ProjectFlags: args.ProjectFlags{
 			ProjectDir: args.ProjectDir{
@@ -43,7 +48,7 @@ func (cmd *controllerInstallCmd) Run(ctx context.Context) error {
 		},
 		KubeconfigFlags: cmd.KubeconfigFlags,
 		TargetFlags: args.TargetFlags{
-			Context: cmd.Context,
+			Context: contexts,
 		},
 		ArgsFlags: args.ArgsFlags{
 			Arg: deployArgs,
@@ -52,6 +52,8 @@ func (cmd *deleteCmd) Run(ctx context.Context) error {
 		dryRunArgs:           &cmd.DryRunFlags,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
 		commandResultFlags:   &cmd.CommandResultFlags,
+		interactive:          !cmd.Yes && !cmd.DryRun,
+		outputPaths:          append(outputFilePaths(cmd.OutputFormat, true), outputFilePaths(cmd.CommandResultOutput, true)...),
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
 		cmd2 := commands.NewDeleteCommand(cmd.Discriminator, cmdCtx.targetCtx, nil, !cmd.NoWait)
@@ -6,10 +6,20 @@ import (
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
 	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
 	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	"github.com/kluctl/kluctl/v2/pkg/results"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/mattn/go-isatty"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"os"
+	"time"
 )
 
+const metricsPushTimeout = 30 * time.Second
+
 type deployCmd struct {
 	args.ProjectFlags
 	args.KubeconfigFlags
@@ -25,14 +35,40 @@ type deployCmd struct {
 	args.ForceApplyFlags
 	args.ReplaceOnErrorFlags
 	args.AbortOnErrorFlags
+	args.ApplyFlags
 	args.HookFlags
+	args.HelmValuesDiffFlags
+	args.ApiDeprecationFlags
+	args.PruneInclusionFlags
 	args.OutputFormatFlags
 	args.RenderOutputDirFlags
 	args.CommandResultFlags
+	args.ClusterFixtureFlags
 
 	DeployExtraFlags
 
-	Discriminator string `group:"misc" help:"Override the target discriminator."`
+	Discriminator string        `group:"misc" help:"Override the target discriminator."`
+	CanaryPercent int           `group:"misc" help:"Apply a deterministic subset of the given percentage of objects first and wait for them to become ready. The remaining objects are only applied after confirmation, or automatically if --yes is passed and the canary apply succeeded. Must be between 1 and 99."`
+	Wait          bool          `group:"misc" help:"After applying the objects of a deployment item, wait for all applied Deployments, StatefulSets and DaemonSets to become ready. This happens before post-deploy hooks are run and before following barriers are passed. Ignored in dry-run mode."`
+	WaitTimeout   time.Duration `group:"misc" help:"Maximum time to wait for each workload when --wait is used. Timeouts are recorded as errors. If not specified, --readiness-timeout is used."`
+	ConfirmEach   bool          `group:"misc" help:"Interactively confirm each deployment item before it gets applied, with the option to apply it, skip it or to abort the deployment. Deployment items are applied one after another in this mode. Requires an interactive terminal."`
+
+	PinDigests              bool `group:"misc" help:"Resolve all container images to their current digests (using the registry credentials) and rewrite the images to reference these digests before applying. The resolved digests are recorded in the command result."`
+	PinDigestsAllowFailures bool `group:"misc" help:"Treat failures to resolve digests as warnings instead of errors when --pin-digests is used. Images that could not be resolved are applied unmodified."`
+
+	MetricsPushGateway string `group:"misc" help:"Push Prometheus metrics about the deployment (duration, applied objects, retries, resolved conflicts and hook waits) to the given Pushgateway URL after deploying. Failing to push metrics results in a warning."`
+	MetricsJob         string `group:"misc" help:"The job name used when pushing metrics via --metrics-push-gateway." default:"kluctl"`
+
+	NotifyWebhook            string   `group:"misc" help:"Send a POST request with a JSON summary (target, status, object counts, errors and warnings) of the deployment to the given URL after deploying. Failing to notify results in a warning. Dry-run notifications are marked via 'dryRun: true' and a '[DRY-RUN]' prefix in 'text'."`
+	NotifyWebhookTemplate    string   `group:"misc" help:"Path to a Jinja2 template that is rendered with the summary fields as variables and then sent as the body of the webhook request, e.g. to match the format expected by Slack or MS Teams."`
+	NotifyWebhookHeader      []string `group:"misc" help:"Add a header to the webhook request, in the format 'Name: value'. Can be specified multiple times, e.g. for authentication."`
+	NotifyWebhookContentType string   `group:"misc" help:"Content type of the webhook request. Should be changed when --notify-webhook-template renders something else than JSON." default:"application/json"`
+
+	Lock        bool          `group:"misc" help:"Acquire a cluster-side lock (a Lease in the command result namespace) for the target before deploying, preventing concurrent deployments of the same target. Locking is skipped in dry-run mode unless --lock-dry-run is passed."`
+	LockTimeout time.Duration `group:"misc" help:"Maximum time to wait for the lock when --lock is used. If the lock can't be acquired in time, the command fails and reports the current holder of the lock. Fails immediately if not specified."`
+	LockDryRun  bool          `group:"misc" help:"Also acquire the lock in dry-run mode when --lock is used."`
+
+	ResumeFromResult string `group:"misc" help:"Resume a failed deployment by skipping all objects that were successfully applied by the deployment with the given command result id and did not change since then. Hooks are only skipped if the whole deployment item was applied successfully."`
 
 	internal bool
 }
@@ -50,6 +86,15 @@ It will also output a list of prunable objects (without actually deleting them).
 }
 
 func (cmd *deployCmd) Run(ctx context.Context) error {
+	if cmd.ReplayCluster != "" {
+		// replayed clusters can only serve dry-run requests
+		cmd.DryRun = true
+	}
+	err := cmd.checkArgs()
+	if err != nil {
+		return err
+	}
+
 	ptArgs := projectTargetCommandArgs{
 		projectFlags:         cmd.ProjectFlags,
 		kubeconfigFlags:      cmd.KubeconfigFlags,
@@ -65,40 +110,223 @@ func (cmd *deployCmd) Run(ctx context.Context) error {
 		commandResultFlags:   &cmd.CommandResultFlags,
 		internalDeploy:       cmd.internal,
 		discriminator:        cmd.Discriminator,
+		clusterFixtureFlags:  cmd.ClusterFixtureFlags,
+		interactive:          (!cmd.Yes || cmd.ConfirmEach) && !cmd.DryRun,
+		outputPaths:          append(outputFilePaths(cmd.OutputFormat, true), outputFilePaths(cmd.CommandResultOutput, true)...),
+	}
+	if cmd.HelmValuesDiff {
+		ptArgs.commandResultReadOnlyFlags = &cmd.CommandResultReadOnlyFlags
 	}
-	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+	err = withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
 		return cmd.runCmdDeploy(ctx, cmdCtx)
 	})
+	if cmd.NotifyWebhook != "" {
+		// notify about failures that happened before a notification could be sent, e.g. because the target or vars
+		// could not be loaded
+		if err2 := withoutWebhookNotifiedErrors(err); err2 != nil {
+			target := cmd.Target
+			if cmd.TargetNameOverride != "" {
+				target = cmd.TargetNameOverride
+			}
+			notifyWebhook(ctx, nil, buildWebhookFailureNotification(target, cmd.DryRun, err2), cmd.webhookOptions())
+		}
+	}
+	return err
+}
+
+func (cmd *deployCmd) checkArgs() error {
+	if cmd.Wait && cmd.NoWait {
+		return fmt.Errorf("--wait and --no-wait can't be used together")
+	}
+	if cmd.ConfirmEach && !cmd.DryRun && !isatty.IsTerminal(os.Stdin.Fd()) {
+		return fmt.Errorf("--confirm-each requires an interactive terminal")
+	}
+	if cmd.AbortAfter < 0 {
+		return fmt.Errorf("--abort-after must not be negative")
+	}
+	// 0 disables the canary, while 100 would apply all objects twice
+	if cmd.CanaryPercent != 0 && (cmd.CanaryPercent < 1 || cmd.CanaryPercent > 99) {
+		return fmt.Errorf("--canary-percent must be between 1 and 99")
+	}
+	return nil
+}
+
+func (cmd *deployCmd) webhookOptions() webhookOptions {
+	return webhookOptions{
+		url:          cmd.NotifyWebhook,
+		templateFile: cmd.NotifyWebhookTemplate,
+		headers:      cmd.NotifyWebhookHeader,
+		contentType:  cmd.NotifyWebhookContentType,
+	}
 }
 
 func (cmd *deployCmd) runCmdDeploy(ctx context.Context, cmdCtx *commandCtx) error {
 	status.Trace(ctx, "enter runCmdDeploy")
 	defer status.Trace(ctx, "leave runCmdDeploy")
 
-	cmd2 := commands.NewDeployCommand(cmdCtx.targetCtx)
-	cmd2.ForceApply = cmd.ForceApply
-	cmd2.ReplaceOnError = cmd.ReplaceOnError
-	cmd2.ForceReplaceOnError = cmd.ForceReplaceOnError
-	cmd2.AbortOnError = cmd.AbortOnError
-	cmd2.ReadinessTimeout = cmd.ReadinessTimeout
-	cmd2.NoWait = cmd.NoWait
-	cmd2.Prune = cmd.Prune
-	cmd2.WaitPrune = !cmd.NoWait
+	pruneInclusion, err := cmd.ParsePruneInclusionFromArgs()
+	if err != nil {
+		return err
+	}
 
-	cb := func(diffResult *result.CommandResult) error {
-		return cmd.diffResultCb(ctx, cmdCtx, diffResult)
+	opts := commands.DeployOptions{
+		ForceApply:                 cmd.ForceApply,
+		ReplaceOnError:             cmd.ReplaceOnError,
+		ForceReplaceOnError:        cmd.ForceReplaceOnError || len(cmd.ForceReplaceOnErrorKind) != 0,
+		ForceReplaceOnErrorKinds:   cmd.ForceReplaceOnErrorKind,
+		ReplacePreserveMetadata:    cmd.ReplacePreserveMetadata,
+		PinDigests:                 cmd.PinDigests,
+		PinDigestsAllowFailures:    cmd.PinDigestsAllowFailures,
+		AbortOnError:               cmd.AbortOnError,
+		AbortAfterErrors:           cmd.AbortAfter,
+		ReadinessTimeout:           cmd.ReadinessTimeout,
+		NoWait:                     cmd.NoWait,
+		Prune:                      cmd.Prune,
+		WaitPrune:                  !cmd.NoWait,
+		PruneInclusion:             pruneInclusion,
+		PruneAllowKinds:            cmd.PruneAllowlist,
+		PruneDenyKinds:             cmd.GetPruneDenylist(),
+		CanaryPercent:              cmd.CanaryPercent,
+		WaitRollout:                cmd.Wait,
+		WaitRolloutTimeout:         cmd.WaitTimeout,
+		ApplyParallelism:           cmd.ApplyParallelism,
+		ApplyTimeout:               cmd.ApplyTimeout,
+		BarrierTimeout:             cmd.BarrierTimeout,
+		HookPollInterval:           cmd.HookPollInterval,
+		HookPollMaxInterval:        cmd.HookPollMaxInterval,
+		RetryBackoff:               cmd.ApplyRetryBackoff,
+		RetryMaxBackoff:            cmd.ApplyRetryMaxBackoff,
+		ApplyLabelSelector:         cmd.ApplyLabelSelector,
+		FailOnApiDeprecation:       cmd.FailOnApiDeprecation || len(cmd.FailOnApiDeprecationGroup) != 0,
+		FailOnApiDeprecationGroups: cmd.FailOnApiDeprecationGroup,
+		WarningsAsErrors:           cmd.WarningsAsErrors || len(cmd.WarningsAsErrorsPattern) != 0,
+		WarningsAsErrorsPatterns:   cmd.WarningsAsErrorsPattern,
 	}
-	if cmd.Yes || cmd.DryRun {
-		cb = nil
+	if cmd.ConfirmEach && !cmd.DryRun {
+		opts.ConfirmDeploymentItem = func(name string, summary string) (utils2.ConfirmDecision, error) {
+			return confirmDeploymentItem(ctx, name, summary)
+		}
+	}
+	if !cmd.Yes && !cmd.DryRun {
+		opts.DiffResultCallback = func(diffResult *result.CommandResult) error {
+			return cmd.diffResultCb(ctx, cmdCtx, diffResult)
+		}
+		opts.CanaryResultCallback = func(canaryResult *result.CommandResult) error {
+			return cmd.canaryResultCb(ctx, cmdCtx, canaryResult)
+		}
+	}
+
+	if cmd.ResumeFromResult != "" {
+		if cmdCtx.resultStore == nil {
+			return fmt.Errorf("--resume-from-result requires a result store")
+		}
+		opts.ResumeFromResult, err = cmdCtx.resultStore.GetCommandResult(results.GetCommandResultOptions{Id: cmd.ResumeFromResult})
+		if err != nil {
+			return fmt.Errorf("failed to load command result %s: %w", cmd.ResumeFromResult, err)
+		}
+		if opts.ResumeFromResult == nil {
+			return fmt.Errorf("command result %s not found", cmd.ResumeFromResult)
+		}
 	}
 
-	result := cmd2.Run(cb)
-	err := outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
+	var metricsRegistry *prometheus.Registry
+	if cmd.MetricsPushGateway != "" {
+		metricsRegistry = prometheus.NewRegistry()
+		opts.Metrics, err = utils2.NewApplyMetrics(metricsRegistry)
+		if err != nil {
+			return err
+		}
+	}
+
+	var lockLost func() error
+	if cmd.Lock {
+		if cmd.DryRun && !cmd.LockDryRun {
+			status.Info(ctx, "Skipping locking in dry-run mode")
+		} else {
+			release, lost, err := acquireDeployLock(ctx, cmdCtx, cmd.CommandResultNamespace, cmd.LockTimeout)
+			if err != nil {
+				return err
+			}
+			defer release()
+			lockLost = lost
+		}
+	}
+
+	result := commands.Deploy(cmdCtx.targetCtx, opts)
+	addLockLostError(result, lockLost)
+	if metricsRegistry != nil {
+		err = pushMetrics(ctx, metricsRegistry, cmd.MetricsPushGateway, cmd.MetricsJob)
+		if err != nil {
+			status.Warningf(ctx, "Failed to push metrics to %s: %s", cmd.MetricsPushGateway, err.Error())
+		}
+	}
+	notified := false
+	if cmd.NotifyWebhook != "" {
+		// the result id is otherwise only assigned by outputCommandResult
+		result.Id = cmdCtx.resultId
+		notifyWebhook(ctx, cmdCtx.targetCtx.DeploymentProject.VarsCtx.J2, buildWebhookNotification(result, cmd.DryRun), cmd.webhookOptions())
+		notified = true
+	}
+	if cmd.HelmValuesDiff {
+		err = addHelmValuesChanges(ctx, cmdCtx, result)
+		if err != nil {
+			return markWebhookNotified(err, notified)
+		}
+	}
+	err = outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
 	if err != nil {
-		return err
+		return markWebhookNotified(err, notified)
 	}
 	if len(result.Errors) != 0 {
-		return fmt.Errorf("command failed")
+		return markWebhookNotified(fmt.Errorf("command failed"), notified)
+	}
+	return nil
+}
+
+// pushMetrics pushes the given metrics to the Pushgateway. The push is limited by metricsPushTimeout, so that an
+// unreachable gateway can not block the command after the deployment has already finished.
+func pushMetrics(ctx context.Context, registry *prometheus.Registry, url string, job string) error {
+	ctx, cancel := context.WithTimeout(ctx, metricsPushTimeout)
+	defer cancel()
+	return push.New(url, job).Gatherer(registry).PushContext(ctx)
+}
+
+func confirmDeploymentItem(ctx context.Context, name string, summary string) (utils2.ConfirmDecision, error) {
+	var choices utils.OrderedMap[string, string]
+	choices.Set("y", "Apply")
+	choices.Set("s", "Skip")
+	choices.Set("a", "Abort")
+
+	response, err := prompts.AskForChoice(ctx, fmt.Sprintf("Deployment item %s contains %s. Do you want to apply it?", name, summary), &choices)
+	if err != nil {
+		return utils2.ConfirmAbort, err
+	}
+	switch response {
+	case "s":
+		return utils2.ConfirmSkip, nil
+	case "a":
+		return utils2.ConfirmAbort, nil
+	default:
+		return utils2.ConfirmProceed, nil
+	}
+}
+
+func (cmd *deployCmd) canaryResultCb(ctx context.Context, cmdCtx *commandCtx, canaryResult *result.CommandResult) error {
+	flags := cmd.OutputFormatFlags
+	flags.OutputFormat = nil // use default output format
+
+	err := outputCommandResult(ctx, cmdCtx, flags, canaryResult, false)
+	if err != nil {
+		return err
+	}
+	if len(canaryResult.Errors) != 0 {
+		if !prompts.AskForConfirmation(ctx, "The canary apply resulted in errors, do you still want to apply the remaining objects?") {
+			return fmt.Errorf("aborted")
+		}
+	} else {
+		if !prompts.AskForConfirmation(ctx, "The canary apply succeeded, do you want to apply the remaining objects?") {
+			return fmt.Errorf("aborted")
+		}
 	}
 	return nil
 }
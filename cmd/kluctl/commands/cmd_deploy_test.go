@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeployCheckArgsCanaryPercent(t *testing.T) {
+	testCases := []struct {
+		percent int
+		err     bool
+	}{
+		{percent: 0},
+		{percent: 1},
+		{percent: 99},
+		{percent: -1, err: true},
+		{percent: 100, err: true},
+		{percent: 150, err: true},
+	}
+	for _, tc := range testCases {
+		cmd := deployCmd{CanaryPercent: tc.percent}
+		err := cmd.checkArgs()
+		if tc.err {
+			assert.EqualError(t, err, "--canary-percent must be between 1 and 99", "percent=%d", tc.percent)
+		} else {
+			assert.NoError(t, err, "percent=%d", tc.percent)
+		}
+	}
+}
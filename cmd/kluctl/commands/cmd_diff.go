@@ -20,8 +20,12 @@ type diffCmd struct {
 	args.ForceApplyFlags
 	args.ReplaceOnErrorFlags
 	args.IgnoreFlags
+	args.HelmValuesDiffFlags
+	args.ApiDeprecationFlags
 	args.OutputFormatFlags
 	args.RenderOutputDirFlags
+	args.CommandResultReadOnlyFlags
+	args.ClusterFixtureFlags
 
 	Discriminator string `group:"misc" help:"Override the target discriminator."`
 }
@@ -46,17 +50,34 @@ func (cmd *diffCmd) Run(ctx context.Context) error {
 		registryCredentials:  cmd.RegistryCredentials,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
 		discriminator:        cmd.Discriminator,
+		clusterFixtureFlags:  cmd.ClusterFixtureFlags,
+		outputPaths:          outputFilePaths(cmd.OutputFormat, true),
+	}
+	if cmd.HelmValuesDiff {
+		ptArgs.commandResultReadOnlyFlags = &cmd.CommandResultReadOnlyFlags
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
 		cmd2 := commands.NewDiffCommand(cmdCtx.targetCtx)
 		cmd2.ForceApply = cmd.ForceApply
 		cmd2.ReplaceOnError = cmd.ReplaceOnError
-		cmd2.ForceReplaceOnError = cmd.ForceReplaceOnError
+		cmd2.ForceReplaceOnError = cmd.ForceReplaceOnError || len(cmd.ForceReplaceOnErrorKind) != 0
+		cmd2.ForceReplaceOnErrorKinds = cmd.ForceReplaceOnErrorKind
+		cmd2.ReplacePreserveMetadata = cmd.ReplacePreserveMetadata
 		cmd2.IgnoreTags = cmd.IgnoreTags
 		cmd2.IgnoreLabels = cmd.IgnoreLabels
 		cmd2.IgnoreAnnotations = cmd.IgnoreAnnotations
 		cmd2.IgnoreKluctlMetadata = cmd.IgnoreKluctlMetadata
+		cmd2.FailOnApiDeprecation = cmd.FailOnApiDeprecation || len(cmd.FailOnApiDeprecationGroup) != 0
+		cmd2.FailOnApiDeprecationGroups = cmd.FailOnApiDeprecationGroup
+		cmd2.WarningsAsErrors = cmd.WarningsAsErrors || len(cmd.WarningsAsErrorsPattern) != 0
+		cmd2.WarningsAsErrorsPatterns = cmd.WarningsAsErrorsPattern
 		result := cmd2.Run()
+		if cmd.HelmValuesDiff {
+			err := addHelmValuesChanges(ctx, cmdCtx, result)
+			if err != nil {
+				return err
+			}
+		}
 		err := outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, false)
 		if err != nil {
 			return err
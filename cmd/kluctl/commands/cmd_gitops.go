@@ -487,6 +487,13 @@ func (g *gitopsCmdHelper) buildOverridePatch(ctx context.Context, kdIn *v1beta1.
 	handleFlag("force-replace-on-error", func(f *flag.Flag) {
 		kd.Spec.ForceReplaceOnError = g.overridableArgs.ForceReplaceOnError
 	})
+	handleFlag("force-replace-on-error-kind", func(f *flag.Flag) {
+		kd.Spec.ForceReplaceOnError = true
+		kd.Spec.ForceReplaceOnErrorKinds = g.overridableArgs.ForceReplaceOnErrorKind
+	})
+	handleFlag("replace-preserve-metadata", func(f *flag.Flag) {
+		kd.Spec.ReplacePreserveMetadata = g.overridableArgs.ReplacePreserveMetadata
+	})
 	handleFlag("abort-on-error", func(f *flag.Flag) {
 		kd.Spec.AbortOnError = g.overridableArgs.AbortOnError
 	})
@@ -512,11 +519,17 @@ func (g *gitopsCmdHelper) buildOverridePatch(ctx context.Context, kdIn *v1beta1.
 		return nil, err
 	}
 	kd.Spec.Images = append(kd.Spec.Images, fis...)
+	if len(g.overridableArgs.ImageFlags.OverrideImage) != 0 {
+		return nil, fmt.Errorf("--override-image is not supported for gitops commands")
+	}
 
 	inc, err := g.overridableArgs.InclusionFlags.ParseInclusionFromArgs()
 	if err != nil {
 		return nil, err
 	}
+	if utils.InclusionOrder(g.overridableArgs.InclusionOrder) == utils.InclusionOrderExcludeInclude {
+		return nil, fmt.Errorf("--inclusion-order=%s is not supported for gitops commands", g.overridableArgs.InclusionOrder)
+	}
 	kd.Spec.IncludeTags = append(kd.Spec.IncludeTags, inc.GetIncludes("tag")...)
 	kd.Spec.ExcludeTags = append(kd.Spec.ExcludeTags, inc.GetExcludes("tag")...)
 	kd.Spec.IncludeDeploymentDirs = append(kd.Spec.IncludeDeploymentDirs, inc.GetIncludes("deploymentItemDir")...)
@@ -60,6 +60,9 @@ func (cmd *helmPullCmd) Run(ctx context.Context) error {
 	} else {
 		gitAuthProvider.RegisterAuthProvider(x, false)
 	}
+	if x := cmd.GitCredentials.BuildCredentialsFileAuthProvider(messageCallbacks); x != nil {
+		gitAuthProvider.RegisterAuthProviderAfterEnv(x)
+	}
 	if x, err := cmd.HelmCredentials.BuildAuthProvider(ctx); err != nil {
 		return err
 	} else {
@@ -71,7 +74,7 @@ func (cmd *helmPullCmd) Run(ctx context.Context) error {
 		ociAuthProvider.RegisterAuthProvider(x, false)
 	}
 
-	gitRp := repocache.NewGitRepoCache(ctx, sshPool, gitAuthProvider, nil, time.Second*60)
+	gitRp := repocache.NewGitRepoCache(ctx, sshPool, gitAuthProvider, nil, time.Second*60, 0)
 	defer gitRp.Clear()
 
 	ociRp := repocache.NewOciRepoCache(ctx, ociAuthProvider, nil, time.Second*60)
@@ -44,6 +44,8 @@ func (cmd *listImagesCmd) Run(ctx context.Context) error {
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
 		offlineKubernetes:    cmd.OfflineKubernetes,
 		kubernetesVersion:    cmd.KubernetesVersion,
+		offlineApiResources:  cmd.OfflineApiResources,
+		outputPaths:          outputFilePaths(cmd.Output, false),
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
 		result := types.FixedImagesConfig{
@@ -45,6 +45,8 @@ func (cmd *pokeImagesCmd) Run(ctx context.Context) error {
 		dryRunArgs:           &cmd.DryRunFlags,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
 		commandResultFlags:   &cmd.CommandResultFlags,
+		interactive:          !cmd.Yes && !cmd.DryRun,
+		outputPaths:          append(outputFilePaths(cmd.OutputFormat, true), outputFilePaths(cmd.CommandResultOutput, true)...),
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
 		if !cmd.Yes && !cmd.DryRun {
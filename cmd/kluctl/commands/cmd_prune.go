@@ -15,6 +15,7 @@ type pruneCmd struct {
 	args.ArgsFlags
 	args.ImageFlags
 	args.InclusionFlags
+	args.PruneInclusionFlags
 	args.GitCredentials
 	args.HelmCredentials
 	args.RegistryCredentials
@@ -50,6 +51,8 @@ func (cmd *pruneCmd) Run(ctx context.Context) error {
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
 		commandResultFlags:   &cmd.CommandResultFlags,
 		discriminator:        cmd.Discriminator,
+		interactive:          !cmd.Yes && !cmd.DryRun,
+		outputPaths:          append(outputFilePaths(cmd.OutputFormat, true), outputFilePaths(cmd.CommandResultOutput, true)...),
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
 		return cmd.runCmdPrune(ctx, cmdCtx)
@@ -57,11 +60,19 @@ func (cmd *pruneCmd) Run(ctx context.Context) error {
 }
 
 func (cmd *pruneCmd) runCmdPrune(ctx context.Context, cmdCtx *commandCtx) error {
+	pruneInclusion, err := cmd.ParsePruneInclusionFromArgs()
+	if err != nil {
+		return err
+	}
+
 	cmd2 := commands.NewPruneCommand(cmdCtx.targetCtx.Target.Discriminator, cmdCtx.targetCtx, true)
+	cmd2.PruneInclusion = pruneInclusion
+	cmd2.PruneAllowKinds = cmd.PruneAllowlist
+	cmd2.PruneDenyKinds = cmd.GetPruneDenylist()
 	result := cmd2.Run(func(refs []k8s2.ObjectRef) error {
 		return confirmDeletion(ctx, refs, cmd.DryRun, cmd.Yes)
 	})
-	err := outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
+	err = outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
 	if err != nil {
 		return err
 	}
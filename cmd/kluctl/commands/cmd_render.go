@@ -22,6 +22,7 @@ type renderCmd struct {
 	args.RegistryCredentials
 	args.RenderOutputDirFlags
 	args.OfflineKubernetesFlags
+	args.ClusterFixtureFlags
 
 	PrintAll bool `group:"misc" help:"Write all rendered manifests to stdout"`
 }
@@ -32,8 +33,11 @@ a temporary directory or a specified directory.`
 }
 
 func (cmd *renderCmd) Run(ctx context.Context) error {
+	// single file render output formats are written to stdout if no output dir is specified
+	renderOutputToStdout := cmd.RenderOutputFormat != "" && cmd.RenderOutputFormat != renderOutputFormatDir && cmd.RenderOutputDir == ""
+
 	isTmp := false
-	if cmd.RenderOutputDir == "" {
+	if cmd.RenderOutputDir == "" && !renderOutputToStdout {
 		p, err := ioutil.TempDir(utils.GetTmpBaseDir(ctx), "rendered-")
 		if err != nil {
 			return err
@@ -55,20 +59,26 @@ func (cmd *renderCmd) Run(ctx context.Context) error {
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
 		offlineKubernetes:    cmd.OfflineKubernetes,
 		kubernetesVersion:    cmd.KubernetesVersion,
+		offlineApiResources:  cmd.OfflineApiResources,
+		clusterFixtureFlags:  cmd.ClusterFixtureFlags,
+		renderOutputToStdout: renderOutputToStdout,
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
 		if cmd.PrintAll {
 			var all []any
-			for _, d := range cmdCtx.targetCtx.DeploymentCollection.Deployments {
-				for _, o := range d.Objects {
-					all = append(all, o)
-				}
+			for _, o := range cmdCtx.targetCtx.DeploymentCollection.FilterRenderedObjects() {
+				all = append(all, o)
 			}
 			if isTmp {
 				defer os.RemoveAll(cmd.RenderOutputDir)
 			}
 			status.Flush(ctx)
 			return yaml.WriteYamlAllStream(getStdout(ctx), all)
+		} else if renderOutputToStdout {
+			status.Flush(ctx)
+			return writeSingleRenderOutputTo(getStdout(ctx), cmdCtx.targetCtx, cmd.RenderOutputFormat)
+		} else if cmdCtx.renderOutputFile != "" {
+			status.Infof(ctx, "Rendered into %s", cmdCtx.renderOutputFile)
 		} else {
 			status.Infof(ctx, "Rendered into %s", cmdCtx.targetCtx.SharedContext.RenderDir)
 		}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"net/url"
+)
+
+type renderVarsCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.OutputFlags
+	args.OfflineKubernetesFlags
+
+	OnlySource       string `group:"misc" help:"Only load the vars source with the given index or type name (e.g. 'vault'). Prior vars sources are not loaded unless --with-prior-sources is passed."`
+	WithPriorSources bool   `group:"misc" help:"When --only-source is used, load all prior vars sources before loading the selected vars source."`
+	NoObfuscate      bool   `group:"misc" help:"Disable obfuscation of sensitive vars and secret-bearing vars source fields"`
+
+	DebugVarsProvenance bool `group:"misc" help:"Track which vars source wrote each final value and output a list of all leaf vars together with their source instead of the rendered vars sources."`
+}
+
+func (cmd *renderVarsCmd) Help() string {
+	return `Loads the vars sources found in the root deployment.yml one by one and outputs the rendered
+vars sources together with the vars that each source contributed. Loading stops at the first
+failing vars source, which is then output with the error message.
+Only the root deployment.yml is loaded, so that vars sources can be debugged even if loading the
+whole deployment project fails.
+With --debug-vars-provenance, a list of all leaf vars together with the vars source that last
+wrote them is output instead, which helps to find out where a final value comes from.`
+}
+
+func (cmd *renderVarsCmd) Run(ctx context.Context) error {
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:        cmd.ProjectFlags,
+		kubeconfigFlags:     cmd.KubeconfigFlags,
+		targetFlags:         cmd.TargetFlags,
+		argsFlags:           cmd.ArgsFlags,
+		gitCredentials:      cmd.GitCredentials,
+		helmCredentials:     cmd.HelmCredentials,
+		registryCredentials: cmd.RegistryCredentials,
+		offlineKubernetes:   cmd.OfflineKubernetes,
+		kubernetesVersion:   cmd.KubernetesVersion,
+		offlineApiResources: cmd.OfflineApiResources,
+		noLoadDeployment:    true,
+		outputPaths:         outputFilePaths(cmd.Output, false),
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		if cmd.WithPriorSources && cmd.OnlySource == "" {
+			return fmt.Errorf("--with-prior-sources can only be used together with --only-source")
+		}
+
+		cmdCtx.targetCtx.SharedContext.VarsLoader.SetTrackProvenance(cmd.DebugVarsProvenance)
+
+		sources, err := cmdCtx.targetCtx.LoadRootProjectVarsSources(cmdCtx.varsCtx, cmd.OnlySource, cmd.WithPriorSources)
+		if err != nil {
+			return err
+		}
+
+		var failed error
+		var result []any
+		for _, s := range sources {
+			if s.Error != "" {
+				failed = fmt.Errorf("vars source %d failed: %s", s.Index, s.Error)
+			}
+			if !cmd.NoObfuscate {
+				obfuscateVarsSource(s.Source)
+			}
+			result = append(result, s)
+		}
+
+		if cmd.DebugVarsProvenance {
+			err = outputYamlResult(ctx, cmd.Output, cmdCtx.targetCtx.SharedContext.VarsLoader.GetProvenance(), false)
+		} else {
+			err = outputYamlResult(ctx, cmd.Output, result, true)
+		}
+		if err != nil {
+			return err
+		}
+		return failed
+	})
+}
+
+// obfuscateVarsSource obfuscates the vars contributed by sensitive vars sources and all fields of the rendered vars
+// source that might contain credentials.
+func obfuscateVarsSource(s *types.VarsSource) {
+	if s.RenderedSensitive && s.RenderedVars != nil {
+		s.RenderedVars = obfuscateVars(s.RenderedVars)
+	}
+	if s.Sensitive != nil && *s.Sensitive && s.Values != nil {
+		s.Values = obfuscateVars(s.Values)
+	}
+
+	obfuscateString := func(x *string) {
+		if *x != "" {
+			*x = "*****"
+		}
+	}
+	if s.Vault != nil && s.Vault.Auth != nil && s.Vault.Auth.AppRole != nil {
+		obfuscateString(&s.Vault.Auth.AppRole.SecretId)
+	}
+	if s.OnePassword != nil {
+		obfuscateString(&s.OnePassword.Token)
+	}
+	if s.Gitlab != nil {
+		obfuscateString(&s.Gitlab.Token)
+	}
+	if s.Http != nil {
+		for k := range s.Http.Headers {
+			s.Http.Headers[k] = "*****"
+		}
+		if s.Http.Body != nil {
+			obfuscateString(s.Http.Body)
+		}
+		if s.Http.Url.User != nil {
+			s.Http.Url.User = url.User("*****")
+		}
+	}
+}
+
+func obfuscateVars(o *uo.UnstructuredObject) *uo.UnstructuredObject {
+	o = o.Clone()
+	_ = o.NewIterator().IterateLeafs(func(it *uo.ObjectIterator) error {
+		return it.SetValue("*****")
+	})
+	return o
+}
@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type resultsCmd struct {
+	List   resultsListCmd   `cmd:"" help:"List command results"`
+	Delete resultsDeleteCmd `cmd:"" help:"Delete the objects applied by a command result"`
+}
+
+type resultsListCmd struct {
+	args.KubeconfigFlags
+	args.CommandResultReadOnlyFlags
+	args.OutputFlags
+
+	Context           string `group:"misc" help:"Override the context to use."`
+	AllNamespaces     bool   `group:"misc" short:"A" help:"List command results from all namespaces instead of only the namespace specified via --command-result-namespace. This requires permissions to list secrets cluster-wide."`
+	NamespaceSelector string `group:"misc" help:"List command results from all namespaces matching this label selector instead of only the namespace specified via --command-result-namespace."`
+}
+
+func (cmd *resultsListCmd) Help() string {
+	return `Lists the summaries of all command results found in the cluster.
+By default, only the namespace specified via --command-result-namespace is read. Pass --all-namespaces or
+--namespace-selector to aggregate command results that were written into multiple namespaces, e.g. when
+each team writes results into its own namespace.`
+}
+
+func (cmd *resultsListCmd) Run(ctx context.Context) error {
+	if cmd.AllNamespaces && cmd.NamespaceSelector != "" {
+		return fmt.Errorf("--all-namespaces and --namespace-selector can not be used together")
+	}
+
+	nonClusterStore, err := buildNonClusterResultStore(ctx, &cmd.CommandResultReadOnlyFlags, nil, false, 0, 0)
+	if err != nil {
+		return err
+	}
+	if nonClusterStore != nil {
+		return cmd.listSummaries(ctx, nonClusterStore)
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = cmd.Kubeconfig.String()
+	configOverrides := &clientcmd.ConfigOverrides{
+		CurrentContext: cmd.Context,
+	}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	err = cmd.KubeconfigFlags.ApplyToRestConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	err = k8s.CheckImpersonation(ctx, restConfig)
+	if err != nil {
+		return err
+	}
+
+	_, mapper, err := k8s.CreateDiscoveryAndMapper(ctx, restConfig)
+	if err != nil {
+		return err
+	}
+	c, err := client.NewWithWatch(restConfig, client.Options{
+		Mapper: mapper,
+	})
+	if err != nil {
+		return err
+	}
+
+	var readNamespaces []string
+	if cmd.NamespaceSelector != "" {
+		sel, err := labels.Parse(cmd.NamespaceSelector)
+		if err != nil {
+			return err
+		}
+		var l corev1.NamespaceList
+		err = c.List(ctx, &l, client.MatchingLabelsSelector{Selector: sel})
+		if err != nil {
+			return err
+		}
+		for _, ns := range l.Items {
+			readNamespaces = append(readNamespaces, ns.Name)
+		}
+		if len(readNamespaces) == 0 {
+			return outputYamlResult(ctx, cmd.Output, []result.CommandResultSummary{}, false)
+		}
+	} else if !cmd.AllNamespaces {
+		readNamespaces = []string{cmd.CommandResultNamespace}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rs, err := results.NewResultStoreSecrets(ctx, restConfig, c, false, cmd.CommandResultNamespace, readNamespaces, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	return cmd.listSummaries(ctx, rs)
+}
+
+func (cmd *resultsListCmd) listSummaries(ctx context.Context, rs results.ResultStore) error {
+	summaries, err := rs.ListCommandResultSummaries(results.ListResultSummariesOptions{})
+	if err != nil {
+		return err
+	}
+	return outputYamlResult(ctx, cmd.Output, summaries, false)
+}
+
+type resultsDeleteCmd struct {
+	args.KubeconfigFlags
+	args.CommandResultReadOnlyFlags
+	args.YesFlags
+	args.DryRunFlags
+	args.OutputFormatFlags
+
+	Context              string `group:"misc" help:"Override the context to use."`
+	ResultId             string `group:"misc" help:"The id of the command result to delete the applied objects for."`
+	IncludeHooks         bool   `group:"misc" help:"Also delete hooks that were applied by the command."`
+	IncludeClusterScoped bool   `group:"misc" help:"Also delete cluster-scoped objects (e.g. Namespaces and CRDs) that were applied by the command. These are skipped by default, as they are often shared with other deployments."`
+	NoWait               bool   `group:"misc" help:"Don't wait for deletion of objects to finish."`
+}
+
+func (cmd *resultsDeleteCmd) Help() string {
+	return `Deletes exactly the objects that were applied by the command with the given result id.
+This is meant for short-lived environments (e.g. preview environments), where deleting based on the
+stored command result is safer than deleting based on the discriminator. Objects that are now owned by
+another discriminator or that are marked with 'kluctl.io/skip-delete' are not deleted.`
+}
+
+func (cmd *resultsDeleteCmd) Run(ctx context.Context) error {
+	if cmd.ResultId == "" {
+		return fmt.Errorf("--result-id must be specified")
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = cmd.Kubeconfig.String()
+	configOverrides := &clientcmd.ConfigOverrides{
+		CurrentContext: cmd.Context,
+	}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	err = cmd.KubeconfigFlags.ApplyToRestConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	err = k8s.CheckImpersonation(ctx, restConfig)
+	if err != nil {
+		return err
+	}
+
+	dc, mapper, err := k8s.CreateDiscoveryAndMapper(ctx, restConfig)
+	if err != nil {
+		return err
+	}
+
+	rs, err := buildResultStoreRO(ctx, restConfig, mapper, &cmd.CommandResultReadOnlyFlags)
+	if err != nil {
+		return err
+	}
+	cr, err := rs.GetCommandResult(results.GetCommandResultOptions{Id: cmd.ResultId})
+	if err != nil {
+		return fmt.Errorf("failed to load command result %s: %w", cmd.ResultId, err)
+	}
+	if cr == nil {
+		return fmt.Errorf("command result %s not found", cmd.ResultId)
+	}
+
+	k, err := k8s.NewK8sCluster(ctx, restConfig, dc, mapper, cmd.DryRun)
+	if err != nil {
+		return err
+	}
+
+	cmd2 := commands.NewDeleteResultCommand(cr, commands.DeleteResultOptions{
+		IncludeHooks:         cmd.IncludeHooks,
+		IncludeClusterScoped: cmd.IncludeClusterScoped,
+		Wait:                 !cmd.NoWait,
+	})
+	r := cmd2.Run(ctx, k, func(refs []k8s2.ObjectRef) error {
+		return confirmDeletion(ctx, refs, cmd.DryRun, cmd.Yes)
+	})
+
+	if !cmd.NoObfuscate {
+		var obfuscator diff.Obfuscator
+		err = obfuscator.ObfuscateResult(r)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = outputCommandResult2(ctx, cmd.OutputFormatFlags, r)
+	if err != nil {
+		return err
+	}
+	if len(r.Errors) != 0 {
+		return fmt.Errorf("command failed")
+	}
+	return nil
+}
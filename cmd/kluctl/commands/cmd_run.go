@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/shlex"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"sort"
+	"strings"
+)
+
+const macrosConfigKey = "macros"
+
+type runCmd struct {
+}
+
+func (cmd *runCmd) Help() string {
+	return `Runs a command macro defined in the kluctl config file ($HOME/.kluctl/config.yaml or /etc/kluctl/config.yaml).
+Macros are defined in the 'macros' map, e.g. 'prod-deploy: deploy -t prod --yes'. Invoking
+'kluctl run prod-deploy' expands to the full macro, while all additional arguments are appended
+to the expanded arguments, allowing to override individual flags.
+When no macro name is given, all available macros are listed.`
+}
+
+func (cmd *runCmd) Run(ctx context.Context) error {
+	macros, err := loadMacros()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for n := range macros {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	status.Flush(ctx)
+	stdout := getStdout(ctx)
+	for _, n := range names {
+		_, err = fmt.Fprintf(stdout, "%s: %s\n", n, strings.Join(macros[n], " "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadMacros reads all command macros from the viper config. See parseMacros for details.
+func loadMacros() (map[string][]string, error) {
+	return parseMacros(viper.Get(macrosConfigKey))
+}
+
+// parseMacros parses the macros config value. Each macro can either be specified as a single string, which is split
+// into arguments with shell-like quoting rules, or as a list of arguments.
+func parseMacros(v any) (map[string][]string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("config value %s has unexpected type", macrosConfigKey)
+	}
+
+	ret := make(map[string][]string, len(m))
+	for name, x := range m {
+		switch y := x.(type) {
+		case string:
+			a, err := shlex.Split(y)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse macro %s: %w", name, err)
+			}
+			ret[name] = a
+		case []any:
+			var a []string
+			for _, z := range y {
+				s, ok := z.(string)
+				if !ok {
+					return nil, fmt.Errorf("macro %s has unexpected type", name)
+				}
+				a = append(a, s)
+			}
+			ret[name] = a
+		default:
+			return nil, fmt.Errorf("macro %s has unexpected type", name)
+		}
+		if len(ret[name]) == 0 {
+			return nil, fmt.Errorf("macro %s is empty", name)
+		}
+		if ret[name][0] == "run" {
+			return nil, fmt.Errorf("macro %s must not invoke another macro", name)
+		}
+	}
+	return ret, nil
+}
+
+// findRunCommand returns the index of the 'run' subcommand in args or -1 if 'run' is not the invoked subcommand. Global
+// flags (see GlobalFlags) are allowed to be placed before the subcommand, in which case flags is used to determine
+// which of them consume the following argument as value.
+func findRunCommand(args []string, flags *pflag.FlagSet) int {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(a, "-") || a == "-" {
+			if a == "run" {
+				return i
+			}
+			return -1
+		}
+		if strings.Contains(a, "=") {
+			continue
+		}
+
+		var f *pflag.Flag
+		if strings.HasPrefix(a, "--") {
+			f = flags.Lookup(a[2:])
+		} else if len(a) == 2 {
+			f = flags.ShorthandLookup(a[1:])
+		}
+		if f != nil && f.NoOptDefVal == "" {
+			// the flag requires a value, which is passed as the next argument
+			i++
+		}
+	}
+	return -1
+}
+
+// expandMacros replaces 'run <macro>' at the given index with the arguments of the macro. Global flags in front of
+// 'run' are kept and additional arguments are appended after the expanded arguments, so that they override flags set
+// by the macro.
+func expandMacros(args []string, runIdx int, macros map[string][]string) ([]string, error) {
+	if runIdx < 0 || len(args) < runIdx+2 || strings.HasPrefix(args[runIdx+1], "-") {
+		return args, nil
+	}
+	name := args[runIdx+1]
+	// viper treats keys case-insensitive
+	m, ok := macros[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("macro %s not found", name)
+	}
+
+	ret := make([]string, 0, len(m)+len(args)-2)
+	ret = append(ret, args[:runIdx]...)
+	ret = append(ret, m...)
+	ret = append(ret, args[runIdx+2:]...)
+	return ret, nil
+}
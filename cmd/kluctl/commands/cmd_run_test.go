@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoadMacros(t *testing.T) {
+	testCases := []struct {
+		name   string
+		v      any
+		result map[string][]string
+		err    string
+	}{
+		{name: "none", v: nil, result: nil},
+		{name: "string", v: map[string]any{"prod": "deploy -t prod --arg 'a b'"}, result: map[string][]string{"prod": {"deploy", "-t", "prod", "--arg", "a b"}}},
+		{name: "list", v: map[string]any{"prod": []any{"deploy", "-t", "prod"}}, result: map[string][]string{"prod": {"deploy", "-t", "prod"}}},
+		{name: "invalid-config", v: "x", err: "config value macros has unexpected type"},
+		{name: "invalid-macro", v: map[string]any{"prod": 1}, err: "macro prod has unexpected type"},
+		{name: "invalid-list", v: map[string]any{"prod": []any{"deploy", 1}}, err: "macro prod has unexpected type"},
+		{name: "invalid-quoting", v: map[string]any{"prod": "deploy 'a"}, err: "failed to parse macro prod: EOF found when expecting closing quote"},
+		{name: "empty", v: map[string]any{"prod": ""}, err: "macro prod is empty"},
+		{name: "recursive", v: map[string]any{"prod": "run other"}, err: "macro prod must not invoke another macro"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := parseMacros(tc.v)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.result, m)
+		})
+	}
+}
+
+func TestExpandMacros(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Bool("debug", false, "")
+	flags.BoolP("no-color", "n", false, "")
+	flags.String("cpu-profile", "", "")
+	flags.StringP("gops-agent-addr", "g", "", "")
+
+	macros := map[string][]string{
+		"prod": {"deploy", "-t", "prod"},
+	}
+
+	testCases := []struct {
+		name   string
+		args   []string
+		result []string
+		err    string
+	}{
+		{name: "no-args", args: []string{}, result: []string{}},
+		{name: "other-command", args: []string{"deploy", "-t", "prod"}, result: []string{"deploy", "-t", "prod"}},
+		{name: "other-command-with-run-arg", args: []string{"deploy", "run", "prod"}, result: []string{"deploy", "run", "prod"}},
+		{name: "list", args: []string{"run"}, result: []string{"run"}},
+		{name: "help", args: []string{"run", "--help"}, result: []string{"run", "--help"}},
+		{name: "macro", args: []string{"run", "prod"}, result: []string{"deploy", "-t", "prod"}},
+		{name: "case-insensitive", args: []string{"run", "Prod"}, result: []string{"deploy", "-t", "prod"}},
+		{name: "additional-args", args: []string{"run", "prod", "--yes"}, result: []string{"deploy", "-t", "prod", "--yes"}},
+		{name: "bool-global-flag", args: []string{"--debug", "run", "prod"}, result: []string{"--debug", "deploy", "-t", "prod"}},
+		{name: "short-global-flag", args: []string{"-n", "run", "prod"}, result: []string{"-n", "deploy", "-t", "prod"}},
+		{name: "global-flag-with-value", args: []string{"--cpu-profile", "run", "run", "prod"}, result: []string{"--cpu-profile", "run", "deploy", "-t", "prod"}},
+		{name: "global-flag-with-inline-value", args: []string{"--cpu-profile=x", "run", "prod"}, result: []string{"--cpu-profile=x", "deploy", "-t", "prod"}},
+		{name: "short-global-flag-with-value", args: []string{"-g", "run", "deploy"}, result: []string{"-g", "run", "deploy"}},
+		{name: "terminator", args: []string{"--", "run", "prod"}, result: []string{"--", "run", "prod"}},
+		{name: "not-found", args: []string{"--debug", "run", "missing"}, err: "macro missing not found"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args, err := expandMacros(tc.args, findRunCommand(tc.args, flags), macros)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.result, args)
+		})
+	}
+}
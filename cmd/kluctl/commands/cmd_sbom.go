@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/sbom"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"time"
+)
+
+type sbomCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.ImageFlags
+	args.InclusionFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.OutputFlags
+	args.RenderOutputDirFlags
+	args.OfflineKubernetesFlags
+
+	SbomFormat       string `group:"misc" help:"Specify the SBOM format. Can either be 'cyclonedx' or 'spdx'." default:"cyclonedx"`
+	NoResolveDigests bool   `group:"misc" help:"Do not query registries to resolve image digests."`
+}
+
+func (cmd *sbomCmd) Help() string {
+	return `Renders the target and outputs an image-level SBOM of all container images used by the
+rendered objects. Images are resolved to their digests by querying the registries, using
+the registry credentials passed via the registry arguments.
+The SBOM is written as JSON document in either the CycloneDX or SPDX format.`
+}
+
+func (cmd *sbomCmd) Run(ctx context.Context) error {
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		imageFlags:           cmd.ImageFlags,
+		inclusionFlags:       cmd.InclusionFlags,
+		gitCredentials:       cmd.GitCredentials,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		offlineKubernetes:    cmd.OfflineKubernetes,
+		kubernetesVersion:    cmd.KubernetesVersion,
+		offlineApiResources:  cmd.OfflineApiResources,
+		outputPaths:          outputFilePaths(cmd.Output, false),
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		var objects []*uo.UnstructuredObject
+		for _, d := range cmdCtx.targetCtx.DeploymentCollection.Deployments {
+			if d.Config.OnlyRender {
+				continue
+			}
+			objects = append(objects, d.Objects...)
+		}
+
+		images := sbom.CollectImages(objects)
+		if !cmd.NoResolveDigests {
+			s := status.Startf(ctx, "Resolving digests of %d images", len(images))
+			err := sbom.ResolveDigests(ctx, images, cmdCtx.targetCtx.SharedContext.OciAuthProvider)
+			if err != nil {
+				s.FailedWithMessage(err.Error())
+				return err
+			}
+			s.Success()
+		}
+
+		doc, err := sbom.BuildDocument(cmd.SbomFormat, cmdCtx.targetCtx.Target.Name, images, time.Now())
+		if err != nil {
+			return err
+		}
+		return outputResult2(ctx, cmd.Output, doc)
+	})
+}
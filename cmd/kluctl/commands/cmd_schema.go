@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/types/jsonschema"
+)
+
+type schemaCmd struct {
+	args.OutputFlags
+
+	Name string `group:"misc" help:"The name of the schema to output. Can be 'deployment' (deployment.yaml), 'kluctl-project' (.kluctl.yaml), 'kluctl-library' (.kluctl-library.yaml), 'vars-source' (a single vars source), 'fixed-images' (--fixed-images-file) or 'helm-chart-config' (helm-chart.yaml)." default:"deployment"`
+}
+
+func (cmd *schemaCmd) Help() string {
+	return `The schema is generated from the same Go structs that are used to load the configuration files, so it always
+matches the running version of kluctl. It can be used in IDEs to get auto-completion and validation.`
+}
+
+func (cmd *schemaCmd) Run(ctx context.Context) error {
+	b, err := jsonschema.GetSchemaJson(cmd.Name)
+	if err != nil {
+		return err
+	}
+	return outputResult2(ctx, cmd.Output, string(b)+"\n")
+}
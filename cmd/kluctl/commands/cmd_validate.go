@@ -20,7 +20,9 @@ type validateCmd struct {
 	args.RegistryCredentials
 	args.OutputFlags
 	args.RenderOutputDirFlags
+	args.OfflineKubernetesFlags
 
+	ValidateOnly     bool          `group:"misc" help:"Only render the deployment and validate the rendered objects locally, without retrieving the deployed objects from the cluster. Can be combined with --offline-kubernetes."`
 	Wait             time.Duration `group:"misc" help:"Wait for the given amount of time until the deployment validates"`
 	Sleep            time.Duration `group:"misc" help:"Sleep duration between validation attempts" default:"5s"`
 	WarningsAsErrors bool          `group:"misc" help:"Consider warnings as failures"`
@@ -29,10 +31,17 @@ type validateCmd struct {
 func (cmd *validateCmd) Help() string {
 	return `This means that all objects are retrieved from the cluster and checked for readiness.
 
+With --validate-only, the deployment is only rendered and the rendered objects are validated locally instead, which
+does not require access to the deployed objects.
+
 TODO: This needs to be better documented!`
 }
 
 func (cmd *validateCmd) Run(ctx context.Context) error {
+	if cmd.OfflineKubernetes && !cmd.ValidateOnly {
+		return fmt.Errorf("--offline-kubernetes can only be used together with --validate-only")
+	}
+
 	ptArgs := projectTargetCommandArgs{
 		projectFlags:         cmd.ProjectFlags,
 		kubeconfigFlags:      cmd.KubeconfigFlags,
@@ -43,14 +52,36 @@ func (cmd *validateCmd) Run(ctx context.Context) error {
 		helmCredentials:      cmd.HelmCredentials,
 		registryCredentials:  cmd.RegistryCredentials,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		offlineKubernetes:    cmd.OfflineKubernetes,
+		kubernetesVersion:    cmd.KubernetesVersion,
+		offlineApiResources:  cmd.OfflineApiResources,
+		outputPaths:          outputFilePaths(cmd.Output, true),
 	}
 
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
 		cmd2 := commands.NewValidateCommand("", cmdCtx.targetCtx)
+		if cmd.ValidateOnly {
+			return cmd.doValidateRendered(ctx, cmdCtx, cmd2)
+		}
 		return cmd.doValidate(ctx, cmdCtx, cmd2)
 	})
 }
 
+func (cmd *validateCmd) doValidateRendered(ctx context.Context, cmdCtx *commandCtx, cmd2 *commands.ValidateCommand) error {
+	result := cmd2.RunRendered(ctx)
+	failed := len(result.Errors) != 0 || (cmd.WarningsAsErrors && len(result.Warnings) != 0)
+
+	err := outputValidateResult(ctx, cmdCtx, cmd.Output, result)
+	if err != nil {
+		return err
+	}
+	if failed {
+		return fmt.Errorf("Validation failed")
+	}
+	status.Info(ctx, "Validation succeeded")
+	return nil
+}
+
 func (cmd *validateCmd) doValidate(ctx context.Context, cmdCtx *commandCtx, cmd2 *commands.ValidateCommand) error {
 	startTime := time.Now()
 	for true {
@@ -212,6 +212,16 @@ func (c *rootCommand) buildCobraArg(cg *commandAndGroups, f reflect.StructField,
 			parsedDefault = int(x)
 		}
 		cg.cmd.PersistentFlags().IntVarP(v2.(*int), name, shortFlag, parsedDefault, help)
+	case *float32:
+		var parsedDefault float32
+		if defaultValue != "" {
+			x, err := strconv.ParseFloat(defaultValue, 32)
+			if err != nil {
+				return err
+			}
+			parsedDefault = float32(x)
+		}
+		cg.cmd.PersistentFlags().Float32VarP(v2.(*float32), name, shortFlag, parsedDefault, help)
 	case *time.Duration:
 		var parsedDefault time.Duration
 		if defaultValue != "" {
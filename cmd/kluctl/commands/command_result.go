@@ -11,6 +11,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"io"
 	"os"
 	"strings"
@@ -65,6 +66,20 @@ func formatCommandResultText(cr *result.CommandResult, short bool) string {
 		}
 	}
 
+	if len(cr.HelmValuesChanges) != 0 {
+		buf.WriteString("\nChanged Helm values:\n")
+		for _, hvc := range cr.HelmValuesChanges {
+			buf.WriteString(fmt.Sprintf("  %s\n", formatHelmRelease(hvc.ReleaseName, hvc.Namespace)))
+		}
+
+		if !short {
+			for _, hvc := range cr.HelmValuesChanges {
+				buf.WriteString("\n")
+				prettyChangesTable(buf, fmt.Sprintf("Diff for Helm values of %s", formatHelmRelease(hvc.ReleaseName, hvc.Namespace)), hvc.Changes)
+			}
+		}
+	}
+
 	if len(deletedObjects) != 0 {
 		buf.WriteString("\nDeleted objects:\n")
 		prettyObjectRefs(buf, deletedObjects)
@@ -104,12 +119,27 @@ func prettyErrors(buf io.StringWriter, errors []result.DeploymentError) {
 		if s := e.Ref.String(); s != "" {
 			prefix = fmt.Sprintf("%s: ", s)
 		}
-		_, _ = buf.WriteString(fmt.Sprintf("  %s%s\n", prefix, e.Message))
+		suffix := ""
+		if e.Count > 1 {
+			suffix = fmt.Sprintf(" (%d objects)", e.Count)
+		}
+		_, _ = buf.WriteString(fmt.Sprintf("  %s%s%s\n", prefix, e.Message, suffix))
+	}
+}
+
+func formatHelmRelease(releaseName string, namespace string) string {
+	if namespace == "" {
+		return releaseName
 	}
+	return fmt.Sprintf("%s/%s", namespace, releaseName)
 }
 
 func prettyChanges(buf io.StringWriter, ref k8s.ObjectRef, changes []result.Change) {
-	_, _ = buf.WriteString(fmt.Sprintf("Diff for object %s\n", ref.String()))
+	prettyChangesTable(buf, fmt.Sprintf("Diff for object %s", ref.String()), changes)
+}
+
+func prettyChangesTable(buf io.StringWriter, title string, changes []result.Change) {
+	_, _ = buf.WriteString(title + "\n")
 
 	var t utils.PrettyTable
 	t.AddRow("Path", "Diff")
@@ -129,10 +159,42 @@ func formatCommandResultYaml(cr *result.CommandResult) (string, error) {
 	return b, nil
 }
 
-func formatCommandResult(cr *result.CommandResult, format string, short bool) (string, error) {
+// formatCommandResultUnified outputs unified diffs (compatible with 'git diff') for all new, changed and deleted objects
+func formatCommandResultUnified(cr *result.CommandResult) (string, error) {
+	buf := bytes.NewBuffer(nil)
+	for _, o := range cr.Objects {
+		var oldObject, newObject *uo.UnstructuredObject
+		if o.New {
+			newObject = o.Applied
+		} else if o.Deleted {
+			oldObject = o.Remote
+		} else if len(o.Changes) != 0 {
+			oldObject = o.Remote
+			newObject = o.Applied
+		}
+		if oldObject == nil && newObject == nil {
+			continue
+		}
+		ud, err := diff.UnifiedObjectDiff(o.Ref, oldObject, newObject, o.Rendered)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(ud)
+	}
+	return buf.String(), nil
+}
+
+func formatCommandResult(cr *result.CommandResult, format string, short bool, diffFormat string) (string, error) {
 	switch format {
 	case "text":
-		return formatCommandResultText(cr, short), nil
+		switch diffFormat {
+		case "", "text":
+			return formatCommandResultText(cr, short), nil
+		case "unified":
+			return formatCommandResultUnified(cr)
+		default:
+			return "", fmt.Errorf("invalid diff format: %s", diffFormat)
+		}
 	case "yaml":
 		return formatCommandResultYaml(cr)
 	default:
@@ -197,6 +259,27 @@ func formatValidateResult(vr *result.ValidateResult, format string) (string, err
 	}
 }
 
+// outputFilePaths returns the files referenced by the given output flags, excluding stdout. If withFormat is set, the
+// flags are expected in the format 'format=path'.
+func outputFilePaths(output []string, withFormat bool) []string {
+	var ret []string
+	for _, o := range output {
+		path := o
+		if withFormat {
+			s := strings.SplitN(o, "=", 2)
+			if len(s) < 2 {
+				continue
+			}
+			path = s[1]
+		}
+		if path == "" || path == "-" {
+			continue
+		}
+		ret = append(ret, path)
+	}
+	return ret
+}
+
 func outputHelper(ctx context.Context, output []string, cb func(format string) (string, error)) error {
 	if len(output) == 0 {
 		output = []string{"text"}
@@ -249,7 +332,12 @@ func outputCommandResult(ctx context.Context, cmdCtx *commandCtx, flags args.Out
 		}
 
 		resultStoreErr = cmdCtx.resultStore.WriteCommandResult(cr)
-		if resultStoreErr != nil {
+		if resultStoreErr != nil && cmdCtx.commandResultFlags != nil && cmdCtx.commandResultFlags.CommandResultBestEffort {
+			// best-effort mode, don't let the result store fail the command
+			s.UpdateAndInfoFallbackf("Failed to write result to result store: %s", resultStoreErr.Error())
+			s.Warning()
+			resultStoreErr = nil
+		} else if resultStoreErr != nil {
 			s.FailedWithMessagef("Failed to write result to result store: %s", resultStoreErr.Error())
 		} else {
 			if didWarn {
@@ -260,6 +348,9 @@ func outputCommandResult(ctx context.Context, cmdCtx *commandCtx, flags args.Out
 		}
 	}
 	err := outputCommandResult2(ctx, flags, cr)
+	if err == nil && cmdCtx.commandResultFlags != nil {
+		err = outputStoredCommandResult(ctx, cmdCtx.commandResultFlags.CommandResultOutput, cr)
+	}
 	if err == nil && resultStoreErr != nil {
 		return resultStoreErr
 	}
@@ -269,12 +360,49 @@ func outputCommandResult(ctx context.Context, cmdCtx *commandCtx, flags args.Out
 func outputCommandResult2(ctx context.Context, flags args.OutputFormatFlags, cr *result.CommandResult) error {
 	status.Flush(ctx)
 	err := outputHelper(ctx, flags.OutputFormat, func(format string) (string, error) {
-		return formatCommandResult(cr, format, flags.ShortOutput)
+		return formatCommandResult(cr, format, flags.ShortOutput, flags.DiffFormat)
 	})
 	status.Flush(ctx)
 	return err
 }
 
+// outputStoredCommandResult writes the command result in the same structure as it is persisted by the result store,
+// with the rendered/remote/applied objects being compacted.
+func outputStoredCommandResult(ctx context.Context, output []string, cr *result.CommandResult) error {
+	if len(output) == 0 {
+		return nil
+	}
+	status.Flush(ctx)
+	for _, o := range output {
+		s := strings.SplitN(o, "=", 2)
+		var path *string
+		if len(s) > 1 {
+			path = &s[1]
+		}
+
+		var r string
+		var err error
+		switch s[0] {
+		case "json":
+			r, err = yaml.WriteJsonString(cr.ToCompacted())
+			r += "\n"
+		case "yaml":
+			r, err = yaml.WriteYamlString(cr.ToCompacted())
+		default:
+			return fmt.Errorf("invalid command result output format: %s", s[0])
+		}
+		if err != nil {
+			return err
+		}
+
+		err = outputResult(ctx, path, r)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func outputValidateResult(ctx context.Context, cmdCtx *commandCtx, output []string, vr *result.ValidateResult) error {
 	vr.Id = cmdCtx.resultId
 
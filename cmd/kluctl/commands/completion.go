@@ -238,7 +238,14 @@ func loadKubeconfig(ctx context.Context, cmdStruct interface{}) (api.Config, *re
 		kubeconfigPath = cmdV.FieldByName("Kubeconfig").Interface().(string)
 	}
 	if cmdV.FieldByName("Context").IsValid() {
-		kubeContext = cmdV.FieldByName("Context").Interface().(string)
+		switch v := cmdV.FieldByName("Context").Interface().(type) {
+		case string:
+			kubeContext = v
+		case []string:
+			if len(v) != 0 {
+				kubeContext = v[0]
+			}
+		}
 	}
 
 	rules := clientcmd.NewDefaultClientConfigLoadingRules()
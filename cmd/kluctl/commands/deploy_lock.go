@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"os"
+	"time"
+)
+
+// acquireDeployLock acquires a Lease based lock for the current target, so that concurrent deployments of the same
+// target are prevented. The context of the target context is replaced with one that is cancelled when the lock gets
+// lost, which aborts the deployment so that no new objects are applied. The returned release function must be called
+// to release the lock. The returned lost function returns the reason if the lock got lost while being held.
+func acquireDeployLock(ctx context.Context, cmdCtx *commandCtx, namespace string, timeout time.Duration) (release func(), lost func() error, err error) {
+	if cmdCtx.targetCtx.SharedContext.K == nil {
+		return nil, nil, fmt.Errorf("locking requires a Kubernetes cluster")
+	}
+	c, err := cmdCtx.targetCtx.SharedContext.K.ToClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targetName := cmdCtx.targetCtx.Target.Name
+	if targetName == "" {
+		targetName = "no-name"
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "unknown"
+	}
+	identity := fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8])
+
+	lock := k8s.NewLeaseLock(c, namespace, "kluctl-lock-"+targetName, identity)
+
+	s := status.Startf(ctx, "Acquiring lock for target %s", targetName)
+	err = lock.Acquire(ctx, timeout)
+	if err != nil {
+		s.FailedWithMessage(err.Error())
+		return nil, nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	s.Success()
+
+	deployCtx, cancel := context.WithCancelCause(cmdCtx.targetCtx.SharedContext.Ctx)
+	cmdCtx.targetCtx.SharedContext.Ctx = deployCtx
+	go func() {
+		select {
+		case <-lock.Lost():
+			status.Errorf(ctx, "%s, cancelling deployment", lock.LostErr().Error())
+			cancel(lock.LostErr())
+		case <-deployCtx.Done():
+		}
+	}()
+
+	release = func() {
+		cancel(nil)
+		err := lock.Release(context.Background())
+		if err != nil && lock.LostErr() == nil {
+			status.Warningf(ctx, "Failed to release lock: %s", err.Error())
+		}
+	}
+	return release, lock.LostErr, nil
+}
+
+// addLockLostError adds an error to the command result if the deploy lock got lost while deploying
+func addLockLostError(r *result.CommandResult, lost func() error) {
+	if lost == nil {
+		return
+	}
+	if err := lost(); err != nil {
+		r.Errors = append(r.Errors, result.DeploymentError{Message: err.Error()})
+	}
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"sort"
+)
+
+type helmValuesKey struct {
+	releaseName string
+	namespace   string
+}
+
+func collectRenderedHelmValues(d *types.DeploymentProjectConfig, m map[helmValuesKey]*uo.UnstructuredObject) {
+	if d == nil {
+		return
+	}
+	for _, di := range d.Deployments {
+		if di.RenderedHelmChartConfig != nil && di.RenderedHelmValues != nil {
+			k := helmValuesKey{releaseName: di.RenderedHelmChartConfig.ReleaseName}
+			if di.RenderedHelmChartConfig.Namespace != nil {
+				k.namespace = *di.RenderedHelmChartConfig.Namespace
+			}
+			m[k] = di.RenderedHelmValues
+		}
+		collectRenderedHelmValues(di.RenderedInclude, m)
+	}
+}
+
+// findPreviousCommandResult returns the newest command result of the same project and target that was not performed
+// in dry-run mode.
+func findPreviousCommandResult(resultStore results.ResultStore, cr *result.CommandResult) (*result.CommandResult, error) {
+	summaries, err := resultStore.ListCommandResultSummaries(results.ListResultSummariesOptions{
+		ProjectFilter: &cr.ProjectKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range summaries {
+		if s.Id == cr.Id || s.TargetKey != cr.TargetKey || s.Command.DryRun {
+			continue
+		}
+		return resultStore.GetCommandResult(results.GetCommandResultOptions{
+			Id:      s.Id,
+			Reduced: true,
+		})
+	}
+	return nil, nil
+}
+
+// addHelmValuesChanges compares the rendered Helm values of the given command result with the ones found in the
+// previous command result and stores the changes in HelmValuesChanges.
+func addHelmValuesChanges(ctx context.Context, cmdCtx *commandCtx, cr *result.CommandResult) error {
+	if cmdCtx.resultStore == nil {
+		status.Warningf(ctx, "No result store available, skipping diff of Helm values.")
+		return nil
+	}
+
+	prev, err := findPreviousCommandResult(cmdCtx.resultStore, cr)
+	if err != nil {
+		return fmt.Errorf("failed to load previous command result: %w", err)
+	}
+	if prev == nil {
+		status.Info(ctx, "No previous command result found, skipping diff of Helm values.")
+		return nil
+	}
+
+	oldValues := map[helmValuesKey]*uo.UnstructuredObject{}
+	newValues := map[helmValuesKey]*uo.UnstructuredObject{}
+	collectRenderedHelmValues(prev.Deployment, oldValues)
+	collectRenderedHelmValues(cr.Deployment, newValues)
+
+	keys := map[helmValuesKey]bool{}
+	for k := range oldValues {
+		keys[k] = true
+	}
+	for k := range newValues {
+		keys[k] = true
+	}
+
+	cr.HelmValuesChanges = nil
+	for k := range keys {
+		o, n := oldValues[k], newValues[k]
+		if o == nil {
+			o = uo.New()
+		}
+		if n == nil {
+			n = uo.New()
+		}
+		changes, err := diff.Diff(o, n)
+		if err != nil {
+			return fmt.Errorf("failed to diff values of Helm release %s: %w", k.releaseName, err)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		cr.HelmValuesChanges = append(cr.HelmValuesChanges, result.HelmValuesChange{
+			ReleaseName: k.releaseName,
+			Namespace:   k.namespace,
+			Changes:     changes,
+		})
+	}
+	sort.Slice(cr.HelmValuesChanges, func(i, j int) bool {
+		a, b := cr.HelmValuesChanges[i], cr.HelmValuesChanges[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.ReleaseName < b.ReleaseName
+	})
+	return nil
+}
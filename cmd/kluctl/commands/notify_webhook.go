@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	"github.com/kluctl/kluctl/lib/go-jinja2"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const notifyWebhookTimeout = 30 * time.Second
+
+type webhookNotification struct {
+	Text     string `json:"text"`
+	Command  string `json:"command"`
+	Target   string `json:"target"`
+	Status   string `json:"status"`
+	DryRun   bool   `json:"dryRun"`
+	ResultId string `json:"resultId,omitempty"`
+
+	AppliedObjects int `json:"appliedObjects"`
+	NewObjects     int `json:"newObjects"`
+	ChangedObjects int `json:"changedObjects"`
+	OrphanObjects  int `json:"orphanObjects"`
+	DeletedObjects int `json:"deletedObjects"`
+
+	Errors   []result.DeploymentError `json:"errors"`
+	Warnings []result.DeploymentError `json:"warnings"`
+}
+
+func buildWebhookNotification(r *result.CommandResult, dryRun bool) *webhookNotification {
+	s := r.BuildSummary()
+
+	n := &webhookNotification{
+		Command:        s.Command.Command,
+		Target:         s.Target.Name,
+		Status:         "succeeded",
+		DryRun:         dryRun,
+		ResultId:       s.Id,
+		AppliedObjects: s.AppliedObjects,
+		NewObjects:     s.NewObjects,
+		ChangedObjects: s.ChangedObjects,
+		OrphanObjects:  s.OrphanObjects,
+		DeletedObjects: s.DeletedObjects,
+		Errors:         s.Errors,
+		Warnings:       s.Warnings,
+	}
+	if len(s.Errors) != 0 {
+		n.Status = "failed"
+	}
+	if n.Target == "" {
+		n.Target = "no-name"
+	}
+
+	// most chat webhooks (e.g. Slack and MS Teams) only show the 'text' field
+	n.Text = fmt.Sprintf("%s of target %s %s: %d new, %d changed, %d deleted objects, %d errors, %d warnings",
+		n.Command, n.Target, n.Status, n.NewObjects, n.ChangedObjects, n.DeletedObjects, len(n.Errors), len(n.Warnings))
+	if dryRun {
+		n.Text = "[DRY-RUN] " + n.Text
+	}
+	return n
+}
+
+func buildWebhookBody(j2 *jinja2.Jinja2, n *webhookNotification, templateFile string) ([]byte, error) {
+	if templateFile == "" {
+		return json.Marshal(n)
+	}
+
+	t, err := os.ReadFile(templateFile)
+	if err != nil {
+		return nil, err
+	}
+	globals, err := uo.FromStruct(n)
+	if err != nil {
+		return nil, err
+	}
+	globalsMap, err := globals.ToMap()
+	if err != nil {
+		return nil, err
+	}
+	s, err := j2.RenderString(string(t), jinja2.WithGlobals(globalsMap))
+	if err != nil {
+		return nil, fmt.Errorf("failed to render webhook template %s: %w", templateFile, err)
+	}
+	return []byte(s), nil
+}
+
+// buildWebhookFailureNotification builds the notification for a deployment that failed before a command result was
+// available, e.g. because loading the project, target or vars failed
+func buildWebhookFailureNotification(target string, dryRun bool, err error) *webhookNotification {
+	r := &result.CommandResult{
+		Command: result.CommandInfo{
+			Command: "deploy",
+		},
+		Target: types.Target{
+			Name: target,
+		},
+		Errors: []result.DeploymentError{
+			{Message: err.Error()},
+		},
+	}
+	return buildWebhookNotification(r, dryRun)
+}
+
+type webhookOptions struct {
+	url          string
+	templateFile string
+	headers      []string
+	contentType  string
+}
+
+// webhookNotifiedError marks errors that were already reported via a webhook notification, so that no additional
+// failure notification is sent for them
+type webhookNotifiedError struct {
+	err error
+}
+
+func (e *webhookNotifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *webhookNotifiedError) Unwrap() error {
+	return e.err
+}
+
+func markWebhookNotified(err error, notified bool) error {
+	if err == nil || !notified {
+		return err
+	}
+	return &webhookNotifiedError{err: err}
+}
+
+// withoutWebhookNotifiedErrors returns err without the errors that were already reported via a webhook notification,
+// or nil if all errors were already reported
+func withoutWebhookNotifiedErrors(err error) error {
+	var nerr *webhookNotifiedError
+	if errors.As(err, &nerr) {
+		return nil
+	}
+	var merr *multierror.Error
+	if errors.As(err, &merr) {
+		var ret *multierror.Error
+		for _, e := range merr.Errors {
+			e = withoutWebhookNotifiedErrors(e)
+			if e != nil {
+				ret = multierror.Append(ret, e)
+			}
+		}
+		return ret.ErrorOrNil()
+	}
+	return err
+}
+
+// notifyWebhook sends the given notification to the webhook. j2 is used to render the template and might be nil, in
+// which case a new Jinja2 instance is created when needed. Failures are only reported as warnings, as a failing
+// notification must not fail the command itself.
+func notifyWebhook(ctx context.Context, j2 *jinja2.Jinja2, n *webhookNotification, opts webhookOptions) {
+	err := doNotifyWebhook(ctx, j2, n, opts)
+	if err != nil {
+		status.Warningf(ctx, "Failed to notify webhook: %s", err.Error())
+	}
+}
+
+func doNotifyWebhook(ctx context.Context, j2 *jinja2.Jinja2, n *webhookNotification, opts webhookOptions) error {
+	if j2 == nil && opts.templateFile != "" {
+		var err error
+		j2, err = kluctl_jinja2.NewKluctlJinja2(ctx, true, getCobraGlobalFlags(ctx).UseSystemPython)
+		if err != nil {
+			return err
+		}
+		defer j2.Close()
+	}
+	body, err := buildWebhookBody(j2, n, opts.templateFile)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	contentType := opts.contentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	for _, h := range opts.headers {
+		k, v, ok := strings.Cut(h, ":")
+		if !ok {
+			// don't print the header, it might contain credentials
+			return fmt.Errorf("invalid header, expected 'Name: value'")
+		}
+		req.Header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
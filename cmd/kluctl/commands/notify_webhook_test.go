@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testWebhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newTestWebhookServer(t *testing.T, statusCode int) (*httptest.Server, *[]testWebhookRequest) {
+	var requests []testWebhookRequest
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		requests = append(requests, testWebhookRequest{header: r.Header.Clone(), body: body})
+		w.WriteHeader(statusCode)
+	}))
+	t.Cleanup(s.Close)
+	return s, &requests
+}
+
+func TestNotifyWebhook(t *testing.T) {
+	s, requests := newTestWebhookServer(t, http.StatusOK)
+
+	r := &result.CommandResult{
+		Id:      "result-id",
+		Command: result.CommandInfo{Command: "deploy"},
+		Target:  types.Target{Name: "prod"},
+		Errors:  []result.DeploymentError{{Message: "error"}},
+	}
+	err := doNotifyWebhook(context.TODO(), nil, buildWebhookNotification(r, true), webhookOptions{
+		url:     s.URL,
+		headers: []string{"Authorization: Bearer token"},
+	})
+	assert.NoError(t, err)
+
+	if assert.Len(t, *requests, 1) {
+		req := (*requests)[0]
+		assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", req.header.Get("Authorization"))
+
+		var n webhookNotification
+		assert.NoError(t, json.Unmarshal(req.body, &n))
+		assert.Equal(t, "result-id", n.ResultId)
+		assert.Equal(t, "prod", n.Target)
+		assert.Equal(t, "failed", n.Status)
+		assert.True(t, n.DryRun)
+		assert.Equal(t, "[DRY-RUN] deploy of target prod failed: 0 new, 0 changed, 0 deleted objects, 1 errors, 0 warnings", n.Text)
+	}
+}
+
+func TestNotifyWebhookTemplate(t *testing.T) {
+	s, requests := newTestWebhookServer(t, http.StatusOK)
+
+	j2, err := kluctl_jinja2.NewKluctlJinja2(context.Background(), true, false)
+	assert.NoError(t, err)
+	defer j2.Close()
+
+	templateFile := filepath.Join(t.TempDir(), "template.txt")
+	assert.NoError(t, os.WriteFile(templateFile, []byte("{{ target }} {{ status }}"), 0o600))
+
+	r := &result.CommandResult{
+		Command: result.CommandInfo{Command: "deploy"},
+		Target:  types.Target{Name: "prod"},
+	}
+	err = doNotifyWebhook(context.TODO(), j2, buildWebhookNotification(r, false), webhookOptions{
+		url:          s.URL,
+		templateFile: templateFile,
+		contentType:  "text/plain",
+	})
+	assert.NoError(t, err)
+
+	if assert.Len(t, *requests, 1) {
+		req := (*requests)[0]
+		assert.Equal(t, "text/plain", req.header.Get("Content-Type"))
+		assert.Equal(t, "prod succeeded", string(req.body))
+	}
+}
+
+func TestNotifyWebhookErrors(t *testing.T) {
+	s, _ := newTestWebhookServer(t, http.StatusInternalServerError)
+
+	n := buildWebhookFailureNotification("prod", false, fmt.Errorf("failed to load vars"))
+	assert.Equal(t, "failed", n.Status)
+	assert.Equal(t, []result.DeploymentError{{Message: "failed to load vars"}}, n.Errors)
+
+	err := doNotifyWebhook(context.TODO(), nil, n, webhookOptions{url: s.URL})
+	assert.EqualError(t, err, "webhook returned status 500 Internal Server Error")
+
+	err = doNotifyWebhook(context.TODO(), nil, n, webhookOptions{url: s.URL, headers: []string{"invalid"}})
+	assert.EqualError(t, err, "invalid header, expected 'Name: value'")
+}
+
+func TestWithoutWebhookNotifiedErrors(t *testing.T) {
+	err1 := fmt.Errorf("err1")
+	err2 := fmt.Errorf("err2")
+
+	assert.NoError(t, withoutWebhookNotifiedErrors(nil))
+	assert.Equal(t, err1, withoutWebhookNotifiedErrors(err1))
+	assert.Equal(t, err1, withoutWebhookNotifiedErrors(markWebhookNotified(err1, false)))
+	assert.NoError(t, withoutWebhookNotifiedErrors(markWebhookNotified(err1, true)))
+
+	// only the errors of contexts that were not notified are kept
+	var merr *multierror.Error
+	merr = multierror.Append(merr, fmt.Errorf("context c1: %w", markWebhookNotified(err1, true)))
+	merr = multierror.Append(merr, fmt.Errorf("context c2: %w", err2))
+	err := withoutWebhookNotifiedErrors(merr.ErrorOrNil())
+	assert.ErrorIs(t, err, err2)
+	assert.NotErrorIs(t, err, err1)
+
+	merr = nil
+	merr = multierror.Append(merr, fmt.Errorf("context c1: %w", markWebhookNotified(err1, true)))
+	assert.NoError(t, withoutWebhookNotifiedErrors(merr.ErrorOrNil()))
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	renderOutputFormatDir        = "dir"
+	renderOutputFormatSingleYaml = "single-yaml"
+	renderOutputFormatJson       = "json"
+)
+
+// renderedHookAnnotation marks hooks in single file render outputs, as these can not be distinguished from ordinary
+// objects by their location
+const renderedHookAnnotation = "kluctl.io/rendered-hook"
+
+func checkRenderOutputFormat(format string) error {
+	switch format {
+	case renderOutputFormatDir, renderOutputFormatSingleYaml, renderOutputFormatJson:
+		return nil
+	default:
+		return fmt.Errorf("invalid render output format '%s', must be one of '%s', '%s' or '%s'", format,
+			renderOutputFormatDir, renderOutputFormatSingleYaml, renderOutputFormatJson)
+	}
+}
+
+// writeSingleRenderOutput writes all rendered objects, in deployment order, into a single file inside dir. The file
+// is either a multi-document yaml file or a json array, depending on format. Returns the path of the written file.
+func writeSingleRenderOutput(targetCtx *target_context.TargetContext, format string, dir string) (string, error) {
+	var p string
+	switch format {
+	case renderOutputFormatSingleYaml:
+		p = filepath.Join(dir, "rendered.yaml")
+	case renderOutputFormatJson:
+		p = filepath.Join(dir, "rendered.json")
+	default:
+		return "", checkRenderOutputFormat(format)
+	}
+
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	err = writeSingleRenderOutputTo(f, targetCtx, format)
+	if err != nil {
+		return "", err
+	}
+	return p, f.Close()
+}
+
+// writeSingleRenderOutputTo writes all rendered objects, in deployment order, to w. See writeSingleRenderOutput for
+// details.
+func writeSingleRenderOutputTo(w io.Writer, targetCtx *target_context.TargetContext, format string) error {
+	var all []interface{}
+	for _, o := range targetCtx.DeploymentCollection.FilterRenderedObjects() {
+		if deployment.IsHookObject(o) {
+			o = o.Clone()
+			o.SetK8sAnnotation(renderedHookAnnotation, "true")
+		}
+		all = append(all, o.Object)
+	}
+
+	switch format {
+	case renderOutputFormatSingleYaml:
+		return yaml.WriteYamlAllStream(w, all)
+	case renderOutputFormatJson:
+		if all == nil {
+			all = []interface{}{}
+		}
+		s, err := yaml.WriteJsonString(all)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, s)
+		return err
+	default:
+		return checkRenderOutputFormat(format)
+	}
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"bytes"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newRenderOutputTestTargetContext() *target_context.TargetContext {
+	newConfigMap := func(name string, annotations map[string]string) *uo.UnstructuredObject {
+		o := uo.FromMap(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+		})
+		for k, v := range annotations {
+			o.SetK8sAnnotation(k, v)
+		}
+		return o
+	}
+
+	return &target_context.TargetContext{
+		DeploymentCollection: &deployment.DeploymentCollection{
+			Deployments: []*deployment.DeploymentItem{
+				{Config: &types.DeploymentItemConfig{}, Objects: []*uo.UnstructuredObject{newConfigMap("cm1", nil)}},
+				{Config: &types.DeploymentItemConfig{}, Objects: []*uo.UnstructuredObject{
+					newConfigMap("hook", map[string]string{"kluctl.io/hook": "pre-deploy"}),
+					newConfigMap("cm2", nil),
+				}},
+			},
+		},
+	}
+}
+
+func TestWriteSingleRenderOutput(t *testing.T) {
+	targetCtx := newRenderOutputTestTargetContext()
+
+	checkObjects := func(l []any) {
+		if !assert.Len(t, l, 3) {
+			return
+		}
+		var names []string
+		for _, x := range l {
+			o := uo.FromMap(x.(map[string]any))
+			names = append(names, o.GetK8sName())
+			if o.GetK8sName() == "hook" {
+				assert.Equal(t, "true", *o.GetK8sAnnotation(renderedHookAnnotation))
+			} else {
+				assert.Nil(t, o.GetK8sAnnotation(renderedHookAnnotation))
+			}
+		}
+		assert.Equal(t, []string{"cm1", "hook", "cm2"}, names)
+	}
+
+	dir := t.TempDir()
+	p, err := writeSingleRenderOutput(targetCtx, renderOutputFormatSingleYaml, filepath.Join(dir, "sub"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "sub", "rendered.yaml"), p)
+	b, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	l, err := yaml.ReadYamlAllString(string(b))
+	assert.NoError(t, err)
+	checkObjects(l)
+
+	p, err = writeSingleRenderOutput(targetCtx, renderOutputFormatJson, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "rendered.json"), p)
+	var l2 []any
+	err = yaml.ReadYamlFile(p, &l2)
+	assert.NoError(t, err)
+	checkObjects(l2)
+
+	buf := bytes.NewBuffer(nil)
+	err = writeSingleRenderOutputTo(buf, targetCtx, renderOutputFormatSingleYaml)
+	assert.NoError(t, err)
+	l, err = yaml.ReadYamlAllString(buf.String())
+	assert.NoError(t, err)
+	checkObjects(l)
+
+	// an empty render output must still be a valid json array
+	buf = bytes.NewBuffer(nil)
+	err = writeSingleRenderOutputTo(buf, &target_context.TargetContext{DeploymentCollection: &deployment.DeploymentCollection{}}, renderOutputFormatJson)
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", buf.String())
+
+	_, err = writeSingleRenderOutput(targetCtx, "invalid", dir)
+	assert.ErrorContains(t, err, "invalid render output format 'invalid'")
+}
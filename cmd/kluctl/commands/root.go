@@ -62,6 +62,7 @@ type GlobalFlags struct {
 type cli struct {
 	GlobalFlags
 
+	CheckDrift  checkDriftCmd  `cmd:"" help:"Checks deployed objects for manual modifications since the last deployment"`
 	Delete      deleteCmd      `cmd:"" help:"Delete a target (or parts of it) from the corresponding cluster"`
 	Deploy      deployCmd      `cmd:"" help:"Deploys a target to the corresponding cluster"`
 	Diff        diffCmd        `cmd:"" help:"Perform a diff between the locally rendered target and the already deployed target"`
@@ -72,6 +73,11 @@ type cli struct {
 	PokeImages  pokeImagesCmd  `cmd:"" help:"Replace all images in target"`
 	Prune       pruneCmd       `cmd:"" help:"Searches the target cluster for prunable objects and deletes them"`
 	Render      renderCmd      `cmd:"" help:"Renders all resources and configuration files"`
+	RenderVars  renderVarsCmd  `cmd:"" help:"Loads vars sources one by one and outputs the contributed vars"`
+	Results     resultsCmd     `cmd:"" help:"Command results related sub-commands"`
+	RunMacro    runCmd         `cmd:"run" help:"Runs a command macro defined in the kluctl config"`
+	Sbom        sbomCmd        `cmd:"" help:"Renders the target and outputs an SBOM of all used container images"`
+	Schema      schemaCmd      `cmd:"" help:"Outputs the JSON schema of Kluctl configuration files"`
 	Validate    validateCmd    `cmd:"" help:"Validates the already deployed deployment"`
 	Controller  controllerCmd  `cmd:"" help:"Kluctl controller sub-commands"`
 	Gitops      gitopsCmd      `cmd:"" help:"GitOps sub-commands"`
@@ -347,6 +353,18 @@ composed of multiple smaller parts (Helm/Kustomize/...) in a manageable and unif
 		return err
 	}
 
+	// macros are only loaded when actually used, so that invalid macros do not break other commands
+	if runIdx := findRunCommand(args, rootCmd.PersistentFlags()); runIdx != -1 {
+		macros, err := loadMacros()
+		if err != nil {
+			return err
+		}
+		args, err = expandMacros(args, runIdx, macros)
+		if err != nil {
+			return err
+		}
+	}
+
 	rootCmd.SetContext(ctx)
 	rootCmd.SetArgs(args)
 	rootCmd.Version = version.GetVersion()
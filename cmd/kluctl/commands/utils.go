@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/hashicorp/go-multierror"
 	"github.com/kluctl/kluctl/lib/git"
 	"github.com/kluctl/kluctl/lib/git/auth"
 	"github.com/kluctl/kluctl/lib/git/messages"
@@ -21,13 +22,20 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
 	"github.com/kluctl/kluctl/v2/pkg/results"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 	"os"
+	"path/filepath"
+	"regexp"
 	client2 "sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
+	"sync"
 )
 
 func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.KubeconfigFlags, projectFlags args.ProjectFlags,
@@ -38,6 +46,10 @@ func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.Kubeco
 	internalDeploy bool, strictTemplates bool, forCompletion bool, cb func(ctx context.Context, p *kluctl_project.LoadedKluctlProject) error) error {
 	globalFlags := getCobraGlobalFlags(ctx)
 
+	if kubeconfigFlags != nil && !forCompletion {
+		kubeconfigFlags.WarnRateLimits(ctx)
+	}
+
 	j2, err := kluctl_jinja2.NewKluctlJinja2(ctx, strictTemplates, globalFlags.UseSystemPython)
 	if err != nil {
 		return err
@@ -85,6 +97,9 @@ func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.Kubeco
 	} else {
 		gitAuth.RegisterAuthProvider(x, false)
 	}
+	if x := gitCredentials.BuildCredentialsFileAuthProvider(messageCallbacks); x != nil {
+		gitAuth.RegisterAuthProviderAfterEnv(x)
+	}
 	if x, err := helmCredentials.BuildAuthProvider(ctx); err != nil {
 		return err
 	} else {
@@ -96,10 +111,12 @@ func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.Kubeco
 		ociAuth.RegisterAuthProvider(x, false)
 	}
 
-	gitRp := repocache.NewGitRepoCache(ctx, sshPool, gitAuth, sourceOverrides, projectFlags.GitCacheUpdateInterval)
+	gitRp := repocache.NewGitRepoCache(ctx, sshPool, gitAuth, sourceOverrides, projectFlags.GitCacheUpdateInterval, projectFlags.GitTimeout)
+	gitRp.SetOffline(projectFlags.NoGitFetch)
 	defer gitRp.Clear()
 
 	ociRp := repocache.NewOciRepoCache(ctx, ociAuth, sourceOverrides, projectFlags.GitCacheUpdateInterval)
+	ociRp.SetOffline(projectFlags.NoGitFetch)
 	defer gitRp.Clear()
 
 	externalArgs, err := argsFlags.LoadArgs()
@@ -116,6 +133,8 @@ func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.Kubeco
 		OciRP:              ociRp,
 		OciAuthProvider:    ociAuth,
 		HelmAuthProvider:   helmAuth,
+		SopsAgeKeyFiles:    projectFlags.SopsAgeKeyFile,
+		StrictArgs:         projectFlags.StrictArgs,
 		ClientConfigGetter: clientConfigGetter(kubeconfigFlags, forCompletion),
 	}
 
@@ -141,20 +160,45 @@ type projectTargetCommandArgs struct {
 	renderOutputDirFlags args.RenderOutputDirFlags
 	commandResultFlags   *args.CommandResultFlags
 
+	// commandResultReadOnlyFlags is used to create a read-only result store in case commandResultFlags is not set
+	commandResultReadOnlyFlags *args.CommandResultReadOnlyFlags
+
 	discriminator string
 
-	internalDeploy    bool
-	forCompletion     bool
-	offlineKubernetes bool
-	kubernetesVersion string
+	internalDeploy      bool
+	forCompletion       bool
+	offlineKubernetes   bool
+	kubernetesVersion   string
+	offlineApiResources string
+	clusterFixtureFlags args.ClusterFixtureFlags
+
+	// noLoadDeployment skips loading the deployment project. The command context will then only contain the prepared
+	// target context and the initial vars
+	noLoadDeployment bool
+
+	// renderOutputToStdout allows single file render output formats without --render-output-dir. The command is then
+	// responsible for writing the render output to stdout
+	renderOutputToStdout bool
+
+	// interactive must be set when the command might prompt the user, which is not possible for multiple contexts
+	// running in parallel
+	interactive bool
+	// outputPaths contains all files the command writes its output to. These would overwrite each other when running
+	// the command for multiple contexts
+	outputPaths []string
 }
 
 type commandCtx struct {
 	targetCtx *target_context.TargetContext
 	images    *deployment.Images
+	varsCtx   *vars.VarsCtx
 
-	resultId    string
-	resultStore results.ResultStore
+	resultId           string
+	resultStore        results.ResultStore
+	commandResultFlags *args.CommandResultFlags
+
+	// renderOutputFile is set when a single file render output format is used
+	renderOutputFile string
 }
 
 func withProjectCommandContext(ctx context.Context, args projectTargetCommandArgs, cb func(cmdCtx *commandCtx) error) error {
@@ -163,7 +207,118 @@ func withProjectCommandContext(ctx context.Context, args projectTargetCommandArg
 	})
 }
 
+// withProjectTargetCommandContext invokes cb once per selected kube context (see --context and --all-contexts-matching),
+// or exactly once if no or a single context was selected. Each invocation gets its own target context and command
+// result id. Errors of all invocations are aggregated.
 func withProjectTargetCommandContext(ctx context.Context, args projectTargetCommandArgs, p *kluctl_project.LoadedKluctlProject, cb func(cmdCtx *commandCtx) error) error {
+	contexts, err := selectKubeContexts(args.kubeconfigFlags, args.targetFlags)
+	if err != nil {
+		return err
+	}
+	if len(contexts) == 0 {
+		return withProjectTargetCommandContextForContext(ctx, args, p, "", cb)
+	}
+	if len(contexts) == 1 {
+		return withProjectTargetCommandContextForContext(ctx, args, p, contexts[0], cb)
+	}
+
+	parallelism := args.targetFlags.ContextsParallelism
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	err = checkMultiContextArgs(args, parallelism)
+	if err != nil {
+		return err
+	}
+
+	status.Infof(ctx, "Running command for %d contexts: %s", len(contexts), strings.Join(contexts, ", "))
+
+	return runForKubeContexts(contexts, parallelism, func(kubeContext string) error {
+		status.Infof(ctx, "Running command for context %s", kubeContext)
+
+		contextArgs := args
+		if args.renderOutputDirFlags.RenderOutputDir != "" {
+			// each context gets its own render output directory
+			contextArgs.renderOutputDirFlags.RenderOutputDir = filepath.Join(args.renderOutputDirFlags.RenderOutputDir, kubeContext)
+		}
+		return withProjectTargetCommandContextForContext(ctx, contextArgs, p, kubeContext, cb)
+	})
+}
+
+// checkMultiContextArgs verifies that the command can be run for multiple contexts. File outputs are rejected as every
+// context would overwrite the output of the previous one, and prompts can not be shown for multiple contexts at
+// the same time.
+func checkMultiContextArgs(args projectTargetCommandArgs, parallelism int) error {
+	if len(args.outputPaths) != 0 {
+		return fmt.Errorf("writing output to files (%s) is not supported when running for multiple contexts, as the outputs of the contexts would overwrite each other", strings.Join(args.outputPaths, ", "))
+	}
+	if parallelism > 1 && args.interactive {
+		return fmt.Errorf("--contexts-parallelism > 1 requires --yes or --dry-run, as confirmation prompts can not be shown for multiple contexts at the same time")
+	}
+	return nil
+}
+
+// runForKubeContexts invokes cb for all contexts, with at most parallelism invocations running at the same time.
+// Errors of all invocations are aggregated.
+func runForKubeContexts(contexts []string, parallelism int, cb func(kubeContext string) error) error {
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var errs *multierror.Error
+	sem := make(chan struct{}, parallelism)
+	for _, kubeContext := range contexts {
+		kubeContext := kubeContext
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := cb(kubeContext)
+			if err != nil {
+				mutex.Lock()
+				errs = multierror.Append(errs, fmt.Errorf("context %s: %w", kubeContext, err))
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errs.ErrorOrNil()
+}
+
+// selectKubeContexts returns the contexts selected via --context or --all-contexts-matching, in a stable order
+func selectKubeContexts(kubeconfigFlags args.KubeconfigFlags, targetFlags args.TargetFlags) ([]string, error) {
+	if targetFlags.AllContextsMatching == "" {
+		return targetFlags.Context, nil
+	}
+	if len(targetFlags.Context) != 0 {
+		return nil, fmt.Errorf("--context and --all-contexts-matching can not be combined")
+	}
+
+	r, err := regexp.Compile(targetFlags.AllContextsMatching)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --all-contexts-matching regex: %w", err)
+	}
+	configLoadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	configLoadingRules.ExplicitPath = kubeconfigFlags.Kubeconfig.String()
+	rawConfig, err := configLoadingRules.Load()
+	if err != nil {
+		return nil, err
+	}
+	var ret []string
+	for n := range rawConfig.Contexts {
+		if r.MatchString(n) {
+			ret = append(ret, n)
+		}
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("no kubeconfig context matches '%s'", targetFlags.AllContextsMatching)
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+func withProjectTargetCommandContextForContext(ctx context.Context, args projectTargetCommandArgs, p *kluctl_project.LoadedKluctlProject, contextOverride string, cb func(cmdCtx *commandCtx) error) error {
 	tmpDir, err := os.MkdirTemp(utils.GetTmpBaseDir(ctx), "project-")
 	if err != nil {
 		return fmt.Errorf("creating temporary project directory failed: %w", err)
@@ -179,14 +334,37 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 		return err
 	}
 	images.PrependFixedImages(fixedImages)
+	imageOverrides, err := args.imageFlags.LoadImageOverridesFromArgs()
+	if err != nil {
+		return err
+	}
+	err = images.AddImageOverrides(imageOverrides)
+	if err != nil {
+		return err
+	}
 
 	inclusion, err := args.inclusionFlags.ParseInclusionFromArgs()
 	if err != nil {
 		return err
 	}
 
+	renderOutputFormat := args.renderOutputDirFlags.RenderOutputFormat
+	if renderOutputFormat == "" {
+		renderOutputFormat = renderOutputFormatDir
+	}
+	err = checkRenderOutputFormat(renderOutputFormat)
+	if err != nil {
+		return err
+	}
+	if renderOutputFormat != renderOutputFormatDir && args.renderOutputDirFlags.RenderOutputDir == "" && !args.renderOutputToStdout {
+		return fmt.Errorf("--render-output-format=%s requires --render-output-dir", renderOutputFormat)
+	}
+	if args.renderOutputDirFlags.RenderExcludeHooks && args.renderOutputDirFlags.RenderOnlyHooks {
+		return fmt.Errorf("--render-exclude-hooks and --render-only-hooks can not be used together")
+	}
+
 	renderOutputDir := args.renderOutputDirFlags.RenderOutputDir
-	if renderOutputDir == "" {
+	if renderOutputDir == "" || renderOutputFormat != renderOutputFormatDir {
 		tmpDir, err := os.MkdirTemp(tmpDir, "rendered")
 		if err != nil {
 			return err
@@ -195,45 +373,76 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 		renderOutputDir = tmpDir
 	}
 
+	var offlineApiResources *k8s.OfflineApiResources
+	if args.offlineKubernetes && args.offlineApiResources != "" {
+		offlineApiResources, err = k8s.LoadOfflineApiResources(args.offlineApiResources)
+		if err != nil {
+			return err
+		}
+	}
+
 	targetParams := target_context.TargetContextParams{
-		TargetName:         args.targetFlags.Target,
-		TargetNameOverride: args.targetFlags.TargetNameOverride,
-		ContextOverride:    args.targetFlags.Context,
-		Discriminator:      args.discriminator,
-		OfflineK8s:         args.offlineKubernetes,
-		K8sVersion:         args.kubernetesVersion,
-		DryRun:             args.dryRunArgs == nil || args.dryRunArgs.DryRun || args.forCompletion,
-		Images:             images,
-		Inclusion:          inclusion,
-		OciAuthProvider:    p.LoadArgs.OciAuthProvider,
-		HelmAuthProvider:   p.LoadArgs.HelmAuthProvider,
-		RenderOutputDir:    renderOutputDir,
+		TargetName:          args.targetFlags.Target,
+		TargetNameOverride:  args.targetFlags.TargetNameOverride,
+		ContextOverride:     contextOverride,
+		Discriminator:       args.discriminator,
+		OfflineK8s:          args.offlineKubernetes,
+		K8sVersion:          args.kubernetesVersion,
+		OfflineApiResources: offlineApiResources,
+		DryRun:              args.dryRunArgs == nil || args.dryRunArgs.DryRun || args.forCompletion,
+		Images:              images,
+		Inclusion:           inclusion,
+		OciAuthProvider:     p.LoadArgs.OciAuthProvider,
+		HelmAuthProvider:    p.LoadArgs.HelmAuthProvider,
+		RenderOutputDir:     renderOutputDir,
+		RenderExcludeHooks:  args.renderOutputDirFlags.RenderExcludeHooks,
+		RenderOnlyHooks:     args.renderOutputDirFlags.RenderOnlyHooks,
+
+		AllowMissingSopsKeys: args.projectFlags.AllowMissingSopsKeys,
+		NoIgnoreMissingVars:  args.projectFlags.NoIgnoreMissingVars,
 	}
 
 	commandResultId := uuid.NewString()
 
-	clientConfig, contextName, err := p.LoadK8sConfig(ctx, targetParams.TargetName, targetParams.ContextOverride, targetParams.OfflineK8s)
+	clientConfig, contextName, err := loadK8sConfigWithFixture(ctx, p, targetParams, args.clusterFixtureFlags)
 	if err != nil {
 		return err
 	}
 
 	var k *k8s.K8sCluster
-	var resultStore results.ResultStore
+	var mapper meta.RESTMapper
 	if clientConfig != nil {
-		discovery, mapper, err := k8s.CreateDiscoveryAndMapper(ctx, clientConfig)
+		if args.clusterFixtureFlags.ReplayCluster == "" {
+			err = k8s.CheckImpersonation(ctx, clientConfig)
+			if err != nil {
+				return err
+			}
+		}
+
+		var dc discovery.CachedDiscoveryInterface
+		var m meta.RESTMapper
+		if args.clusterFixtureFlags.RecordCluster != "" || args.clusterFixtureFlags.ReplayCluster != "" {
+			dc, m, err = k8s.CreateUncachedDiscoveryAndMapper(clientConfig)
+		} else {
+			dc, m, err = k8s.CreateDiscoveryAndMapper(ctx, clientConfig)
+		}
 		if err != nil {
 			return err
 		}
+		mapper = m
 
 		s := status.Start(ctx, fmt.Sprintf("Initializing k8s client"))
-		k, err = k8s.NewK8sCluster(ctx, clientConfig, discovery, mapper, targetParams.DryRun)
+		k, err = k8s.NewK8sCluster(ctx, clientConfig, dc, mapper, targetParams.DryRun)
 		if err != nil {
 			s.Failed()
 			return err
 		}
 		s.Success()
+	}
 
-		resultStore, err = buildResultStoreRW(ctx, clientConfig, mapper, args.commandResultFlags, false)
+	var resultStore results.ResultStore
+	if args.clusterFixtureFlags.ReplayCluster == "" {
+		resultStore, err = buildResultStoreRW(ctx, clientConfig, mapper, args.commandResultFlags, p.LoadArgs.OciAuthProvider, false)
 		if err != nil {
 			if !errors.IsForbidden(err) {
 				return err
@@ -241,28 +450,86 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 			status.Warningf(ctx, "Not enough permissions to write to the result store.")
 		}
 	}
+	if resultStore == nil && args.clusterFixtureFlags.ReplayCluster == "" && args.commandResultReadOnlyFlags != nil && (clientConfig != nil || args.commandResultReadOnlyFlags.CommandResultStore == "fs") {
+		resultStore, err = buildResultStoreRO(ctx, clientConfig, mapper, args.commandResultReadOnlyFlags)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.noLoadDeployment {
+		targetCtx, varsCtx, err := target_context.PrepareTargetContext(ctx, p, contextName, k, targetParams)
+		if err != nil {
+			return err
+		}
+		return cb(&commandCtx{
+			targetCtx:          targetCtx,
+			images:             images,
+			varsCtx:            varsCtx,
+			resultId:           commandResultId,
+			resultStore:        resultStore,
+			commandResultFlags: args.commandResultFlags,
+		})
+	}
 
 	targetCtx, err := target_context.NewTargetContext(ctx, p, contextName, k, targetParams)
 	if err != nil {
 		return err
 	}
 
+	var renderOutputFile string
 	if !args.forCompletion {
 		err = targetCtx.DeploymentCollection.Prepare()
 		if err != nil {
-			return err
+			return targetCtx.SharedContext.VarsLoader.WrapMissingSopsKeysError(err)
+		}
+
+		if renderOutputFormat != renderOutputFormatDir && args.renderOutputDirFlags.RenderOutputDir != "" {
+			renderOutputFile, err = writeSingleRenderOutput(targetCtx, renderOutputFormat, args.renderOutputDirFlags.RenderOutputDir)
+			if err != nil {
+				return fmt.Errorf("failed to write render output: %w", err)
+			}
 		}
 	}
 	cmdCtx := &commandCtx{
-		targetCtx:   targetCtx,
-		images:      images,
-		resultId:    commandResultId,
-		resultStore: resultStore,
+		targetCtx:          targetCtx,
+		images:             images,
+		resultId:           commandResultId,
+		resultStore:        resultStore,
+		commandResultFlags: args.commandResultFlags,
+		renderOutputFile:   renderOutputFile,
 	}
 
 	return cb(cmdCtx)
 }
 
+// loadK8sConfigWithFixture loads the client config for the target, unless a cluster fixture is being replayed. When
+// recording a cluster fixture, the returned config records all requests into the fixture directory.
+func loadK8sConfigWithFixture(ctx context.Context, p *kluctl_project.LoadedKluctlProject, targetParams target_context.TargetContextParams, fixtureFlags args.ClusterFixtureFlags) (*rest.Config, string, error) {
+	if fixtureFlags.RecordCluster != "" && fixtureFlags.ReplayCluster != "" {
+		return nil, "", fmt.Errorf("--record-cluster and --replay-cluster can not be combined")
+	}
+	if fixtureFlags.ReplayCluster != "" {
+		status.Infof(ctx, "Replaying cluster fixture from %s", fixtureFlags.ReplayCluster)
+		return k8s.NewReplayConfig(fixtureFlags.ReplayCluster)
+	}
+
+	clientConfig, contextName, err := p.LoadK8sConfig(ctx, targetParams.TargetName, targetParams.ContextOverride, targetParams.OfflineK8s)
+	if err != nil {
+		return nil, "", err
+	}
+	if fixtureFlags.RecordCluster != "" {
+		if clientConfig == nil {
+			return nil, "", fmt.Errorf("--record-cluster requires a cluster connection")
+		}
+		clientConfig, err = k8s.NewRecordingConfig(clientConfig, fixtureFlags.RecordCluster, contextName)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return clientConfig, contextName, nil
+}
+
 func clientConfigGetter(kubeconfigFlags *args.KubeconfigFlags, forCompletion bool) func(context *string) (*rest.Config, *api.Config, error) {
 	return func(context *string) (*rest.Config, *api.Config, error) {
 		if forCompletion {
@@ -289,15 +556,53 @@ func clientConfigGetter(kubeconfigFlags *args.KubeconfigFlags, forCompletion boo
 		if err != nil {
 			return nil, nil, err
 		}
+		if kubeconfigFlags != nil {
+			err = kubeconfigFlags.ApplyToRestConfig(restConfig)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
 		return restConfig, &rawConfig, nil
 	}
 }
 
+// buildNonClusterResultStore returns a file or OCI based result store if --command-result-store=fs or
+// --command-result-store=oci was passed. It returns nil if the result store is cluster based. If ociAuth is nil, the
+// default OCI auth providers are used.
+func buildNonClusterResultStore(ctx context.Context, flags *args.CommandResultReadOnlyFlags, ociAuth auth_provider.OciAuthProvider, allowWrite bool, keepCommandResultsCount int, keepValidateResultsCount int) (results.ResultStore, error) {
+	switch flags.CommandResultStore {
+	case "", "secrets":
+		return nil, nil
+	case "fs":
+		s, err := results.NewResultStoreFile(ctx, flags.CommandResultPath, allowWrite, keepCommandResultsCount, keepValidateResultsCount)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case "oci":
+		if ociAuth == nil {
+			ociAuth = auth_provider.NewDefaultAuthProviders("KLUCTL_REGISTRY")
+		}
+		s, err := results.NewResultStoreOci(ctx, flags.CommandResultOciUrl, ociAuth, allowWrite, keepCommandResultsCount, keepValidateResultsCount)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("invalid command result store %s", flags.CommandResultStore)
+	}
+}
+
 func buildResultStoreRO(ctx context.Context, restConfig *rest.Config, mapper meta.RESTMapper, flags *args.CommandResultReadOnlyFlags) (results.ResultStore, error) {
 	if flags == nil {
 		return nil, nil
 	}
 
+	nonClusterStore, err := buildNonClusterResultStore(ctx, flags, nil, false, 0, 0)
+	if err != nil || nonClusterStore != nil {
+		return nonClusterStore, err
+	}
+
 	c, err := client2.NewWithWatch(restConfig, client2.Options{
 		Mapper: mapper,
 	})
@@ -305,7 +610,7 @@ func buildResultStoreRO(ctx context.Context, restConfig *rest.Config, mapper met
 		return nil, err
 	}
 
-	resultStore, err := results.NewResultStoreSecrets(ctx, restConfig, c, false, flags.CommandResultNamespace, 0, 0)
+	resultStore, err := results.NewResultStoreSecrets(ctx, restConfig, c, false, flags.CommandResultNamespace, nil, 0, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -313,11 +618,19 @@ func buildResultStoreRO(ctx context.Context, restConfig *rest.Config, mapper met
 	return resultStore, nil
 }
 
-func buildResultStoreRW(ctx context.Context, restConfig *rest.Config, mapper meta.RESTMapper, flags *args.CommandResultFlags, startCleanup bool) (results.ResultStore, error) {
+func buildResultStoreRW(ctx context.Context, restConfig *rest.Config, mapper meta.RESTMapper, flags *args.CommandResultFlags, ociAuth auth_provider.OciAuthProvider, startCleanup bool) (results.ResultStore, error) {
 	if flags == nil || !flags.WriteCommandResult {
 		return nil, nil
 	}
 
+	nonClusterStore, err := buildNonClusterResultStore(ctx, &flags.CommandResultReadOnlyFlags, ociAuth, true, flags.KeepCommandResultsCount, flags.KeepValidateResultsCount)
+	if err != nil || nonClusterStore != nil {
+		return nonClusterStore, err
+	}
+	if restConfig == nil {
+		return nil, nil
+	}
+
 	c, err := client2.NewWithWatch(restConfig, client2.Options{
 		Mapper: mapper,
 	})
@@ -325,7 +638,7 @@ func buildResultStoreRW(ctx context.Context, restConfig *rest.Config, mapper met
 		return nil, err
 	}
 
-	resultStore, err := results.NewResultStoreSecrets(ctx, restConfig, c, true, flags.CommandResultNamespace, flags.KeepCommandResultsCount, flags.KeepValidateResultsCount)
+	resultStore, err := results.NewResultStoreSecrets(ctx, restConfig, c, true, flags.CommandResultNamespace, nil, flags.KeepCommandResultsCount, flags.KeepValidateResultsCount)
 	if err != nil {
 		return nil, err
 	}
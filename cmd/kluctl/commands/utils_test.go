@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: c
+  cluster:
+    server: https://127.0.0.1:6443
+users:
+- name: u
+  user:
+    token: t
+contexts:
+- name: prod-b
+  context: {cluster: c, user: u}
+- name: prod-a
+  context: {cluster: c, user: u}
+- name: test
+  context: {cluster: c, user: u}
+current-context: test
+`
+
+func TestSelectKubeContexts(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	assert.NoError(t, os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600))
+	kubeconfigFlags := args.KubeconfigFlags{Kubeconfig: args.ExistingFileType(kubeconfig)}
+
+	testCases := []struct {
+		name        string
+		targetFlags args.TargetFlags
+		result      []string
+		err         string
+	}{
+		{name: "none", targetFlags: args.TargetFlags{}, result: nil},
+		{name: "context", targetFlags: args.TargetFlags{Context: []string{"test", "prod-b"}}, result: []string{"test", "prod-b"}},
+		{name: "matching", targetFlags: args.TargetFlags{AllContextsMatching: "^prod-"}, result: []string{"prod-a", "prod-b"}},
+		{name: "no-match", targetFlags: args.TargetFlags{AllContextsMatching: "^staging-"}, err: "no kubeconfig context matches '^staging-'"},
+		{name: "invalid-regex", targetFlags: args.TargetFlags{AllContextsMatching: "("}, err: "invalid --all-contexts-matching regex"},
+		{name: "combined", targetFlags: args.TargetFlags{Context: []string{"test"}, AllContextsMatching: "prod"}, err: "--context and --all-contexts-matching can not be combined"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			contexts, err := selectKubeContexts(kubeconfigFlags, tc.targetFlags)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.result, contexts)
+		})
+	}
+}
+
+func TestCheckMultiContextArgs(t *testing.T) {
+	assert.NoError(t, checkMultiContextArgs(projectTargetCommandArgs{}, 4))
+	assert.NoError(t, checkMultiContextArgs(projectTargetCommandArgs{interactive: true}, 1))
+	assert.EqualError(t, checkMultiContextArgs(projectTargetCommandArgs{interactive: true}, 2),
+		"--contexts-parallelism > 1 requires --yes or --dry-run, as confirmation prompts can not be shown for multiple contexts at the same time")
+	assert.EqualError(t, checkMultiContextArgs(projectTargetCommandArgs{outputPaths: []string{"out.yaml"}}, 1),
+		"writing output to files (out.yaml) is not supported when running for multiple contexts, as the outputs of the contexts would overwrite each other")
+}
+
+func TestOutputFilePaths(t *testing.T) {
+	assert.Equal(t, []string{"a.yaml"}, outputFilePaths([]string{"text", "text=-", "yaml=a.yaml"}, true))
+	assert.Equal(t, []string{"a.yaml"}, outputFilePaths([]string{"-", "a.yaml"}, false))
+}
+
+func TestRunForKubeContexts(t *testing.T) {
+	contexts := []string{"c1", "c2", "c3", "c4", "c5"}
+
+	var mutex sync.Mutex
+	var called []string
+	var running atomic.Int32
+	var maxRunning atomic.Int32
+	err := runForKubeContexts(contexts, 2, func(kubeContext string) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		called = append(called, kubeContext)
+		mutex.Unlock()
+
+		if kubeContext == "c2" || kubeContext == "c4" {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	})
+
+	// all contexts are run, even if some of them fail
+	assert.ElementsMatch(t, contexts, called)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+	assert.ErrorContains(t, err, "context c2: failed")
+	assert.ErrorContains(t, err, "context c4: failed")
+	assert.NotContains(t, err.Error(), "context c1")
+
+	// parallelism of 1 runs the contexts sequentially in the given order
+	called = nil
+	err = runForKubeContexts(contexts, 1, func(kubeContext string) error {
+		called = append(called, kubeContext)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, contexts, called)
+}
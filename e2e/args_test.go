@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/kluctl/kluctl/v2/e2e/test_project"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
 	"os"
 	"testing"
 )
@@ -257,3 +258,61 @@ func TestArgsInDefaultDiscriminator(t *testing.T) {
 func TestArgsInTargetDiscriminator(t *testing.T) {
 	testArgsInDiscriminator(t, false)
 }
+
+func TestStrictArgs(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+	})
+
+	args := []any{
+		map[string]any{
+			"name": "a",
+			"type": "string",
+		},
+		map[string]any{
+			"name":    "b",
+			"default": false,
+		},
+		map[string]any{
+			"name": "c",
+			"default": map[string]any{
+				"nested": "default",
+			},
+		},
+	}
+
+	p.UpdateKluctlYaml(func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField(args, "args")
+		return nil
+	})
+
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"a": `{{ args.a }}`,
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test", "--strict-args", "-aa=a", "-ab=true", "-ac.nested=x")
+	cm := k.MustGetCoreV1(t, "configmaps", p.TestSlug(), "cm")
+	assertNestedFieldEquals(t, cm, "a", "data", "a")
+
+	_, _, err := p.Kluctl(t, "deploy", "--yes", "-t", "test", "--strict-args", "-aa=a", "-ax=x")
+	assert.ErrorContains(t, err, "unknown argument x")
+
+	_, _, err = p.Kluctl(t, "deploy", "--yes", "-t", "test", "--strict-args", "-aa=a", "-ab=x")
+	assert.ErrorContains(t, err, "argument b has the wrong type, expected boolean but got string")
+
+	_, _, err = p.Kluctl(t, "deploy", "--yes", "-t", "test", "--strict-args", "-aa=1")
+	assert.ErrorContains(t, err, "argument a has the wrong type, expected string")
+
+	// without --strict-args, unknown args are still allowed
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test", "-aa=a", "-ax=x")
+}
@@ -0,0 +1,65 @@
+package e2e
+
+import (
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAndReplayCluster(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"a": "v1",
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+	p.KluctlMust(t, "deploy", "--yes")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm")
+
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"a": "v2",
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	fixtureDir := filepath.Join(t.TempDir(), "fixture")
+	recordedStdout, _ := p.KluctlMust(t, "diff", "--record-cluster", fixtureDir)
+	assert.Contains(t, recordedStdout, "Changed objects:")
+
+	// change the cluster state, which must not be visible when replaying
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"a": "v3",
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+	p.KluctlMust(t, "deploy", "--yes")
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"a": "v2",
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	replayedStdout, _ := p.KluctlMust(t, "diff", "--replay-cluster", fixtureDir)
+	assert.Equal(t, recordedStdout, replayedStdout)
+
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"a": "v4",
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+	_, _, err := p.Kluctl(t, "diff", "--replay-cluster", fixtureDir)
+	assert.ErrorContains(t, err, "was not recorded in cluster fixture")
+}
@@ -0,0 +1,51 @@
+package e2e
+
+import (
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommandResultOutput(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+	})
+
+	addConfigMapDeployment(p, "cm", nil, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	jsonPath := filepath.Join(t.TempDir(), "result.json")
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test", "--write-command-result=false", "--command-result-output", "json="+jsonPath)
+	assertConfigMapExists(t, k, p.TestSlug(), "cm")
+
+	b, err := os.ReadFile(jsonPath)
+	assert.NoError(t, err)
+
+	var cr result.CompactedCommandResult
+	err = yaml.ReadYamlBytes(b, &cr)
+	assert.NoError(t, err)
+	assert.Equal(t, "deploy", cr.Command.Command)
+	assert.Equal(t, "test", cr.Command.Target)
+	assert.Len(t, cr.ToNonCompacted().Objects, 1)
+
+	stdout, _ := p.KluctlMust(t, "deploy", "--yes", "-t", "test", "--dry-run", "-o", "text=/dev/null", "--command-result-output", "yaml")
+	var cr2 result.CompactedCommandResult
+	err = yaml.ReadYamlString(stdout, &cr2)
+	assert.NoError(t, err)
+	assert.Equal(t, "deploy", cr2.Command.Command)
+	assert.True(t, cr2.Command.DryRun)
+}
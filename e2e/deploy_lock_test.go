@@ -0,0 +1,62 @@
+package e2e
+
+import (
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+	"time"
+)
+
+func TestDeployLockLost(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	// cm1 never gets ready, so the deployment is still waiting for it when the lock gets lost
+	addConfigMapDeployment(p, "cm1", nil, resourceOpts{
+		name:      "cm1",
+		namespace: p.TestSlug(),
+		annotations: map[string]string{
+			"kluctl.io/wait-readiness": "true",
+			"kluctl.io/is-ready":       "false",
+		},
+	})
+	p.AddDeploymentItem(".", uo.FromMap(map[string]interface{}{
+		"barrier": true,
+	}))
+	addConfigMapDeployment(p, "cm2", nil, resourceOpts{
+		name:      "cm2",
+		namespace: p.TestSlug(),
+	})
+
+	leaseGvr := schema.GroupVersionResource{Group: "coordination.k8s.io", Version: "v1", Resource: "leases"}
+
+	go func() {
+		// wait for the deployment to hold the lock and then take it over
+		for i := 0; i < 300; i++ {
+			_, err := k.Get(leaseGvr, p.TestSlug(), "kluctl-lock-test")
+			if err == nil {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		patchObject(t, k, leaseGvr, p.TestSlug(), "kluctl-lock-test", func(o *uo.UnstructuredObject) {
+			_ = o.SetNestedField("other", "spec", "holderIdentity")
+		})
+	}()
+
+	_, stderr, err := p.Kluctl(t, "deploy", "--yes", "-t", "test", "--lock", "--command-result-namespace", p.TestSlug())
+	assert.Error(t, err)
+	assert.Contains(t, stderr, "lock was taken over by someone else")
+
+	assertConfigMapExists(t, k, p.TestSlug(), "cm1")
+	// the item after the barrier must not be applied after the lock got lost
+	assertConfigMapNotExists(t, k, p.TestSlug(), "cm2")
+}
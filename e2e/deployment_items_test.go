@@ -6,6 +6,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/stretchr/testify/assert"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -287,3 +288,66 @@ func TestIncludeLocalFromRoot(t *testing.T) {
 func TestIncludeLocalFromSubdir(t *testing.T) {
 	testLocalIncludes(t, "foo")
 }
+
+func TestMessageDeploymentItem(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	addConfigMapDeployment(p, "cm", nil, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+	p.AddDeploymentItem(".", uo.FromMap(map[string]interface{}{
+		"barrier": true,
+	}))
+	p.AddDeploymentItem(".", uo.FromMap(map[string]interface{}{
+		"message": "About to deploy cm2 to {{ target.name }}",
+	}))
+	addConfigMapDeployment(p, "cm2", nil, resourceOpts{
+		name:      "cm2",
+		namespace: p.TestSlug(),
+	})
+
+	// the message must be printed exactly once, no matter if the preceding diff or a canary pass walked the
+	// deployment items already
+	_, stderr := p.KluctlMust(t, "deploy", "--yes", "-t", "test", "--dry-run")
+	assert.Equal(t, 1, strings.Count(stderr, "About to deploy cm2 to test"))
+
+	_, stderr = p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	assert.Equal(t, 1, strings.Count(stderr, "About to deploy cm2 to test"))
+	assertConfigMapExists(t, k, p.TestSlug(), "cm")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+
+	_, stderr = p.KluctlMust(t, "deploy", "--yes", "-t", "test", "--canary-percent", "50")
+	assert.Equal(t, 1, strings.Count(stderr, "About to deploy cm2 to test"))
+}
+
+func TestConfirmEachNonInteractive(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	addConfigMapDeployment(p, "cm", nil, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	// tests are never run with an interactive stdin, so --confirm-each must fail instead of waiting for input
+	_, _, err := p.Kluctl(t, "deploy", "--yes", "--confirm-each")
+	assert.ErrorContains(t, err, "--confirm-each requires an interactive terminal")
+	assertConfigMapNotExists(t, k, p.TestSlug(), "cm")
+
+	// dry-runs never prompt
+	p.KluctlMust(t, "deploy", "--yes", "--confirm-each", "--dry-run")
+}
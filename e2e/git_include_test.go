@@ -8,6 +8,7 @@ import (
 	"github.com/kluctl/kluctl/v2/e2e/test_project"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/stretchr/testify/assert"
+	"net"
 	"testing"
 )
 
@@ -219,3 +220,43 @@ func TestGitIncludeRef(t *testing.T) {
 	assertConfigMapExists(t, k, p.TestSlug(), "tag5")
 	assertConfigMapExists(t, k, p.TestSlug(), "commit6")
 }
+
+func TestGitIncludeTimeout(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	// accepts connections but never answers
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() {
+				_ = c.Close()
+			})
+		}
+	}()
+
+	p := test_project.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {})
+
+	gitUrl := fmt.Sprintf("http://%s/repos/hanging", l.Addr().String())
+	p.AddDeploymentItem("", uo.FromMap(map[string]interface{}{
+		"git": map[string]any{
+			"url": gitUrl,
+		},
+	}))
+
+	_, _, err = p.Kluctl(t, "deploy", "--yes", "-t", "test", "--git-timeout", "1s")
+	assert.ErrorContains(t, err, fmt.Sprintf("git operation for %s timed out after 1s", gitUrl))
+}
@@ -364,7 +364,7 @@ func (suite *GitopsTestSuite) getCommandResult(id string) *result.CommandResult
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	rs, err := results.NewResultStoreSecrets(ctx, suite.k.RESTConfig(), suite.k.Client, false, "", 0, 0)
+	rs, err := results.NewResultStoreSecrets(ctx, suite.k.RESTConfig(), suite.k.Client, false, "", nil, 0, 0)
 	assert.NoError(suite.T(), err)
 
 	cr, err := rs.GetCommandResult(results.GetCommandResultOptions{Id: id})
@@ -378,7 +378,7 @@ func (suite *GitopsTestSuite) getValidateResult(id string) *result.ValidateResul
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	rs, err := results.NewResultStoreSecrets(ctx, suite.k.RESTConfig(), suite.k.Client, false, "", 0, 0)
+	rs, err := results.NewResultStoreSecrets(ctx, suite.k.RESTConfig(), suite.k.Client, false, "", nil, 0, 0)
 	assert.NoError(suite.T(), err)
 
 	vr, err := rs.GetValidateResult(results.GetValidateResultOptions{Id: id})
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/kluctl/kluctl/lib/yaml"
 	test_utils "github.com/kluctl/kluctl/v2/e2e/test-utils"
 	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/vars/sops_test_resources"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -1244,3 +1247,68 @@ func TestHelmWithTarget(t *testing.T) {
 
 	assertConfigMapExists(t, k, p.TestSlug(), "test-helm1-test-chart1")
 }
+
+func TestHelmValuesDiff(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t, test_project.WithUseProcess(true))
+	setSopsKey(p)
+
+	createNamespace(t, k, p.TestSlug())
+
+	charts := []test_utils.RepoChart{
+		{ChartName: "test-chart1", Version: "0.1.0"},
+	}
+	repo := test_utils.NewHelmTestRepo(test_utils.TestHelmRepo_Helm, "", charts)
+	repo.Start(t)
+
+	p.AddHelmDeployment("helm1", repo, "test-chart1", "0.1.0", "test-helm1", p.TestSlug(), map[string]any{
+		"data": map[string]any{
+			"a": "x1",
+		},
+	})
+	p.UpdateYaml("helm1/helm-chart.yaml", func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField([]map[string]any{
+			{"file": "encrypted-values.yaml"},
+		}, "helmChart", "values")
+		return nil
+	}, "")
+	p.UpdateFile("helm1/encrypted-values.yaml", func(f string) (string, error) {
+		b, _ := sops_test_resources.TestResources.ReadFile("test.yaml")
+		return string(b), nil
+	}, "")
+
+	p.KluctlMust(t, "helm-pull")
+
+	jsonPath := filepath.Join(t.TempDir(), "result.json")
+	p.KluctlMust(t, "deploy", "--yes", "--command-result-output", "json="+jsonPath)
+	assertConfigMapExists(t, k, p.TestSlug(), "test-helm1-test-chart1")
+
+	b, err := os.ReadFile(jsonPath)
+	assert.NoError(t, err)
+	var cr result.CompactedCommandResult
+	err = yaml.ReadYamlBytes(b, &cr)
+	assert.NoError(t, err)
+	assert.Len(t, cr.Deployment.Deployments, 1)
+
+	renderedValues := cr.Deployment.Deployments[0].RenderedHelmValues
+	assertNestedFieldEquals(t, renderedValues, "x1", "data", "a")
+	assertNestedFieldEquals(t, renderedValues, "*****", "test1", "test2")
+
+	p.UpdateYaml("helm1/helm-values.yaml", func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField("x2", "data", "a")
+		return nil
+	}, "")
+
+	stdout, _ := p.KluctlMust(t, "diff", "--helm-values-diff", "-o", "yaml")
+	var cr2 result.CompactedCommandResult
+	err = yaml.ReadYamlString(stdout, &cr2)
+	assert.NoError(t, err)
+	assert.Len(t, cr2.HelmValuesChanges, 1)
+	assert.Equal(t, "test-helm1", cr2.HelmValuesChanges[0].ReleaseName)
+	assert.Equal(t, p.TestSlug(), cr2.HelmValuesChanges[0].Namespace)
+	assert.Len(t, cr2.HelmValuesChanges[0].Changes, 1)
+	assert.Equal(t, "data.a", cr2.HelmValuesChanges[0].Changes[0].JsonPath)
+}
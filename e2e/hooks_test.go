@@ -78,13 +78,13 @@ func (s *hooksTestContext) addHookConfigMap(dir string, opts resourceOpts, isHel
 	if isHelm {
 		annotations["helm.sh/hook"] = hook
 		if hookDeletionPolicy != "" {
-			annotations["helm.sh/hook-deletion-policy"] = hookDeletionPolicy
+			annotations["helm.sh/hook-delete-policy"] = hookDeletionPolicy
 		}
 	} else {
 		annotations["kluctl.io/hook"] = hook
 	}
 	if hookDeletionPolicy != "" {
-		annotations["kluctl.io/hook-deletion-policy"] = hookDeletionPolicy
+		annotations["kluctl.io/hook-delete-policy"] = hookDeletionPolicy
 	}
 
 	opts.annotations = uo.CopyMergeStrMap(opts.annotations, annotations)
@@ -320,3 +320,49 @@ func TestHooksWait(t *testing.T) {
 	_, err = s.ensureHookExecuted2(t, 5*time.Second, "cm1", "hook1", "hook2", "hook3")
 	assert.NoError(t, err)
 }
+
+func TestHooksDeletePolicySucceeded(t *testing.T) {
+	t.Parallel()
+	s := prepareHookTestProject(t, "post-deploy", "hook-succeeded", false)
+	s.ensureHookExecuted(t, "cm1", "hook1")
+	assertConfigMapExists(t, s.k, s.p.TestSlug(), "cm1")
+	assertConfigMapNotExists(t, s.k, s.p.TestSlug(), "hook1")
+}
+
+func TestHooksDeletePolicyFailed(t *testing.T) {
+	t.Parallel()
+
+	s := prepareHookTestProjectBase(t)
+
+	s.p.AddKustomizeDeployment("hook", nil, nil)
+
+	s.addConfigMap("hook", resourceOpts{name: "cm1", namespace: s.p.TestSlug()})
+	s.addHookConfigMap("hook", resourceOpts{name: "hook1", namespace: s.p.TestSlug(), annotations: map[string]string{
+		"kluctl.io/is-ready":     "false",
+		"kluctl.io/hook-timeout": "3s",
+	}}, false, "post-deploy", "hook-failed")
+	s.addHookConfigMap("hook", resourceOpts{name: "hook2", namespace: s.p.TestSlug()}, false, "post-deploy", "hook-failed")
+
+	_, err := s.ensureHookExecuted2(t, 0, "cm1", "hook1", "hook2")
+	assert.Error(t, err)
+
+	assertConfigMapExists(t, s.k, s.p.TestSlug(), "cm1")
+	// hook1 failed to get ready
+	assertConfigMapNotExists(t, s.k, s.p.TestSlug(), "hook1")
+	// hook2 succeeded
+	assertConfigMapExists(t, s.k, s.p.TestSlug(), "hook2")
+}
+
+func TestHooksDeletePolicyDryRun(t *testing.T) {
+	t.Parallel()
+	s := prepareHookTestProject(t, "post-deploy", "before-hook-creation,hook-succeeded", false)
+	s.addHookConfigMap("hook", resourceOpts{name: "hook2", namespace: s.p.TestSlug()}, false, "post-deploy", "")
+	s.ensureHookExecuted(t, "cm1", "hook1", "hook2")
+	assertConfigMapNotExists(t, s.k, s.p.TestSlug(), "hook1")
+	assertConfigMapExists(t, s.k, s.p.TestSlug(), "hook2")
+
+	// dry-run must neither delete nor (re-)create hooks
+	s.p.KluctlMust(t, "deploy", "--yes", "-t", "test", "--dry-run")
+	assertConfigMapNotExists(t, s.k, s.p.TestSlug(), "hook1")
+	assertConfigMapExists(t, s.k, s.p.TestSlug(), "hook2")
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/kluctl/kluctl/v2/e2e/test-utils"
 	"github.com/kluctl/kluctl/v2/e2e/test_project"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"path/filepath"
 	"reflect"
@@ -230,3 +231,56 @@ func TestInclusionDelete(t *testing.T) {
 	}
 	doAssertExists(nil, a)
 }
+
+func TestInclusionOrder(t *testing.T) {
+	t.Parallel()
+	p, k := prepareInclusionTestProject(t, true)
+
+	shouldExists := make(map[string]bool)
+	doAssertExists := func(add ...string) {
+		assertExistsHelper(t, p, k, shouldExists, add, nil)
+	}
+
+	doAssertExists()
+
+	// exclude a whole include and re-include a single deployment dir from it
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test", "--inclusion-order", "exclude-include",
+		"-E", "include2", "-E", "tag1", "--include-deployment-dir", "include2/icm3")
+	doAssertExists("cm1", "cm2", "icm1", "icm3", "icm4", "icm5")
+
+	// exclude a broad tag and re-include a narrow one
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test", "--inclusion-order", "exclude-include",
+		"-E", "tag1", "-E", "include2", "-I", "tag3", "-I", "include2")
+	doAssertExists("cm4", "icm2")
+
+	// the default order lets exclusions win
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test", "-E", "tag1", "-I", "tag4")
+	doAssertExists()
+
+	_, _, err := p.Kluctl(t, "deploy", "--yes", "-t", "test", "--inclusion-order", "invalid")
+	assert.ErrorContains(t, err, "invalid --inclusion-order invalid")
+}
+
+func TestInclusionTemplatedTags(t *testing.T) {
+	t.Parallel()
+	p, k := prepareInclusionTestProject(t, false)
+
+	shouldExists := make(map[string]bool)
+	doAssertExists := func(add ...string) {
+		assertExistsHelper(t, p, k, shouldExists, add, nil)
+	}
+
+	doAssertExists()
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test", "-a", "tags=tag3,tag4", "-I", "{{ args.tags }}")
+	doAssertExists("cm4", "cm5")
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test", "-a", "tag=tag5", "-I", "tag1", "-E", "{{ args.tag }}")
+	doAssertExists("cm3", "cm7")
+
+	_, _, err := p.Kluctl(t, "deploy", "--yes", "-t", "test", "-I", "{{ args.tags")
+	assert.ErrorContains(t, err, "failed to render inclusion tag '{{ args.tags'")
+
+	_, _, err = p.Kluctl(t, "deploy", "--yes", "-t", "test", "-a", "tags=", "-I", "{{ args.tags }}")
+	assert.ErrorContains(t, err, "inclusion tag '{{ args.tags }}' rendered to an empty value")
+}
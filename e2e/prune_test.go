@@ -2,7 +2,10 @@ package e2e
 
 import (
 	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,6 +43,46 @@ func TestPrune(t *testing.T) {
 	assertConfigMapNotExists(t, k, p.TestSlug(), "cm2")
 }
 
+func TestPruneDryRun(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_utils.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	addConfigMapDeployment(p, "cm1", map[string]string{}, resourceOpts{
+		name:      "cm1",
+		namespace: p.TestSlug(),
+	})
+	addConfigMapDeployment(p, "cm2", map[string]string{}, resourceOpts{
+		name:      "cm2",
+		namespace: p.TestSlug(),
+	})
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm1")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+
+	p.DeleteKustomizeDeployment("cm2")
+
+	cr, _ := p.KluctlMustCommandResult(t, "prune", "--yes", "-t", "test", "--dry-run", "-oyaml")
+
+	var deleted []k8s.ObjectRef
+	for _, o := range cr.Objects {
+		if o.Deleted {
+			deleted = append(deleted, o.Ref)
+		}
+	}
+	assert.Equal(t, []k8s.ObjectRef{{Version: "v1", Kind: "ConfigMap", Name: "cm2", Namespace: p.TestSlug()}}, deleted)
+
+	assertConfigMapExists(t, k, p.TestSlug(), "cm1")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+}
+
 func TestDeployWithPrune(t *testing.T) {
 	t.Parallel()
 
@@ -125,3 +168,55 @@ func TestPruneForceManaged(t *testing.T) {
 	p.KluctlMust(t, "prune", "--yes", "-t", "test")
 	assertConfigMapNotExists(t, k, p.TestSlug(), "cm2")
 }
+
+func TestPruneReport(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_utils.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	for _, n := range []string{"cm1", "cm2", "cm3"} {
+		addConfigMapDeployment(p, n, map[string]string{}, resourceOpts{
+			name:      n,
+			namespace: p.TestSlug(),
+		})
+	}
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm3")
+
+	p.DeleteKustomizeDeployment("cm2")
+	addConfigMapDeployment(p, "cm3", map[string]string{}, resourceOpts{
+		name:        "cm3",
+		namespace:   p.TestSlug(),
+		annotations: map[string]string{"kluctl.io/delete": "true"},
+	})
+
+	cm2Ref := k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm2", Namespace: p.TestSlug()}
+	cm3Ref := k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm3", Namespace: p.TestSlug()}
+
+	cr, _ := p.KluctlMustCommandResult(t, "deploy", "--yes", "-t", "test", "--prune", "--dry-run", "-oyaml")
+	assert.Equal(t, []result.PruneCandidates{
+		{DeploymentItem: "cm3", Refs: []k8s.ObjectRef{cm3Ref}},
+		{Orphan: true, Refs: []k8s.ObjectRef{cm2Ref}},
+	}, cr.PruneReport)
+	assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm3")
+
+	cr, _ = p.KluctlMustCommandResult(t, "diff", "-t", "test", "-oyaml")
+	assert.Equal(t, []result.PruneCandidates{
+		{DeploymentItem: "cm3", Refs: []k8s.ObjectRef{cm3Ref}},
+		{Orphan: true, Refs: []k8s.ObjectRef{cm2Ref}},
+	}, cr.PruneReport)
+
+	cr, _ = p.KluctlMustCommandResult(t, "prune", "--yes", "-t", "test", "--dry-run", "-oyaml")
+	assert.Equal(t, []result.PruneCandidates{
+		{Orphan: true, Refs: []k8s.ObjectRef{cm2Ref}},
+	}, cr.PruneReport)
+	assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+}
@@ -118,3 +118,106 @@ func TestWaitReadinessViaKustomizationWithHook(t *testing.T) {
 		}, "")
 	})
 }
+
+func TestBarrierTimeout(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+	})
+
+	addConfigMapDeployment(p, "cm1", nil, resourceOpts{
+		name:      "cm1",
+		namespace: p.TestSlug(),
+		annotations: map[string]string{
+			"kluctl.io/is-ready":       "false",
+			"kluctl.io/wait-readiness": "true",
+		},
+	})
+	p.AddDeploymentItem(".", uo.FromMap(map[string]interface{}{
+		"barrier": true,
+	}))
+	addConfigMapDeployment(p, "cm2", nil, resourceOpts{
+		name:      "cm2",
+		namespace: p.TestSlug(),
+	})
+
+	_, stderr, err := p.Kluctl(t, "deploy", "--yes", "-t", "test", "--barrier-timeout", (3 * time.Second).String(), "--abort-on-error")
+	assert.Error(t, err)
+	assert.Contains(t, stderr, "timed out after 3s while waiting on barrier <barrier>, stalled deployment items: cm1")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm1")
+	assertConfigMapNotExists(t, k, p.TestSlug(), "cm2")
+
+	// without --abort-on-error, deploying continues after the barrier timed out
+	_, stderr, err = p.Kluctl(t, "deploy", "--yes", "-t", "test", "--barrier-timeout", (3 * time.Second).String())
+	assert.Error(t, err)
+	assert.Contains(t, stderr, "timed out after 3s while waiting on barrier <barrier>, stalled deployment items: cm1")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+}
+
+func TestWaitRollout(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+	})
+
+	// there are no controllers running in the test cluster, so the Deployment will never get ready on its own
+	deploy := uo.FromMap(map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name":      "d1",
+			"namespace": p.TestSlug(),
+			"annotations": map[string]any{
+				"kluctl.io/is-ready": "false",
+			},
+		},
+		"spec": map[string]any{
+			"selector": map[string]any{
+				"matchLabels": map[string]any{"app": "d1"},
+			},
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"labels": map[string]any{"app": "d1"},
+				},
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"name": "c", "image": "busybox"},
+					},
+				},
+			},
+		},
+	})
+	p.AddKustomizeDeployment("d1", []test_project.KustomizeResource{
+		{Name: "deploy.yaml", Content: deploy},
+	}, nil)
+	p.AddDeploymentItem(".", uo.FromMap(map[string]interface{}{
+		"barrier": true,
+	}))
+	addConfigMapDeployment(p, "cm1", nil, resourceOpts{
+		name:      "cm1",
+		namespace: p.TestSlug(),
+	})
+
+	_, stderr, err := p.Kluctl(t, "deploy", "--yes", "-t", "test", "--wait", "--wait-timeout", "2s", "--abort-on-error")
+	assert.Error(t, err)
+	assert.Contains(t, stderr, fmt.Sprintf("timed out while waiting for readiness of %s/Deployment/d1", p.TestSlug()))
+	assertConfigMapNotExists(t, k, p.TestSlug(), "cm1")
+
+	// dry-run never waits
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test", "--wait", "--wait-timeout", "1s", "--dry-run")
+	assertConfigMapNotExists(t, k, p.TestSlug(), "cm1")
+
+	// without --wait, workloads are not waited for
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm1")
+}
@@ -5,6 +5,8 @@ import (
 	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -59,3 +61,59 @@ func TestRenderNoKubeconfig(t *testing.T) {
 	assert.ErrorContains(t, err, "context \"context1\" does not exist")
 
 }
+
+func TestRenderOfflineApiResources(t *testing.T) {
+	t.Parallel()
+
+	p := test_utils.NewTestProject(t)
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+	})
+
+	p.AddKustomizeDeployment("crs", []test_utils.KustomizeResource{
+		{Name: "namespaced.yaml", Content: uo.FromMap(map[string]any{
+			"apiVersion": "example.com/v1",
+			"kind":       "NamespacedThing",
+			"metadata": map[string]any{
+				"name": "namespaced",
+			},
+		})},
+		{Name: "cluster.yaml", Content: uo.FromMap(map[string]any{
+			"apiVersion": "example.com/v1",
+			"kind":       "ClusterThing",
+			"metadata": map[string]any{
+				"name":      "cluster",
+				"namespace": "should-be-removed",
+			},
+		})},
+		{Name: "unknown.yaml", Content: uo.FromMap(map[string]any{
+			"apiVersion": "example.com/v1",
+			"kind":       "UnknownThing",
+			"metadata": map[string]any{
+				"name": "unknown",
+			},
+		})},
+	}, nil)
+
+	apiResources := `NAME               SHORTNAMES   APIVERSION       NAMESPACED   KIND
+configmaps         cm           v1               true         ConfigMap
+namespacedthings                example.com/v1   true         NamespacedThing
+clusterthings      ct           example.com/v1   false        ClusterThing
+`
+	apiResourcesPath := filepath.Join(t.TempDir(), "api-resources.txt")
+	err := os.WriteFile(apiResourcesPath, []byte(apiResources), 0o600)
+	assert.NoError(t, err)
+
+	stdout, stderr := p.KluctlMust(t, "render", "-t", "test", "--print-all", "--offline-kubernetes", "--offline-api-resources", apiResourcesPath)
+	y, err := uo.FromStringMulti(stdout)
+	assert.NoError(t, err)
+
+	byName := map[string]*uo.UnstructuredObject{}
+	for _, o := range y {
+		byName[o.GetK8sName()] = o
+	}
+	assert.Equal(t, "default", byName["namespaced"].GetK8sNamespace())
+	assert.Equal(t, "", byName["cluster"].GetK8sNamespace())
+	assert.Equal(t, "", byName["unknown"].GetK8sNamespace())
+	assert.Contains(t, stderr, "UnknownThing.example.com is not known in the offline API resources")
+}
@@ -0,0 +1,94 @@
+package e2e
+
+import (
+	"github.com/kluctl/kluctl/lib/yaml"
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRenderVarsOnlySource(t *testing.T) {
+	t.Parallel()
+
+	p := test_utils.NewTestProject(t)
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+	})
+
+	p.UpdateDeploymentYaml(".", func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField([]any{
+			map[string]any{"values": map[string]any{"a": "v1"}},
+			map[string]any{"values": map[string]any{"b": "{{ a }}-v2"}},
+		}, "vars")
+		return nil
+	})
+
+	readDocs := func(stdout string) []*uo.UnstructuredObject {
+		var docs []*uo.UnstructuredObject
+		l, err := yaml.ReadYamlAllString(stdout)
+		assert.NoError(t, err)
+		for _, x := range l {
+			docs = append(docs, uo.FromMap(x.(map[string]any)))
+		}
+		return docs
+	}
+
+	stdout, _ := p.KluctlMust(t, "render-vars", "-t", "test")
+	docs := readDocs(stdout)
+	assert.Len(t, docs, 2)
+	v, _, _ := docs[1].GetNestedString("source", "renderedVars", "b")
+	assert.Equal(t, "v1-v2", v)
+
+	stdout, _ = p.KluctlMust(t, "render-vars", "-t", "test", "--only-source", "1", "--with-prior-sources")
+	docs = readDocs(stdout)
+	assert.Len(t, docs, 1)
+	idx, _, _ := docs[0].GetNestedInt("index")
+	assert.Equal(t, int64(1), idx)
+	v, _, _ = docs[0].GetNestedString("source", "values", "b")
+	assert.Equal(t, "v1-v2", v)
+	v, _, _ = docs[0].GetNestedString("source", "renderedVars", "b")
+	assert.Equal(t, "v1-v2", v)
+
+	stdout, _ = p.KluctlMust(t, "render-vars", "-t", "test", "--only-source", "0")
+	docs = readDocs(stdout)
+	assert.Len(t, docs, 1)
+	v, _, _ = docs[0].GetNestedString("source", "renderedVars", "a")
+	assert.Equal(t, "v1", v)
+
+	_, _, err := p.Kluctl(t, "render-vars", "-t", "test", "--only-source", "2")
+	assert.ErrorContains(t, err, "vars source index 2 is out of range")
+
+	_, _, err = p.Kluctl(t, "render-vars", "-t", "test", "--only-source", "values")
+	assert.ErrorContains(t, err, "multiple vars sources of type values found (indexes [0 1])")
+
+	_, _, err = p.Kluctl(t, "render-vars", "-t", "test", "--only-source", "vault")
+	assert.ErrorContains(t, err, "no vars source of type vault found")
+}
+
+func TestRenderVarsOnlySourceByName(t *testing.T) {
+	// not parallel, as t.Setenv is used
+	p := test_utils.NewTestProject(t)
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+	})
+
+	p.UpdateDeploymentYaml(".", func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField([]any{
+			map[string]any{"values": map[string]any{"a": "v1"}},
+			map[string]any{"systemEnvVars": map[string]any{"b": "RENDER_VARS_TEST_ENV"}},
+		}, "vars")
+		return nil
+	})
+	t.Setenv("RENDER_VARS_TEST_ENV", "v2")
+
+	stdout, _ := p.KluctlMust(t, "render-vars", "-t", "test", "--only-source", "systemEnvVars", "--no-obfuscate")
+	l, err := yaml.ReadYamlAllString(stdout)
+	assert.NoError(t, err)
+	assert.Len(t, l, 1)
+	doc := uo.FromMap(l[0].(map[string]any))
+	idx, _, _ := doc.GetNestedInt("index")
+	assert.Equal(t, int64(1), idx)
+	v, _, _ := doc.GetNestedString("source", "renderedVars", "b")
+	assert.Equal(t, "v2", v)
+}
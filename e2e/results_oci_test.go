@@ -0,0 +1,96 @@
+package e2e
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/lib/yaml"
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test-utils"
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriteResultOciStore(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	repo := test_utils.NewHelmTestRepo(test_utils.TestHelmRepo_Oci, "", nil)
+	repo.Start(t)
+
+	p := test_project.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	addConfigMapDeployment(p, "cm", nil, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	ociUrl := repo.URL.String() + "/results"
+	storeArgs := []string{"--command-result-store", "oci", "--command-result-oci-url", ociUrl}
+
+	p.KluctlMust(t, append([]string{"deploy", "--yes", "-t", "test", "--keep-command-results-count", "1"}, storeArgs...)...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rs, err := results.NewResultStoreOci(ctx, ociUrl, auth_provider.NewDefaultAuthProviders("KLUCTL_REGISTRY"), false, 0, 0)
+	assert.NoError(t, err)
+
+	summaries, err := rs.ListCommandResultSummaries(results.ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 1)
+	assertSummary(t, result.CommandResultSummary{
+		AppliedObjects: 1,
+		NewObjects:     1,
+	}, summaries[0])
+
+	cr, err := rs.GetCommandResult(results.GetCommandResultOptions{Id: summaries[0].Id})
+	assert.NoError(t, err)
+	assert.NotNil(t, cr)
+	assert.Len(t, cr.Objects, 1)
+
+	b := newSecondPassedBarrier(t)
+	b.Wait()
+
+	p.KluctlMust(t, append([]string{"deploy", "--yes", "-t", "test", "--keep-command-results-count", "1"}, storeArgs...)...)
+
+	stdout, _ := p.KluctlMust(t, append([]string{"results", "list"}, storeArgs...)...)
+	var summaries2 []result.CommandResultSummary
+	err = yaml.ReadYamlString(stdout, &summaries2)
+	assert.NoError(t, err)
+	assert.Len(t, summaries2, 1)
+	assert.NotEqual(t, summaries[0].Id, summaries2[0].Id)
+}
+
+func TestWriteResultOciStoreBestEffort(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	addConfigMapDeployment(p, "cm", nil, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	// nothing is listening on this port
+	storeArgs := []string{"--command-result-store", "oci", "--command-result-oci-url", "oci://127.0.0.1:1/results"}
+
+	_, _, err := p.Kluctl(t, append([]string{"deploy", "--yes", "-t", "test"}, storeArgs...)...)
+	assert.Error(t, err)
+	assertConfigMapExists(t, k, p.TestSlug(), "cm")
+
+	_, stderr := p.KluctlMust(t, append([]string{"deploy", "--yes", "-t", "test", "--command-result-best-effort"}, storeArgs...)...)
+	assert.Contains(t, stderr, "Failed to write result to result store")
+}
@@ -3,6 +3,7 @@ package e2e
 import (
 	"context"
 	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/yaml"
 	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
 	"github.com/kluctl/kluctl/v2/pkg/results"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
@@ -49,7 +50,7 @@ func TestWriteResult(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	rs, err := results.NewResultStoreSecrets(ctx, k.RESTConfig(), k.Client, false, "kluctl-results", 0, 0)
+	rs, err := results.NewResultStoreSecrets(ctx, k.RESTConfig(), k.Client, false, "kluctl-results", nil, 0, 0)
 	assert.NoError(t, err)
 
 	opts := results.ListResultSummariesOptions{
@@ -116,3 +117,113 @@ func TestWriteResult(t *testing.T) {
 		DeletedObjects: 1,
 	}, summaries[0])
 }
+
+func TestListResultsNamespaces(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_utils.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	addConfigMapDeployment(p, "cm", nil, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	ns1 := p.TestSlug() + "-r1"
+	ns2 := p.TestSlug() + "-r2"
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test", "--command-result-namespace", ns1)
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test", "--command-result-namespace", ns2)
+
+	listResults := func(args ...string) []result.CommandResultSummary {
+		stdout, _ := p.KluctlMust(t, append([]string{"results", "list"}, args...)...)
+		var summaries []result.CommandResultSummary
+		err := yaml.ReadYamlString(stdout, &summaries)
+		assert.NoError(t, err)
+		return summaries
+	}
+	filterProject := func(summaries []result.CommandResultSummary) []result.CommandResultSummary {
+		var ret []result.CommandResultSummary
+		for _, s := range summaries {
+			if s.ProjectKey.RepoKey == gittypes.ParseGitUrlMust(p.GitUrl()).RepoKey() {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	}
+
+	s1 := listResults("--command-result-namespace", ns1)
+	assert.Len(t, s1, 1)
+	s2 := listResults("--command-result-namespace", ns2)
+	assert.Len(t, s2, 1)
+	assert.NotEqual(t, s1[0].Id, s2[0].Id)
+
+	all := filterProject(listResults("--all-namespaces"))
+	assert.Len(t, all, 2)
+	var ids []string
+	for _, s := range all {
+		ids = append(ids, s.Id)
+	}
+	assert.ElementsMatch(t, []string{s1[0].Id, s2[0].Id}, ids)
+
+	_, _, err := p.Kluctl(t, "results", "list", "--all-namespaces", "--namespace-selector", "a=b")
+	assert.ErrorContains(t, err, "--all-namespaces and --namespace-selector can not be used together")
+}
+
+func TestWriteResultFileStore(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_utils.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	addConfigMapDeployment(p, "cm", nil, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	storeDir := t.TempDir()
+	storeArgs := []string{"--command-result-store", "fs", "--command-result-path", storeDir}
+
+	p.KluctlMust(t, append([]string{"deploy", "--yes", "-t", "test", "--keep-command-results-count", "1"}, storeArgs...)...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rs, err := results.NewResultStoreFile(ctx, storeDir, false, 0, 0)
+	assert.NoError(t, err)
+
+	summaries, err := rs.ListCommandResultSummaries(results.ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 1)
+	assertSummary(t, result.CommandResultSummary{
+		AppliedObjects: 1,
+		NewObjects:     1,
+	}, summaries[0])
+
+	cr, err := rs.GetCommandResult(results.GetCommandResultOptions{Id: summaries[0].Id})
+	assert.NoError(t, err)
+	assert.NotNil(t, cr)
+	assert.Len(t, cr.Objects, 1)
+
+	b := newSecondPassedBarrier(t)
+	b.Wait()
+
+	p.KluctlMust(t, append([]string{"deploy", "--yes", "-t", "test", "--keep-command-results-count", "1"}, storeArgs...)...)
+
+	stdout, _ := p.KluctlMust(t, append([]string{"results", "list"}, storeArgs...)...)
+	var summaries2 []result.CommandResultSummary
+	err = yaml.ReadYamlString(stdout, &summaries2)
+	assert.NoError(t, err)
+	assert.Len(t, summaries2, 1)
+	assert.NotEqual(t, summaries[0].Id, summaries2[0].Id)
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/kluctl/kluctl/v2/pkg/vars/sops_test_resources"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -55,6 +57,55 @@ func TestSopsVars(t *testing.T) {
 	}, "data")
 }
 
+func TestSopsAgeKeyFile(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"v1": "{{ test1.test2 }}",
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+	p.UpdateDeploymentYaml("", func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField([]map[string]any{
+			{
+				"file": "encrypted-vars.yaml",
+			},
+		}, "vars")
+		return nil
+	})
+
+	p.UpdateFile("encrypted-vars.yaml", func(f string) (string, error) {
+		b, _ := sops_test_resources.TestResources.ReadFile("test.yaml")
+		return string(b), nil
+	}, "")
+
+	keyFile := filepath.Join(t.TempDir(), "key.txt")
+	key, _ := sops_test_resources.TestResources.ReadFile("test-key.txt")
+	err := os.WriteFile(keyFile, key, 0o600)
+	assert.NoError(t, err)
+
+	missingKeyFile := filepath.Join(t.TempDir(), "missing.txt")
+	_, _, err = p.Kluctl(t, "deploy", "--yes", "-t", "test", "--sops-age-key-file", missingKeyFile)
+	assert.ErrorContains(t, err, "failed to read sops age key file "+missingKeyFile)
+	assertConfigMapNotExists(t, k, p.TestSlug(), "cm")
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test", "--sops-age-key-file", keyFile)
+
+	cm := assertConfigMapExists(t, k, p.TestSlug(), "cm")
+	assertNestedFieldEquals(t, cm, map[string]any{
+		"v1": "42",
+	}, "data")
+}
+
 func TestSopsResources(t *testing.T) {
 	t.Parallel()
 
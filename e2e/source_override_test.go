@@ -143,6 +143,35 @@ func TestGitGroup(t *testing.T) {
 	assertNestedFieldEquals(t, cm, "o2", "data", "a")
 }
 
+func TestGitWildcardOverride(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+	gs := test_utils.NewTestGitServer(t)
+	pt := prepareLocalSourceOverrideTest(t, k, gs, false)
+
+	u1, _ := gittypes.ParseGitUrl(pt.p.GitServer().GitUrl() + "/repos")
+	k1 := u1.RepoKey().String()
+	u2, _ := gittypes.ParseGitUrl(pt.ip2.GitUrl())
+	k2 := u2.RepoKey().String()
+
+	// the exact override for include2 is more specific than the wildcard and must win
+	exactOverride := pt.ip2.CopyProjectSourceTo(filepath.Join(t.TempDir(), "include2"))
+	cm, err := uo.FromFile(filepath.Join(exactOverride, "subDir", "cm", "configmap-include2-cm.yml"))
+	assert.NoError(t, err)
+	_ = cm.SetNestedField("exact", "data", "a")
+	_ = yaml.WriteYamlFile(filepath.Join(exactOverride, "subDir", "cm", "configmap-include2-cm.yml"), cm)
+
+	pt.p.KluctlMust(t, "deploy", "--yes", "-t", "test",
+		"--local-git-override", fmt.Sprintf("%s/*=%s", k1, pt.overrideGroupDir),
+		"--local-git-override", fmt.Sprintf("%s=%s", k2, exactOverride),
+	)
+	cm = assertConfigMapExists(t, k, pt.p.TestSlug(), "include1-cm")
+	assertNestedFieldEquals(t, cm, "o1", "data", "a")
+	cm = assertConfigMapExists(t, k, pt.p.TestSlug(), "include2-cm")
+	assertNestedFieldEquals(t, cm, "exact", "data", "a")
+}
+
 func TestLocalOciOverride(t *testing.T) {
 	t.Parallel()
 
@@ -42,6 +42,19 @@ func (a *GitAuthProviders) RegisterAuthProvider(p GitAuthProvider, last bool) {
 	}
 }
 
+// RegisterAuthProviderAfterEnv registers the given provider right after the env based provider, meaning that env based
+// credentials still take precedence while all other providers are tried afterwards. If no env based provider is
+// registered, the provider is registered as first provider.
+func (a *GitAuthProviders) RegisterAuthProviderAfterEnv(p GitAuthProvider) {
+	idx := 0
+	for i, x := range a.authProviders {
+		if _, ok := x.(*GitEnvAuthProvider); ok {
+			idx = i + 1
+		}
+	}
+	a.authProviders = append(a.authProviders[:idx], append([]GitAuthProvider{p}, a.authProviders[idx:]...)...)
+}
+
 func (a *GitAuthProviders) BuildAuth(ctx context.Context, gitUrl types.GitUrl) (AuthMethodAndCA, error) {
 	var errs *multierror.Error
 	for _, p := range a.authProviders {
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"github.com/gobwas/glob"
+	"github.com/kluctl/kluctl/lib/git/messages"
+	"github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const defaultTokenUsername = "x-access-token"
+
+type GitCredentialsConfig struct {
+	Credentials []GitCredentialsConfigEntry `json:"credentials"`
+}
+
+type GitCredentialsConfigEntry struct {
+	// RepoKey is a glob in the form <host>/<path>, e.g. github.com/my-org/**
+	RepoKey string `json:"repoKey"`
+
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+
+	SshKeyFile     string `json:"sshKeyFile,omitempty"`
+	KnownHostsFile string `json:"knownHostsFile,omitempty"`
+	CABundleFile   string `json:"caBundleFile,omitempty"`
+}
+
+// GitCredentialsConfigAuthProvider provides credentials from a yaml file which maps repo key globs to credentials.
+// The file is loaded lazily on first use.
+type GitCredentialsConfigAuthProvider struct {
+	MessageCallbacks messages.MessageCallbacks
+
+	Path string
+
+	mutex   sync.Mutex
+	list    *ListAuthProvider
+	listErr error
+}
+
+func (a *GitCredentialsConfigAuthProvider) buildList() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.listErr != nil {
+		return a.listErr
+	}
+	if a.list != nil {
+		return nil
+	}
+	a.listErr = a.doBuildList()
+	return a.listErr
+}
+
+func (a *GitCredentialsConfigAuthProvider) doBuildList() error {
+	var config GitCredentialsConfig
+	err := yaml.ReadYamlFile(a.Path, &config)
+	if err != nil {
+		return fmt.Errorf("failed to read git credentials file %s: %w", a.Path, err)
+	}
+
+	list := &ListAuthProvider{MessageCallbacks: a.MessageCallbacks}
+	for i, c := range config.Credentials {
+		e, err := a.buildEntry(c)
+		if err != nil {
+			return fmt.Errorf("invalid entry %d in git credentials file %s: %w", i, a.Path, err)
+		}
+		list.AddEntry(*e)
+	}
+	a.list = list
+	return nil
+}
+
+func (a *GitCredentialsConfigAuthProvider) buildEntry(c GitCredentialsConfigEntry) (*AuthEntry, error) {
+	repoKey := strings.TrimPrefix(c.RepoKey, "git://")
+	if repoKey == "" {
+		return nil, fmt.Errorf("repoKey is missing")
+	}
+	if c.Token != "" && c.Password != "" {
+		return nil, fmt.Errorf("only one of token and password can be set")
+	}
+	if c.Token == "" && c.Password == "" && c.SshKeyFile == "" {
+		return nil, fmt.Errorf("one of token, password or sshKeyFile must be set")
+	}
+
+	e := &AuthEntry{
+		Username: c.Username,
+		Password: c.Password,
+	}
+
+	x := strings.SplitN(repoKey, "/", 2)
+	e.Host = x[0]
+	if len(x) == 2 && x[1] != "" {
+		g, err := glob.Compile(x[1], '/')
+		if err != nil {
+			return nil, err
+		}
+		e.PathStr = x[1]
+		e.PathGlob = g
+	}
+
+	if c.Token != "" {
+		e.Password = c.Token
+		if e.Username == "" {
+			e.Username = defaultTokenUsername
+		}
+	}
+	if e.Username == "" {
+		if c.SshKeyFile == "" {
+			return nil, fmt.Errorf("username is missing")
+		}
+		e.Username = "git"
+	}
+
+	a.MessageCallbacks.Trace("GitCredentialsConfigAuthProvider: adding entry host=%s, path=%s, username=%s, ssh_key=%s", e.Host, e.PathStr, e.Username, c.SshKeyFile)
+
+	var err error
+	if c.SshKeyFile != "" {
+		e.SshKey, err = a.readFile(c.SshKeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.KnownHostsFile != "" {
+		e.KnownHosts, err = a.readFile(c.KnownHostsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.CABundleFile != "" {
+		e.CABundle, err = a.readFile(c.CABundleFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// readFile reads the given file, relative paths are interpreted relative to the credentials file
+func (a *GitCredentialsConfigAuthProvider) readFile(p string) ([]byte, error) {
+	p = expandHomeDir(p)
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(filepath.Dir(a.Path), p)
+	}
+	return os.ReadFile(p)
+}
+
+func (a *GitCredentialsConfigAuthProvider) BuildAuth(ctx context.Context, gitUrl types.GitUrl) (AuthMethodAndCA, error) {
+	err := a.buildList()
+	if err != nil {
+		return AuthMethodAndCA{}, err
+	}
+	return a.list.BuildAuth(ctx, gitUrl)
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/kluctl/kluctl/lib/git/messages"
+	"github.com/kluctl/kluctl/lib/git/types"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testCredentialsFile = `
+credentials:
+  - repoKey: github.com/my-org/**
+    token: secret-token
+  - repoKey: gitlab.com/my-group/*
+    username: my-user
+    password: secret-password
+`
+
+func writeCredentialsFile(t *testing.T, s string) string {
+	p := filepath.Join(t.TempDir(), "credentials.yaml")
+	err := os.WriteFile(p, []byte(s), 0o600)
+	assert.NoError(t, err)
+	return p
+}
+
+func buildTestAuth(t *testing.T, a GitAuthProvider, u string) AuthMethodAndCA {
+	gitUrl, err := types.ParseGitUrl(u)
+	assert.NoError(t, err)
+	auth, err := a.BuildAuth(context.Background(), *gitUrl)
+	assert.NoError(t, err)
+	return auth
+}
+
+func TestGitCredentialsConfigAuthProvider(t *testing.T) {
+	var traces []string
+	a := &GitCredentialsConfigAuthProvider{
+		MessageCallbacks: messages.MessageCallbacks{
+			TraceFn: func(s string) {
+				traces = append(traces, s)
+			},
+		},
+		Path: writeCredentialsFile(t, testCredentialsFile),
+	}
+
+	auth := buildTestAuth(t, a, "https://github.com/my-org/repo1.git")
+	assert.Equal(t, &http.BasicAuth{Username: defaultTokenUsername, Password: "secret-token"}, auth.AuthMethod)
+
+	auth = buildTestAuth(t, a, "https://gitlab.com/my-group/repo2.git")
+	assert.Equal(t, &http.BasicAuth{Username: "my-user", Password: "secret-password"}, auth.AuthMethod)
+
+	auth = buildTestAuth(t, a, "https://gitlab.com/my-group/sub/repo3.git")
+	assert.Nil(t, auth.AuthMethod)
+
+	auth = buildTestAuth(t, a, "https://github.com/other-org/repo4.git")
+	assert.Nil(t, auth.AuthMethod)
+
+	assert.NotEmpty(t, traces)
+	for _, s := range traces {
+		assert.False(t, strings.Contains(s, "secret"), "trace contains secret: %s", s)
+	}
+}
+
+func TestGitCredentialsConfigAuthProviderInvalid(t *testing.T) {
+	a := &GitCredentialsConfigAuthProvider{
+		Path: writeCredentialsFile(t, `
+credentials:
+  - repoKey: github.com/my-org/**
+    username: my-user
+`),
+	}
+	gitUrl, _ := types.ParseGitUrl("https://github.com/my-org/repo1.git")
+	_, err := a.BuildAuth(context.Background(), *gitUrl)
+	assert.ErrorContains(t, err, "one of token, password or sshKeyFile must be set")
+}
+
+func TestGitCredentialsConfigAuthProviderEnvPrecedence(t *testing.T) {
+	t.Setenv("KLUCTL_TEST_GIT_HOST", "github.com")
+	t.Setenv("KLUCTL_TEST_GIT_USERNAME", "env-user")
+	t.Setenv("KLUCTL_TEST_GIT_PASSWORD", "env-password")
+
+	a := NewDefaultAuthProviders("KLUCTL_TEST_GIT", nil)
+	a.RegisterAuthProviderAfterEnv(&GitCredentialsConfigAuthProvider{
+		Path: writeCredentialsFile(t, testCredentialsFile),
+	})
+
+	auth := buildTestAuth(t, a, "https://github.com/my-org/repo1.git")
+	assert.Equal(t, &http.BasicAuth{Username: "env-user", Password: "env-password"}, auth.AuthMethod)
+
+	auth = buildTestAuth(t, a, "https://gitlab.com/my-group/repo2.git")
+	assert.Equal(t, &http.BasicAuth{Username: "my-user", Password: "secret-password"}, auth.AuthMethod)
+}
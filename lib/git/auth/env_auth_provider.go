@@ -17,9 +17,10 @@ type GitEnvAuthProvider struct {
 
 	Prefix string
 
-	mutext  sync.Mutex
-	list    *ListAuthProvider
-	listErr error
+	mutext     sync.Mutex
+	list       *ListAuthProvider
+	githubApps []*GitHubAppAuthProvider
+	listErr    error
 }
 
 func (a *GitEnvAuthProvider) buildList(ctx context.Context) error {
@@ -40,6 +41,14 @@ func (a *GitEnvAuthProvider) doBuildList(ctx context.Context) error {
 
 	for _, s := range envutils.ParseEnvConfigSets(a.Prefix) {
 		m := s.Map
+		if m["GITHUB_APP_ID"] != "" {
+			err := a.addGitHubApp(m)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
 		e := AuthEntry{
 			Host:     m["HOST"],
 			Username: m["USERNAME"],
@@ -95,10 +104,72 @@ func (a *GitEnvAuthProvider) doBuildList(ctx context.Context) error {
 	return nil
 }
 
+func (a *GitEnvAuthProvider) addGitHubApp(m map[string]string) error {
+	ga := &GitHubAppAuthProvider{
+		MessageCallbacks: a.MessageCallbacks,
+		Host:             m["HOST"],
+		AppID:            m["GITHUB_APP_ID"],
+		InstallationID:   m["GITHUB_APP_INSTALLATION_ID"],
+		ApiUrl:           m["GITHUB_APP_API_URL"],
+	}
+	if ga.Host == "" {
+		ga.Host = "github.com"
+	}
+	if ga.InstallationID == "" {
+		return fmt.Errorf("missing GITHUB_APP_INSTALLATION_ID for GitHub App %s", ga.AppID)
+	}
+
+	if path := m["PATH"]; path != "" {
+		g, err := glob.Compile(path, '/')
+		if err != nil {
+			return err
+		}
+		ga.PathStr = path
+		ga.PathGlob = g
+	}
+
+	keyPath := m["GITHUB_APP_PRIVATE_KEY"]
+	a.MessageCallbacks.Trace(fmt.Sprintf("GitEnvAuthProvider: adding GitHub App host=%s, path=%s, appId=%s, installationId=%s, private_key=%s", ga.Host, ga.PathStr, ga.AppID, ga.InstallationID, keyPath))
+
+	if keyPath == "" {
+		return fmt.Errorf("missing GITHUB_APP_PRIVATE_KEY for GitHub App %s", ga.AppID)
+	}
+	b, err := os.ReadFile(expandHomeDir(keyPath))
+	if err != nil {
+		return fmt.Errorf("failed to read private key for GitHub App %s: %w", ga.AppID, err)
+	}
+	ga.PrivateKey, err = ParseGitHubAppPrivateKey(b)
+	if err != nil {
+		return err
+	}
+
+	if caBundlePath := m["CA_BUNDLE"]; caBundlePath != "" {
+		b, err := os.ReadFile(expandHomeDir(caBundlePath))
+		if err != nil {
+			a.MessageCallbacks.Trace(fmt.Sprintf("GitEnvAuthProvider: failed to read ca bundle %s: %v", caBundlePath, err))
+		} else {
+			ga.CABundle = b
+		}
+	}
+
+	a.githubApps = append(a.githubApps, ga)
+	return nil
+}
+
 func (a *GitEnvAuthProvider) BuildAuth(ctx context.Context, gitUrl types.GitUrl) (AuthMethodAndCA, error) {
 	err := a.buildList(ctx)
 	if err != nil {
 		return AuthMethodAndCA{}, err
 	}
-	return a.list.BuildAuth(ctx, gitUrl)
+	auth, err := a.list.BuildAuth(ctx, gitUrl)
+	if err != nil || auth.AuthMethod != nil {
+		return auth, err
+	}
+	for _, ga := range a.githubApps {
+		auth, err = ga.BuildAuth(ctx, gitUrl)
+		if err != nil || auth.AuthMethod != nil {
+			return auth, err
+		}
+	}
+	return AuthMethodAndCA{}, nil
 }
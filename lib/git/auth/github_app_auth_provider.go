@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/gobwas/glob"
+	"github.com/kluctl/kluctl/lib/git/messages"
+	"github.com/kluctl/kluctl/lib/git/types"
+	"io"
+	http2 "net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	githubAppTokenUsername = "x-access-token"
+
+	// tokens are refreshed when they expire within this duration, so that long-running operations don't end up
+	// with an expired token
+	githubAppTokenRefreshMargin = 5 * time.Minute
+)
+
+// GitHubAppAuthProvider mints short-lived installation tokens for a GitHub App installation and uses them for http(s)
+// based Git authentication. Tokens are cached and refreshed shortly before they expire.
+type GitHubAppAuthProvider struct {
+	MessageCallbacks messages.MessageCallbacks
+
+	Host     string
+	PathGlob glob.Glob
+	PathStr  string
+
+	AppID          string
+	InstallationID string
+	PrivateKey     *rsa.PrivateKey
+
+	// ApiUrl defaults to https://api.github.com for github.com and to https://<host>/api/v3 for GitHub Enterprise
+	ApiUrl string
+
+	CABundle []byte
+
+	mutex     sync.Mutex
+	token     string
+	expiresAt time.Time
+	now       func() time.Time
+}
+
+func ParseGitHubAppPrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block of GitHub App private key")
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return k, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	rk, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key is not a RSA key")
+	}
+	return rk, nil
+}
+
+func (a *GitHubAppAuthProvider) BuildAuth(ctx context.Context, gitUrlIn types.GitUrl) (AuthMethodAndCA, error) {
+	gitUrl := gitUrlIn.Normalize()
+	if gitUrl.Scheme != "http" && gitUrl.Scheme != "https" {
+		return AuthMethodAndCA{}, nil
+	}
+	if gitUrl.Host != a.Host {
+		return AuthMethodAndCA{}, nil
+	}
+	if a.PathGlob != nil {
+		urlPath := strings.TrimPrefix(gitUrl.Path, "/")
+		if !a.PathGlob.Match(urlPath) {
+			return AuthMethodAndCA{}, nil
+		}
+	}
+
+	a.MessageCallbacks.Trace("GitHubAppAuthProvider: BuildAuth for %s, appId=%s, installationId=%s", gitUrl.String(), a.AppID, a.InstallationID)
+
+	token, err := a.getToken(ctx)
+	if err != nil {
+		return AuthMethodAndCA{}, err
+	}
+
+	return AuthMethodAndCA{
+		AuthMethod: &http.BasicAuth{
+			Username: githubAppTokenUsername,
+			Password: token,
+		},
+		CABundle: a.CABundle,
+	}, nil
+}
+
+func (a *GitHubAppAuthProvider) getNow() time.Time {
+	if a.now != nil {
+		return a.now()
+	}
+	return time.Now()
+}
+
+func (a *GitHubAppAuthProvider) getApiUrl() string {
+	if a.ApiUrl != "" {
+		return strings.TrimSuffix(a.ApiUrl, "/")
+	}
+	if a.Host == "github.com" {
+		return "https://api.github.com"
+	}
+	return fmt.Sprintf("https://%s/api/v3", a.Host)
+}
+
+func (a *GitHubAppAuthProvider) getToken(ctx context.Context) (string, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.token != "" && a.getNow().Add(githubAppTokenRefreshMargin).Before(a.expiresAt) {
+		return a.token, nil
+	}
+
+	a.MessageCallbacks.Trace("GitHubAppAuthProvider: requesting new installation token for appId=%s, installationId=%s", a.AppID, a.InstallationID)
+
+	jwt, err := a.buildJwt()
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%s/access_tokens", a.getApiUrl(), a.InstallationID)
+	req, err := http2.NewRequestWithContext(ctx, http2.MethodPost, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := a.buildHttpClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request GitHub App installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to request GitHub App installation token: %w", err)
+	}
+	if resp.StatusCode != http2.StatusCreated {
+		return "", fmt.Errorf("failed to request GitHub App installation token for installation %s: %s", a.InstallationID, resp.Status)
+	}
+
+	var tokenResp struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	err = json.Unmarshal(body, &tokenResp)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse GitHub App installation token response: %w", err)
+	}
+	if tokenResp.Token == "" {
+		return "", fmt.Errorf("GitHub App installation token response did not contain a token")
+	}
+
+	a.token = tokenResp.Token
+	a.expiresAt = tokenResp.ExpiresAt
+
+	return a.token, nil
+}
+
+func (a *GitHubAppAuthProvider) buildHttpClient() *http2.Client {
+	if a.CABundle == nil {
+		return http2.DefaultClient
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(a.CABundle)
+	t := http2.DefaultTransport.(*http2.Transport).Clone()
+	t.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return &http2.Client{Transport: t}
+}
+
+// buildJwt builds the RS256 signed JWT used to authenticate as the GitHub App
+func (a *GitHubAppAuthProvider) buildJwt() (string, error) {
+	now := a.getNow()
+
+	header, err := json.Marshal(map[string]any{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		// allow some clock drift, as recommended by GitHub
+		"iat": now.Add(-60 * time.Second).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": a.AppID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	h := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.PrivateKey, crypto.SHA256, h[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/assert"
+	http2 "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func startTestGitHubApi(t *testing.T, key *rsa.PrivateKey, expiresIn time.Duration) (*httptest.Server, *atomic.Int32) {
+	var count atomic.Int32
+	s := httptest.NewServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
+		if r.Method != http2.MethodPost || r.URL.Path != "/app/installations/456/access_tokens" {
+			w.WriteHeader(http2.StatusNotFound)
+			return
+		}
+
+		jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(jwt, ".")
+		if len(parts) != 3 {
+			w.WriteHeader(http2.StatusUnauthorized)
+			return
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		h := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, h[:], sig) != nil {
+			w.WriteHeader(http2.StatusUnauthorized)
+			return
+		}
+		claimsJson, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var claims map[string]any
+		_ = json.Unmarshal(claimsJson, &claims)
+		if claims["iss"] != "123" {
+			w.WriteHeader(http2.StatusUnauthorized)
+			return
+		}
+
+		n := count.Add(1)
+		w.WriteHeader(http2.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      fmt.Sprintf("token-%d", n),
+			"expires_at": time.Now().Add(expiresIn).UTC().Format(time.RFC3339),
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s, &count
+}
+
+func TestGitHubAppAuthProvider(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	s, count := startTestGitHubApi(t, key, time.Hour)
+
+	now := time.Now()
+	a := &GitHubAppAuthProvider{
+		Host:           "github.com",
+		AppID:          "123",
+		InstallationID: "456",
+		PrivateKey:     key,
+		ApiUrl:         s.URL,
+		now: func() time.Time {
+			return now
+		},
+	}
+
+	auth := buildTestAuth(t, a, "https://github.com/my-org/repo1.git")
+	assert.Equal(t, &http.BasicAuth{Username: githubAppTokenUsername, Password: "token-1"}, auth.AuthMethod)
+
+	// token is cached
+	auth = buildTestAuth(t, a, "https://github.com/my-org/repo2.git")
+	assert.Equal(t, &http.BasicAuth{Username: githubAppTokenUsername, Password: "token-1"}, auth.AuthMethod)
+	assert.Equal(t, int32(1), count.Load())
+
+	// token is refreshed when it's about to expire
+	now = now.Add(time.Hour - githubAppTokenRefreshMargin + time.Second)
+	auth = buildTestAuth(t, a, "https://github.com/my-org/repo1.git")
+	assert.Equal(t, &http.BasicAuth{Username: githubAppTokenUsername, Password: "token-2"}, auth.AuthMethod)
+	assert.Equal(t, int32(2), count.Load())
+
+	// other hosts and ssh urls are not handled
+	auth = buildTestAuth(t, a, "https://gitlab.com/my-org/repo1.git")
+	assert.Nil(t, auth.AuthMethod)
+	auth = buildTestAuth(t, a, "git@github.com:my-org/repo1.git")
+	assert.Nil(t, auth.AuthMethod)
+}
+
+func TestGitHubAppEnvAuthProvider(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	s, _ := startTestGitHubApi(t, key, time.Hour)
+
+	keyFile := filepath.Join(t.TempDir(), "key.pem")
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}), 0o600)
+	assert.NoError(t, err)
+
+	t.Setenv("KLUCTL_TEST_GIT_GITHUB_APP_ID", "123")
+	t.Setenv("KLUCTL_TEST_GIT_GITHUB_APP_INSTALLATION_ID", "456")
+	t.Setenv("KLUCTL_TEST_GIT_GITHUB_APP_PRIVATE_KEY", keyFile)
+	t.Setenv("KLUCTL_TEST_GIT_GITHUB_APP_API_URL", s.URL)
+	t.Setenv("KLUCTL_TEST_GIT_PATH", "my-org/**")
+
+	a := &GitEnvAuthProvider{Prefix: "KLUCTL_TEST_GIT"}
+
+	auth := buildTestAuth(t, a, "https://github.com/my-org/repo1.git")
+	assert.Equal(t, &http.BasicAuth{Username: githubAppTokenUsername, Password: "token-1"}, auth.AuthMethod)
+
+	auth = buildTestAuth(t, a, "https://github.com/other-org/repo1.git")
+	assert.Nil(t, auth.AuthMethod)
+}
@@ -214,18 +214,18 @@ func (g *MirroredGitRepo) cleanupMirrorDir() error {
 	return nil
 }
 
-func (g *MirroredGitRepo) update(repoDir string) error {
+func (g *MirroredGitRepo) update(ctx context.Context, repoDir string) error {
 	r, err := git.PlainOpen(repoDir)
 	if err != nil {
 		return err
 	}
 
-	auth, err := g.authProviders.BuildAuth(g.ctx, g.url)
+	auth, err := g.authProviders.BuildAuth(ctx, g.url)
 	if err != nil {
 		return err
 	}
 
-	remoteRefs, err := ListRemoteRefs(g.ctx, g.url, g.sshPool, auth)
+	remoteRefs, err := ListRemoteRefs(ctx, g.url, g.sshPool, auth)
 	if err != nil {
 		return err
 	}
@@ -274,8 +274,8 @@ func (g *MirroredGitRepo) update(repoDir string) error {
 		// go-git does not respect the context deadline in some situations, especially after errors occur internally.
 		// This leads to hanging fetches, which can easily deadlock the whole kluctl process. The only way to handle
 		// this currently is to panic when the deadline is exceeded too much.
-		err = RunWithDeadlineAndPanic(g.ctx, 5*time.Second, func() error {
-			return remote.FetchContext(g.ctx, &git.FetchOptions{
+		err = RunWithDeadlineAndPanic(ctx, 5*time.Second, func() error {
+			return remote.FetchContext(ctx, &git.FetchOptions{
 				Auth:     auth.AuthMethod,
 				CABundle: auth.CABundle,
 				Tags:     git.AllTags,
@@ -311,11 +311,11 @@ func (g *MirroredGitRepo) update(repoDir string) error {
 	return nil
 }
 
-func (g *MirroredGitRepo) cloneOrUpdate() error {
+func (g *MirroredGitRepo) cloneOrUpdate(ctx context.Context) error {
 	initMarker := filepath.Join(g.mirrorDir, ".cache2.init")
 	st, err := os.Stat(initMarker)
 	if err == nil && st.Mode().IsRegular() {
-		err = g.update(g.mirrorDir)
+		err = g.update(ctx, g.mirrorDir)
 		if err == nil {
 			return nil
 		} else if strings.Contains(err.Error(), "multi_ack") {
@@ -323,7 +323,7 @@ func (g *MirroredGitRepo) cloneOrUpdate() error {
 			// in that case, retry a full clone which does hopefully not rely on multi_ack.
 			// See https://github.com/go-git/go-git/pull/613
 			// TODO remove this when https://github.com/go-git/go-git/issues/64 gets fully fixed
-			status.Tracef(g.ctx, "Got multi_ack related error from remote. Retrying full clone: %v", err)
+			status.Tracef(ctx, "Got multi_ack related error from remote. Retrying full clone: %v", err)
 		} else {
 			return err
 		}
@@ -353,7 +353,7 @@ func (g *MirroredGitRepo) cloneOrUpdate() error {
 		return err
 	}
 
-	err = g.update(tmpMirrorDir)
+	err = g.update(ctx, tmpMirrorDir)
 	if err != nil {
 		return err
 	}
@@ -377,7 +377,13 @@ func (g *MirroredGitRepo) cloneOrUpdate() error {
 }
 
 func (g *MirroredGitRepo) Update() error {
-	err := g.cloneOrUpdate()
+	return g.UpdateWithContext(g.ctx)
+}
+
+// UpdateWithContext is like Update, but uses the given context for all remote operations. This allows to bound
+// individual updates with a timeout.
+func (g *MirroredGitRepo) UpdateWithContext(ctx context.Context) error {
+	err := g.cloneOrUpdate(ctx)
 	if err != nil {
 		return err
 	}
@@ -386,11 +392,20 @@ func (g *MirroredGitRepo) Update() error {
 }
 
 func (g *MirroredGitRepo) CloneProjectByCommit(commit string, targetDir string) error {
+	return g.CloneProjectByCommitSparse(commit, targetDir, nil)
+}
+
+// CloneProjectByCommitSparse is like CloneProjectByCommit, but only checks out the given directories. If sparseDirs
+// is empty, the full worktree is checked out.
+func (g *MirroredGitRepo) CloneProjectByCommitSparse(commit string, targetDir string, sparseDirs []string) error {
 	if !g.IsLocked() || !g.hasUpdated {
 		panic("tried to clone from a project that is not locked/updated")
 	}
 
-	err := PoorMansClone(g.mirrorDir, targetDir, &git.CheckoutOptions{Hash: plumbing.NewHash(commit)})
+	err := PoorMansClone(g.mirrorDir, targetDir, &git.CheckoutOptions{
+		Hash:                      plumbing.NewHash(commit),
+		SparseCheckoutDirectories: sparseDirs,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to clone %s from %s: %w", commit, g.url.String(), err)
 	}
@@ -0,0 +1,36 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
+)
+
+// GetAwsAppConfigConfiguration retrieves the currently deployed configuration of the given configuration profile and
+// environment. A types.ResourceNotFoundException is returned if the application, environment or configuration profile
+// does not exist.
+func GetAwsAppConfigConfiguration(ctx context.Context, aws AwsClientFactory, profile *string, region *string, application string, environment string, configurationProfile string) (string, error) {
+	name := fmt.Sprintf("%s/%s/%s", application, environment, configurationProfile)
+
+	acClient, err := aws.AppConfigDataClient(ctx, profile, region)
+	if err != nil {
+		return "", fmt.Errorf("getting configuration %s from AWS AppConfig failed: %w", name, err)
+	}
+
+	session, err := acClient.StartConfigurationSession(ctx, &appconfigdata.StartConfigurationSessionInput{
+		ApplicationIdentifier:          &application,
+		EnvironmentIdentifier:          &environment,
+		ConfigurationProfileIdentifier: &configurationProfile,
+	})
+	if err != nil {
+		return "", fmt.Errorf("getting configuration %s from AWS AppConfig failed: %w", name, err)
+	}
+
+	r, err := acClient.GetLatestConfiguration(ctx, &appconfigdata.GetLatestConfigurationInput{
+		ConfigurationToken: session.InitialConfigurationToken,
+	})
+	if err != nil {
+		return "", fmt.Errorf("getting configuration %s from AWS AppConfig failed: %w", name, err)
+	}
+	return string(r.Configuration), nil
+}
@@ -3,22 +3,47 @@ package aws
 import (
 	"context"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
 )
 
 type GetSecretValueInterface interface {
 	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
 }
 
+type SsmParametersInterface interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+	GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error)
+}
+
+type AppConfigDataInterface interface {
+	StartConfigurationSession(ctx context.Context, params *appconfigdata.StartConfigurationSessionInput, optFns ...func(*appconfigdata.Options)) (*appconfigdata.StartConfigurationSessionOutput, error)
+	GetLatestConfiguration(ctx context.Context, params *appconfigdata.GetLatestConfigurationInput, optFns ...func(*appconfigdata.Options)) (*appconfigdata.GetLatestConfigurationOutput, error)
+}
+
 type AwsClientFactory interface {
 	SecretsManagerClient(ctx context.Context, profile *string, region *string) (GetSecretValueInterface, error)
+	SsmClient(ctx context.Context, profile *string, region *string) (SsmParametersInterface, error)
+	AppConfigDataClient(ctx context.Context, profile *string, region *string) (AppConfigDataInterface, error)
 }
 
 type awsClientFactory struct {
 	client    client.Client
 	awsConfig *types.AwsConfig
+
+	// appConfigClients caches AppConfig data clients per profile and region, so that multiple awsAppConfig vars sources
+	// don't need to load the AWS config and credentials again
+	appConfigClients      map[clientCacheKey]AppConfigDataInterface
+	appConfigClientsMutex sync.Mutex
+}
+
+type clientCacheKey struct {
+	profile string
+	region  string
 }
 
 func (a *awsClientFactory) SecretsManagerClient(ctx context.Context, profile *string, region *string) (GetSecretValueInterface, error) {
@@ -35,9 +60,55 @@ func (a *awsClientFactory) SecretsManagerClient(ctx context.Context, profile *st
 	return secretsmanager.NewFromConfig(cfg), nil
 }
 
+func (a *awsClientFactory) SsmClient(ctx context.Context, profile *string, region *string) (SsmParametersInterface, error) {
+	var configOpts []func(*config.LoadOptions) error
+
+	if region != nil {
+		configOpts = append(configOpts, config.WithRegion(*region))
+	}
+
+	cfg, err := LoadAwsConfigHelper(ctx, a.client, a.awsConfig, profile, configOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return ssm.NewFromConfig(cfg), nil
+}
+
+func (a *awsClientFactory) AppConfigDataClient(ctx context.Context, profile *string, region *string) (AppConfigDataInterface, error) {
+	var key clientCacheKey
+	if profile != nil {
+		key.profile = *profile
+	}
+	if region != nil {
+		key.region = *region
+	}
+
+	a.appConfigClientsMutex.Lock()
+	defer a.appConfigClientsMutex.Unlock()
+
+	if c, ok := a.appConfigClients[key]; ok {
+		return c, nil
+	}
+
+	var configOpts []func(*config.LoadOptions) error
+
+	if region != nil {
+		configOpts = append(configOpts, config.WithRegion(*region))
+	}
+
+	cfg, err := LoadAwsConfigHelper(ctx, a.client, a.awsConfig, profile, configOpts...)
+	if err != nil {
+		return nil, err
+	}
+	c := appconfigdata.NewFromConfig(cfg)
+	a.appConfigClients[key] = c
+	return c, nil
+}
+
 func NewClientFactory(c client.Client, awsConfig *types.AwsConfig) AwsClientFactory {
 	return &awsClientFactory{
-		client:    c,
-		awsConfig: awsConfig,
+		client:           c,
+		awsConfig:        awsConfig,
+		appConfigClients: map[clientCacheKey]AppConfigDataInterface{},
 	}
 }
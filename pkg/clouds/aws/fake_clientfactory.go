@@ -4,14 +4,23 @@ import (
 	"context"
 	"fmt"
 	arn2 "github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
+	appconfigdatatypes "github.com/aws/aws-sdk-go-v2/service/appconfigdata/types"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"sort"
+	"strings"
 )
 
 type FakeAwsClientFactory struct {
 	GetSecretValueInterface
 
-	Secrets map[string]string
+	Secrets    map[string]string
+	Parameters map[string]string
+	// AppConfigs is keyed by "application/environment/configurationProfile"
+	AppConfigs map[string]string
 }
 
 func (f *FakeAwsClientFactory) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
@@ -41,6 +50,89 @@ func (f *FakeAwsClientFactory) SecretsManagerClient(ctx context.Context, profile
 	return f, nil
 }
 
+func (f *FakeAwsClientFactory) GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	name := *params.Name
+	arn, err := arn2.Parse(name)
+	if err == nil {
+		name = strings.TrimPrefix(arn.Resource, "parameter")
+	}
+
+	v, ok := f.Parameters[name]
+	if ok {
+		return &ssm.GetParameterOutput{
+			Parameter: &ssmtypes.Parameter{
+				Name:  &name,
+				Value: &v,
+			},
+		}, nil
+	}
+
+	errMsg := fmt.Sprintf("parameter %s not found", *params.Name)
+	return nil, &ssmtypes.ParameterNotFound{
+		Message: &errMsg,
+	}
+}
+
+func (f *FakeAwsClientFactory) GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	prefix := strings.TrimSuffix(*params.Path, "/") + "/"
+	recursive := params.Recursive != nil && *params.Recursive
+
+	var names []string
+	for n := range f.Parameters {
+		if !strings.HasPrefix(n, prefix) {
+			continue
+		}
+		if !recursive && strings.Contains(n[len(prefix):], "/") {
+			continue
+		}
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var ret ssm.GetParametersByPathOutput
+	for _, n := range names {
+		v := f.Parameters[n]
+		ret.Parameters = append(ret.Parameters, ssmtypes.Parameter{
+			Name:  &n,
+			Value: &v,
+		})
+	}
+	return &ret, nil
+}
+
+func (f *FakeAwsClientFactory) SsmClient(ctx context.Context, profile *string, region *string) (SsmParametersInterface, error) {
+	return f, nil
+}
+
+func (f *FakeAwsClientFactory) StartConfigurationSession(ctx context.Context, params *appconfigdata.StartConfigurationSessionInput, optFns ...func(*appconfigdata.Options)) (*appconfigdata.StartConfigurationSessionOutput, error) {
+	key := fmt.Sprintf("%s/%s/%s", *params.ApplicationIdentifier, *params.EnvironmentIdentifier, *params.ConfigurationProfileIdentifier)
+	if _, ok := f.AppConfigs[key]; ok {
+		// the key is used as token, so that GetLatestConfiguration can find the configuration again
+		return &appconfigdata.StartConfigurationSessionOutput{
+			InitialConfigurationToken: &key,
+		}, nil
+	}
+
+	errMsg := fmt.Sprintf("configuration %s not found", key)
+	return nil, &appconfigdatatypes.ResourceNotFoundException{
+		Message: &errMsg,
+	}
+}
+
+func (f *FakeAwsClientFactory) GetLatestConfiguration(ctx context.Context, params *appconfigdata.GetLatestConfigurationInput, optFns ...func(*appconfigdata.Options)) (*appconfigdata.GetLatestConfigurationOutput, error) {
+	c, ok := f.AppConfigs[*params.ConfigurationToken]
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration token %s", *params.ConfigurationToken)
+	}
+	return &appconfigdata.GetLatestConfigurationOutput{
+		Configuration: []byte(c),
+	}, nil
+}
+
+func (f *FakeAwsClientFactory) AppConfigDataClient(ctx context.Context, profile *string, region *string) (AppConfigDataInterface, error) {
+	return f, nil
+}
+
 func NewFakeClientFactory() *FakeAwsClientFactory {
 	return &FakeAwsClientFactory{}
 }
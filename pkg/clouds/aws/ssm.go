@@ -0,0 +1,72 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+func GetAwsSsmParameter(ctx context.Context, aws AwsClientFactory, profile *string, region *string, name string) (string, error) {
+	ssmClient, err := aws.SsmClient(ctx, profile, region)
+	if err != nil {
+		return "", fmt.Errorf("getting parameter %s from AWS SSM parameter store failed: %w", name, err)
+	}
+
+	withDecryption := true
+	r, err := ssmClient.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           &name,
+		WithDecryption: &withDecryption,
+	})
+	if err != nil {
+		return "", fmt.Errorf("getting parameter %s from AWS SSM parameter store failed: %w", name, err)
+	}
+	if r.Parameter == nil || r.Parameter.Value == nil {
+		return "", nil
+	}
+	return *r.Parameter.Value, nil
+}
+
+// GetAwsSsmParametersByPath recursively reads all parameters below the given path. The returned map is keyed by the
+// full parameter names. If no parameter exists below the path, a types.ParameterNotFound error is returned.
+func GetAwsSsmParametersByPath(ctx context.Context, aws AwsClientFactory, profile *string, region *string, path string) (map[string]string, error) {
+	ssmClient, err := aws.SsmClient(ctx, profile, region)
+	if err != nil {
+		return nil, fmt.Errorf("getting parameters by path %s from AWS SSM parameter store failed: %w", path, err)
+	}
+
+	recursive := true
+	withDecryption := true
+	ret := map[string]string{}
+	var nextToken *string
+	for {
+		r, err := ssmClient.GetParametersByPath(ctx, &ssm.GetParametersByPathInput{
+			Path:           &path,
+			Recursive:      &recursive,
+			WithDecryption: &withDecryption,
+			NextToken:      nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("getting parameters by path %s from AWS SSM parameter store failed: %w", path, err)
+		}
+		for _, p := range r.Parameters {
+			if p.Name == nil || p.Value == nil {
+				continue
+			}
+			ret[*p.Name] = *p.Value
+		}
+		if r.NextToken == nil || *r.NextToken == "" {
+			break
+		}
+		nextToken = r.NextToken
+	}
+
+	if len(ret) == 0 {
+		errMsg := fmt.Sprintf("no parameters found below path %s", path)
+		return nil, fmt.Errorf("getting parameters by path %s from AWS SSM parameter store failed: %w", path, &types.ParameterNotFound{
+			Message: &errMsg,
+		})
+	}
+
+	return ret, nil
+}
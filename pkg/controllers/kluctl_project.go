@@ -799,17 +799,18 @@ func (pt *preparedTarget) kluctlDeployOrPokeImages(deployMode string, targetCont
 func (pt *preparedTarget) kluctlDeploy(targetContext *target_context.TargetContext) *result.CommandResult {
 	timer := prometheus.NewTimer(internal_metrics.NewKluctlDeploymentDuration(pt.pp.obj.ObjectMeta.Namespace, pt.pp.obj.ObjectMeta.Name, pt.pp.obj.Spec.DeployMode))
 	defer timer.ObserveDuration()
-	cmd := commands.NewDeployCommand(targetContext)
-	cmd.ForceApply = pt.pp.obj.Spec.ForceApply
-	cmd.ReplaceOnError = pt.pp.obj.Spec.ReplaceOnError
-	cmd.ForceReplaceOnError = pt.pp.obj.Spec.ForceReplaceOnError
-	cmd.AbortOnError = pt.pp.obj.Spec.AbortOnError
-	cmd.ReadinessTimeout = time.Minute * 10
-	cmd.NoWait = pt.pp.obj.Spec.NoWait
-	cmd.Prune = pt.pp.obj.Spec.Prune
-	cmd.WaitPrune = false
-
-	cmdResult := cmd.Run(nil)
+	cmdResult := commands.Deploy(targetContext, commands.DeployOptions{
+		ForceApply:               pt.pp.obj.Spec.ForceApply,
+		ReplaceOnError:           pt.pp.obj.Spec.ReplaceOnError,
+		ForceReplaceOnError:      pt.pp.obj.Spec.ForceReplaceOnError,
+		ForceReplaceOnErrorKinds: pt.pp.obj.Spec.ForceReplaceOnErrorKinds,
+		ReplacePreserveMetadata:  pt.pp.obj.Spec.ReplacePreserveMetadata,
+		AbortOnError:             pt.pp.obj.Spec.AbortOnError,
+		ReadinessTimeout:         time.Minute * 10,
+		NoWait:                   pt.pp.obj.Spec.NoWait,
+		Prune:                    pt.pp.obj.Spec.Prune,
+		WaitPrune:                false,
+	})
 	return cmdResult
 }
 
@@ -839,6 +840,8 @@ func (pt *preparedTarget) kluctlDiff(targetContext *target_context.TargetContext
 	cmd.ForceApply = pt.pp.obj.Spec.ForceApply
 	cmd.ReplaceOnError = pt.pp.obj.Spec.ReplaceOnError
 	cmd.ForceReplaceOnError = pt.pp.obj.Spec.ForceReplaceOnError
+	cmd.ForceReplaceOnErrorKinds = pt.pp.obj.Spec.ForceReplaceOnErrorKinds
+	cmd.ReplacePreserveMetadata = pt.pp.obj.Spec.ReplacePreserveMetadata
 	cmd.SkipResourceVersions = resourceVersions
 
 	cmdResult := cmd.Run()
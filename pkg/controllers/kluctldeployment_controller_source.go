@@ -446,7 +446,7 @@ func (r *KluctlDeploymentReconciler) buildGitRepoCache(ctx context.Context, secr
 		return nil, err
 	}
 
-	rc := repocache.NewGitRepoCache(ctx, r.SshPool, ga, soClient, 0)
+	rc := repocache.NewGitRepoCache(ctx, r.SshPool, ga, soClient, 0, 0)
 	return rc, nil
 }
 
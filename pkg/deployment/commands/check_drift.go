@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+type CheckDriftCommand struct {
+	targetCtx *target_context.TargetContext
+
+	IgnoreTags           bool
+	IgnoreLabels         bool
+	IgnoreAnnotations    bool
+	IgnoreKluctlMetadata bool
+}
+
+func NewCheckDriftCommand(targetCtx *target_context.TargetContext) *CheckDriftCommand {
+	return &CheckDriftCommand{
+		targetCtx: targetCtx,
+	}
+}
+
+func (cmd *CheckDriftCommand) Run() *result.CommandResult {
+	dew := utils.NewDeploymentErrorsAndWarnings()
+
+	r := newCommandResult(cmd.targetCtx, cmd.targetCtx.KluctlProject.LoadTime, "check-drift")
+
+	defer func() {
+		finishCommandResult(r, cmd.targetCtx, dew)
+	}()
+
+	ru := utils.NewRemoteObjectsUtil(cmd.targetCtx.SharedContext.Ctx, dew)
+	err := ru.UpdateRemoteObjects(cmd.targetCtx.SharedContext.K, &cmd.targetCtx.Target.Discriminator, cmd.targetCtx.DeploymentCollection.LocalObjectRefs(), false)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+
+	for _, d := range cmd.targetCtx.DeploymentCollection.Deployments {
+		if d.Config.OnlyRender {
+			continue
+		}
+		ignoreForDiffs := d.Project.GetIgnoreForDiffs(cmd.IgnoreTags, cmd.IgnoreLabels, cmd.IgnoreAnnotations, cmd.IgnoreKluctlMetadata)
+		for _, lo := range d.Objects {
+			ref := lo.GetK8sRef()
+			ro := ru.GetRemoteObject(ref)
+			if ro == nil {
+				// not deployed yet, which is not considered drift
+				continue
+			}
+
+			driftStatus, changes, err := utils.CheckDrift(lo, ro, ignoreForDiffs)
+			if err != nil {
+				dew.AddError(ref, err)
+				continue
+			}
+			switch driftStatus {
+			case utils.DriftStatusUnknown:
+				dew.AddWarning(ref, fmt.Errorf("object has no %s annotation, drift can not be determined", utils.RenderedChecksumAnnotation))
+				continue
+			case utils.DriftStatusOutdated:
+				dew.AddWarning(ref, fmt.Errorf("rendered object changed since the last deployment"))
+				continue
+			case utils.DriftStatusInSync:
+				continue
+			}
+
+			r.Objects = append(r.Objects, result.ResultObject{
+				BaseObject: result.BaseObject{
+					Ref:     ref,
+					Changes: changes,
+				},
+				Rendered: lo,
+				Remote:   ro,
+			})
+		}
+	}
+
+	return r
+}
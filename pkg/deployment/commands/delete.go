@@ -67,7 +67,7 @@ func (cmd *DeleteCommand) Run(ctx context.Context, k *k8s.K8sCluster, confirmCb
 		return r
 	}
 
-	deleteRefs, err := utils2.FindObjectsForDelete(k, ru.GetFilteredRemoteObjects(inclusion), inclusion.HasType("tags"), nil)
+	deleteRefs, err := utils2.FindObjectsForDelete(k, ru.GetFilteredRemoteObjects(), []*utils.Inclusion{inclusion}, nil)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 		return r
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"sort"
+	"time"
+)
+
+type DeleteResultOptions struct {
+	// IncludeHooks causes hooks that were applied by the command to be deleted as well
+	IncludeHooks bool
+	// IncludeClusterScoped causes cluster-scoped objects (e.g. Namespaces and CRDs) to be deleted as well. These are
+	// skipped by default, as they are often shared between multiple deployments.
+	IncludeClusterScoped bool
+	Wait                 bool
+}
+
+// DeleteResultCommand deletes exactly the objects that were applied by a previous command, as recorded in its
+// command result. In contrast to DeleteCommand, it does not require the deployment project and does not look for
+// objects based on the discriminator.
+type DeleteResultCommand struct {
+	cr   *result.CommandResult
+	opts DeleteResultOptions
+}
+
+func NewDeleteResultCommand(cr *result.CommandResult, opts DeleteResultOptions) *DeleteResultCommand {
+	return &DeleteResultCommand{
+		cr:   cr,
+		opts: opts,
+	}
+}
+
+func (cmd *DeleteResultCommand) Run(ctx context.Context, k *k8s.K8sCluster, confirmCb func(refs []k8s2.ObjectRef) error) *result.CommandResult {
+	startTime := time.Now()
+
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+
+	r := newDeleteCommandResult(k, startTime, nil)
+	r.ProjectKey = cmd.cr.ProjectKey
+	r.TargetKey = cmd.cr.TargetKey
+	r.Target = cmd.cr.Target
+	if k != nil {
+		r.Command.DryRun = k.DryRun
+	}
+
+	defer func() {
+		finishCommandResult(r, nil, dew)
+	}()
+
+	refs := cmd.selectRefs(ctx)
+
+	ru := utils2.NewRemoteObjectsUtil(ctx, dew)
+	err := ru.UpdateRemoteObjects(k, nil, refs, false)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+
+	var remoteObjects []*uo.UnstructuredObject
+	for _, ref := range refs {
+		o := ru.GetRemoteObject(ref)
+		if o == nil {
+			// already gone
+			continue
+		}
+		if d := cmd.cr.TargetKey.Discriminator; d != "" {
+			rd := o.GetK8sLabel("kluctl.io/discriminator")
+			if rd == nil || *rd != d {
+				dew.AddWarning(ref, fmt.Errorf("object is now owned by another discriminator, skipping deletion"))
+				continue
+			}
+		}
+		remoteObjects = append(remoteObjects, o)
+	}
+
+	// FindObjectsForDelete is only used to filter out objects that must never be deleted (e.g. due to
+	// 'kluctl.io/skip-delete'), as its ordering does not reflect the deployment order
+	allowedRefs, err := utils2.FindObjectsForDelete(k, remoteObjects, nil, nil)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+	allowed := map[k8s2.ObjectRef]bool{}
+	for _, ref := range allowedRefs {
+		allowed[ref] = true
+	}
+	var deleteRefs []k8s2.ObjectRef
+	for _, o := range remoteObjects {
+		if allowed[o.GetK8sRef()] {
+			deleteRefs = append(deleteRefs, o.GetK8sRef())
+		}
+	}
+
+	if confirmCb != nil {
+		err = confirmCb(deleteRefs)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+	}
+
+	deleted := utils2.DeleteObjectsInOrder(ctx, k, deleteRefs, dew, cmd.opts.Wait)
+
+	r.Objects = collectObjects(nil, ru, nil, nil, nil, deleted)
+
+	return r
+}
+
+// selectRefs returns the refs of all objects that were applied by the command, excluding hooks and cluster-scoped
+// objects unless requested otherwise. Namespaces are only included if they were created by the command, as deleting a
+// Namespace would also delete all other objects inside it. The refs are returned in reverse deployment order.
+func (cmd *DeleteResultCommand) selectRefs(ctx context.Context) []k8s2.ObjectRef {
+	var ret []k8s2.ObjectRef
+	skippedHooks := 0
+	skippedClusterScoped := 0
+	skippedNamespaces := 0
+	for _, o := range cmd.cr.Objects {
+		if o.Deleted || (o.Applied == nil && !o.Hook) {
+			continue
+		}
+		if o.Hook && !cmd.opts.IncludeHooks {
+			skippedHooks++
+			continue
+		}
+		if o.Ref.Namespace == "" && !cmd.opts.IncludeClusterScoped {
+			skippedClusterScoped++
+			continue
+		}
+		if o.Ref.Group == "" && o.Ref.Kind == "Namespace" && !o.New {
+			skippedNamespaces++
+			continue
+		}
+		ret = append(ret, o.Ref)
+	}
+	if skippedHooks != 0 {
+		status.Infof(ctx, "Skipping deletion of %d hooks", skippedHooks)
+	}
+	if skippedClusterScoped != 0 {
+		status.Infof(ctx, "Skipping deletion of %d cluster-scoped objects", skippedClusterScoped)
+	}
+	if skippedNamespaces != 0 {
+		status.Infof(ctx, "Skipping deletion of %d namespaces that were not created by the command", skippedNamespaces)
+	}
+
+	sortRefsForDelete(ret, collectDeploymentOrder(cmd.cr.Deployment, nil))
+	return ret
+}
+
+// collectDeploymentOrder returns all rendered objects of the given deployment project, including included projects,
+// in the order they were deployed
+func collectDeploymentOrder(c *types.DeploymentProjectConfig, ret []k8s2.ObjectRef) []k8s2.ObjectRef {
+	if c == nil {
+		return ret
+	}
+	for _, di := range c.Deployments {
+		ret = append(ret, di.RenderedObjects...)
+		ret = collectDeploymentOrder(di.RenderedInclude, ret)
+	}
+	return ret
+}
+
+// sortRefsForDelete sorts refs in reverse deployment order. Objects that are not part of the deployment order are
+// deleted first, as they can not be a dependency of any deployed object.
+func sortRefsForDelete(refs []k8s2.ObjectRef, deploymentOrder []k8s2.ObjectRef) {
+	index := map[k8s2.ObjectRef]int{}
+	for i, ref := range deploymentOrder {
+		index[ref] = i
+	}
+	getIndex := func(ref k8s2.ObjectRef) int {
+		if i, ok := index[ref]; ok {
+			return i
+		}
+		return len(deploymentOrder)
+	}
+	sort.SliceStable(refs, func(i, j int) bool {
+		return getIndex(refs[i]) > getIndex(refs[j])
+	})
+}
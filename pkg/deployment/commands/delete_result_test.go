@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newDeleteResultTestObject(ref k8s2.ObjectRef, applied bool, f func(o *result.ResultObject)) result.ResultObject {
+	o := result.ResultObject{}
+	o.Ref = ref
+	if applied {
+		o.Applied = uo.New()
+	}
+	if f != nil {
+		f(&o)
+	}
+	return o
+}
+
+func TestDeleteResultSelectRefs(t *testing.T) {
+	ns := k8s2.ObjectRef{Version: "v1", Kind: "Namespace", Name: "ns"}
+	newNs := k8s2.ObjectRef{Version: "v1", Kind: "Namespace", Name: "new-ns"}
+	crd := k8s2.ObjectRef{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition", Name: "crd"}
+	cm1 := k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm1", Namespace: "ns"}
+	cm2 := k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm2", Namespace: "new-ns"}
+	hook := k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "hook", Namespace: "ns"}
+	deleted := k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "deleted", Namespace: "ns"}
+	rendered := k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "rendered", Namespace: "ns"}
+
+	cr := &result.CommandResult{
+		Objects: []result.ResultObject{
+			newDeleteResultTestObject(cm1, true, nil),
+			newDeleteResultTestObject(ns, true, nil),
+			newDeleteResultTestObject(newNs, true, func(o *result.ResultObject) { o.New = true }),
+			newDeleteResultTestObject(crd, true, func(o *result.ResultObject) { o.New = true }),
+			newDeleteResultTestObject(cm2, true, nil),
+			newDeleteResultTestObject(hook, false, func(o *result.ResultObject) { o.Hook = true }),
+			newDeleteResultTestObject(deleted, true, func(o *result.ResultObject) { o.Deleted = true }),
+			newDeleteResultTestObject(rendered, false, nil),
+		},
+	}
+
+	testCases := []struct {
+		name   string
+		opts   DeleteResultOptions
+		result []k8s2.ObjectRef
+	}{
+		{name: "default", opts: DeleteResultOptions{}, result: []k8s2.ObjectRef{cm1, cm2}},
+		{name: "hooks", opts: DeleteResultOptions{IncludeHooks: true}, result: []k8s2.ObjectRef{cm1, cm2, hook}},
+		{name: "cluster-scoped", opts: DeleteResultOptions{IncludeClusterScoped: true}, result: []k8s2.ObjectRef{cm1, newNs, crd, cm2}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := NewDeleteResultCommand(cr, tc.opts)
+			assert.Equal(t, tc.result, cmd.selectRefs(context.Background()))
+		})
+	}
+}
+
+func TestDeleteResultSelectRefsOrder(t *testing.T) {
+	ns := k8s2.ObjectRef{Version: "v1", Kind: "Namespace", Name: "ns"}
+	crd := k8s2.ObjectRef{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition", Name: "crd"}
+	cr1 := k8s2.ObjectRef{Group: "example.com", Version: "v1", Kind: "Example", Name: "cr1", Namespace: "ns"}
+	cm1 := k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm1", Namespace: "ns"}
+	cm2 := k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm2", Namespace: "ns"}
+	unknown := k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "unknown", Namespace: "ns"}
+
+	cr := &result.CommandResult{
+		Deployment: &types.DeploymentProjectConfig{
+			Deployments: []*types.DeploymentItemConfig{
+				{RenderedObjects: []k8s2.ObjectRef{ns, crd}},
+				{RenderedInclude: &types.DeploymentProjectConfig{
+					Deployments: []*types.DeploymentItemConfig{
+						{RenderedObjects: []k8s2.ObjectRef{cm1}},
+						{RenderedObjects: []k8s2.ObjectRef{cr1}},
+					},
+				}},
+				{RenderedObjects: []k8s2.ObjectRef{cm2}},
+			},
+		},
+		// the order of objects in the result is unrelated to the deployment order
+		Objects: []result.ResultObject{
+			newDeleteResultTestObject(cm1, true, nil),
+			newDeleteResultTestObject(cm2, true, nil),
+			newDeleteResultTestObject(cr1, true, nil),
+			newDeleteResultTestObject(crd, true, func(o *result.ResultObject) { o.New = true }),
+			newDeleteResultTestObject(ns, true, func(o *result.ResultObject) { o.New = true }),
+			newDeleteResultTestObject(unknown, true, nil),
+		},
+	}
+
+	cmd := NewDeleteResultCommand(cr, DeleteResultOptions{IncludeClusterScoped: true})
+	assert.Equal(t, []k8s2.ObjectRef{unknown, cm2, cr1, cm1, crd, ns}, cmd.selectRefs(context.Background()))
+}
@@ -7,29 +7,161 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"k8s.io/apimachinery/pkg/labels"
 	"time"
 )
 
-type DeployCommand struct {
-	targetCtx *target_context.TargetContext
-
+// DeployOptions holds all options of a deployment. It does not depend on any CLI flags, so that library consumers can
+// perform deployments without invoking the CLI. See Deploy for details.
+type DeployOptions struct {
 	ForceApply          bool
 	ReplaceOnError      bool
 	ForceReplaceOnError bool
 	AbortOnError        bool
+	AbortAfterErrors    int
 	ReadinessTimeout    time.Duration
 	NoWait              bool
 	Prune               bool
 	WaitPrune           bool
+	PruneInclusion      *utils.Inclusion
+	// PruneAllowKinds and PruneDenyKinds restrict pruning to/exclude the given kinds, in the format 'Kind.group'
+	PruneAllowKinds     []string
+	PruneDenyKinds      []string
+	CanaryPercent       int
+	WaitRollout         bool
+	WaitRolloutTimeout  time.Duration
+	ApplyParallelism    int
+	ApplyTimeout        time.Duration
+	BarrierTimeout      time.Duration
+	HookPollInterval    time.Duration
+	HookPollMaxInterval time.Duration
+	RetryBackoff        time.Duration
+	RetryMaxBackoff     time.Duration
+
+	// ApplyLabelSelector restricts applying to objects matching the given label selector. Non-matching objects are
+	// skipped, but are never pruned.
+	ApplyLabelSelector string
+
+	FailOnApiDeprecation       bool
+	FailOnApiDeprecationGroups []string
+
+	// WarningsAsErrors causes warnings returned by the cluster to be treated as errors. WarningsAsErrorsPatterns
+	// optionally restricts this to warnings matching at least one of the given regular expressions.
+	WarningsAsErrors         bool
+	WarningsAsErrorsPatterns []string
+
+	// ForceReplaceOnErrorKinds restricts ForceReplaceOnError to the given kinds, in the format 'Kind.group'
+	ForceReplaceOnErrorKinds []string
+	// ReplacePreserveMetadata is a list of glob patterns for annotation and label keys to carry over from the remote
+	// object when replacing objects
+	ReplacePreserveMetadata []string
+
+	// PinDigests causes all container images to be resolved to their current digests and rewritten to reference these
+	// digests before anything is applied. Resolution failures are treated as warnings if PinDigestsAllowFailures is set.
+	PinDigests              bool
+	PinDigestsAllowFailures bool
+
+	// ResumeFromResult, if set, is the result of a previous (failed) deployment of the same target. Objects that were
+	// applied successfully by this deployment and did not change since then are not applied again. Hooks are only
+	// skipped if the whole deployment item was applied successfully.
+	ResumeFromResult *result.CommandResult
+
+	// ObjectValidator allows to register local policies that are checked before objects are applied
+	ObjectValidator utils2.ObjectValidator
+
+	// EventCallback receives structured events while objects are applied. Events are not emitted for the diff that
+	// is performed before the actual deployment.
+	EventCallback utils2.ApplyEventCallback
+
+	// ConfirmDeploymentItem is invoked before each deployment item gets applied, allowing to skip the item or to abort
+	// the whole deployment. It is not invoked for the diff and the canary apply.
+	ConfirmDeploymentItem utils2.ConfirmDeploymentItemCallback
+
+	// DiffResultCallback, if set, is invoked with the result of the diff that is performed before the actual
+	// deployment. Returning an error aborts the deployment. The diff is skipped if no callback is set.
+	DiffResultCallback func(diffResult *result.CommandResult) error
+	// Metrics, if set, receives metrics about the deployment. Metrics are not collected for the diff that is
+	// performed before the actual deployment.
+	Metrics *utils2.ApplyMetrics
+	// CanaryResultCallback, if set, is invoked with the result of the canary apply (see CanaryPercent). Returning an
+	// error aborts the deployment. Without a callback, the deployment only proceeds if the canary apply succeeded.
+	CanaryResultCallback func(canaryResult *result.CommandResult) error
+}
+
+type deployCommand struct {
+	targetCtx *target_context.TargetContext
+
+	DeployOptions
 }
 
-func NewDeployCommand(targetCtx *target_context.TargetContext) *DeployCommand {
-	return &DeployCommand{
-		targetCtx: targetCtx,
+// Deploy performs a deployment of the given target context, the same way as 'kluctl deploy' does. The deployment
+// collection of the target context must already be prepared (see DeploymentCollection.Prepare). Errors are reported
+// through the returned command result. This is the only entry point for deployments, used by the CLI, the controller
+// and library consumers alike.
+func Deploy(targetCtx *target_context.TargetContext, opts DeployOptions) *result.CommandResult {
+	cmd := &deployCommand{
+		targetCtx:     targetCtx,
+		DeployOptions: opts,
 	}
+	return cmd.run()
 }
 
-func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult) error) *result.CommandResult {
+// buildApplyUtilOptions builds the options used for the diff that is performed before the actual deployment
+func (o *DeployOptions) buildApplyUtilOptions() (*utils2.ApplyUtilOptions, error) {
+	replacePreserveMetadata, err := parseGlobs(o.ReplacePreserveMetadata)
+	if err != nil {
+		return nil, err
+	}
+	warningsAsErrorsPatterns, err := parseRegexps(o.WarningsAsErrorsPatterns)
+	if err != nil {
+		return nil, err
+	}
+	var applyLabelSelector labels.Selector
+	if o.ApplyLabelSelector != "" {
+		applyLabelSelector, err = labels.Parse(o.ApplyLabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid apply label selector '%s': %w", o.ApplyLabelSelector, err)
+		}
+	}
+
+	return &utils2.ApplyUtilOptions{
+		ForceApply:          o.ForceApply,
+		ReplaceOnError:      o.ReplaceOnError,
+		ForceReplaceOnError: o.ForceReplaceOnError,
+		DryRun:              true,
+		AbortOnError:        false,
+		ReadinessTimeout:    o.ReadinessTimeout,
+		NoWait:              o.NoWait,
+		Parallelism:         o.ApplyParallelism,
+		ApplyTimeout:        o.ApplyTimeout,
+		BarrierTimeout:      o.BarrierTimeout,
+		HookPollInterval:    o.HookPollInterval,
+		HookPollMaxInterval: o.HookPollMaxInterval,
+		RetryBackoff:        o.RetryBackoff,
+		RetryMaxBackoff:     o.RetryMaxBackoff,
+		ObjectValidator:     o.ObjectValidator,
+		// messages are printed by the deployment itself, not by the preceding diff
+		SkipMessages: true,
+
+		ApplyLabelSelector: applyLabelSelector,
+
+		ForceReplaceOnErrorKinds: parseGroupKinds(o.ForceReplaceOnErrorKinds),
+		ReplacePreserveMetadata:  replacePreserveMetadata,
+
+		WarningsAsErrors:           o.WarningsAsErrors,
+		WarningsAsErrorsPatterns:   warningsAsErrorsPatterns,
+		FailOnApiDeprecation:       o.FailOnApiDeprecation,
+		FailOnApiDeprecationGroups: o.FailOnApiDeprecationGroups,
+	}, nil
+}
+
+func (cmd *deployCommand) run() *result.CommandResult {
+	startTime := time.Now()
+	defer func() {
+		cmd.Metrics.ObserveDeployDuration(time.Since(startTime))
+	}()
+
 	dew := utils2.NewDeploymentErrorsAndWarnings()
 
 	r := newCommandResult(cmd.targetCtx, cmd.targetCtx.KluctlProject.LoadTime, "deploy")
@@ -48,6 +180,25 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 		dew.AddWarning(k8s2.ObjectRef{}, fmt.Errorf("no discriminator configured. Orphan object detection will not work"))
 	}
 
+	var resumeAppliedObjects map[k8s2.ObjectRef]string
+	if cmd.ResumeFromResult != nil {
+		err := checkResumeFromResult(cmd.targetCtx, cmd.ResumeFromResult)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+		resumeAppliedObjects = utils2.BuildResumeAppliedObjects(cmd.ResumeFromResult)
+		status.Infof(cmd.targetCtx.SharedContext.Ctx, "Resuming from command result %s with %d previously applied objects", cmd.ResumeFromResult.Id, len(resumeAppliedObjects))
+	}
+
+	if cmd.PinDigests {
+		pinned, ok := pinImageDigests(cmd.targetCtx, dew, cmd.PinDigestsAllowFailures)
+		r.PinnedImages = pinned
+		if !ok {
+			return r
+		}
+	}
+
 	ru := utils2.NewRemoteObjectsUtil(cmd.targetCtx.SharedContext.Ctx, dew)
 	err := ru.UpdateRemoteObjects(cmd.targetCtx.SharedContext.K, &cmd.targetCtx.Target.Discriminator, cmd.targetCtx.DeploymentCollection.LocalObjectRefs(), false)
 	if err != nil {
@@ -56,17 +207,13 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 	}
 
 	// prepare for a diff
-	o := &utils2.ApplyUtilOptions{
-		ForceApply:          cmd.ForceApply,
-		ReplaceOnError:      cmd.ReplaceOnError,
-		ForceReplaceOnError: cmd.ForceReplaceOnError,
-		DryRun:              true,
-		AbortOnError:        false,
-		ReadinessTimeout:    cmd.ReadinessTimeout,
-		NoWait:              cmd.NoWait,
+	o, err := cmd.buildApplyUtilOptions()
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
 	}
 
-	if diffResultCb != nil {
+	if cmd.DiffResultCallback != nil {
 		diffDew := dew.Clone()
 		au := utils2.NewApplyDeploymentsUtil(cmd.targetCtx.SharedContext.Ctx, diffDew, ru, cmd.targetCtx.SharedContext.K, o)
 		au.ApplyDeployments(cmd.targetCtx.DeploymentCollection.Deployments)
@@ -74,15 +221,22 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 		du := utils2.NewDiffUtil(diffDew, ru, au.GetAppliedObjectsMap())
 		du.DiffDeploymentItems(cmd.targetCtx.DeploymentCollection.Deployments)
 
-		orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+		orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection, cmd.PruneInclusion)
+		var pruneOrphans []k8s2.ObjectRef
+		var pruneSkipped []result.PruneSkippedObject
+		if cmd.Prune {
+			pruneOrphans, pruneSkipped = buildPruneKindFilter(cmd.PruneAllowKinds, cmd.PruneDenyKinds).Filter(orphanObjects)
+		}
 		diffResult := &result.CommandResult{
-			Objects:    collectObjects(cmd.targetCtx.DeploymentCollection, ru, au, du, orphanObjects, nil),
-			Errors:     diffDew.GetErrorsList(),
-			Warnings:   diffDew.GetWarningsList(),
-			SeenImages: cmd.targetCtx.DeploymentCollection.Images.SeenImages(false),
+			Objects:      collectObjects(cmd.targetCtx.DeploymentCollection, ru, au, du, orphanObjects, nil),
+			Errors:       diffDew.GetErrorsList(),
+			Warnings:     diffDew.GetDeduplicatedWarningsList(),
+			SeenImages:   cmd.targetCtx.DeploymentCollection.Images.SeenImages(false),
+			PruneReport:  utils2.BuildPruneReport(au, pruneOrphans),
+			PruneSkipped: pruneSkipped,
 		}
 
-		err = diffResultCb(diffResult)
+		err = cmd.DiffResultCallback(diffResult)
 		if err != nil {
 			dew.AddError(k8s2.ObjectRef{}, err)
 			return r
@@ -91,7 +245,24 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 
 	// modify options to become a deploy
 	o.DryRun = cmd.targetCtx.SharedContext.K.DryRun
+	o.SkipMessages = false
 	o.AbortOnError = cmd.AbortOnError
+	o.AbortAfterErrors = cmd.AbortAfterErrors
+	o.EventCallback = cmd.EventCallback
+	o.WaitRollout = cmd.WaitRollout
+	o.WaitRolloutTimeout = cmd.WaitRolloutTimeout
+	o.Metrics = cmd.Metrics
+	o.ResumeAppliedObjects = resumeAppliedObjects
+	// hooks marked via kluctl.io/hook-dry-run are only executed for real by the deployment itself, never by the diff
+	o.RunDryRunHooks = true
+
+	if cmd.CanaryPercent > 0 {
+		if !cmd.runCanary(dew, ru, o) {
+			return r
+		}
+	}
+
+	o.ConfirmDeploymentItem = cmd.ConfirmDeploymentItem
 
 	au := utils2.NewApplyDeploymentsUtil(cmd.targetCtx.SharedContext.Ctx, dew, ru, cmd.targetCtx.SharedContext.K, o)
 	au.ApplyDeployments(cmd.targetCtx.DeploymentCollection.Deployments)
@@ -102,21 +273,90 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 	var orphanObjects []k8s2.ObjectRef
 	var deleted []k8s2.ObjectRef
 
-	orphanObjects, err = FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+	orphanObjects, err = FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection, cmd.PruneInclusion)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 	}
 
 	if cmd.Prune && cmd.targetCtx.Target.Discriminator == "" {
 		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("pruning without a discriminator is not supported"))
+	} else if cmd.Prune && cmd.targetCtx.SharedContext.Ctx.Err() != nil {
+		// e.g. the deploy lock got lost, so another deployment might already be running
+		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("skipped pruning as the deployment got cancelled"))
 	} else if cmd.Prune {
-		deleted = utils2.DeleteObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, orphanObjects, dew, cmd.WaitPrune)
+		var pruneOrphans []k8s2.ObjectRef
+		pruneOrphans, r.PruneSkipped = buildPruneKindFilter(cmd.PruneAllowKinds, cmd.PruneDenyKinds).Filter(orphanObjects)
+		deleted = utils2.DeleteObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, pruneOrphans, dew, cmd.WaitPrune)
 
 		// now clean up the list of orphan objects (remove the ones that got deleted)
 		orphanObjects = filterDeletedOrphans(orphanObjects, deleted)
 	}
 
 	r.Objects = collectObjects(cmd.targetCtx.DeploymentCollection, ru, au, du, orphanObjects, deleted)
+	r.PruneReport = utils2.BuildPruneReport(au, deleted)
+	r.AppliedObjectsSummary = utils2.BuildAppliedObjectsSummary(au)
 
 	return r
 }
+
+func (cmd *deployCommand) runCanary(dew *utils2.DeploymentErrorsAndWarnings, ru *utils2.RemoteObjectUtils, o *utils2.ApplyUtilOptions) bool {
+	canaryObjects := utils2.SelectCanaryObjects(cmd.targetCtx.DeploymentCollection.Deployments, cmd.CanaryPercent)
+	if len(canaryObjects) == 0 {
+		return true
+	}
+
+	status.Infof(cmd.targetCtx.SharedContext.Ctx, "Applying %d canary objects (%d%%)", len(canaryObjects), cmd.CanaryPercent)
+
+	co := *o
+	co.CanaryObjects = canaryObjects
+	// messages are printed by the main pass, which walks all deployment items again
+	co.SkipMessages = true
+
+	// the canary has its own errors and warnings, so that the canary result only shows what the canary caused and
+	// the main deployment is not polluted with (or aborted due to) errors that were already reported by the canary
+	canaryDew := utils2.NewDeploymentErrorsAndWarnings()
+
+	au := utils2.NewApplyDeploymentsUtil(cmd.targetCtx.SharedContext.Ctx, canaryDew, ru, cmd.targetCtx.SharedContext.K, &co)
+	au.ApplyDeployments(cmd.targetCtx.DeploymentCollection.Deployments)
+
+	// pre-deploy hooks that were run for the canary must not run again
+	o.SkipPreDeployHooks = au.GetPreDeployHooksDone()
+
+	canaryResult := &result.CommandResult{
+		Objects:  collectObjects(nil, nil, au, nil, nil, nil),
+		Errors:   canaryDew.GetErrorsList(),
+		Warnings: canaryDew.GetDeduplicatedWarningsList(),
+	}
+
+	if cmd.CanaryResultCallback == nil {
+		// auto-proceed, but only if the canary did not fail
+		if len(canaryResult.Errors) != 0 {
+			for _, e := range canaryResult.Errors {
+				dew.AddError(e.Ref, fmt.Errorf("canary: %s", e.Message))
+			}
+			dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("canary apply failed, not proceeding with the remaining objects"))
+			return false
+		}
+		return true
+	}
+
+	err := cmd.CanaryResultCallback(canaryResult)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return false
+	}
+	return true
+}
+
+func checkResumeFromResult(targetCtx *target_context.TargetContext, cr *result.CommandResult) error {
+	if cr.Command.Command != "deploy" {
+		return fmt.Errorf("can only resume from the result of a deploy command, but result %s is from a '%s' command", cr.Id, cr.Command.Command)
+	}
+	if cr.Command.DryRun {
+		return fmt.Errorf("can not resume from result %s, as it was performed in dry-run mode", cr.Id)
+	}
+	if cr.TargetKey.TargetName != targetCtx.Target.Name || cr.TargetKey.Discriminator != targetCtx.Target.Discriminator {
+		return fmt.Errorf("can not resume from result %s, as it belongs to a different target", cr.Id)
+	}
+	return nil
+}
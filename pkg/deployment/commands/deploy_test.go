@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+	"time"
+)
+
+func TestBuildApplyUtilOptions(t *testing.T) {
+	o := DeployOptions{
+		ForceApply:                 true,
+		ReplaceOnError:             true,
+		AbortOnError:               true,
+		ReadinessTimeout:           time.Minute,
+		ApplyParallelism:           3,
+		BarrierTimeout:             time.Second,
+		ApplyLabelSelector:         "a=b",
+		ForceReplaceOnErrorKinds:   []string{"Job.batch"},
+		ReplacePreserveMetadata:    []string{"example.com/*"},
+		WarningsAsErrors:           true,
+		WarningsAsErrorsPatterns:   []string{"deprecated"},
+		FailOnApiDeprecation:       true,
+		FailOnApiDeprecationGroups: []string{"policy"},
+	}
+
+	au, err := o.buildApplyUtilOptions()
+	assert.NoError(t, err)
+
+	// the options are used for the diff, so they must never apply anything or abort
+	assert.True(t, au.DryRun)
+	assert.False(t, au.AbortOnError)
+	assert.True(t, au.SkipMessages)
+
+	assert.True(t, au.ForceApply)
+	assert.True(t, au.ReplaceOnError)
+	assert.Equal(t, time.Minute, au.ReadinessTimeout)
+	assert.Equal(t, 3, au.Parallelism)
+	assert.Equal(t, time.Second, au.BarrierTimeout)
+	assert.Equal(t, "a=b", au.ApplyLabelSelector.String())
+	assert.Equal(t, []schema.GroupKind{{Group: "batch", Kind: "Job"}}, au.ForceReplaceOnErrorKinds)
+	if assert.Len(t, au.ReplacePreserveMetadata, 1) {
+		assert.True(t, au.ReplacePreserveMetadata[0].Match("example.com/x"))
+	}
+	assert.True(t, au.WarningsAsErrors)
+	if assert.Len(t, au.WarningsAsErrorsPatterns, 1) {
+		assert.Equal(t, "deprecated", au.WarningsAsErrorsPatterns[0].String())
+	}
+	assert.True(t, au.FailOnApiDeprecation)
+	assert.Equal(t, []string{"policy"}, au.FailOnApiDeprecationGroups)
+
+	au, err = (&DeployOptions{}).buildApplyUtilOptions()
+	assert.NoError(t, err)
+	assert.Nil(t, au.ApplyLabelSelector)
+
+	_, err = (&DeployOptions{ApplyLabelSelector: "a in"}).buildApplyUtilOptions()
+	assert.ErrorContains(t, err, "invalid apply label selector 'a in'")
+
+	_, err = (&DeployOptions{WarningsAsErrorsPatterns: []string{"("}}).buildApplyUtilOptions()
+	assert.ErrorContains(t, err, "invalid regular expression '('")
+
+	_, err = (&DeployOptions{ReplacePreserveMetadata: []string{"["}}).buildApplyUtilOptions()
+	assert.ErrorContains(t, err, "invalid glob pattern '['")
+}
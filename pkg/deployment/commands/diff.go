@@ -20,6 +20,20 @@ type DiffCommand struct {
 	IgnoreAnnotations    bool
 	IgnoreKluctlMetadata bool
 
+	FailOnApiDeprecation       bool
+	FailOnApiDeprecationGroups []string
+
+	// WarningsAsErrors causes warnings returned by the cluster to be treated as errors. WarningsAsErrorsPatterns
+	// optionally restricts this to warnings matching at least one of the given regular expressions.
+	WarningsAsErrors         bool
+	WarningsAsErrorsPatterns []string
+
+	// ForceReplaceOnErrorKinds restricts ForceReplaceOnError to the given kinds, in the format 'Kind.group'
+	ForceReplaceOnErrorKinds []string
+	// ReplacePreserveMetadata is a list of glob patterns for annotation and label keys to carry over from the remote
+	// object when replacing objects
+	ReplacePreserveMetadata []string
+
 	SkipResourceVersions map[k8s2.ObjectRef]string
 }
 
@@ -53,14 +67,34 @@ func (cmd *DiffCommand) Run() *result.CommandResult {
 		return r
 	}
 
+	replacePreserveMetadata, err := parseGlobs(cmd.ReplacePreserveMetadata)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+	warningsAsErrorsPatterns, err := parseRegexps(cmd.WarningsAsErrorsPatterns)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+
 	o := &utils.ApplyUtilOptions{
 		ForceApply:           cmd.ForceApply,
 		ReplaceOnError:       cmd.ReplaceOnError,
 		ForceReplaceOnError:  cmd.ForceReplaceOnError,
 		DryRun:               true,
+		SkipMessages:         true,
 		AbortOnError:         false,
 		ReadinessTimeout:     0,
 		SkipResourceVersions: cmd.SkipResourceVersions,
+
+		ForceReplaceOnErrorKinds: parseGroupKinds(cmd.ForceReplaceOnErrorKinds),
+		ReplacePreserveMetadata:  replacePreserveMetadata,
+
+		WarningsAsErrors:           cmd.WarningsAsErrors,
+		WarningsAsErrorsPatterns:   warningsAsErrorsPatterns,
+		FailOnApiDeprecation:       cmd.FailOnApiDeprecation,
+		FailOnApiDeprecationGroups: cmd.FailOnApiDeprecationGroups,
 	}
 	au := utils.NewApplyDeploymentsUtil(cmd.targetCtx.SharedContext.Ctx, dew, ru, cmd.targetCtx.SharedContext.K, o)
 	au.ApplyDeployments(cmd.targetCtx.DeploymentCollection.Deployments)
@@ -72,12 +106,13 @@ func (cmd *DiffCommand) Run() *result.CommandResult {
 	du.IgnoreKluctlMetadata = cmd.IgnoreKluctlMetadata
 	du.DiffDeploymentItems(cmd.targetCtx.DeploymentCollection.Deployments)
 
-	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection, nil)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 		return r
 	}
 	r.Objects = collectObjects(cmd.targetCtx.DeploymentCollection, ru, au, du, orphanObjects, nil)
+	r.PruneReport = utils.BuildPruneReport(au, orphanObjects)
 
 	return r
 }
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"github.com/kluctl/kluctl/lib/status"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/sbom"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// pinImageDigests resolves the digests of all container images used in the rendered objects and rewrites the images
+// to reference these digests. Resolution failures are reported as warnings if allowFailures is true, in which case
+// the affected images are left untouched. The returned bool is false if at least one resolution failed fatally.
+func pinImageDigests(targetCtx *target_context.TargetContext, dew *utils2.DeploymentErrorsAndWarnings, allowFailures bool) ([]result.PinnedImage, bool) {
+	ctx := targetCtx.SharedContext.Ctx
+
+	var objects []*uo.UnstructuredObject
+	for _, d := range targetCtx.DeploymentCollection.Deployments {
+		objects = append(objects, d.Objects...)
+	}
+
+	images := sbom.CollectImages(objects)
+	if len(images) == 0 {
+		return nil, true
+	}
+
+	s := status.Startf(ctx, "Resolving digests of %d images", len(images))
+
+	ok := true
+	digests := map[string]string{}
+	var pinned []result.PinnedImage
+	for _, image := range images {
+		digest, err := sbom.ResolveDigest(ctx, image.Image, targetCtx.SharedContext.OciAuthProvider)
+		if err != nil {
+			for _, ref := range image.Objects {
+				if allowFailures {
+					dew.AddWarning(ref, err)
+				} else {
+					dew.AddError(ref, err)
+				}
+			}
+			if !allowFailures {
+				ok = false
+			}
+			continue
+		}
+		digests[image.Image] = digest
+		pinned = append(pinned, result.PinnedImage{
+			Image:  image.Image,
+			Digest: digest,
+		})
+	}
+
+	err := sbom.PinDigests(objects, digests)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		ok = false
+	}
+
+	if ok {
+		s.Success()
+	} else {
+		s.Failed()
+	}
+	return pinned, ok
+}
@@ -100,7 +100,7 @@ func (cmd *PokeImagesCommand) Run() *result.CommandResult {
 	du := utils2.NewDiffUtil(dew, ru, au.GetAppliedObjectsMap())
 	du.DiffDeploymentItems(cmd.targetCtx.DeploymentCollection.Deployments)
 
-	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection, nil)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 		return r
@@ -8,12 +8,18 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
 )
 
 type PruneCommand struct {
 	discriminator string
 	targetCtx     *target_context.TargetContext
 	wait          bool
+
+	PruneInclusion *utils.Inclusion
+	// PruneAllowKinds and PruneDenyKinds restrict pruning to/exclude the given kinds, in the format 'Kind.group'
+	PruneAllowKinds []string
+	PruneDenyKinds  []string
 }
 
 func NewPruneCommand(discriminator string, targetCtx *target_context.TargetContext, wait bool) *PruneCommand {
@@ -49,28 +55,36 @@ func (cmd *PruneCommand) Run(confirmCb func(refs []k8s2.ObjectRef) error) *resul
 		return r
 	}
 
-	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection, cmd.PruneInclusion)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 		return r
 	}
 
+	var pruneOrphans []k8s2.ObjectRef
+	pruneOrphans, r.PruneSkipped = buildPruneKindFilter(cmd.PruneAllowKinds, cmd.PruneDenyKinds).Filter(orphanObjects)
+
 	if confirmCb != nil {
-		err = confirmCb(orphanObjects)
+		err = confirmCb(pruneOrphans)
 		if err != nil {
 			dew.AddError(k8s2.ObjectRef{}, err)
 			return r
 		}
 	}
 
-	deleted := utils2.DeleteObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, orphanObjects, dew, cmd.wait)
+	deleted := utils2.DeleteObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, pruneOrphans, dew, cmd.wait)
 	orphanObjects = filterDeletedOrphans(orphanObjects, deleted)
 
 	r.Objects = collectObjects(cmd.targetCtx.DeploymentCollection, ru, nil, nil, orphanObjects, deleted)
+	r.PruneReport = utils2.BuildPruneReport(nil, deleted)
 
 	return r
 }
 
-func FindOrphanObjects(k *k8s.K8sCluster, ru *utils2.RemoteObjectUtils, c *deployment.DeploymentCollection) ([]k8s2.ObjectRef, error) {
-	return utils2.FindObjectsForDelete(k, ru.GetFilteredRemoteObjects(c.Inclusion), c.Inclusion.HasType("tags"), c.LocalObjectRefs())
+// FindOrphanObjects finds all remote objects that are not part of the deployment collection anymore. Only remote objects
+// that match the inclusion rules of the deployment collection are considered, as objects from excluded deployment items
+// are not rendered and would otherwise be treated as orphans. If pruneInclusion is non-nil, the result is further
+// limited to objects matching it.
+func FindOrphanObjects(k *k8s.K8sCluster, ru *utils2.RemoteObjectUtils, c *deployment.DeploymentCollection, pruneInclusion *utils.Inclusion) ([]k8s2.ObjectRef, error) {
+	return utils2.FindObjectsForDelete(k, ru.GetFilteredRemoteObjects(), []*utils.Inclusion{c.Inclusion, pruneInclusion}, c.LocalObjectRefs())
 }
@@ -98,7 +98,7 @@ func newDeleteCommandResult(k *k8s2.K8sCluster, startTime time.Time, inclusion *
 
 func finishCommandResult(r *result.CommandResult, targetCtx *target_context.TargetContext, dew *utils2.DeploymentErrorsAndWarnings) {
 	r.Errors = append(r.Errors, dew.GetErrorsList()...)
-	r.Warnings = append(r.Warnings, dew.GetWarningsList()...)
+	r.Warnings = append(r.Warnings, dew.GetDeduplicatedWarningsList()...)
 	if targetCtx != nil {
 		r.SeenImages = targetCtx.DeploymentCollection.Images.SeenImages(false)
 	}
@@ -107,7 +107,7 @@ func finishCommandResult(r *result.CommandResult, targetCtx *target_context.Targ
 
 func finishValidateResult(r *result.ValidateResult, targetCtx *target_context.TargetContext, dew *utils2.DeploymentErrorsAndWarnings) {
 	r.Errors = append(r.Errors, dew.GetErrorsList()...)
-	r.Warnings = append(r.Warnings, dew.GetWarningsList()...)
+	r.Warnings = append(r.Warnings, dew.GetDeduplicatedWarningsList()...)
 	r.EndTime = metav1.Now()
 }
 
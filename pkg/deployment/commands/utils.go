@@ -1,10 +1,14 @@
 package commands
 
 import (
+	"fmt"
+	"github.com/gobwas/glob"
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
 	"github.com/kluctl/kluctl/v2/pkg/deployment/utils"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"regexp"
 	"sort"
 )
 
@@ -141,3 +145,44 @@ func filterDeletedOrphans(orphans []k8s.ObjectRef, deleted []k8s.ObjectRef) []k8
 	}
 	return tmp
 }
+
+// parseGroupKinds parses a list of kinds in the format 'Kind.group', e.g. 'Job.batch'. Kinds without a group refer to
+// the core API group.
+func parseGlobs(l []string) ([]glob.Glob, error) {
+	var ret []glob.Glob
+	for _, x := range l {
+		g, err := glob.Compile(x)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern '%s': %w", x, err)
+		}
+		ret = append(ret, g)
+	}
+	return ret, nil
+}
+
+func parseRegexps(l []string) ([]*regexp.Regexp, error) {
+	var ret []*regexp.Regexp
+	for _, x := range l {
+		r, err := regexp.Compile(x)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression '%s': %w", x, err)
+		}
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
+
+func parseGroupKinds(l []string) []schema.GroupKind {
+	var ret []schema.GroupKind
+	for _, x := range l {
+		ret = append(ret, schema.ParseGroupKind(x))
+	}
+	return ret
+}
+
+func buildPruneKindFilter(allow []string, deny []string) *utils.PruneKindFilter {
+	return &utils.PruneKindFilter{
+		Allow: parseGroupKinds(allow),
+		Deny:  parseGroupKinds(deny),
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/validation"
@@ -71,7 +72,10 @@ func (cmd *ValidateCommand) Run(ctx context.Context) *result.ValidateResult {
 
 			au := ad.NewApplyUtil(ctx, nil)
 			h := utils2.NewHooksUtil(au)
+			ref := o.GetK8sRef()
 			if hook := h.GetHook(d, o); hook != nil {
+				// the hook might have been applied to another namespace, see kluctl.io/hook-namespace
+				ref = hook.GetK8sRef()
 				if !hook.IsPersistent() {
 					// the hook is not expected to exist after deployment
 					continue
@@ -82,14 +86,16 @@ func (cmd *ValidateCommand) Run(ctx context.Context) *result.ValidateResult {
 				}
 			}
 
-			ref := o.GetK8sRef()
-
 			remoteObject := cmd.ru.GetRemoteObject(ref)
 			if remoteObject == nil {
 				ret.Errors = append(ret.Errors, result.DeploymentError{Ref: ref, Message: "object not found"})
 				continue
 			}
-			r := validation.ValidateObject(ctx, cmd.targetCtx.SharedContext.K, remoteObject, true, false)
+			var readinessChecks []types.ReadinessCheckConfig
+			if d.Project != nil {
+				readinessChecks = d.Project.GetReadinessChecks()
+			}
+			r := validation.ValidateObject(ctx, cmd.targetCtx.SharedContext.K, remoteObject, readinessChecks, true, false)
 			if !r.Ready {
 				ret.Ready = false
 			}
@@ -102,6 +108,37 @@ func (cmd *ValidateCommand) Run(ctx context.Context) *result.ValidateResult {
 	return ret
 }
 
+// RunRendered validates the rendered objects locally, without retrieving them from the target cluster. This allows
+// to validate a deployment in offline mode or without having permissions to read the deployed objects.
+func (cmd *ValidateCommand) RunRendered(ctx context.Context) *result.ValidateResult {
+	startTime := cmd.targetCtx.KluctlProject.LoadTime
+
+	cmd.dew.Init()
+
+	ret := newValidateCommandResult(cmd.targetCtx, startTime)
+	ret.Ready = true
+
+	defer func() {
+		finishValidateResult(ret, cmd.targetCtx, cmd.dew)
+	}()
+
+	for _, d := range cmd.targetCtx.DeploymentCollection.Deployments {
+		for _, o := range d.Objects {
+			if o.GetK8sAnnotationBoolNoError("kluctl.io/delete", false) {
+				continue
+			}
+			r := validation.ValidateRenderedObject(ctx, cmd.targetCtx.SharedContext.K, o)
+			if !r.Ready {
+				ret.Ready = false
+			}
+			ret.Errors = append(ret.Errors, r.Errors...)
+			ret.Warnings = append(ret.Warnings, r.Warnings...)
+		}
+	}
+
+	return ret
+}
+
 func (cmd *ValidateCommand) ForgetRemoteObject(ref k8s2.ObjectRef) {
 	cmd.ru.ForgetRemoteObject(ref)
 }
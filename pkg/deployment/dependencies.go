@@ -0,0 +1,175 @@
+package deployment
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// resolveDependsOn resolves the dependsOn entries of all deployment items of the given project. itemsByIndex maps
+// the indexes of the project's deployments list to the deployment items collected for it. Included projects might
+// result in multiple items, in which case depending on the include means depending on all of these items.
+func resolveDependsOn(project *DeploymentProject, itemsByIndex map[int][]*DeploymentItem) error {
+	findIndexes := func(name string) []int {
+		name = filepath.Clean(name)
+		var ret []int
+		for i, di := range project.Config.Deployments {
+			if di.Path != nil && filepath.Clean(*di.Path) == name {
+				ret = append(ret, i)
+			} else if di.Include != nil && filepath.Clean(*di.Include) == name {
+				ret = append(ret, i)
+			}
+		}
+		return ret
+	}
+
+	for i, di := range project.Config.Deployments {
+		for _, dep := range di.DependsOn {
+			indexes := findIndexes(dep)
+			if len(indexes) == 0 {
+				return fmt.Errorf("%s depends on '%s', which is not a path or include of the same deployment project", project.describeDeploymentItem(i), dep)
+			}
+			for _, j := range indexes {
+				if j == i {
+					return fmt.Errorf("%s can not depend on itself", project.describeDeploymentItem(i))
+				}
+				// items skipped due to 'when' are not in itemsByIndex, meaning that the dependency is ignored
+				for _, d := range itemsByIndex[i] {
+					d.DependsOn = append(d.DependsOn, itemsByIndex[j]...)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// sortByDependencies reorders the given deployment items so that all dependencies (see DependsOn) are deployed before
+// their dependents. Reordering only happens between barriers, which means that dependencies must not be placed behind
+// a barrier that comes after the dependent item. Cycles are detected and reported as errors.
+func sortByDependencies(deployments []*DeploymentItem) ([]*DeploymentItem, error) {
+	hasDeps := false
+	for _, d := range deployments {
+		if len(d.DependsOn) != 0 {
+			hasDeps = true
+			break
+		}
+	}
+	if !hasDeps {
+		return deployments, nil
+	}
+
+	isBarrier := func(d *DeploymentItem) bool {
+		return d.Config.Barrier || d.Barrier
+	}
+
+	segmentOf := map[*DeploymentItem]int{}
+	segment := 0
+	for _, d := range deployments {
+		segmentOf[d] = segment
+		if isBarrier(d) {
+			segment++
+		}
+	}
+
+	err := checkDependencyCycles(deployments)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range deployments {
+		for _, dep := range d.DependsOn {
+			// barriers always stay at the end of their segment, so items of the same segment can't depend on them
+			if segmentOf[dep] > segmentOf[d] || (segmentOf[dep] == segmentOf[d] && isBarrier(dep)) {
+				return nil, fmt.Errorf("%s depends on %s, which is either a barrier or placed behind a barrier", d.describe(), dep.describe())
+			}
+		}
+	}
+
+	// stable topological sort inside each segment
+	ret := make([]*DeploymentItem, 0, len(deployments))
+	added := map[*DeploymentItem]bool{}
+	var add func(d *DeploymentItem)
+	add = func(d *DeploymentItem) {
+		if added[d] {
+			return
+		}
+		added[d] = true
+		for _, dep := range d.DependsOn {
+			if segmentOf[dep] == segmentOf[d] {
+				add(dep)
+			}
+		}
+		ret = append(ret, d)
+	}
+	start := 0
+	for i, d := range deployments {
+		if isBarrier(d) {
+			for _, d2 := range deployments[start:i] {
+				add(d2)
+			}
+			add(d)
+			start = i + 1
+		}
+	}
+	for _, d := range deployments[start:] {
+		add(d)
+	}
+	return ret, nil
+}
+
+func (di *DeploymentItem) describe() string {
+	if di.RelToProjectItemDir != "" {
+		return di.RelToProjectItemDir
+	}
+	return "<unnamed>"
+}
+
+func checkDependencyCycles(deployments []*DeploymentItem) error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[*DeploymentItem]int{}
+	var stack []*DeploymentItem
+
+	var visit func(d *DeploymentItem) error
+	visit = func(d *DeploymentItem) error {
+		switch state[d] {
+		case visited:
+			return nil
+		case visiting:
+			var names []string
+			inCycle := false
+			for _, x := range stack {
+				if x == d {
+					inCycle = true
+				}
+				if inCycle {
+					names = append(names, x.describe())
+				}
+			}
+			names = append(names, d.describe())
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(names, " -> "))
+		}
+
+		state[d] = visiting
+		stack = append(stack, d)
+		for _, dep := range d.DependsOn {
+			err := visit(dep)
+			if err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[d] = visited
+		return nil
+	}
+
+	for _, d := range deployments {
+		err := visit(d)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package deployment
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newTestItem(name string, barrier bool) *DeploymentItem {
+	return &DeploymentItem{
+		Config:              &types.DeploymentItemConfig{Barrier: barrier},
+		RelToProjectItemDir: name,
+	}
+}
+
+func itemNames(items []*DeploymentItem) []string {
+	var ret []string
+	for _, d := range items {
+		ret = append(ret, d.describe())
+	}
+	return ret
+}
+
+func TestSortByDependencies(t *testing.T) {
+	a := newTestItem("a", false)
+	b := newTestItem("b", false)
+	c := newTestItem("c", false)
+	barrier := newTestItem("barrier", true)
+	d := newTestItem("d", false)
+	e := newTestItem("e", false)
+
+	// no dependencies keeps the order
+	items := []*DeploymentItem{a, b, c, barrier, d, e}
+	sorted, err := sortByDependencies(items)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "barrier", "d", "e"}, itemNames(sorted))
+
+	// a depends on c, d depends on e and b (which is placed before the barrier)
+	a.DependsOn = []*DeploymentItem{c}
+	d.DependsOn = []*DeploymentItem{e, b}
+	sorted, err = sortByDependencies(items)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "a", "b", "barrier", "e", "d"}, itemNames(sorted))
+}
+
+func TestSortByDependenciesBehindBarrier(t *testing.T) {
+	a := newTestItem("a", false)
+	barrier := newTestItem("barrier", true)
+	b := newTestItem("b", false)
+
+	a.DependsOn = []*DeploymentItem{b}
+	_, err := sortByDependencies([]*DeploymentItem{a, barrier, b})
+	assert.ErrorContains(t, err, "a depends on b, which is either a barrier or placed behind a barrier")
+
+	a.DependsOn = []*DeploymentItem{barrier}
+	_, err = sortByDependencies([]*DeploymentItem{a, barrier, b})
+	assert.ErrorContains(t, err, "a depends on barrier, which is either a barrier or placed behind a barrier")
+
+	a.DependsOn = nil
+	b.DependsOn = []*DeploymentItem{barrier}
+	_, err = sortByDependencies([]*DeploymentItem{a, barrier, b})
+	assert.NoError(t, err)
+}
+
+func TestSortByDependenciesCycle(t *testing.T) {
+	a := newTestItem("a", false)
+	b := newTestItem("b", false)
+	c := newTestItem("c", false)
+
+	a.DependsOn = []*DeploymentItem{b}
+	b.DependsOn = []*DeploymentItem{c}
+	c.DependsOn = []*DeploymentItem{a}
+	_, err := sortByDependencies([]*DeploymentItem{a, b, c})
+	assert.EqualError(t, err, "dependency cycle detected: a -> b -> c -> a")
+}
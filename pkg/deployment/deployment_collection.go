@@ -42,6 +42,10 @@ func NewDeploymentCollection(ctx SharedContext, project *DeploymentProject, imag
 	if err != nil {
 		return nil, err
 	}
+	deployments, err = sortByDependencies(deployments)
+	if err != nil {
+		return nil, err
+	}
 	dc.Deployments = make([]*DeploymentItem, 0, len(deployments))
 	for _, d := range deployments {
 		if d.CheckInclusionForDeploy() {
@@ -86,6 +90,7 @@ func findDeploymentItemIndex(project *DeploymentProject, pth *string, indexes ma
 
 func (c *DeploymentCollection) collectAllDeployments(project *DeploymentProject, indexes map[string]int) ([]*DeploymentItem, error) {
 	var ret []*DeploymentItem
+	itemsByIndex := map[int][]*DeploymentItem{}
 
 	if x, err := project.CheckWhenTrue(); !x || err != nil {
 		return nil, err
@@ -94,11 +99,12 @@ func (c *DeploymentCollection) collectAllDeployments(project *DeploymentProject,
 	for i, _ := range project.Config.Deployments {
 		diConfig := &project.Config.Deployments[i]
 
-		whenTrue, err := project.VarsCtx.CheckConditional(diConfig.When)
+		whenTrue, err := project.checkDeploymentItemWhen(i)
 		if err != nil {
 			return nil, err
 		}
 		if !whenTrue {
+			status.Infof(c.ctx.Ctx, "Skipping %s as its 'when' condition is false", project.describeDeploymentItem(i))
 			continue
 		}
 
@@ -112,6 +118,7 @@ func (c *DeploymentCollection) collectAllDeployments(project *DeploymentProject,
 				return nil, err
 			}
 			ret = append(ret, ret2...)
+			itemsByIndex[i] = ret2
 			if diConfig.Barrier {
 				ret = append(ret, c.createBarrierDummy(project))
 			}
@@ -122,9 +129,15 @@ func (c *DeploymentCollection) collectAllDeployments(project *DeploymentProject,
 				return nil, err
 			}
 			ret = append(ret, di)
+			itemsByIndex[i] = []*DeploymentItem{di}
 		}
 	}
 
+	err := resolveDependsOn(project, itemsByIndex)
+	if err != nil {
+		return nil, err
+	}
+
 	return ret, nil
 }
 
@@ -244,7 +257,7 @@ func (c *DeploymentCollection) writeRenderedYamls() error {
 }
 
 func (c *DeploymentCollection) fixNamespaces() error {
-	if c.ctx.K == nil {
+	if c.ctx.K == nil && c.ctx.OfflineApiResources == nil {
 		return nil
 	}
 	namespacedFromCRDs := c.buildNamespacedFromCRDs()
@@ -259,7 +272,16 @@ func (c *DeploymentCollection) fixNamespaces() error {
 
 			namespaced := namespacedFromCRDs[o.GetK8sRef().GroupKind()]
 			if namespaced == nil {
-				namespaced = c.ctx.K.IsNamespaced(o.GetK8sRef().GroupVersionKind())
+				if c.ctx.K != nil {
+					namespaced = c.ctx.K.IsNamespaced(o.GetK8sRef().GroupVersionKind())
+				} else {
+					gk := o.GetK8sRef().GroupKind()
+					namespaced = c.ctx.OfflineApiResources.IsNamespaced(gk)
+					if namespaced == nil {
+						// best-effort, keep the object as it is
+						status.WarningOncef(c.ctx.Ctx, "offline-api-resources-"+gk.String(), "%s is not known in the offline API resources, its namespace can't be resolved", gk.String())
+					}
+				}
 			}
 
 			if namespaced != nil {
@@ -322,8 +344,14 @@ func (c *DeploymentCollection) LocalObjectsByRef() map[k8s2.ObjectRef]*uo.Unstru
 
 func (c *DeploymentCollection) LocalObjectRefs() []k8s2.ObjectRef {
 	var ret []k8s2.ObjectRef
-	for ref := range c.LocalObjectsByRef() {
+	for ref, o := range c.LocalObjectsByRef() {
 		ret = append(ret, ref)
+
+		// hooks might get applied to another namespace
+		if ns := o.GetK8sAnnotation("kluctl.io/hook-namespace"); ns != nil && *ns != "" && ref.Namespace != "" {
+			ref.Namespace = *ns
+			ret = append(ret, ref)
+		}
 	}
 	return ret
 }
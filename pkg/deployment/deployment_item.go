@@ -37,6 +37,9 @@ type DeploymentItem struct {
 	Objects []*uo.UnstructuredObject
 	Tags    *utils.OrderedMap[string, bool]
 
+	// DependsOn contains the resolved dependencies as configured via dependsOn
+	DependsOn []*DeploymentItem
+
 	RenderedSourceRootDir string
 	RelToSourceItemDir    string
 	RelToProjectItemDir   string
@@ -235,7 +238,12 @@ func (di *DeploymentItem) renderHelmCharts() error {
 
 		di.Config.RenderedHelmChartConfig = hr.Config
 
-		return hr.Render(di.ctx.Ctx, di.ctx.K, di.ctx.K8sVersion, di.ctx.SopsDecrypter)
+		err = hr.Render(di.ctx.Ctx, di.ctx.K, di.ctx.K8sVersion, di.ctx.OfflineApiResources, di.ctx.SopsDecrypter)
+		if err != nil {
+			return err
+		}
+		di.Config.RenderedHelmValues = hr.RenderedValues
+		return nil
 	})
 	if err != nil {
 		return err
@@ -255,6 +263,12 @@ func (di *DeploymentItem) buildInclusionEntries() []utils.InclusionEntry {
 	return values
 }
 
+// IsMessage returns true if the deployment item does not deploy anything and only carries a message that is printed
+// when the item is reached while applying.
+func (di *DeploymentItem) IsMessage() bool {
+	return di.Config.Message != nil && di.dir == nil && !di.Config.Barrier && len(di.Config.DeleteObjects) == 0 && len(di.Config.WaitReadinessObjects) == 0
+}
+
 func (di *DeploymentItem) CheckInclusionForDeploy() bool {
 	if di.Inclusion == nil {
 		return true
@@ -467,7 +481,7 @@ func (di *DeploymentItem) writeRenderedYaml() error {
 	}
 
 	var objects []interface{}
-	for _, o := range di.Objects {
+	for _, o := range filterRenderedObjects(&di.ctx, di.Objects) {
 		objects = append(objects, o.Object)
 	}
 
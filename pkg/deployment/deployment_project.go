@@ -73,6 +73,26 @@ func (p *DeploymentProject) loadVarsList(varsCtx *vars.VarsCtx, varsList []types
 	return p.ctx.VarsLoader.LoadVarsList(p.ctx.Ctx, varsCtx, varsList, p.getRenderSearchDirs(), "")
 }
 
+func (p *DeploymentProject) validateVarsSchema() error {
+	if p.Config.VarsSchema == nil {
+		return nil
+	}
+
+	schema := p.Config.VarsSchema.Schema
+	if p.Config.VarsSchema.File != nil {
+		schemaPath, err := securejoin.SecureJoin(p.source.dir, filepath.Join(p.relDir, *p.Config.VarsSchema.File))
+		if err != nil {
+			return err
+		}
+		schema, err = uo.FromFile(schemaPath)
+		if err != nil {
+			return fmt.Errorf("failed to load vars schema: %w", err)
+		}
+	}
+
+	return vars.ValidateVarsSchema(p.VarsCtx.Vars, schema)
+}
+
 func (p *DeploymentProject) loadConfig() error {
 	configPath := filepath.Join(p.absDir, "deployment.yml")
 	if !yaml.Exists(configPath) {
@@ -114,6 +134,9 @@ func (p *DeploymentProject) processConfig() error {
 		if item.RenderedInclude != nil {
 			return fmt.Errorf("renderedInclude is not allowed here")
 		}
+		if item.RenderedHelmValues != nil {
+			return fmt.Errorf("renderedHelmValues is not allowed here")
+		}
 	}
 
 	err := p.loadVarsList(p.VarsCtx, p.Config.Vars)
@@ -121,6 +144,11 @@ func (p *DeploymentProject) processConfig() error {
 		return fmt.Errorf("failed to load deployment.yml vars: %w", err)
 	}
 
+	err = p.validateVarsSchema()
+	if err != nil {
+		return err
+	}
+
 	// If there are no explicit tags set, interpret the path as a tag, which allows to
 	// enable/disable single deployments via included/excluded tags
 	for i, _ := range p.Config.Deployments {
@@ -180,13 +208,42 @@ func (p *DeploymentProject) CheckWhenTrue() (bool, error) {
 	return p.VarsCtx.CheckConditional(p.Config.When)
 }
 
+// describeDeploymentItem returns a human-readable description of the deployment item with the given index, suitable
+// for error and log messages
+func (p *DeploymentProject) describeDeploymentItem(i int) string {
+	di := &p.Config.Deployments[i]
+	var s string
+	switch {
+	case di.Path != nil:
+		s = fmt.Sprintf("path %s", *di.Path)
+	case di.Include != nil:
+		s = fmt.Sprintf("include %s", *di.Include)
+	case di.Git != nil:
+		s = fmt.Sprintf("git include %s", di.Git.Url.String())
+	case di.Oci != nil:
+		s = fmt.Sprintf("oci include %s", di.Oci.Url)
+	default:
+		s = "item"
+	}
+	return fmt.Sprintf("deployments[%d] (%s) of %s", i, s, filepath.Join(p.relDir, "deployment.yml"))
+}
+
+// checkDeploymentItemWhen evaluates the 'when' condition of the deployment item with the given index
+func (p *DeploymentProject) checkDeploymentItemWhen(i int) (bool, error) {
+	whenTrue, err := p.VarsCtx.CheckConditional(p.Config.Deployments[i].When)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate 'when' of %s: %w", p.describeDeploymentItem(i), err)
+	}
+	return whenTrue, nil
+}
+
 func (p *DeploymentProject) loadIncludes() error {
 	for i, _ := range p.Config.Deployments {
 		inc := &p.Config.Deployments[i]
 		var err error
 		var newProject *DeploymentProject
 
-		whenTrue, err := p.VarsCtx.CheckConditional(inc.When)
+		whenTrue, err := p.checkDeploymentItemWhen(i)
 		if err != nil {
 			return err
 		}
@@ -402,3 +459,19 @@ func (p *DeploymentProject) GetConflictResolutionConfigs() []types.ConflictResol
 	}
 	return ret
 }
+
+func (p *DeploymentProject) GetApplyOrderConfigs() []types.ApplyOrderItemConfig {
+	var ret []types.ApplyOrderItemConfig
+	for _, e := range p.getParents() {
+		ret = append(ret, e.p.Config.ApplyOrder...)
+	}
+	return ret
+}
+
+func (p *DeploymentProject) GetReadinessChecks() []types.ReadinessCheckConfig {
+	var ret []types.ReadinessCheckConfig
+	for _, e := range p.getParents() {
+		ret = append(ret, e.p.Config.ReadinessChecks...)
+	}
+	return ret
+}
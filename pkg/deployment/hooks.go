@@ -0,0 +1,74 @@
+package deployment
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"strings"
+)
+
+// helmHooksAsKluctlHooks lists all helm hooks that are treated as kluctl hooks (see HooksUtil.GetHook)
+var helmHooksAsKluctlHooks = map[string]bool{
+	"pre-install": true, "post-install": true,
+	"pre-delete": true, "post-delete": true,
+	"pre-upgrade": true, "post-upgrade": true,
+	"pre-rollback": true, "post-rollback": true,
+}
+
+// IsHookObject returns true if the object is treated as a hook when deploying. It must match the logic found in
+// HooksUtil.GetHook, which can't be used here as it requires a fully initialized deployment.
+func IsHookObject(o *uo.UnstructuredObject) bool {
+	if o.GetK8sAnnotationBoolNoError("kluctl.io/delete", false) {
+		return false
+	}
+
+	splitAnnotation := func(name string) []string {
+		a := o.GetK8sAnnotation(name)
+		if a == nil {
+			return nil
+		}
+		var ret []string
+		for _, x := range strings.Split(*a, ",") {
+			x = strings.TrimSpace(x)
+			if x != "" {
+				ret = append(ret, x)
+			}
+		}
+		return ret
+	}
+
+	if len(splitAnnotation("kluctl.io/hook")) != 0 {
+		return true
+	}
+	for _, h := range splitAnnotation("helm.sh/hook") {
+		if helmHooksAsKluctlHooks[h] {
+			return true
+		}
+	}
+	return false
+}
+
+// filterRenderedObjects applies the RenderExcludeHooks/RenderOnlyHooks options of the shared context to the given
+// objects. It only affects what is written to the render output, the deployment itself is unaffected.
+func filterRenderedObjects(ctx *SharedContext, objects []*uo.UnstructuredObject) []*uo.UnstructuredObject {
+	if !ctx.RenderExcludeHooks && !ctx.RenderOnlyHooks {
+		return objects
+	}
+	var ret []*uo.UnstructuredObject
+	for _, o := range objects {
+		isHook := IsHookObject(o)
+		if (isHook && ctx.RenderExcludeHooks) || (!isHook && ctx.RenderOnlyHooks) {
+			continue
+		}
+		ret = append(ret, o)
+	}
+	return ret
+}
+
+// FilterRenderedObjects returns the objects of all deployment items, in deployment order, filtered by the
+// RenderExcludeHooks/RenderOnlyHooks options of the shared context
+func (c *DeploymentCollection) FilterRenderedObjects() []*uo.UnstructuredObject {
+	var ret []*uo.UnstructuredObject
+	for _, d := range c.Deployments {
+		ret = append(ret, filterRenderedObjects(&c.ctx, d.Objects)...)
+	}
+	return ret
+}
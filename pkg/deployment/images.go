@@ -19,9 +19,15 @@ import (
 )
 
 type Images struct {
-	fixedImages []types.FixedImage
-	seenImages  []types.FixedImage
-	mutex       sync.Mutex
+	fixedImages    []types.FixedImage
+	imageOverrides []imageOverride
+	seenImages     []types.FixedImage
+	mutex          sync.Mutex
+}
+
+type imageOverride struct {
+	regex       *regexp.Regexp
+	replacement string
 }
 
 func NewImages() (*Images, error) {
@@ -39,6 +45,65 @@ func (images *Images) PrependFixedImages(fis []types.FixedImage) {
 	images.fixedImages = newFixedImages
 }
 
+// AddImageOverrides adds regex based image overrides. In contrast to fixed images, these are also applied to container
+// images that are not templated via images.get_image. Fixed images always take precedence over image overrides.
+func (images *Images) AddImageOverrides(overrides []types.ImageOverride) error {
+	for _, o := range overrides {
+		r, err := regexp.Compile(o.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid image override regex '%s': %w", o.Regex, err)
+		}
+		images.imageOverrides = append(images.imageOverrides, imageOverride{
+			regex:       r,
+			replacement: o.Replacement,
+		})
+	}
+	return nil
+}
+
+// overrideImage applies the last matching image override to the given image
+func (images *Images) overrideImage(image string) (string, bool) {
+	for i := len(images.imageOverrides) - 1; i >= 0; i-- {
+		o := images.imageOverrides[i]
+		if o.regex.MatchString(image) {
+			return o.regex.ReplaceAllString(image, o.replacement), true
+		}
+	}
+	return image, false
+}
+
+var containerListKeys = map[string]bool{
+	"containers":          true,
+	"initContainers":      true,
+	"ephemeralContainers": true,
+}
+
+// overrideContainerImages applies image overrides to all container images that are not templated via
+// images.get_image, which are instead handled in resolveImage
+func (images *Images) overrideContainerImages(o *uo.UnstructuredObject) error {
+	if len(images.imageOverrides) == 0 {
+		return nil
+	}
+	return uo.NewObjectIterator(o.Object).IterateLeafs(func(it *uo.ObjectIterator) error {
+		kp := it.KeyPath()
+		if len(kp) < 3 || kp[len(kp)-1] != "image" {
+			return nil
+		}
+		listKey, ok := kp[len(kp)-3].(string)
+		if !ok || !containerListKeys[listKey] {
+			return nil
+		}
+		s, ok := it.Value().(string)
+		if !ok || strings.Contains(s, beginPlaceholder) {
+			return nil
+		}
+		if newImage, ok := images.overrideImage(s); ok {
+			return it.SetValue(newImage)
+		}
+		return nil
+	})
+}
+
 func (images *Images) FixedImages() []types.FixedImage {
 	if images == nil {
 		return nil
@@ -233,6 +298,11 @@ func (images *Images) FindPlaceholders(o *uo.UnstructuredObject) ([]placeHolder,
 }
 
 func (images *Images) ResolvePlaceholders(ctx context.Context, k *k8s.K8sCluster, o *uo.UnstructuredObject, deploymentDir string, tags []string, vars *uo.UnstructuredObject) error {
+	err := images.overrideContainerImages(o)
+	if err != nil {
+		return err
+	}
+
 	placeholders, err := images.FindPlaceholders(o)
 	if err != nil {
 		return err
@@ -294,6 +364,11 @@ func (images *Images) resolveImage(ctx context.Context, ph placeHolder, ref k8s2
 	if err != nil {
 		return nil, err
 	}
+	if result == nil {
+		if newImage, ok := images.overrideImage(ph.Image); ok {
+			result = &newImage
+		}
+	}
 
 	si := types.FixedImage{
 		Image:         &ph.Image,
@@ -0,0 +1,86 @@
+package deployment
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func buildTestPlaceholder(image string) string {
+	b, _ := json.Marshal(map[string]interface{}{"image": image})
+	return beginPlaceholder + base64.StdEncoding.EncodeToString(b) + endPlaceholder
+}
+
+func buildTestDeployment(images ...string) *uo.UnstructuredObject {
+	o := uo.FromMap(map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "d1",
+			"namespace": "ns",
+		},
+	})
+	var containers []interface{}
+	for _, image := range images {
+		containers = append(containers, map[string]interface{}{
+			"name":  "c",
+			"image": image,
+		})
+	}
+	_ = o.SetNestedField(containers, "spec", "template", "spec", "containers")
+	return o
+}
+
+func getTestImages(t *testing.T, o *uo.UnstructuredObject) []string {
+	l, _, err := o.GetNestedObjectList("spec", "template", "spec", "containers")
+	assert.NoError(t, err)
+	var ret []string
+	for _, c := range l {
+		image, _, _ := c.GetNestedString("image")
+		ret = append(ret, image)
+	}
+	return ret
+}
+
+func TestImageOverrides(t *testing.T) {
+	images, err := NewImages()
+	assert.NoError(t, err)
+
+	err = images.AddImageOverrides([]types.ImageOverride{
+		{Regex: `^myregistry/(.*):.*`, Replacement: `myregistry/$1:canary`},
+		{Regex: `^other/(?P<name>.*)`, Replacement: `myregistry/${name}-other`},
+	})
+	assert.NoError(t, err)
+	images.PrependFixedImages([]types.FixedImage{
+		{Image: utils.Ptr("myregistry/fixed:1.0"), ResultImage: "myregistry/fixed:2.0"},
+	})
+
+	o := buildTestDeployment(
+		"myregistry/app:1.0",
+		"myregistry/group/app:1.0",
+		"other/app",
+		"unrelated/app:1.0",
+		buildTestPlaceholder("myregistry/templated:1.0"),
+		buildTestPlaceholder("myregistry/fixed:1.0"),
+	)
+
+	err = images.ResolvePlaceholders(context.TODO(), nil, o, "", nil, uo.New())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"myregistry/app:canary",
+		"myregistry/group/app:canary",
+		"myregistry/app-other",
+		"unrelated/app:1.0",
+		"myregistry/templated:canary",
+		// fixed images win over overrides
+		"myregistry/fixed:2.0",
+	}, getTestImages(t, o))
+
+	err = images.AddImageOverrides([]types.ImageOverride{{Regex: `(`}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package deployment
+
+import (
+	"fmt"
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
+	"path/filepath"
+	"strconv"
+)
+
+type RenderedVarsSource struct {
+	Index  int               `json:"index"`
+	Source *types.VarsSource `json:"source"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// LoadRootProjectVarsSources loads the vars sources of the root deployment project one by one and returns the
+// rendered sources together with the vars they contributed. Only the deployment.yml of the root project is loaded,
+// so that vars sources can be debugged even if loading the whole project would fail.
+// If onlySource is not empty, only the source with the given index or type name (e.g. "vault") is returned. In that
+// case, the prior sources are only loaded when withPriorSources is true. Otherwise, the selected source is loaded with
+// the initial vars only.
+func LoadRootProjectVarsSources(ctx SharedContext, varsCtx *vars.VarsCtx, source Source, relDir string, onlySource string, withPriorSources bool) ([]RenderedVarsSource, error) {
+	p := &DeploymentProject{
+		ctx:      ctx,
+		VarsCtx:  varsCtx.Copy(),
+		source:   source,
+		relDir:   relDir,
+		includes: map[int]*DeploymentProject{},
+	}
+
+	dir, err := securejoin.SecureJoin(p.source.dir, p.relDir)
+	if err != nil {
+		return nil, err
+	}
+	p.absDir = dir
+
+	configPath := filepath.Join(p.absDir, "deployment.yml")
+	if !yaml.Exists(configPath) {
+		return nil, nil
+	}
+	configPath = yaml.FixPathExt(configPath)
+
+	err = p.VarsCtx.RenderYamlFile(configPath, p.getRenderSearchDirs(), &p.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deployment.yml: %w", err)
+	}
+
+	onlyIndex, err := findVarsSourceIndex(p.Config.Vars, onlySource)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []RenderedVarsSource
+	for i := range p.Config.Vars {
+		vs := &p.Config.Vars[i]
+		if onlyIndex >= 0 && i != onlyIndex {
+			if i > onlyIndex || !withPriorSources {
+				continue
+			}
+			err = p.ctx.VarsLoader.LoadVars(p.ctx.Ctx, p.VarsCtx, vs, p.getRenderSearchDirs(), "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to load prior vars source %d: %w", i, err)
+			}
+			continue
+		}
+
+		r := RenderedVarsSource{
+			Index: i,
+		}
+		r.Source, err = p.ctx.VarsLoader.RenderVarsSource(p.VarsCtx, vs)
+		if err != nil {
+			r.Source = vs
+			r.Error = err.Error()
+			ret = append(ret, r)
+			break
+		}
+
+		err = p.ctx.VarsLoader.LoadVars(p.ctx.Ctx, p.VarsCtx, vs, p.getRenderSearchDirs(), "")
+		if err != nil {
+			r.Error = err.Error()
+			ret = append(ret, r)
+			break
+		}
+		r.Source.RenderedVars = vs.RenderedVars
+		r.Source.RenderedSensitive = vs.RenderedSensitive
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
+
+// findVarsSourceIndex returns the index of the vars source selected by s, which is either an index or the type name of
+// the vars source. Selecting by type name is only possible if exactly one vars source of that type exists. It returns
+// -1 if s is empty.
+func findVarsSourceIndex(sources []types.VarsSource, s string) (int, error) {
+	if s == "" {
+		return -1, nil
+	}
+	if idx, err := strconv.Atoi(s); err == nil {
+		if idx < 0 || idx >= len(sources) {
+			return -1, fmt.Errorf("vars source index %d is out of range, deployment.yml only contains %d vars sources", idx, len(sources))
+		}
+		return idx, nil
+	}
+
+	var found []int
+	for i := range sources {
+		if sources[i].TypeName() == s {
+			found = append(found, i)
+		}
+	}
+	if len(found) == 0 {
+		return -1, fmt.Errorf("no vars source of type %s found", s)
+	} else if len(found) != 1 {
+		return -1, fmt.Errorf("multiple vars sources of type %s found (indexes %v), please select the vars source by index", s, found)
+	}
+	return found[0], nil
+}
@@ -11,16 +11,22 @@ import (
 )
 
 type SharedContext struct {
-	Ctx              context.Context
-	K                *k8s.K8sCluster
-	K8sVersion       string
-	GitRP            *repocache.GitRepoCache
-	OciRP            *repocache.OciRepoCache
-	SopsDecrypter    *decryptor.Decryptor
-	VarsLoader       *vars.VarsLoader
-	HelmAuthProvider helm_auth.HelmAuthProvider
-	OciAuthProvider  auth_provider.OciAuthProvider
+	Ctx        context.Context
+	K          *k8s.K8sCluster
+	K8sVersion string
+	// OfflineApiResources is only set when running in offline mode and API resources were provided by the user
+	OfflineApiResources *k8s.OfflineApiResources
+	GitRP               *repocache.GitRepoCache
+	OciRP               *repocache.OciRepoCache
+	SopsDecrypter       *decryptor.Decryptor
+	VarsLoader          *vars.VarsLoader
+	HelmAuthProvider    helm_auth.HelmAuthProvider
+	OciAuthProvider     auth_provider.OciAuthProvider
 
 	Discriminator string
 	RenderDir     string
+
+	// RenderExcludeHooks and RenderOnlyHooks control which objects are written to the render output
+	RenderExcludeHooks bool
+	RenderOnlyHooks    bool
 }
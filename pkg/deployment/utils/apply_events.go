@@ -0,0 +1,51 @@
+package utils
+
+import (
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+)
+
+type ApplyEventType string
+
+const (
+	ApplyEventApplyStart  ApplyEventType = "apply-start"
+	ApplyEventApplyResult ApplyEventType = "apply-result"
+	ApplyEventHookWaiting ApplyEventType = "hook-waiting"
+	ApplyEventWarning     ApplyEventType = "warning"
+	ApplyEventError       ApplyEventType = "error"
+)
+
+// ApplyEvent is a structured event emitted while objects are applied, allowing to render live progress per object.
+type ApplyEvent struct {
+	Type ApplyEventType
+	Ref  k8s2.ObjectRef
+
+	// DeploymentItem is the name of the deployment item that the object belongs to. It might be empty, e.g. for
+	// errors that are not related to a specific deployment item.
+	DeploymentItem string
+	Hook           bool
+
+	// Message is only set for warning and error events
+	Message string
+}
+
+// ApplyEventCallback is invoked for every ApplyEvent. Calls are serialized, so the callback does not have to be safe
+// for concurrent use even though deployment items are applied in parallel. The callback should return quickly as it
+// blocks applying.
+type ApplyEventCallback func(e ApplyEvent)
+
+func (a *ApplyUtil) emitEvent(t ApplyEventType, ref k8s2.ObjectRef, hook bool, message string) {
+	if a.o.EventCallback == nil {
+		return
+	}
+
+	a.eventsMutex.Lock()
+	defer a.eventsMutex.Unlock()
+
+	a.o.EventCallback(ApplyEvent{
+		Type:           t,
+		Ref:            ref,
+		DeploymentItem: a.deploymentItemName,
+		Hook:           hook,
+		Message:        message,
+	})
+}
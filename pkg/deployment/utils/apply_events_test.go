@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestApplyEvents(t *testing.T) {
+	var events []ApplyEvent
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{
+		EventCallback: func(e ApplyEvent) {
+			events = append(events, e)
+		},
+	})
+	a := ad.NewApplyUtil(context.TODO(), nil)
+	a.deploymentItemName = "item"
+
+	cm := newTestConfigMap("cm", nil, nil)
+	ref := cm.GetK8sRef()
+
+	a.handleResult(cm, true)
+	a.handleApiWarnings(ref, []k8s.ApiWarning{{Text: "w1"}})
+	a.HandleWarning(ref, fmt.Errorf("w2"))
+	a.HandleError(ref, fmt.Errorf("e1"))
+
+	assert.Equal(t, []ApplyEvent{
+		{Type: ApplyEventApplyResult, Ref: ref, DeploymentItem: "item", Hook: true},
+		{Type: ApplyEventWarning, Ref: ref, DeploymentItem: "item", Message: "w1"},
+		{Type: ApplyEventWarning, Ref: ref, DeploymentItem: "item", Message: "w2"},
+		{Type: ApplyEventError, Ref: ref, DeploymentItem: "item", Message: "e1"},
+	}, events)
+}
+
+func TestApplyEventsConcurrent(t *testing.T) {
+	// the callback is intentionally not thread-safe, as calls are expected to be serialized
+	count := 0
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{
+		EventCallback: func(e ApplyEvent) {
+			count++
+		},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		a := ad.NewApplyUtil(context.TODO(), nil)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				a.handleResult(newTestConfigMap(fmt.Sprintf("cm-%d", j), nil, nil), false)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1000, count)
+}
+
+func TestApplyEventsNoCallback(t *testing.T) {
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{})
+	a := ad.NewApplyUtil(context.TODO(), nil)
+
+	cm := newTestConfigMap("cm", nil, nil)
+	a.handleResult(cm, false)
+	a.HandleError(cm.GetK8sRef(), fmt.Errorf("e1"))
+	assert.Len(t, dew.GetErrorsList(), 1)
+}
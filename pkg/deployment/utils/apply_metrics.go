@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"time"
+)
+
+const applyMetricsNamespace = "kluctl"
+
+const (
+	ApplyRetryReasonConflicts    = "conflicts"
+	ApplyRetryReasonReplace      = "replace"
+	ApplyRetryReasonForceReplace = "force-replace"
+)
+
+// ApplyMetrics collects Prometheus metrics about deployments. All methods are no-ops when invoked on a nil
+// *ApplyMetrics, so that no metrics are collected unless explicitly requested.
+type ApplyMetrics struct {
+	deployDuration    prometheus.Histogram
+	objectsApplied    *prometheus.CounterVec
+	applyRetries      *prometheus.CounterVec
+	conflictsResolved prometheus.Counter
+	hookWaitDuration  *prometheus.HistogramVec
+}
+
+// NewApplyMetrics creates all metrics and registers them at the given registerer
+func NewApplyMetrics(reg prometheus.Registerer) (*ApplyMetrics, error) {
+	m := &ApplyMetrics{
+		deployDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: applyMetricsNamespace,
+			Name:      "deploy_duration_seconds",
+			Help:      "How long a single deployment takes in seconds.",
+		}),
+		objectsApplied: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: applyMetricsNamespace,
+			Name:      "objects_applied_total",
+			Help:      "How many objects have been applied.",
+		}, []string{"hook"}),
+		applyRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: applyMetricsNamespace,
+			Name:      "apply_retries_total",
+			Help:      "How many applies have been retried after a failure.",
+		}, []string{"reason"}),
+		conflictsResolved: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: applyMetricsNamespace,
+			Name:      "conflicts_resolved_total",
+			Help:      "How many applies succeeded after resolving conflicts.",
+		}),
+		hookWaitDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: applyMetricsNamespace,
+			Name:      "hook_wait_duration_seconds",
+			Help:      "How long waiting for a single hook takes in seconds.",
+		}, []string{"ready"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.deployDuration, m.objectsApplied, m.applyRetries, m.conflictsResolved, m.hookWaitDuration} {
+		err := reg.Register(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *ApplyMetrics) ObserveDeployDuration(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.deployDuration.Observe(d.Seconds())
+}
+
+func (m *ApplyMetrics) objectApplied(hook bool) {
+	if m == nil {
+		return
+	}
+	m.objectsApplied.WithLabelValues(strconv.FormatBool(hook)).Inc()
+}
+
+func (m *ApplyMetrics) applyRetried(reason string) {
+	if m == nil {
+		return
+	}
+	m.applyRetries.WithLabelValues(reason).Inc()
+}
+
+func (m *ApplyMetrics) conflictResolved() {
+	if m == nil {
+		return
+	}
+	m.conflictsResolved.Inc()
+}
+
+func (m *ApplyMetrics) hookWaited(d time.Duration, ready bool) {
+	if m == nil {
+		return
+	}
+	m.hookWaitDuration.WithLabelValues(strconv.FormatBool(ready)).Observe(d.Seconds())
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestApplyMetricsNil(t *testing.T) {
+	var m *ApplyMetrics
+	m.ObserveDeployDuration(time.Second)
+	m.objectApplied(false)
+	m.applyRetried(ApplyRetryReasonConflicts)
+	m.conflictResolved()
+	m.hookWaited(time.Second, true)
+}
+
+func TestApplyMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewApplyMetrics(reg)
+	assert.NoError(t, err)
+
+	m.objectApplied(false)
+	m.objectApplied(false)
+	m.objectApplied(true)
+	m.applyRetried(ApplyRetryReasonConflicts)
+	m.applyRetried(ApplyRetryReasonReplace)
+	m.applyRetried(ApplyRetryReasonReplace)
+	m.conflictResolved()
+	m.hookWaited(time.Second, true)
+	m.ObserveDeployDuration(time.Second)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.objectsApplied.WithLabelValues("false")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.objectsApplied.WithLabelValues("true")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.applyRetries.WithLabelValues(ApplyRetryReasonConflicts)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.applyRetries.WithLabelValues(ApplyRetryReasonReplace)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.conflictsResolved))
+
+	count, err := testutil.GatherAndCount(reg, "kluctl_deploy_duration_seconds", "kluctl_hook_wait_duration_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// registering twice must fail
+	_, err = NewApplyMetrics(reg)
+	assert.Error(t, err)
+}
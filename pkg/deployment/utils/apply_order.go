@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"path"
+	"sort"
+)
+
+func matchApplyOrderItem(ref k8s2.ObjectRef, item types.ApplyOrderItemConfig) bool {
+	checkMatch := func(v string, pattern *string) bool {
+		if pattern == nil {
+			return true
+		}
+		m, err := path.Match(*pattern, v)
+		return err == nil && m
+	}
+	return checkMatch(ref.Group, item.Group) &&
+		checkMatch(ref.Kind, item.Kind) &&
+		checkMatch(ref.Namespace, item.Namespace) &&
+		checkMatch(ref.Name, item.Name)
+}
+
+// SortObjectsByApplyOrder sorts the given objects according to the explicit apply order. Objects are ordered by the
+// first apply order item they match. Objects that match no item are applied afterward, keeping their original order.
+func SortObjectsByApplyOrder(objects []*uo.UnstructuredObject, applyOrder []types.ApplyOrderItemConfig) []*uo.UnstructuredObject {
+	if len(applyOrder) == 0 {
+		return objects
+	}
+
+	indexes := make(map[*uo.UnstructuredObject]int, len(objects))
+	for _, o := range objects {
+		ref := o.GetK8sRef()
+		idx := len(applyOrder)
+		for i, item := range applyOrder {
+			if matchApplyOrderItem(ref, item) {
+				idx = i
+				break
+			}
+		}
+		indexes[o] = idx
+	}
+
+	ret := append([]*uo.UnstructuredObject{}, objects...)
+	sort.SliceStable(ret, func(i, j int) bool {
+		return indexes[ret[i]] < indexes[ret[j]]
+	})
+	return ret
+}
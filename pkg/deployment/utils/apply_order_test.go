@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func buildApplyOrderTestObject(group string, kind string, namespace string, name string) *uo.UnstructuredObject {
+	o := uo.New()
+	o.SetK8sGVKs(group, "v1", kind)
+	o.SetK8sNamespace(namespace)
+	o.SetK8sName(name)
+	return o
+}
+
+func TestSortObjectsByApplyOrder(t *testing.T) {
+	s := func(s string) *string {
+		return &s
+	}
+
+	cm1 := buildApplyOrderTestObject("", "ConfigMap", "ns", "cm1")
+	cm2 := buildApplyOrderTestObject("", "ConfigMap", "ns", "legacy-config")
+	deployment := buildApplyOrderTestObject("apps", "Deployment", "ns", "legacy-app")
+	svc := buildApplyOrderTestObject("", "Service", "ns", "svc")
+	job := buildApplyOrderTestObject("batch", "Job", "ns", "migrate")
+
+	objects := []*uo.UnstructuredObject{cm1, cm2, deployment, svc, job}
+
+	assert.Equal(t, objects, SortObjectsByApplyOrder(objects, nil))
+
+	sorted := SortObjectsByApplyOrder(objects, []types.ApplyOrderItemConfig{
+		{Group: s("batch"), Kind: s("Job")},
+		{Name: s("legacy-*")},
+	})
+	assert.Equal(t, []*uo.UnstructuredObject{job, cm2, deployment, cm1, svc}, sorted)
+
+	// the input must not be modified
+	assert.Equal(t, []*uo.UnstructuredObject{cm1, cm2, deployment, svc, job}, objects)
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"github.com/kluctl/kluctl/lib/status"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"math/rand"
+	"time"
+)
+
+const applyRetryAbortCheckInterval = 100 * time.Millisecond
+
+// retryDelay returns the delay to wait before the given retry attempt (starting with 1), without jitter
+func (a *ApplyUtil) retryDelay(attempt int) time.Duration {
+	if a.o.RetryBackoff <= 0 {
+		return 0
+	}
+	maxBackoff := a.o.RetryMaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultApplyRetryMaxBackoff
+	}
+	b := newPollBackoff(a.o.RetryBackoff, maxBackoff)
+	for i := 1; i < attempt; i++ {
+		b.Next()
+	}
+	return b.Next()
+}
+
+// addRetryJitter randomizes the given delay by +/- 20%, so that retries of parallel applies don't happen in lockstep
+func addRetryJitter(d time.Duration) time.Duration {
+	j := int64(d) / 5
+	if j <= 0 {
+		return d
+	}
+	return d - time.Duration(j) + time.Duration(rand.Int63n(2*j+1))
+}
+
+// waitBeforeRetry waits before the given retry attempt (see RetryBackoff). It returns false if the deployment got
+// aborted in the meantime, in which case the retry must not be performed.
+func (a *ApplyUtil) waitBeforeRetry(ref k8s2.ObjectRef, attempt int) bool {
+	if a.abortSignal.Load().(bool) {
+		return false
+	}
+	d := a.retryDelay(attempt)
+	if d <= 0 {
+		return true
+	}
+	d = addRetryJitter(d)
+	status.Tracef(a.ctx, "waiting %s before retrying to apply %s", d.String(), ref.String())
+
+	deadline := time.Now().Add(d)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return true
+		}
+		if remaining > applyRetryAbortCheckInterval {
+			remaining = applyRetryAbortCheckInterval
+		}
+		select {
+		case <-time.After(remaining):
+		case <-a.ctx.Done():
+			return false
+		}
+		if a.abortSignal.Load().(bool) {
+			return false
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestApplyRetryDelay(t *testing.T) {
+	newApplyUtil := func(backoff time.Duration, maxBackoff time.Duration) *ApplyUtil {
+		dew := NewDeploymentErrorsAndWarnings()
+		ru := NewRemoteObjectsUtil(context.TODO(), dew)
+		ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{
+			RetryBackoff:    backoff,
+			RetryMaxBackoff: maxBackoff,
+		})
+		return ad.NewApplyUtil(context.TODO(), nil)
+	}
+
+	a := newApplyUtil(0, 0)
+	assert.Equal(t, time.Duration(0), a.retryDelay(1))
+	assert.Equal(t, time.Duration(0), a.retryDelay(2))
+
+	a = newApplyUtil(time.Second, 0)
+	assert.Equal(t, time.Second, a.retryDelay(1))
+	assert.Equal(t, 2*time.Second, a.retryDelay(2))
+
+	a = newApplyUtil(time.Second, 1500*time.Millisecond)
+	assert.Equal(t, time.Second, a.retryDelay(1))
+	assert.Equal(t, 1500*time.Millisecond, a.retryDelay(2))
+
+	for i := 0; i < 100; i++ {
+		d := addRetryJitter(time.Second)
+		assert.GreaterOrEqual(t, d, 800*time.Millisecond)
+		assert.LessOrEqual(t, d, 1200*time.Millisecond)
+	}
+}
+
+func TestApplyRetryAbort(t *testing.T) {
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{
+		ReplaceOnError: true,
+		RetryBackoff:   time.Hour,
+	})
+	a := ad.NewApplyUtil(context.TODO(), nil)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		a.abortSignal.Store(true)
+	}()
+
+	startTime := time.Now()
+	ref := k8s2.NewObjectRef("", "v1", "ConfigMap", "cm", "default")
+	assert.False(t, a.waitBeforeRetry(ref, 1))
+	assert.Less(t, time.Since(startTime), 10*time.Second)
+
+	// an aborted retry must record the original error instead of trying to replace the object
+	cm := newTestConfigMap("cm", nil, nil)
+	a.retryApplyWithReplace(cm, false, cm, fmt.Errorf("apply failed"))
+	assert.Len(t, dew.GetErrorsList(), 1)
+	assert.Equal(t, "apply failed", dew.GetErrorsList()[0].Message)
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
+)
+
+type ApplySummaryCounts struct {
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+type ApplySummaryChangedObject struct {
+	Ref           k8s2.ObjectRef
+	Hook          bool
+	ChangedFields []string
+}
+
+// ApplySummary is a compact summary of the changes caused by applying objects. It is especially useful after a
+// dry-run apply, as it allows to show what would be changed without rendering full diffs. Hooks are counted
+// separately from normal objects.
+type ApplySummary struct {
+	Objects ApplySummaryCounts
+	Hooks   ApplySummaryCounts
+
+	// ChangedObjects contains all updated objects and hooks together with the json paths of all changed fields
+	ChangedObjects []ApplySummaryChangedObject
+}
+
+func (s *ApplySummary) add(s2 *ApplySummary) {
+	s.Objects.Created += s2.Objects.Created
+	s.Objects.Updated += s2.Objects.Updated
+	s.Objects.Unchanged += s2.Objects.Unchanged
+	s.Hooks.Created += s2.Hooks.Created
+	s.Hooks.Updated += s2.Hooks.Updated
+	s.Hooks.Unchanged += s2.Hooks.Unchanged
+	s.ChangedObjects = append(s.ChangedObjects, s2.ChangedObjects...)
+}
+
+func (s *ApplySummary) sort() {
+	sort.Slice(s.ChangedObjects, func(i, j int) bool {
+		return s.ChangedObjects[i].Ref.String() < s.ChangedObjects[j].Ref.String()
+	})
+}
+
+// Summary compares all applied objects with the remote objects found before applying and returns the resulting
+// ApplySummary. Errors that occur while diffing are recorded as errors for the affected object.
+func (a *ApplyUtil) Summary() *ApplySummary {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	ret := &ApplySummary{}
+	for ref, ao := range a.appliedObjects {
+		_, hook := a.appliedHookObjects[ref]
+		counts := &ret.Objects
+		if hook {
+			counts = &ret.Hooks
+		}
+
+		ro := a.ru.GetRemoteObject(ref)
+		if ro == nil {
+			counts.Created++
+			continue
+		}
+
+		nao, err := diff.NormalizeObject(ao, a.ignoreForDiffs, ao)
+		if err != nil {
+			a.dew.AddError(ref, err)
+			continue
+		}
+		nro, err := diff.NormalizeObject(ro, a.ignoreForDiffs, ao)
+		if err != nil {
+			a.dew.AddError(ref, err)
+			continue
+		}
+		changes, err := diff.Diff(nro, nao)
+		if err != nil {
+			a.dew.AddError(ref, err)
+			continue
+		}
+		if len(changes) == 0 {
+			counts.Unchanged++
+			continue
+		}
+
+		counts.Updated++
+		co := ApplySummaryChangedObject{
+			Ref:  ref,
+			Hook: hook,
+		}
+		for _, c := range changes {
+			co.ChangedFields = append(co.ChangedFields, c.JsonPath)
+		}
+		ret.ChangedObjects = append(ret.ChangedObjects, co)
+	}
+	ret.sort()
+	return ret
+}
+
+// Summary returns the combined ApplySummary of all deployment items
+func (ad *ApplyDeploymentsUtil) Summary() *ApplySummary {
+	ad.resultsMutex.Lock()
+	defer ad.resultsMutex.Unlock()
+
+	ret := &ApplySummary{}
+	for _, a := range ad.results {
+		ret.add(a.Summary())
+	}
+	ret.sort()
+	return ret
+}
+
+// BuildAppliedObjectsSummary counts all applied objects and hooks of all deployment items per GroupKind. In contrast
+// to Summary, it does not compute diffs and is thus cheap enough to be stored with every command result.
+func BuildAppliedObjectsSummary(ad *ApplyDeploymentsUtil) *result.AppliedObjectsSummary {
+	objects := map[schema.GroupKind]*result.AppliedKindCounts{}
+	hooks := map[schema.GroupKind]*result.AppliedKindCounts{}
+
+	ad.resultsMutex.Lock()
+	for _, a := range ad.results {
+		a.mutex.Lock()
+		for ref := range a.appliedObjects {
+			m := objects
+			if _, ok := a.appliedHookObjects[ref]; ok {
+				m = hooks
+			}
+			gk := ref.GroupKind()
+			c, ok := m[gk]
+			if !ok {
+				c = &result.AppliedKindCounts{Group: gk.Group, Kind: gk.Kind}
+				m[gk] = c
+			}
+			if a.ru.GetRemoteObject(ref) == nil {
+				c.Created++
+			} else {
+				c.Updated++
+			}
+		}
+		a.mutex.Unlock()
+	}
+	ad.resultsMutex.Unlock()
+
+	toList := func(m map[schema.GroupKind]*result.AppliedKindCounts) []result.AppliedKindCounts {
+		var ret []result.AppliedKindCounts
+		for _, c := range m {
+			ret = append(ret, *c)
+		}
+		sort.Slice(ret, func(i, j int) bool {
+			if ret[i].Group != ret[j].Group {
+				return ret[i].Group < ret[j].Group
+			}
+			return ret[i].Kind < ret[j].Kind
+		})
+		return ret
+	}
+
+	return &result.AppliedObjectsSummary{
+		Objects: toList(objects),
+		Hooks:   toList(hooks),
+	}
+}
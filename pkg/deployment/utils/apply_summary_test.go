@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestApplySummary(t *testing.T) {
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+
+	unchanged := newTestConfigMap("unchanged", map[string]interface{}{"a": "v1"}, nil)
+	updatedRemote := newTestConfigMap("updated", map[string]interface{}{"a": "v1", "b": "v1"}, nil)
+	updated := newTestConfigMap("updated", map[string]interface{}{"a": "v2", "b": "v1"}, nil)
+	created := newTestConfigMap("created", map[string]interface{}{"a": "v1"}, nil)
+	hookRemote := newTestConfigMap("hook", map[string]interface{}{"a": "v1"}, nil)
+	hook := newTestConfigMap("hook", map[string]interface{}{"a": "v2"}, nil)
+	createdHook := newTestConfigMap("created-hook", map[string]interface{}{"a": "v1"}, nil)
+
+	ru.remoteObjects[unchanged.GetK8sRef()] = unchanged
+	ru.remoteObjects[updatedRemote.GetK8sRef()] = updatedRemote
+	ru.remoteObjects[hookRemote.GetK8sRef()] = hookRemote
+
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{})
+	a1 := ad.NewApplyUtil(context.TODO(), nil)
+	a1.handleResult(unchanged, false)
+	a1.handleResult(updated, false)
+	a1.handleResult(created, false)
+
+	a2 := ad.NewApplyUtil(context.TODO(), nil)
+	a2.handleResult(hook, true)
+	a2.handleResult(createdHook, true)
+
+	s := ad.Summary()
+	assert.Equal(t, ApplySummaryCounts{Created: 1, Updated: 1, Unchanged: 1}, s.Objects)
+	assert.Equal(t, ApplySummaryCounts{Created: 1, Updated: 1}, s.Hooks)
+	assert.Equal(t, []ApplySummaryChangedObject{
+		{Ref: hook.GetK8sRef(), Hook: true, ChangedFields: []string{"data.a"}},
+		{Ref: updated.GetK8sRef(), Hook: false, ChangedFields: []string{"data.a"}},
+	}, s.ChangedObjects)
+	assert.Empty(t, dew.GetErrorsList())
+}
+
+func TestBuildAppliedObjectsSummary(t *testing.T) {
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+
+	existing := newTestConfigMap("existing", nil, nil)
+	created := newTestConfigMap("created", nil, nil)
+	deploy := newTestConfigMap("deploy", nil, nil)
+	deploy.SetK8sGVKs("apps", "v1", "Deployment")
+	hook := newTestConfigMap("hook", nil, nil)
+	hook.SetK8sGVKs("batch", "v1", "Job")
+
+	ru.remoteObjects[existing.GetK8sRef()] = existing
+
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{})
+	a1 := ad.NewApplyUtil(context.TODO(), nil)
+	a1.handleResult(existing, false)
+	a1.handleResult(created, false)
+
+	a2 := ad.NewApplyUtil(context.TODO(), nil)
+	a2.handleResult(deploy, false)
+	a2.handleResult(hook, true)
+
+	s := BuildAppliedObjectsSummary(ad)
+	assert.Equal(t, &result.AppliedObjectsSummary{
+		Objects: []result.AppliedKindCounts{
+			{Kind: "ConfigMap", Created: 1, Updated: 1},
+			{Group: "apps", Kind: "Deployment", Created: 1},
+		},
+		Hooks: []result.AppliedKindCounts{
+			{Group: "batch", Kind: "Job", Created: 1},
+		},
+	}, s)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	errors2 "errors"
 	"fmt"
+	"github.com/gobwas/glob"
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
@@ -19,8 +20,10 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"reflect"
+	"regexp"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -28,6 +31,15 @@ import (
 	"time"
 )
 
+const defaultApplyParallelism = 8
+
+const (
+	defaultHookPollInterval    = 500 * time.Millisecond
+	defaultHookPollMaxInterval = 5 * time.Second
+)
+
+const defaultApplyRetryMaxBackoff = 5 * time.Second
+
 type ApplyUtilOptions struct {
 	ForceApply          bool
 	ReplaceOnError      bool
@@ -37,7 +49,91 @@ type ApplyUtilOptions struct {
 	ReadinessTimeout    time.Duration
 	NoWait              bool
 
+	// AbortAfterErrors causes applying to be aborted once the given number of errors was recorded across all deployment
+	// items. 0 means to never abort early. AbortOnError takes precedence and is equivalent to a value of 1.
+	AbortAfterErrors int
+
+	// ForceReplaceOnErrorKinds, if not empty, restricts ForceReplaceOnError to objects of the given kinds. Objects of
+	// other kinds are not deleted and the failed replace is recorded as an error instead.
+	ForceReplaceOnErrorKinds []schema.GroupKind
+
+	// ReplacePreserveMetadata specifies annotation and label keys which are carried over from the remote object when
+	// an object is replaced (see ReplaceOnError). This avoids losing metadata added by other controllers.
+	ReplacePreserveMetadata []glob.Glob
+
+	// Parallelism specifies how many deployment items are applied in parallel. 0 means to use the default.
+	Parallelism int
+	// ApplyTimeout limits the time a single patch/update request may take. 0 means no timeout.
+	ApplyTimeout time.Duration
+	// BarrierTimeout limits the time to wait at a barrier for the preceding deployment items. Stalled items are
+	// cancelled and an error is recorded. 0 means no timeout.
+	BarrierTimeout time.Duration
+	// HookPollInterval is the initial interval used to poll hooks while waiting for them. The interval is doubled on
+	// every poll until HookPollMaxInterval is reached. 0 means to use the defaults.
+	HookPollInterval    time.Duration
+	HookPollMaxInterval time.Duration
+	// RetryBackoff is the initial delay before falling back to conflict resolution or replacing after a failed apply.
+	// The delay is doubled for every further fallback until RetryMaxBackoff is reached and is randomized by +/- 20%.
+	// 0 means to retry immediately. If RetryMaxBackoff is 0, a default of 5s is used.
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+
 	SkipResourceVersions map[k8s2.ObjectRef]string
+
+	// ResumeAppliedObjects maps objects that were successfully applied by a previous deployment to the rendered
+	// checksum they were applied with. Matching objects are not applied again, see isResumable for details.
+	ResumeAppliedObjects map[k8s2.ObjectRef]string
+
+	// CanaryObjects, if set, restricts applying to the given objects. Pre-deploy hooks of deployment items with at
+	// least one canary object are run, post-deploy hooks and deletions are skipped. All applied objects are waited for
+	// until they get ready.
+	CanaryObjects map[k8s2.ObjectRef]bool
+	// SkipPreDeployHooks contains deployment items for which the pre-deploy hooks were already run, e.g. by the canary
+	// phase. See ApplyDeploymentsUtil.GetPreDeployHooksDone.
+	SkipPreDeployHooks map[*deployment.DeploymentItem]bool
+
+	// ObjectValidator, if set, is invoked for every object before it gets applied. See ObjectValidator for details.
+	ObjectValidator ObjectValidator
+
+	// EventCallback, if set, receives structured events for every applied object. See ApplyEvent for details.
+	EventCallback ApplyEventCallback
+
+	// WaitRollout causes all applied workloads (see rolloutKinds) to be waited for until they get ready, after all
+	// objects of a deployment item got applied. WaitRolloutTimeout is the per-object timeout, 0 means to use
+	// ReadinessTimeout.
+	WaitRollout        bool
+	WaitRolloutTimeout time.Duration
+
+	// ConfirmDeploymentItem, if set, is invoked before each deployment item gets applied. Deployment items are then
+	// applied one after another instead of in parallel.
+	ConfirmDeploymentItem ConfirmDeploymentItemCallback
+
+	// Metrics, if set, receives metrics about applied objects, retries and hook waits
+	Metrics *ApplyMetrics
+
+	// WarningsAsErrors causes warnings returned by the cluster to be handled as errors, which also means that
+	// AbortOnError is honored. If WarningsAsErrorsPatterns is not empty, only warnings matching at least one of the
+	// patterns are handled as errors.
+	WarningsAsErrors         bool
+	WarningsAsErrorsPatterns []*regexp.Regexp
+	// FailOnApiDeprecation causes API deprecation warnings returned by the cluster to be handled as errors, the same
+	// way as WarningsAsErrors does. If FailOnApiDeprecationGroups is not empty, only deprecation warnings for objects of
+	// the given API groups are handled as errors. The core API group is specified as "core".
+	FailOnApiDeprecation       bool
+	FailOnApiDeprecationGroups []string
+
+	// RunDryRunHooks causes hooks annotated with kluctl.io/hook-dry-run to be really executed and waited for, even
+	// when DryRun is set. All other objects and hooks are still only applied in dry-run mode.
+	RunDryRunHooks bool
+
+	// SkipMessages causes the messages of message-only deployment items to not be printed. This is used for passes that
+	// precede the actual deployment (e.g. the diff and the canary), so that messages are printed exactly once.
+	SkipMessages bool
+
+	// ApplyLabelSelector, if set, restricts applying to objects matching the selector. Non-matching objects are skipped
+	// but are still treated as rendered objects, so they are never pruned. Hooks of a deployment item are only run if
+	// at least one object of the item matches or if the hook itself matches.
+	ApplyLabelSelector labels.Selector
 }
 
 type ApplyUtil struct {
@@ -51,14 +147,30 @@ type ApplyUtil struct {
 	appliedHookObjects map[k8s2.ObjectRef]*uo.UnstructuredObject
 	deletedObjects     map[k8s2.ObjectRef]bool
 	deletedHookObjects map[k8s2.ObjectRef]bool
+	resumedCount       int
+	selectorSkipCount  int
 	mutex              sync.Mutex
 
-	abortSignal   *atomic.Value
-	allNamespaces *sync.Map
-	allCRDs       *sync.Map
+	// dryRun is initialized from ApplyUtilOptions.DryRun and only disabled temporarily, see HooksUtil.withHookDryRun
+	dryRun bool
+
+	abortSignal     *atomic.Value
+	abortErrorCount *atomic.Int32
+	allNamespaces   *sync.Map
+	allCRDs         *sync.Map
+	eventsMutex     *sync.Mutex
 
 	crdCache *k8s.CrdCache
 
+	// ignoreForDiffs is only used to build the ApplySummary
+	ignoreForDiffs []types2.IgnoreForDiffItemConfig
+	// readinessChecks are passed to validation.ValidateObject while waiting for readiness
+	readinessChecks []types2.ReadinessCheckConfig
+	// deploymentItemName is used to build the prune report and to emit apply events
+	deploymentItemName string
+	// preDeployHooksDone is set to the deployment item for which pre-deploy hooks were run in the canary phase
+	preDeployHooksDone *deployment.DeploymentItem
+
 	ru   *RemoteObjectUtils
 	k    *k8s.K8sCluster
 	o    *ApplyUtilOptions
@@ -74,6 +186,8 @@ type ApplyDeploymentsUtil struct {
 	o   *ApplyUtilOptions
 
 	abortSignal atomic.Value
+	// counts errors across all ApplyUtil instances, see ApplyUtilOptions.AbortAfterErrors
+	abortErrorCount atomic.Int32
 
 	// Used to track all created namespaces and CRDs
 	// All ApplyUtil instances write to this in parallel and we ignore that order might be unstable
@@ -85,6 +199,9 @@ type ApplyDeploymentsUtil struct {
 
 	resultsMutex sync.Mutex
 	results      []*ApplyUtil
+
+	// serializes calls to ApplyUtilOptions.EventCallback
+	eventsMutex sync.Mutex
 }
 
 func NewApplyDeploymentsUtil(ctx context.Context, dew *DeploymentErrorsAndWarnings, ru *RemoteObjectUtils, k *k8s.K8sCluster, o *ApplyUtilOptions) *ApplyDeploymentsUtil {
@@ -112,23 +229,29 @@ func (ad *ApplyDeploymentsUtil) NewApplyUtil(ctx context.Context, statusCtx *sta
 		deletedObjects:     map[k8s2.ObjectRef]bool{},
 		deletedHookObjects: map[k8s2.ObjectRef]bool{},
 		abortSignal:        &ad.abortSignal,
+		abortErrorCount:    &ad.abortErrorCount,
 		allNamespaces:      &ad.allNamespaces,
 		allCRDs:            &ad.allCRDs,
+		eventsMutex:        &ad.eventsMutex,
 		crdCache:           &ad.crdCache,
 		ru:                 ad.ru,
 		k:                  ad.k,
 		o:                  ad.o,
 		sctx:               statusCtx,
+		dryRun:             ad.o.DryRun,
 	}
 	ad.results = append(ad.results, ret)
 	return ret
 }
 
 func (a *ApplyUtil) handleResult(appliedObject *uo.UnstructuredObject, hook bool) {
+	ref := appliedObject.GetK8sRef()
+	a.emitEvent(ApplyEventApplyResult, ref, hook, "")
+	a.o.Metrics.objectApplied(hook)
+
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	ref := appliedObject.GetK8sRef()
 	if hook {
 		a.appliedHookObjects[ref] = appliedObject
 	}
@@ -140,14 +263,69 @@ func (a *ApplyUtil) handleResult(appliedObject *uo.UnstructuredObject, hook bool
 }
 
 func (a *ApplyUtil) handleApiWarnings(ref k8s2.ObjectRef, warnings []k8s.ApiWarning) {
+	var remaining []k8s.ApiWarning
+	for _, w := range warnings {
+		if a.isWarningAsError(ref, w) {
+			a.HandleError(ref, fmt.Errorf("%s", w.Text))
+			continue
+		}
+		a.emitEvent(ApplyEventWarning, ref, false, w.Text)
+		remaining = append(remaining, w)
+	}
+
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	a.dew.AddApiWarnings(ref, warnings)
-	a.warningCount += len(warnings)
+	a.dew.AddApiWarnings(ref, remaining)
+	a.warningCount += len(remaining)
+}
+
+func (a *ApplyUtil) isWarningAsError(ref k8s2.ObjectRef, w k8s.ApiWarning) bool {
+	if a.isApiDeprecationError(ref, w) {
+		return true
+	}
+	if !a.o.WarningsAsErrors {
+		return false
+	}
+	if len(a.o.WarningsAsErrorsPatterns) == 0 {
+		return true
+	}
+	for _, p := range a.o.WarningsAsErrorsPatterns {
+		if p.MatchString(w.Text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *ApplyUtil) isApiDeprecationError(ref k8s2.ObjectRef, w k8s.ApiWarning) bool {
+	if !a.o.FailOnApiDeprecation || !isApiDeprecationWarning(w) {
+		return false
+	}
+	if len(a.o.FailOnApiDeprecationGroups) == 0 {
+		return true
+	}
+	group := ref.Group
+	if group == "" {
+		group = "core"
+	}
+	for _, g := range a.o.FailOnApiDeprecationGroups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// isApiDeprecationWarning checks if the warning was sent by the api server because a deprecated API version was used,
+// e.g. "policy/v1beta1 PodSecurityPolicy is deprecated in v1.21+, unavailable in v1.25+"
+func isApiDeprecationWarning(w k8s.ApiWarning) bool {
+	return strings.Contains(w.Text, " is deprecated")
 }
 
 func (a *ApplyUtil) HandleWarning(ref k8s2.ObjectRef, warning error) {
+	a.emitEvent(ApplyEventWarning, ref, false, warning.Error())
+
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
@@ -156,6 +334,8 @@ func (a *ApplyUtil) HandleWarning(ref k8s2.ObjectRef, warning error) {
 }
 
 func (a *ApplyUtil) HandleError(ref k8s2.ObjectRef, err error) {
+	a.emitEvent(ApplyEventError, ref, false, err.Error())
+
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
@@ -163,21 +343,37 @@ func (a *ApplyUtil) HandleError(ref k8s2.ObjectRef, err error) {
 		a.abortSignal.Store(true)
 	}
 
-	if a.o.AbortOnError && a.abortSignal != nil {
-		a.abortSignal.Store(true)
-	}
+	a.countAbortError()
 
 	a.dew.AddError(ref, err)
 	a.errorCount++
 }
 
+// countAbortError counts an error towards the abort threshold and sets the abort signal once the threshold is reached.
+// See ApplyUtilOptions.AbortAfterErrors for details.
+func (a *ApplyUtil) countAbortError() {
+	if a.abortSignal == nil || a.abortErrorCount == nil {
+		return
+	}
+	threshold := a.o.AbortAfterErrors
+	if a.o.AbortOnError {
+		threshold = 1
+	}
+	if threshold <= 0 {
+		return
+	}
+	if int(a.abortErrorCount.Add(1)) >= threshold {
+		a.abortSignal.Store(true)
+	}
+}
+
 func (a *ApplyUtil) HadError(ref k8s2.ObjectRef) bool {
 	return a.dew.HadError(ref)
 }
 
 func (a *ApplyUtil) DeleteObject(ref k8s2.ObjectRef, hook bool) bool {
 	o := k8s.DeleteOptions{
-		ForceDryRun: a.o.DryRun,
+		ForceDryRun: a.dryRun,
 	}
 	apiWarnings, err := a.k.DeleteSingleObject(ref, o)
 	a.handleApiWarnings(ref, apiWarnings)
@@ -196,7 +392,7 @@ func (a *ApplyUtil) DeleteObject(ref k8s2.ObjectRef, hook bool) bool {
 		a.HandleError(ref, err)
 		return false
 	}
-	if !a.o.DryRun {
+	if !a.dryRun {
 		// just ignore 404 errors
 		return false
 	}
@@ -233,7 +429,7 @@ func (a *ApplyUtil) DeleteObject(ref k8s2.ObjectRef, hook bool) bool {
 func (a *ApplyUtil) retryApplyForceReplace(x *uo.UnstructuredObject, hook bool, remoteObject *uo.UnstructuredObject, applyError error) {
 	ref := x.GetK8sRef()
 
-	if !a.o.ForceReplaceOnError {
+	if !a.o.ForceReplaceOnError || !a.isForceReplaceAllowed(ref) {
 		a.HandleError(ref, applyError)
 		return
 	}
@@ -248,17 +444,24 @@ func (a *ApplyUtil) retryApplyForceReplace(x *uo.UnstructuredObject, hook bool,
 		return
 	}
 
+	if !a.waitBeforeRetry(ref, 2) {
+		a.HandleError(ref, applyError)
+		return
+	}
+
 	warn := fmt.Errorf("patching %s failed, retrying by deleting and re-applying", ref.String())
 	a.HandleWarning(ref, warn)
 	status.Warning(a.ctx, warn.Error())
+	a.o.Metrics.applyRetried(ApplyRetryReasonForceReplace)
 
 	if !a.DeleteObject(ref, hook) {
 		return
 	}
 
-	if !a.o.DryRun {
+	if !a.dryRun {
 		o := k8s.PatchOptions{
-			ForceDryRun: a.o.DryRun,
+			ForceDryRun: a.dryRun,
+			Timeout:     a.o.ApplyTimeout,
 		}
 		r, apiWarnings, err := a.k.ApplyObject(x, o)
 		a.handleApiWarnings(ref, apiWarnings)
@@ -272,6 +475,19 @@ func (a *ApplyUtil) retryApplyForceReplace(x *uo.UnstructuredObject, hook bool,
 	}
 }
 
+func (a *ApplyUtil) isForceReplaceAllowed(ref k8s2.ObjectRef) bool {
+	if len(a.o.ForceReplaceOnErrorKinds) == 0 {
+		return true
+	}
+	gk := ref.GroupKind()
+	for _, x := range a.o.ForceReplaceOnErrorKinds {
+		if x == gk {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *ApplyUtil) retryApplyWithReplace(x *uo.UnstructuredObject, hook bool, remoteObject *uo.UnstructuredObject, applyError error) {
 	ref := x.GetK8sRef()
 
@@ -280,19 +496,27 @@ func (a *ApplyUtil) retryApplyWithReplace(x *uo.UnstructuredObject, hook bool, r
 		return
 	}
 
+	if !a.waitBeforeRetry(ref, 1) {
+		a.HandleError(ref, applyError)
+		return
+	}
+
 	warn := fmt.Errorf("patching %s failed, retrying with replace instead of patch", ref.String())
 	a.HandleWarning(ref, warn)
 	status.Warning(a.ctx, warn.Error())
+	a.o.Metrics.applyRetried(ApplyRetryReasonReplace)
 
 	rv := remoteObject.GetK8sResourceVersion()
 	x2 := x.Clone()
 	x2.SetK8sResourceVersion(rv)
+	a.preserveRemoteMetadata(x2, remoteObject)
 
 	o := k8s.UpdateOptions{
-		ForceDryRun: a.o.DryRun,
+		ForceDryRun: a.dryRun,
+		Timeout:     a.o.ApplyTimeout,
 	}
 
-	r, apiWarnings, err := a.k.UpdateObject(x, o)
+	r, apiWarnings, err := a.k.UpdateObject(x2, o)
 	a.handleApiWarnings(ref, apiWarnings)
 	if err != nil {
 		a.retryApplyForceReplace(x, hook, remoteObject, err)
@@ -301,6 +525,34 @@ func (a *ApplyUtil) retryApplyWithReplace(x *uo.UnstructuredObject, hook bool, r
 	a.handleResult(r, hook)
 }
 
+// preserveRemoteMetadata copies all remote annotations and labels matching ReplacePreserveMetadata onto x, unless x
+// specifies them by itself
+func (a *ApplyUtil) preserveRemoteMetadata(x *uo.UnstructuredObject, remoteObject *uo.UnstructuredObject) {
+	if len(a.o.ReplacePreserveMetadata) == 0 {
+		return
+	}
+
+	matches := func(k string) bool {
+		for _, g := range a.o.ReplacePreserveMetadata {
+			if g.Match(k) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for k, v := range remoteObject.GetK8sAnnotations() {
+		if x.GetK8sAnnotation(k) == nil && matches(k) {
+			x.SetK8sAnnotation(k, v)
+		}
+	}
+	for k, v := range remoteObject.GetK8sLabels() {
+		if x.GetK8sLabel(k) == nil && matches(k) {
+			x.SetK8sLabel(k, v)
+		}
+	}
+}
+
 func (a *ApplyUtil) retryApplyWithConflicts(d *deployment.DeploymentItem, x *uo.UnstructuredObject, hook bool, remoteObject *uo.UnstructuredObject, applyError error) {
 	ref := x.GetK8sRef()
 
@@ -309,6 +561,13 @@ func (a *ApplyUtil) retryApplyWithConflicts(d *deployment.DeploymentItem, x *uo.
 		return
 	}
 
+	if !a.waitBeforeRetry(ref, 1) {
+		a.HandleError(ref, applyError)
+		return
+	}
+
+	a.o.Metrics.applyRetried(ApplyRetryReasonConflicts)
+
 	var x2 *uo.UnstructuredObject
 	if !a.o.ForceApply {
 		var statusError *errors.StatusError
@@ -334,12 +593,14 @@ func (a *ApplyUtil) retryApplyWithConflicts(d *deployment.DeploymentItem, x *uo.
 	}
 
 	options := k8s.PatchOptions{
-		ForceDryRun: a.o.DryRun,
+		ForceDryRun: a.dryRun,
 		ForceApply:  true,
+		Timeout:     a.o.ApplyTimeout,
 	}
 	r, apiWarnings, err := a.k.ApplyObject(x2, options)
 	a.handleApiWarnings(ref, apiWarnings)
 	if err == nil {
+		a.o.Metrics.conflictResolved()
 		a.handleResult(r, hook)
 	} else {
 		a.retryApplyForceReplace(x, hook, remoteObject, err)
@@ -349,6 +610,25 @@ func (a *ApplyUtil) retryApplyWithConflicts(d *deployment.DeploymentItem, x *uo.
 func (a *ApplyUtil) ApplyObject(d *deployment.DeploymentItem, x *uo.UnstructuredObject, replaced bool, hook bool) {
 	ref := x.GetK8sRef()
 
+	if a.ctx.Err() != nil {
+		// applying got cancelled (e.g. because the deploy lock got lost), so no new objects must be applied
+		return
+	}
+
+	if !a.validateObject(d, x) {
+		return
+	}
+
+	a.emitEvent(ApplyEventApplyStart, ref, hook, "")
+
+	checksum, err := CalcRenderedChecksum(x)
+	if err != nil {
+		a.HandleError(ref, err)
+		return
+	}
+
+	x = x.Clone()
+	x.SetK8sAnnotation(RenderedChecksumAnnotation, checksum)
 	x = a.k.FixObjectForPatch(x)
 	remoteObject := a.ru.GetRemoteObject(ref)
 
@@ -361,9 +641,16 @@ func (a *ApplyUtil) ApplyObject(d *deployment.DeploymentItem, x *uo.Unstructured
 		}
 	}
 
+	if !hook && a.isResumable(ref, checksum, remoteObject) {
+		a.mutex.Lock()
+		a.resumedCount++
+		a.mutex.Unlock()
+		a.handleResult(remoteObject, hook)
+		return
+	}
+
 	var remoteNamespace *uo.UnstructuredObject
 	if ref.Namespace != "" {
-		var err error
 		remoteNamespace, err = a.ru.GetRemoteNamespace(a.k, ref.Namespace)
 		if err != nil {
 			a.HandleError(ref, err)
@@ -372,7 +659,7 @@ func (a *ApplyUtil) ApplyObject(d *deployment.DeploymentItem, x *uo.Unstructured
 	}
 
 	usesDummyName := false
-	if a.o.DryRun && replaced && remoteObject != nil {
+	if a.dryRun && replaced && remoteObject != nil {
 		// The object got deleted before, which was however only simulated when in dry-run mode. This means, that
 		// trying to patch it will either fail or give different results then when actually re-creating it. To simulate
 		// re-creation, we use a temporary name for the dry-run patch and then undo the rename after getting the patch
@@ -380,7 +667,7 @@ func (a *ApplyUtil) ApplyObject(d *deployment.DeploymentItem, x *uo.Unstructured
 		usesDummyName = true
 		x = x.Clone()
 		x.SetK8sName(utils.RandomizeSuffix(ref.Name, 8, 63))
-	} else if a.o.DryRun && remoteNamespace == nil && ref.Namespace != "" {
+	} else if a.dryRun && remoteNamespace == nil && ref.Namespace != "" {
 		if _, ok := a.allNamespaces.Load(ref.Namespace); ok {
 			// The namespace does not really exist, but would have been created if dryRun would be false.
 			// So let's pretend we deploy it to the default namespace with a dummy name
@@ -402,7 +689,8 @@ func (a *ApplyUtil) ApplyObject(d *deployment.DeploymentItem, x *uo.Unstructured
 	}
 
 	options := k8s.PatchOptions{
-		ForceDryRun: a.o.DryRun,
+		ForceDryRun: a.dryRun,
+		Timeout:     a.o.ApplyTimeout,
 	}
 	r, apiWarnings, err := a.k.ApplyObject(x, options)
 
@@ -422,7 +710,7 @@ func (a *ApplyUtil) ApplyObject(d *deployment.DeploymentItem, x *uo.Unstructured
 	undoDummyName(x)
 
 	if r == nil && retryWhenCRDExists {
-		if a.o.DryRun {
+		if a.dryRun {
 			if _, ok := a.allCRDs.Load(x.GetK8sGVK()); ok {
 				// simulate that the apply "succeeded"
 				a.handleResult(x, hook)
@@ -488,7 +776,11 @@ func (a *ApplyUtil) handleObservedCRD(r *uo.UnstructuredObject) {
 }
 
 func (a *ApplyUtil) WaitReadiness(ref k8s2.ObjectRef, timeout time.Duration) bool {
-	if a.o.DryRun {
+	return a.waitReadiness(ref, timeout, newPollBackoff(defaultHookPollInterval, defaultHookPollInterval))
+}
+
+func (a *ApplyUtil) waitReadiness(ref k8s2.ObjectRef, timeout time.Duration, backoff *pollBackoff) bool {
+	if a.dryRun {
 		return true
 	}
 
@@ -527,7 +819,7 @@ func (a *ApplyUtil) WaitReadiness(ref k8s2.ObjectRef, timeout time.Duration) boo
 		} else {
 			seen = true
 
-			v := validation.ValidateObject(a.ctx, a.k, o, false, false)
+			v := validation.ValidateObject(a.ctx, a.k, o, a.readinessChecks, false, false)
 			if v.Ready {
 				if didLog {
 					a.sctx.InfoFallbackf("Finished waiting for %s (%ds elapsed)", ref.String(), elapsed)
@@ -570,7 +862,7 @@ func (a *ApplyUtil) WaitReadiness(ref k8s2.ObjectRef, timeout time.Duration) boo
 		}
 
 		select {
-		case <-time.After(500 * time.Millisecond):
+		case <-time.After(backoff.Next()):
 			continue
 		case <-timeoutTimer.C:
 			err := fmt.Errorf("timed out while waiting for readiness of %s", ref.String())
@@ -593,6 +885,27 @@ func (a *ApplyUtil) WaitReadiness(ref k8s2.ObjectRef, timeout time.Duration) boo
 	return false
 }
 
+// rolloutKinds are the kinds of workloads that are waited for when WaitRollout is enabled
+var rolloutKinds = map[schema.GroupKind]bool{
+	{Group: "apps", Kind: "Deployment"}:  true,
+	{Group: "apps", Kind: "StatefulSet"}: true,
+	{Group: "apps", Kind: "DaemonSet"}:   true,
+}
+
+func (a *ApplyUtil) waitRollout(applyObjects []*uo.UnstructuredObject, alreadyWaited map[k8s2.ObjectRef]bool) {
+	for _, o := range applyObjects {
+		if a.abortSignal.Load().(bool) {
+			break
+		}
+
+		ref := o.GetK8sRef()
+		if !rolloutKinds[ref.GroupKind()] || alreadyWaited[ref] || a.HadError(ref) {
+			continue
+		}
+		a.WaitReadiness(ref, a.o.WaitRolloutTimeout)
+	}
+}
+
 func (a *ApplyUtil) convertObjectRef(x types2.ObjectRefItem, refs map[k8s2.ObjectRef]bool) {
 	ars, err := a.k.GetFilteredPreferredAPIResources(k8s.BuildGVKFilter(x.Group, nil, x.Kind))
 	if err != nil {
@@ -626,7 +939,113 @@ func (a *ApplyUtil) convertObjectRef(x types2.ObjectRefItem, refs map[k8s2.Objec
 	}
 }
 
+func (a *ApplyUtil) applyCanaryObjects(d *deployment.DeploymentItem) {
+	h := HooksUtil{a: a}
+
+	var applyObjects []*uo.UnstructuredObject
+	for _, o := range d.Objects {
+		if _, ok := a.o.CanaryObjects[o.GetK8sRef()]; ok && a.matchesApplyLabelSelector(o) {
+			applyObjects = append(applyObjects, o)
+		}
+	}
+	applyObjects = SortObjectsByApplyOrder(applyObjects, d.Project.GetApplyOrderConfigs())
+
+	// pre-deploy hooks usually prepare things (e.g. migrations) that the canary objects already rely on
+	var preHooks []*hook
+	if len(applyObjects) != 0 {
+		preHooks = a.determinePreDeployHooks(&h, d)
+	}
+
+	// +1 to ensure that we don't prematurely complete the bar (which would happen as we don't count for waiting)
+	a.sctx.SetTotal(len(applyObjects) + len(preHooks) + 1)
+
+	if len(preHooks) != 0 {
+		h.RunHooks(preHooks)
+		a.preDeployHooksDone = d
+	}
+
+	if len(applyObjects) != 0 {
+		a.sctx.InfoFallbackf("Applying %d canary objects", len(applyObjects))
+	}
+	for i, o := range applyObjects {
+		if a.abortSignal.Load().(bool) {
+			break
+		}
+		a.sctx.Updatef("Applying canary object %s (%d of %d)", o.GetK8sRef().String(), i+1, len(applyObjects))
+		a.ApplyObject(d, o, false, false)
+		a.sctx.Increment()
+	}
+	for _, o := range applyObjects {
+		if a.abortSignal.Load().(bool) {
+			break
+		}
+		ref := o.GetK8sRef()
+		if a.HadError(ref) || a.o.NoWait {
+			continue
+		}
+		a.WaitReadiness(ref, 0)
+	}
+
+	if len(applyObjects) == 0 {
+		a.sctx.Update("Nothing to apply.")
+	} else {
+		a.sctx.Update(fmt.Sprintf("Applied %d canary objects.", len(a.appliedObjects)))
+	}
+	if a.errorCount == 0 {
+		a.sctx.Success()
+	} else {
+		a.sctx.Failed()
+	}
+}
+
+func (a *ApplyUtil) isInitialDeploy(d *deployment.DeploymentItem) bool {
+	for _, o := range d.Objects {
+		if a.ru.GetRemoteObject(o.GetK8sRef()) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *ApplyUtil) determinePreDeployHooks(h *HooksUtil, d *deployment.DeploymentItem) []*hook {
+	if a.isInitialDeploy(d) {
+		return h.DetermineHooks(d, []string{"pre-deploy-initial", "pre-deploy"})
+	}
+	return h.DetermineHooks(d, []string{"pre-deploy-upgrade", "pre-deploy"})
+}
+
+func (a *ApplyUtil) matchesApplyLabelSelector(o *uo.UnstructuredObject) bool {
+	if a.o.ApplyLabelSelector == nil {
+		return true
+	}
+	return a.o.ApplyLabelSelector.Matches(labels.Set(o.GetK8sLabels()))
+}
+
+// filterHooksByApplyLabelSelector returns only the hooks that match the apply label selector. It is used for
+// deployment items where none of the objects matched the selector, as running all hooks would be unexpected then.
+func (a *ApplyUtil) filterHooksByApplyLabelSelector(hooks []*hook) []*hook {
+	var ret []*hook
+	for _, h := range hooks {
+		if a.matchesApplyLabelSelector(h.object) {
+			ret = append(ret, h)
+		} else {
+			a.selectorSkipCount++
+		}
+	}
+	return ret
+}
+
 func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
+	if d.Project != nil {
+		a.ignoreForDiffs = d.Project.GetIgnoreForDiffs(false, false, false, false)
+		a.readinessChecks = d.Project.GetReadinessChecks()
+	}
+
+	if a.o.CanaryObjects != nil {
+		a.applyCanaryObjects(d)
+		return
+	}
+
 	h := HooksUtil{a: a}
 
 	toDelete := map[k8s2.ObjectRef]bool{}
@@ -652,12 +1071,7 @@ func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 		}
 	}
 
-	initialDeploy := true
-	for _, o := range d.Objects {
-		if a.ru.GetRemoteObject(o.GetK8sRef()) != nil {
-			initialDeploy = false
-		}
-	}
+	initialDeploy := a.isInitialDeploy(d)
 
 	var applyObjects []*uo.UnstructuredObject
 	for _, o := range d.Objects {
@@ -667,8 +1081,14 @@ func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 		if _, ok := toDelete[o.GetK8sRef()]; ok {
 			continue
 		}
+		if !a.matchesApplyLabelSelector(o) {
+			delete(toWaitReadiness, o.GetK8sRef())
+			a.selectorSkipCount++
+			continue
+		}
 		applyObjects = append(applyObjects, o)
 	}
+	applyObjects = SortObjectsByApplyOrder(applyObjects, d.Project.GetApplyOrderConfigs())
 
 	var preHooks []*hook
 	var postHooks []*hook
@@ -679,6 +1099,18 @@ func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 		preHooks = h.DetermineHooks(d, []string{"pre-deploy-upgrade", "pre-deploy"})
 		postHooks = h.DetermineHooks(d, []string{"post-deploy-upgrade", "post-deploy"})
 	}
+	if a.o.ApplyLabelSelector != nil && len(applyObjects) == 0 {
+		preHooks = a.filterHooksByApplyLabelSelector(preHooks)
+		postHooks = a.filterHooksByApplyLabelSelector(postHooks)
+	}
+	if _, ok := a.o.SkipPreDeployHooks[d]; ok {
+		preHooks = nil
+	}
+	if a.isDeploymentItemResumable(d, &h) && (len(preHooks) != 0 || len(postHooks) != 0) {
+		a.sctx.InfoFallbackf("Skipping hooks as all objects and hooks were applied successfully before")
+		preHooks = nil
+		postHooks = nil
+	}
 
 	// +1 to ensure that we don't prematurely complete the bar (which would happen as we don't count for waiting)
 	total := len(applyObjects) + len(preHooks) + len(postHooks) + 1
@@ -727,6 +1159,9 @@ func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 			a.WaitReadiness(ref, 0)
 		}
 	}
+	if a.o.WaitRollout && !a.o.NoWait {
+		a.waitRollout(applyObjects, toWaitReadiness)
+	}
 	if a.abortSignal.Load().(bool) {
 		return
 	}
@@ -746,6 +1181,12 @@ func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 	if len(a.deletedHookObjects) != 0 {
 		finalStatus += fmt.Sprintf(" Deleted %d hooks.", len(a.deletedHookObjects))
 	}
+	if a.resumedCount != 0 {
+		finalStatus += fmt.Sprintf(" Skipped %d previously applied objects.", a.resumedCount)
+	}
+	if a.selectorSkipCount != 0 {
+		finalStatus += fmt.Sprintf(" Skipped %d objects not matching the apply label selector.", a.selectorSkipCount)
+	}
 	if a.errorCount != 0 {
 		finalStatus += fmt.Sprintf(" Encountered %d errors.", a.errorCount)
 	}
@@ -786,8 +1227,22 @@ func (a *ApplyDeploymentsUtil) ApplyDeployments(deployments []*deployment.Deploy
 		return
 	}
 
+	parallelism := a.o.Parallelism
+	if parallelism <= 0 {
+		parallelism = defaultApplyParallelism
+	}
+
 	var wg sync.WaitGroup
-	sem := semaphore.NewWeighted(8)
+	var pending []*pendingDeploymentItem
+	started := map[*deployment.DeploymentItem]*pendingDeploymentItem{}
+	sem := semaphore.NewWeighted(int64(parallelism))
+
+	// cancellation of the context (e.g. because the deploy lock got lost) must stop all deployment items as soon as
+	// possible, which is only the case for the abort signal
+	stopAbortOnCancel := context.AfterFunc(a.ctx, func() {
+		a.abortSignal.Store(true)
+	})
+	defer stopAbortOnCancel()
 
 	maxNameLen := 0
 	for _, d := range deployments {
@@ -801,29 +1256,87 @@ func (a *ApplyDeploymentsUtil) ApplyDeployments(deployments []*deployment.Deploy
 
 	for _, d_ := range deployments {
 		d := d_
-		if a.abortSignal.Load().(bool) {
+		if a.abortSignal.Load().(bool) || a.ctx.Err() != nil {
 			break
 		}
 
-		_ = sem.Acquire(context.Background(), 1)
+		if d.IsMessage() {
+			if !a.o.SkipMessages {
+				status.Info(a.ctx, *d.Config.Message)
+			}
+			continue
+		}
+
+		if a.o.ConfirmDeploymentItem != nil {
+			// wait for all previous deployment items to finish, so that the prompt does not interfere with running
+			// items and skipped items can't change the ordering in regard to barriers
+			wg.Wait()
+			pending = nil
+			if !a.confirmDeploymentItem(d) {
+				continue
+			}
+		}
+
+		// dependencies are always started before their dependents (see DeploymentCollection), so items not found here
+		// were either excluded or skipped
+		var deps []*pendingDeploymentItem
+		for _, dep := range d.DependsOn {
+			if pd, ok := started[dep]; ok {
+				deps = append(deps, pd)
+			}
+		}
+
+		// items with dependencies acquire the semaphore after their dependencies have finished, so that independent
+		// items are not blocked by them
+		if len(deps) == 0 {
+			_ = sem.Acquire(context.Background(), 1)
+			if a.abortSignal.Load().(bool) || a.ctx.Err() != nil {
+				// aborted while waiting for a free slot
+				sem.Release(1)
+				break
+			}
+		}
 
 		progressName := a.buildProgressName(d)
 		var sctx *status.StatusContext
 		if progressName != nil {
+			initialStatus := "Initializing"
+			if len(deps) != 0 {
+				initialStatus = "Waiting for dependencies"
+			}
 			sctx = status.StartWithOptions(a.ctx,
 				status.WithTotal(-1),
 				status.WithPrefix(*progressName),
-				status.WithStatus("Initializing"),
+				status.WithStatus(initialStatus),
 			)
 		}
-		a2 := a.NewApplyUtil(a.ctx, sctx)
+		itemCtx, cancel := context.WithCancel(a.ctx)
+		pd := &pendingDeploymentItem{d: d, cancel: cancel, done: make(chan struct{})}
+		pending = append(pending, pd)
+		started[d] = pd
+		a2 := a.NewApplyUtil(itemCtx, sctx)
+		if progressName != nil {
+			a2.deploymentItemName = *progressName
+		}
 
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			defer close(pd.done)
+			defer cancel()
+
+			if len(deps) != 0 {
+				if !a.waitForDependencies(a2, deps) {
+					pd.failed = true
+					sctx.Failed()
+					return
+				}
+				_ = sem.Acquire(context.Background(), 1)
+			}
 			defer sem.Release(1)
 
 			a2.applyDeploymentItem(d)
+			pd.failed = a2.errorCount != 0
 
 			// if success was not signalled, get into failed status
 			sctx.Failed()
@@ -836,14 +1349,111 @@ func (a *ApplyDeploymentsUtil) ApplyDeployments(deployments []*deployment.Deploy
 				barrierMessage = fmt.Sprintf("Waiting on barrier: %s", *d.Config.Message)
 			}
 			sctx := status.StartWithOptions(a.ctx, status.WithStatus(barrierMessage), status.WithTotal(1))
-			wg.Wait()
-			sctx.UpdateAndInfoFallback(fmt.Sprintf("Finished waiting"))
-			sctx.Success()
+			if a.waitOnBarrier(pending) {
+				sctx.UpdateAndInfoFallback(fmt.Sprintf("Finished waiting"))
+				sctx.Success()
+			} else {
+				err := a.handleBarrierTimeout(d, pending)
+				sctx.FailedWithMessage(err.Error())
+			}
+			pending = nil
 		}
 	}
 	wg.Wait()
 }
 
+type pendingDeploymentItem struct {
+	d      *deployment.DeploymentItem
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// failed is only valid after done got closed
+	failed bool
+}
+
+// waitForDependencies waits for all given dependencies to finish. It returns false if waiting got cancelled or if at
+// least one dependency failed, in which case an error is recorded for the dependent item.
+func (a *ApplyDeploymentsUtil) waitForDependencies(a2 *ApplyUtil, deps []*pendingDeploymentItem) bool {
+	for _, dep := range deps {
+		select {
+		case <-dep.done:
+		case <-a2.ctx.Done():
+			a2.HandleError(k8s2.ObjectRef{}, fmt.Errorf("failed waiting for dependencies: %w", a2.ctx.Err()))
+			return false
+		}
+		if dep.failed {
+			name := "<unnamed>"
+			if n := a.buildProgressName(dep.d); n != nil {
+				name = *n
+			}
+			a2.HandleError(k8s2.ObjectRef{}, fmt.Errorf("skipped because dependency %s failed", name))
+			return false
+		}
+	}
+	return true
+}
+
+// waitOnBarrier waits for all deployment items started since the previous barrier. Items started before the previous
+// barrier are already finished at this point. It returns false if BarrierTimeout is set and was exceeded.
+func (a *ApplyDeploymentsUtil) waitOnBarrier(pending []*pendingDeploymentItem) bool {
+	var timeoutCh <-chan time.Time
+	if a.o.BarrierTimeout > 0 {
+		timer := time.NewTimer(a.o.BarrierTimeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	for _, pd := range pending {
+		select {
+		case <-pd.done:
+		case <-timeoutCh:
+			return false
+		}
+	}
+	return true
+}
+
+// handleBarrierTimeout cancels all deployment items that did not finish before the barrier timed out, waits for them
+// to return and records an error for the barrier.
+func (a *ApplyDeploymentsUtil) handleBarrierTimeout(barrier *deployment.DeploymentItem, pending []*pendingDeploymentItem) error {
+	var stalled []string
+	var stalledItems []*pendingDeploymentItem
+	for _, pd := range pending {
+		select {
+		case <-pd.done:
+			continue
+		default:
+		}
+		name := "<unnamed>"
+		if n := a.buildProgressName(pd.d); n != nil {
+			name = *n
+		}
+		stalled = append(stalled, name)
+		stalledItems = append(stalledItems, pd)
+		pd.cancel()
+	}
+
+	// drain the cancelled items, so that they don't continue to run in parallel with the items after the barrier
+	for _, pd := range stalledItems {
+		<-pd.done
+	}
+
+	barrierName := "<barrier>"
+	if n := a.buildProgressName(barrier); n != nil {
+		barrierName = *n
+	}
+	if barrier.Config.Message != nil {
+		barrierName = fmt.Sprintf("%s (%s)", barrierName, *barrier.Config.Message)
+	}
+
+	err := fmt.Errorf("timed out after %s while waiting on barrier %s, stalled deployment items: %s", a.o.BarrierTimeout, barrierName, strings.Join(stalled, ", "))
+	status.Error(a.ctx, err.Error())
+
+	a.dew.AddError(k8s2.ObjectRef{}, err)
+	a.countAbortError()
+	return err
+}
+
 func (a *ApplyUtil) ReplaceObject(ref k8s2.ObjectRef, firstVersion *uo.UnstructuredObject, callback func(o *uo.UnstructuredObject) (*uo.UnstructuredObject, error)) {
 	firstCall := true
 	for true {
@@ -857,7 +1467,7 @@ func (a *ApplyUtil) ReplaceObject(ref k8s2.ObjectRef, firstVersion *uo.Unstructu
 			remote = firstVersion
 		} else {
 			o2, apiWarnings, err := a.k.GetSingleObject(ref)
-			a.dew.AddApiWarnings(ref, apiWarnings)
+			a.handleApiWarnings(ref, apiWarnings)
 			if err != nil && !errors.IsNotFound(err) {
 				a.HandleError(ref, err)
 				return
@@ -882,7 +1492,7 @@ func (a *ApplyUtil) ReplaceObject(ref k8s2.ObjectRef, firstVersion *uo.Unstructu
 		}
 
 		result, apiWarnings, err := a.k.UpdateObject(modified, k8s.UpdateOptions{})
-		a.dew.AddApiWarnings(ref, apiWarnings)
+		a.handleApiWarnings(ref, apiWarnings)
 		if err != nil {
 			if errors.IsConflict(err) {
 				status.Tracef(a.ctx, "Conflict while patching %s. Retrying...", ref.String())
@@ -950,6 +1560,21 @@ func (ad *ApplyDeploymentsUtil) GetAppliedHookObjects() []*uo.UnstructuredObject
 	})
 }
 
+// GetPreDeployHooksDone returns the deployment items for which pre-deploy hooks were run in the canary phase. The
+// result is meant to be passed as ApplyUtilOptions.SkipPreDeployHooks to the following deployment.
+func (ad *ApplyDeploymentsUtil) GetPreDeployHooksDone() map[*deployment.DeploymentItem]bool {
+	ad.resultsMutex.Lock()
+	defer ad.resultsMutex.Unlock()
+
+	ret := map[*deployment.DeploymentItem]bool{}
+	for _, a := range ad.results {
+		if a.preDeployHooksDone != nil {
+			ret[a.preDeployHooksDone] = true
+		}
+	}
+	return ret
+}
+
 func (ad *ApplyDeploymentsUtil) GetDeletedObjects() []k8s2.ObjectRef {
 	ad.resultsMutex.Lock()
 	defer ad.resultsMutex.Unlock()
@@ -0,0 +1,271 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/gobwas/glob"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestForceReplaceOnErrorKinds(t *testing.T) {
+	newApplyUtil := func(kinds []schema.GroupKind) (*ApplyUtil, *DeploymentErrorsAndWarnings) {
+		dew := NewDeploymentErrorsAndWarnings()
+		ru := NewRemoteObjectsUtil(context.TODO(), dew)
+		ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{
+			ForceReplaceOnError:      true,
+			ForceReplaceOnErrorKinds: kinds,
+		})
+		return ad.NewApplyUtil(context.TODO(), nil), dew
+	}
+
+	jobRef := k8s2.NewObjectRef("batch", "v1", "Job", "job", "ns")
+	pvcRef := k8s2.NewObjectRef("", "v1", "PersistentVolumeClaim", "pvc", "ns")
+
+	a, _ := newApplyUtil(nil)
+	assert.True(t, a.isForceReplaceAllowed(jobRef))
+	assert.True(t, a.isForceReplaceAllowed(pvcRef))
+
+	a, dew := newApplyUtil([]schema.GroupKind{schema.ParseGroupKind("Job.batch")})
+	assert.True(t, a.isForceReplaceAllowed(jobRef))
+	assert.False(t, a.isForceReplaceAllowed(pvcRef))
+
+	// must not try to delete the object, which would fail as no cluster is available
+	pvc := newTestConfigMap("pvc", nil, nil)
+	pvc.SetK8sGVKs("", "v1", "PersistentVolumeClaim")
+	a.retryApplyForceReplace(pvc, false, pvc, fmt.Errorf("replace failed"))
+	assert.Len(t, dew.GetErrorsList(), 1)
+	assert.Equal(t, "replace failed", dew.GetErrorsList()[0].Message)
+}
+
+func TestPreserveRemoteMetadata(t *testing.T) {
+	newApplyUtil := func(patterns ...string) *ApplyUtil {
+		var globs []glob.Glob
+		for _, p := range patterns {
+			globs = append(globs, glob.MustCompile(p))
+		}
+		dew := NewDeploymentErrorsAndWarnings()
+		ru := NewRemoteObjectsUtil(context.TODO(), dew)
+		ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{
+			ReplacePreserveMetadata: globs,
+		})
+		return ad.NewApplyUtil(context.TODO(), nil)
+	}
+
+	remote := newTestConfigMap("cm", nil, map[string]string{
+		"autoscaling.alpha.kubernetes.io/conditions": "remote",
+		"example.com/owned":                          "remote",
+		"other":                                      "remote",
+	})
+	remote.SetK8sLabel("controller.example.com/revision", "1")
+	remote.SetK8sLabel("other", "remote")
+
+	x := newTestConfigMap("cm", nil, map[string]string{"example.com/owned": "local"})
+	newApplyUtil().preserveRemoteMetadata(x, remote)
+	assert.Equal(t, map[string]string{"example.com/owned": "local"}, x.GetK8sAnnotations())
+	assert.Empty(t, x.GetK8sLabels())
+
+	x = newTestConfigMap("cm", nil, map[string]string{"example.com/owned": "local"})
+	newApplyUtil("autoscaling.alpha.kubernetes.io/*", "example.com/*", "controller.example.com/*").preserveRemoteMetadata(x, remote)
+	assert.Equal(t, map[string]string{
+		"autoscaling.alpha.kubernetes.io/conditions": "remote",
+		"example.com/owned":                          "local",
+	}, x.GetK8sAnnotations())
+	assert.Equal(t, map[string]string{"controller.example.com/revision": "1"}, x.GetK8sLabels())
+}
+
+func TestWarningsAsErrors(t *testing.T) {
+	newApplyUtil := func(o *ApplyUtilOptions) (*ApplyUtil, *DeploymentErrorsAndWarnings) {
+		dew := NewDeploymentErrorsAndWarnings()
+		ru := NewRemoteObjectsUtil(context.TODO(), dew)
+		ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, o)
+		return ad.NewApplyUtil(context.TODO(), nil), dew
+	}
+
+	ref := k8s2.NewObjectRef("policy", "v1beta1", "PodSecurityPolicy", "psp", "")
+	warnings := []k8s.ApiWarning{
+		{Text: "policy/v1beta1 PodSecurityPolicy is deprecated in v1.21+, unavailable in v1.25+"},
+		{Text: "unknown field \"spec.foo\""},
+	}
+
+	a, dew := newApplyUtil(&ApplyUtilOptions{})
+	a.handleApiWarnings(ref, warnings)
+	assert.Empty(t, dew.GetErrorsList())
+	assert.Len(t, dew.GetWarningsList(), 2)
+
+	a, dew = newApplyUtil(&ApplyUtilOptions{WarningsAsErrors: true})
+	a.handleApiWarnings(ref, warnings)
+	assert.Len(t, dew.GetErrorsList(), 2)
+	assert.Empty(t, dew.GetWarningsList())
+	assert.False(t, a.abortSignal.Load().(bool))
+
+	a, dew = newApplyUtil(&ApplyUtilOptions{
+		WarningsAsErrors:         true,
+		WarningsAsErrorsPatterns: []*regexp.Regexp{regexp.MustCompile("is deprecated")},
+		AbortOnError:             true,
+	})
+	a.handleApiWarnings(ref, warnings)
+	assert.Len(t, dew.GetErrorsList(), 1)
+	assert.Equal(t, warnings[0].Text, dew.GetErrorsList()[0].Message)
+	assert.Len(t, dew.GetWarningsList(), 1)
+	assert.Equal(t, warnings[1].Text, dew.GetWarningsList()[0].Message)
+	assert.True(t, a.abortSignal.Load().(bool))
+}
+
+func TestApplyLabelSelector(t *testing.T) {
+	newApplyUtil := func(selector labels.Selector) *ApplyUtil {
+		dew := NewDeploymentErrorsAndWarnings()
+		ru := NewRemoteObjectsUtil(context.TODO(), dew)
+		ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{ApplyLabelSelector: selector})
+		return ad.NewApplyUtil(context.TODO(), nil)
+	}
+
+	critical := newTestConfigMap("critical", nil, nil)
+	critical.SetK8sLabel("tier", "critical")
+	other := newTestConfigMap("other", nil, nil)
+
+	a := newApplyUtil(nil)
+	assert.True(t, a.matchesApplyLabelSelector(critical))
+	assert.True(t, a.matchesApplyLabelSelector(other))
+
+	a = newApplyUtil(labels.SelectorFromSet(labels.Set{"tier": "critical"}))
+	assert.True(t, a.matchesApplyLabelSelector(critical))
+	assert.False(t, a.matchesApplyLabelSelector(other))
+
+	hooks := a.filterHooksByApplyLabelSelector([]*hook{{object: critical}, {object: other}})
+	assert.Len(t, hooks, 1)
+	assert.Equal(t, critical, hooks[0].object)
+	assert.Equal(t, 1, a.selectorSkipCount)
+}
+
+func TestAbortAfterErrors(t *testing.T) {
+	newApplyDeploymentsUtil := func(abortOnError bool, abortAfterErrors int) *ApplyDeploymentsUtil {
+		dew := NewDeploymentErrorsAndWarnings()
+		ru := NewRemoteObjectsUtil(context.TODO(), dew)
+		return NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{
+			AbortOnError:     abortOnError,
+			AbortAfterErrors: abortAfterErrors,
+		})
+	}
+	ref := k8s2.NewObjectRef("", "v1", "ConfigMap", "cm", "default")
+
+	ad := newApplyDeploymentsUtil(false, 0)
+	a := ad.NewApplyUtil(context.TODO(), nil)
+	for i := 0; i < 10; i++ {
+		a.HandleError(ref, fmt.Errorf("e%d", i))
+	}
+	assert.False(t, ad.abortSignal.Load().(bool))
+
+	ad = newApplyDeploymentsUtil(true, 0)
+	a = ad.NewApplyUtil(context.TODO(), nil)
+	a.HandleError(ref, fmt.Errorf("e1"))
+	assert.True(t, ad.abortSignal.Load().(bool))
+
+	// errors of all ApplyUtil instances are counted together
+	ad = newApplyDeploymentsUtil(false, 3)
+	a1 := ad.NewApplyUtil(context.TODO(), nil)
+	a2 := ad.NewApplyUtil(context.TODO(), nil)
+	a1.HandleError(ref, fmt.Errorf("e1"))
+	a2.HandleError(ref, fmt.Errorf("e2"))
+	assert.False(t, ad.abortSignal.Load().(bool))
+	a1.HandleError(ref, fmt.Errorf("e3"))
+	assert.True(t, ad.abortSignal.Load().(bool))
+}
+
+func TestFailOnApiDeprecation(t *testing.T) {
+	newApplyUtil := func(o *ApplyUtilOptions) (*ApplyUtil, *DeploymentErrorsAndWarnings) {
+		dew := NewDeploymentErrorsAndWarnings()
+		ru := NewRemoteObjectsUtil(context.TODO(), dew)
+		ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, o)
+		return ad.NewApplyUtil(context.TODO(), nil), dew
+	}
+
+	pspRef := k8s2.ObjectRef{Group: "policy", Version: "v1beta1", Kind: "PodSecurityPolicy", Name: "psp"}
+	cronJobRef := k8s2.ObjectRef{Group: "batch", Version: "v1beta1", Kind: "CronJob", Name: "cj", Namespace: "ns"}
+	cmRef := k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm", Namespace: "ns"}
+
+	pspWarning := k8s.ApiWarning{Code: 299, Text: "policy/v1beta1 PodSecurityPolicy is deprecated in v1.21+, unavailable in v1.25+"}
+	cronJobWarning := k8s.ApiWarning{Code: 299, Text: "batch/v1beta1 CronJob is deprecated in v1.21+, unavailable in v1.25+; use batch/v1 CronJob"}
+	otherWarning := k8s.ApiWarning{Code: 299, Text: "some other warning"}
+
+	handle := func(a *ApplyUtil) {
+		a.handleApiWarnings(pspRef, []k8s.ApiWarning{pspWarning})
+		a.handleApiWarnings(cronJobRef, []k8s.ApiWarning{cronJobWarning})
+		a.handleApiWarnings(cmRef, []k8s.ApiWarning{otherWarning})
+	}
+
+	a, dew := newApplyUtil(&ApplyUtilOptions{})
+	handle(a)
+	assert.Len(t, dew.GetErrorsList(), 0)
+	assert.Len(t, dew.GetWarningsList(), 3)
+
+	a, dew = newApplyUtil(&ApplyUtilOptions{FailOnApiDeprecation: true})
+	handle(a)
+	assert.Len(t, dew.GetErrorsList(), 2)
+	assert.Equal(t, []result.DeploymentError{{Ref: cmRef, Message: otherWarning.Text}}, dew.GetWarningsList())
+	assert.False(t, a.abortSignal.Load().(bool))
+
+	// deprecation errors are handled like all other errors, so AbortOnError is honored
+	a, dew = newApplyUtil(&ApplyUtilOptions{FailOnApiDeprecation: true, FailOnApiDeprecationGroups: []string{"batch"}, AbortOnError: true})
+	handle(a)
+	assert.Equal(t, []result.DeploymentError{{Ref: cronJobRef, Message: cronJobWarning.Text}}, dew.GetErrorsList())
+	assert.Len(t, dew.GetWarningsList(), 2)
+	assert.True(t, a.abortSignal.Load().(bool))
+
+	a, dew = newApplyUtil(&ApplyUtilOptions{FailOnApiDeprecation: true, FailOnApiDeprecationGroups: []string{"core"}})
+	handle(a)
+	assert.Empty(t, dew.GetErrorsList())
+	assert.Len(t, dew.GetWarningsList(), 3)
+}
+
+func TestBarrierTimeout(t *testing.T) {
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{
+		BarrierTimeout: 50 * time.Millisecond,
+	})
+
+	newPending := func(name string) *pendingDeploymentItem {
+		ctx, cancel := context.WithCancel(context.TODO())
+		pd := &pendingDeploymentItem{
+			d:      &deployment.DeploymentItem{RelToProjectItemDir: name, Config: &types.DeploymentItemConfig{}},
+			cancel: cancel,
+			done:   make(chan struct{}),
+		}
+		go func() {
+			<-ctx.Done()
+			close(pd.done)
+		}()
+		return pd
+	}
+
+	finished := newPending("finished")
+	finished.cancel()
+	<-finished.done
+	assert.True(t, ad.waitOnBarrier([]*pendingDeploymentItem{finished}))
+
+	stalled := newPending("stalled")
+	pending := []*pendingDeploymentItem{finished, stalled}
+	assert.False(t, ad.waitOnBarrier(pending))
+
+	barrier := &deployment.DeploymentItem{RelToProjectItemDir: "barrier", Config: &types.DeploymentItemConfig{}}
+	err := ad.handleBarrierTimeout(barrier, pending)
+	assert.EqualError(t, err, "timed out after 50ms while waiting on barrier barrier, stalled deployment items: stalled")
+
+	// the stalled item must have been cancelled and drained
+	select {
+	case <-stalled.done:
+	default:
+		assert.Fail(t, "stalled item was not drained")
+	}
+	assert.Len(t, dew.GetErrorsList(), 1)
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"hash/fnv"
+	"math"
+	"sort"
+)
+
+func isCanaryEligible(o *uo.UnstructuredObject) bool {
+	if o.GetK8sAnnotationBoolNoError("kluctl.io/delete", false) {
+		return false
+	}
+	if o.GetK8sAnnotation("kluctl.io/hook") != nil || o.GetK8sAnnotation("helm.sh/hook") != nil {
+		return false
+	}
+	return true
+}
+
+func canaryHash(ref k8s2.ObjectRef) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(ref.String()))
+	return h.Sum32()
+}
+
+// SelectCanaryObjects selects a deterministic subset of all eligible objects (hooks and objects marked for deletion
+// are not eligible). Selection is based on a hash of the object ref, so that the same set of objects always results
+// in the same subset being selected.
+func SelectCanaryObjects(deployments []*deployment.DeploymentItem, percent int) map[k8s2.ObjectRef]bool {
+	var refs []k8s2.ObjectRef
+	seen := map[k8s2.ObjectRef]bool{}
+	for _, d := range deployments {
+		if d.Config.OnlyRender {
+			continue
+		}
+		for _, o := range d.Objects {
+			if !isCanaryEligible(o) {
+				continue
+			}
+			ref := o.GetK8sRef()
+			if _, ok := seen[ref]; ok {
+				continue
+			}
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	sort.SliceStable(refs, func(i, j int) bool {
+		hi, hj := canaryHash(refs[i]), canaryHash(refs[j])
+		if hi != hj {
+			return hi < hj
+		}
+		return refs[i].Less(refs[j])
+	})
+
+	cnt := int(math.Ceil(float64(len(refs)) * float64(percent) / 100))
+	if cnt > len(refs) {
+		cnt = len(refs)
+	}
+
+	ret := make(map[k8s2.ObjectRef]bool, cnt)
+	for _, ref := range refs[:cnt] {
+		ret[ref] = true
+	}
+	return ret
+}
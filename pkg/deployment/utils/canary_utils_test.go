@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func buildCanaryTestDeployments(reverse bool) []*deployment.DeploymentItem {
+	var objects []*uo.UnstructuredObject
+	for i := 0; i < 20; i++ {
+		objects = append(objects, newTestConfigMap(fmt.Sprintf("cm%d", i), nil, nil))
+	}
+	objects = append(objects, newTestConfigMap("hook", nil, map[string]string{"kluctl.io/hook": "pre-deploy"}))
+	objects = append(objects, newTestConfigMap("deleted", nil, map[string]string{"kluctl.io/delete": "true"}))
+	if reverse {
+		for i, j := 0, len(objects)-1; i < j; i, j = i+1, j-1 {
+			objects[i], objects[j] = objects[j], objects[i]
+		}
+	}
+
+	return []*deployment.DeploymentItem{
+		{Config: &types.DeploymentItemConfig{}, Objects: objects[:10]},
+		{Config: &types.DeploymentItemConfig{}, Objects: objects[10:]},
+		{Config: &types.DeploymentItemConfig{OnlyRender: true}, Objects: []*uo.UnstructuredObject{newTestConfigMap("only-render", nil, nil)}},
+	}
+}
+
+func TestSelectCanaryObjectsDeterministic(t *testing.T) {
+	s1 := SelectCanaryObjects(buildCanaryTestDeployments(false), 25)
+	s2 := SelectCanaryObjects(buildCanaryTestDeployments(false), 25)
+	s3 := SelectCanaryObjects(buildCanaryTestDeployments(true), 25)
+
+	assert.Len(t, s1, 5)
+	assert.Equal(t, s1, s2)
+	// the order of objects must not influence the selection
+	assert.Equal(t, s1, s3)
+
+	for ref := range s1 {
+		assert.NotContains(t, []string{"hook", "deleted", "only-render"}, ref.Name)
+	}
+}
+
+func TestSelectCanaryObjectsPercent(t *testing.T) {
+	deployments := buildCanaryTestDeployments(false)
+
+	assert.Len(t, SelectCanaryObjects(deployments, 1), 1)
+	assert.Len(t, SelectCanaryObjects(deployments, 100), 20)
+
+	// a larger percentage must always include the smaller selection
+	s10 := SelectCanaryObjects(deployments, 10)
+	s50 := SelectCanaryObjects(deployments, 50)
+	for ref := range s10 {
+		assert.Contains(t, s50, ref)
+	}
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"sort"
+	"strings"
+)
+
+type ConfirmDecision int
+
+const (
+	ConfirmProceed ConfirmDecision = iota
+	ConfirmSkip
+	ConfirmAbort
+)
+
+// ConfirmDeploymentItemCallback is invoked before a deployment item gets applied. All previously started deployment
+// items have finished at this point, so the callback can safely prompt the user. The summary describes the objects
+// of the deployment item, see DescribeDeploymentItemObjects.
+type ConfirmDeploymentItemCallback func(name string, summary string) (ConfirmDecision, error)
+
+// DescribeDeploymentItemObjects returns a short summary about the objects of a deployment item, e.g.
+// "3 objects (2 ConfigMap, 1 Deployment.apps)".
+func DescribeDeploymentItemObjects(d *deployment.DeploymentItem) string {
+	counts := map[string]int{}
+	for _, o := range d.Objects {
+		counts[o.GetK8sRef().GroupKind().String()]++
+	}
+	kinds := make([]string, 0, len(counts))
+	for k := range counts {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+
+	var parts []string
+	for _, k := range kinds {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[k], k))
+	}
+
+	s := fmt.Sprintf("%d objects", len(d.Objects))
+	if len(parts) != 0 {
+		s += fmt.Sprintf(" (%s)", strings.Join(parts, ", "))
+	}
+	if len(d.Config.DeleteObjects) != 0 {
+		s += fmt.Sprintf(", deleting %d object selectors", len(d.Config.DeleteObjects))
+	}
+	return s
+}
+
+// confirmDeploymentItem returns false if the deployment item must not be applied. It also sets the abort signal if
+// the user decided to abort.
+func (a *ApplyDeploymentsUtil) confirmDeploymentItem(d *deployment.DeploymentItem) bool {
+	if len(d.Objects) == 0 && len(d.Config.DeleteObjects) == 0 {
+		return true
+	}
+
+	name := "<unnamed>"
+	if n := a.buildProgressName(d); n != nil {
+		name = *n
+	}
+
+	decision, err := a.o.ConfirmDeploymentItem(name, DescribeDeploymentItemObjects(d))
+	if err != nil {
+		a.dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("failed to confirm deployment item %s: %w", name, err))
+		a.abortSignal.Store(true)
+		return false
+	}
+
+	switch decision {
+	case ConfirmSkip:
+		a.dew.AddWarning(k8s2.ObjectRef{}, fmt.Errorf("deployment item %s was skipped on request", name))
+		return false
+	case ConfirmAbort:
+		a.dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("aborted on request before applying deployment item %s", name))
+		a.abortSignal.Store(true)
+		return false
+	default:
+		return true
+	}
+}
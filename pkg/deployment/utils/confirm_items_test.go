@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newConfirmTestItem(dir string) *deployment.DeploymentItem {
+	deploy := uo.New()
+	deploy.SetK8sGVKs("apps", "v1", "Deployment")
+	deploy.SetK8sName("d1")
+
+	return &deployment.DeploymentItem{
+		RelToProjectItemDir: dir,
+		Config:              &types.DeploymentItemConfig{},
+		Objects: []*uo.UnstructuredObject{
+			newTestConfigMap("cm1", nil, nil),
+			deploy,
+			newTestConfigMap("cm2", nil, nil),
+		},
+	}
+}
+
+func TestDescribeDeploymentItemObjects(t *testing.T) {
+	d := newConfirmTestItem("item")
+	assert.Equal(t, "3 objects (2 ConfigMap, 1 Deployment.apps)", DescribeDeploymentItemObjects(d))
+
+	d.Objects = nil
+	assert.Equal(t, "0 objects", DescribeDeploymentItemObjects(d))
+}
+
+func TestConfirmDeploymentItem(t *testing.T) {
+	newUtil := func(decision ConfirmDecision) (*ApplyDeploymentsUtil, *DeploymentErrorsAndWarnings, *[]string) {
+		var asked []string
+		dew := NewDeploymentErrorsAndWarnings()
+		ru := NewRemoteObjectsUtil(context.TODO(), dew)
+		ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{
+			ConfirmDeploymentItem: func(name string, summary string) (ConfirmDecision, error) {
+				asked = append(asked, name+": "+summary)
+				return decision, nil
+			},
+		})
+		return ad, dew, &asked
+	}
+
+	ad, dew, asked := newUtil(ConfirmProceed)
+	assert.True(t, ad.confirmDeploymentItem(newConfirmTestItem("item")))
+	assert.Equal(t, []string{"item: 3 objects (2 ConfigMap, 1 Deployment.apps)"}, *asked)
+	assert.Empty(t, dew.GetErrorsList())
+	assert.Empty(t, dew.GetWarningsList())
+
+	// items without objects are not confirmed
+	ad, _, asked = newUtil(ConfirmAbort)
+	assert.True(t, ad.confirmDeploymentItem(&deployment.DeploymentItem{Config: &types.DeploymentItemConfig{}}))
+	assert.Empty(t, *asked)
+
+	ad, dew, _ = newUtil(ConfirmSkip)
+	assert.False(t, ad.confirmDeploymentItem(newConfirmTestItem("item")))
+	assert.False(t, ad.abortSignal.Load().(bool))
+	assert.Empty(t, dew.GetErrorsList())
+	assert.Len(t, dew.GetWarningsList(), 1)
+
+	ad, dew, _ = newUtil(ConfirmAbort)
+	assert.False(t, ad.confirmDeploymentItem(newConfirmTestItem("item")))
+	assert.True(t, ad.abortSignal.Load().(bool))
+	assert.Len(t, dew.GetErrorsList(), 1)
+}
@@ -101,6 +101,27 @@ func isManagedByKluctl(o *uo.UnstructuredObject) bool {
 	return true
 }
 
+// isExcludedFromDelete returns true if the object must never be deleted, either because it was explicitly marked or
+// because it is not managed by kluctl
+func isExcludedFromDelete(o *uo.UnstructuredObject, inclusionHasTags bool) bool {
+	// exclude when explicitly requested
+	if isSkipDelete(o) {
+		return true
+	}
+
+	if !isManagedByKluctl(o) {
+		return true
+	}
+
+	// exclude resources which have the 'kluctl.io/skip-delete-if-tags' annotation set
+	if inclusionHasTags {
+		if o.GetK8sAnnotationBoolNoError("kluctl.io/skip-delete-if-tags", false) {
+			return true
+		}
+	}
+	return false
+}
+
 func filterObjectsForDelete(k *k8s.K8sCluster, objects []*uo.UnstructuredObject, apiFilter []string, inclusionHasTags bool, excludedObjects map[k8s2.ObjectRef]bool) ([]*uo.UnstructuredObject, error) {
 	filterFunc := func(ar *v1.APIResource) bool {
 		if len(apiFilter) == 0 {
@@ -135,12 +156,7 @@ func filterObjectsForDelete(k *k8s.K8sCluster, objects []*uo.UnstructuredObject,
 			continue
 		}
 
-		// exclude when explicitly requested
-		if isSkipDelete(o) {
-			continue
-		}
-
-		if !isManagedByKluctl(o) {
+		if isExcludedFromDelete(o, inclusionHasTags) {
 			continue
 		}
 
@@ -149,23 +165,28 @@ func filterObjectsForDelete(k *k8s.K8sCluster, objects []*uo.UnstructuredObject,
 			continue
 		}
 
-		// exclude resources which have the 'kluctl.io/skip-delete-if-tags' annotation set
-		if inclusionHasTags {
-			if o.GetK8sAnnotationBoolNoError("kluctl.io/skip-delete-if-tags", false) {
-				continue
-			}
-		}
-
 		ret = append(ret, o)
 	}
 	return ret, nil
 }
 
-func FindObjectsForDelete(k *k8s.K8sCluster, allClusterObjects []*uo.UnstructuredObject, inclusionHasTags bool, excludedObjects []k8s2.ObjectRef) ([]k8s2.ObjectRef, error) {
+// FindObjectsForDelete returns the refs of all objects from allClusterObjects that may be deleted, in the order they
+// should be deleted. Only objects included by all the given inclusions are considered, so that every caller (deploy,
+// prune, delete) applies the same scoping. Objects marked with 'kluctl.io/skip-delete-if-tags' are skipped if any of
+// the inclusions filters by tags.
+func FindObjectsForDelete(k *k8s.K8sCluster, allClusterObjects []*uo.UnstructuredObject, inclusions []*utils.Inclusion, excludedObjects []k8s2.ObjectRef) ([]k8s2.ObjectRef, error) {
 	if k == nil {
 		return nil, fmt.Errorf("can not determine orphan objects without a Kubernetes API client")
 	}
 
+	inclusionHasTags := false
+	for _, inclusion := range inclusions {
+		if inclusion.HasType("tag") {
+			inclusionHasTags = true
+		}
+	}
+	allClusterObjects = filterObjectsByInclusions(allClusterObjects, inclusions...)
+
 	excludedObjectsMap := make(map[k8s2.ObjectRef]bool)
 	for _, ref := range excludedObjects {
 		excludedObjectsMap[objectRefForExclusion(k, ref)] = true
@@ -188,6 +209,16 @@ func FindObjectsForDelete(k *k8s.K8sCluster, allClusterObjects []*uo.Unstructure
 	return ret, nil
 }
 
+func deleteObject(k *k8s.K8sCluster, ref k8s2.ObjectRef, doWait bool) ([]k8s.ApiWarning, error) {
+	if k.DryRun {
+		return nil, nil
+	}
+	return k.DeleteSingleObject(ref, k8s.DeleteOptions{NoWait: !doWait, IgnoreNotFoundError: true})
+}
+
+// DeleteObjects deletes the given objects and returns the refs of all deleted objects. In dry-run mode, no delete
+// requests are sent to the cluster at all and all objects are reported as deleted, so that the result is a faithful
+// preview of what would be deleted.
 func DeleteObjects(ctx context.Context, k *k8s.K8sCluster, refs []k8s2.ObjectRef, dew *DeploymentErrorsAndWarnings, doWait bool) []k8s2.ObjectRef {
 	g := utils.NewGoHelper(ctx, 8)
 
@@ -212,7 +243,7 @@ func DeleteObjects(ctx context.Context, k *k8s.K8sCluster, refs []k8s2.ObjectRef
 		if ref.GroupVersion().String() == "v1" && ref.Kind == "Namespace" {
 			namespaceNames[ref.Name] = true
 			g.Run(func() {
-				apiWarnings, err := k.DeleteSingleObject(ref, k8s.DeleteOptions{NoWait: !doWait, IgnoreNotFoundError: true})
+				apiWarnings, err := deleteObject(k, ref, doWait)
 				handleResult(ref, apiWarnings, err)
 			})
 		}
@@ -229,7 +260,7 @@ func DeleteObjects(ctx context.Context, k *k8s.K8sCluster, refs []k8s2.ObjectRef
 			continue
 		}
 		g.Run(func() {
-			apiWarnings, err := k.DeleteSingleObject(ref, k8s.DeleteOptions{NoWait: !doWait, IgnoreNotFoundError: true})
+			apiWarnings, err := deleteObject(k, ref, doWait)
 			handleResult(ref, apiWarnings, err)
 		})
 	}
@@ -237,3 +268,24 @@ func DeleteObjects(ctx context.Context, k *k8s.K8sCluster, refs []k8s2.ObjectRef
 
 	return ret
 }
+
+// DeleteObjectsInOrder deletes the given objects one after another, in exactly the given order. In contrast to
+// DeleteObjects, Namespaces are not treated specially, so objects inside a deleted Namespace are still deleted
+// explicitly. Dry-run is handled the same way as in DeleteObjects.
+func DeleteObjectsInOrder(ctx context.Context, k *k8s.K8sCluster, refs []k8s2.ObjectRef, dew *DeploymentErrorsAndWarnings, doWait bool) []k8s2.ObjectRef {
+	var ret []k8s2.ObjectRef
+	for _, ref := range refs {
+		if ctx.Err() != nil {
+			dew.AddError(ref, ctx.Err())
+			break
+		}
+		apiWarnings, err := deleteObject(k, ref, doWait)
+		if err == nil {
+			ret = append(ret, ref)
+		} else {
+			dew.AddError(ref, err)
+		}
+		dew.AddApiWarnings(ref, apiWarnings)
+	}
+	return ret
+}
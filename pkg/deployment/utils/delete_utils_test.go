@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeleteObjectsDryRun(t *testing.T) {
+	// the cluster has no clients, so any delete request would panic
+	k := &k8s.K8sCluster{DryRun: true}
+	dew := NewDeploymentErrorsAndWarnings()
+
+	refs := []k8s2.ObjectRef{
+		{Version: "v1", Kind: "Namespace", Name: "ns1"},
+		{Version: "v1", Kind: "ConfigMap", Name: "cm1", Namespace: "ns1"},
+		{Version: "v1", Kind: "ConfigMap", Name: "cm2", Namespace: "ns2"},
+		{Group: "apps", Version: "v1", Kind: "Deployment", Name: "d1", Namespace: "ns2"},
+	}
+
+	deleted := DeleteObjects(context.TODO(), k, refs, dew, true)
+	assert.ElementsMatch(t, []k8s2.ObjectRef{refs[0], refs[2], refs[3]}, deleted)
+	assert.Empty(t, dew.GetErrorsList())
+}
+
+func newTestPruneObject(name string, itemDir string, tags []string, annotations map[string]string) *uo.UnstructuredObject {
+	o := newTestConfigMap(name, nil, annotations)
+	o.SetK8sAnnotation("kluctl.io/deployment-item-dir", itemDir)
+	for i, t := range tags {
+		o.SetK8sLabel(fmt.Sprintf("kluctl.io/tag-%d", i), t)
+	}
+	_ = o.SetNestedField([]any{map[string]any{"manager": "kluctl"}}, "metadata", "managedFields")
+	return o
+}
+
+func TestFilterObjectsByInclusions(t *testing.T) {
+	objects := []*uo.UnstructuredObject{
+		newTestPruneObject("a1", "a", []string{"a", "common"}, nil),
+		newTestPruneObject("a2", "a", []string{"a", "common", "keep"}, nil),
+		newTestPruneObject("b1", "b", []string{"b", "common"}, nil),
+		newTestPruneObject("c1", "c", []string{"c"}, nil),
+	}
+	names := func(l []*uo.UnstructuredObject) []string {
+		var ret []string
+		for _, o := range l {
+			ret = append(ret, o.GetK8sName())
+		}
+		return ret
+	}
+
+	inclusion := utils.NewInclusion()
+	inclusion.AddInclude("tag", "common")
+
+	pruneInclusion := utils.NewInclusion()
+	pruneInclusion.AddInclude("deploymentItemDir", "a")
+	pruneInclusion.AddInclude("deploymentItemDir", "c")
+	pruneInclusion.AddExclude("tag", "keep")
+
+	assert.Equal(t, []string{"a1", "a2", "b1", "c1"}, names(filterObjectsByInclusions(objects)))
+	assert.Equal(t, []string{"a1", "a2", "b1", "c1"}, names(filterObjectsByInclusions(objects, nil, nil)))
+	assert.Equal(t, []string{"a1", "a2", "b1"}, names(filterObjectsByInclusions(objects, inclusion)))
+	assert.Equal(t, []string{"a1", "c1"}, names(filterObjectsByInclusions(objects, nil, pruneInclusion)))
+	// objects out of scope of any of the inclusions must never be selected
+	assert.Equal(t, []string{"a1"}, names(filterObjectsByInclusions(objects, inclusion, pruneInclusion)))
+}
+
+func TestIsExcludedFromDelete(t *testing.T) {
+	assert.False(t, isExcludedFromDelete(newTestPruneObject("o", "a", nil, nil), false))
+	assert.True(t, isExcludedFromDelete(newTestPruneObject("o", "a", nil, map[string]string{"kluctl.io/skip-delete": "true"}), false))
+	assert.True(t, isExcludedFromDelete(newTestPruneObject("o", "a", nil, map[string]string{"helm.sh/resource-policy": "keep"}), false))
+	assert.True(t, isExcludedFromDelete(newTestConfigMap("o", nil, nil), false))
+
+	o := newTestPruneObject("o", "a", nil, map[string]string{"kluctl.io/skip-delete-if-tags": "true"})
+	assert.False(t, isExcludedFromDelete(o, false))
+	assert.True(t, isExcludedFromDelete(o, true))
+}
+
+func TestFindObjectsForDeleteRequiresClient(t *testing.T) {
+	_, err := FindObjectsForDelete(nil, nil, nil, nil)
+	assert.Error(t, err)
+}
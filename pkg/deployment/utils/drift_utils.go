@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+const RenderedChecksumAnnotation = "kluctl.io/rendered-checksum"
+
+// CalcRenderedChecksum calculates the checksum of the rendered (desired) state of the given object. The checksum
+// annotation itself is excluded from the calculation.
+func CalcRenderedChecksum(o *uo.UnstructuredObject) (string, error) {
+	o = o.Clone()
+	o.RemoveK8sAnnotation(RenderedChecksumAnnotation)
+
+	j, err := yaml.WriteJsonString(o)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256([]byte(j))
+	return hex.EncodeToString(h[:]), nil
+}
+
+type DriftStatus string
+
+const (
+	// DriftStatusUnknown means that the remote object was never stamped with a rendered checksum
+	DriftStatusUnknown DriftStatus = "unknown"
+	// DriftStatusOutdated means that the desired state changed since the last deployment
+	DriftStatusOutdated DriftStatus = "outdated"
+	DriftStatusDrifted  DriftStatus = "drifted"
+	DriftStatusInSync   DriftStatus = "in-sync"
+)
+
+// CheckDrift compares the remote object against the locally rendered object. When the rendered checksum stored on the
+// remote object matches the local one, all fields found in the local object are compared against the remote object.
+// Fields only present in the remote object (e.g. defaults set by the api server) are ignored.
+func CheckDrift(local *uo.UnstructuredObject, remote *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig) (DriftStatus, []result.Change, error) {
+	remoteChecksum := remote.GetK8sAnnotation(RenderedChecksumAnnotation)
+	if remoteChecksum == nil {
+		return DriftStatusUnknown, nil, nil
+	}
+	localChecksum, err := CalcRenderedChecksum(local)
+	if err != nil {
+		return "", nil, err
+	}
+	if localChecksum != *remoteChecksum {
+		return DriftStatusOutdated, nil, nil
+	}
+
+	nlo, err := diff.NormalizeObject(local, ignoreForDiffs, local)
+	if err != nil {
+		return "", nil, err
+	}
+	nro, err := diff.NormalizeObject(remote, ignoreForDiffs, local)
+	if err != nil {
+		return "", nil, err
+	}
+	changes, err := diff.Diff(nro, nlo)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var drifted []result.Change
+	for _, c := range changes {
+		if c.Type == "delete" {
+			// only present in the remote object, so not managed by us
+			continue
+		}
+		drifted = append(drifted, c)
+	}
+	if len(drifted) != 0 {
+		return DriftStatusDrifted, drifted, nil
+	}
+	return DriftStatusInSync, nil, nil
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCalcRenderedChecksum(t *testing.T) {
+	o1 := newTestConfigMap("cm", map[string]interface{}{"a": "1"}, nil)
+	o2 := newTestConfigMap("cm", map[string]interface{}{"a": "2"}, nil)
+
+	c1, err := CalcRenderedChecksum(o1)
+	assert.NoError(t, err)
+	c2, err := CalcRenderedChecksum(o2)
+	assert.NoError(t, err)
+	assert.NotEqual(t, c1, c2)
+
+	// the checksum annotation itself must not influence the checksum
+	o3 := o1.Clone()
+	o3.SetK8sAnnotation(RenderedChecksumAnnotation, "dummy")
+	c3, err := CalcRenderedChecksum(o3)
+	assert.NoError(t, err)
+	assert.Equal(t, c1, c3)
+	assert.Equal(t, "dummy", *o3.GetK8sAnnotation(RenderedChecksumAnnotation))
+}
+
+func TestCheckDrift(t *testing.T) {
+	local := newTestConfigMap("cm", map[string]interface{}{"a": "1"}, nil)
+	checksum, err := CalcRenderedChecksum(local)
+	assert.NoError(t, err)
+
+	stamp := func(data map[string]interface{}, checksum string) *uo.UnstructuredObject {
+		o := newTestConfigMap("cm", data, nil)
+		o.SetK8sAnnotation(RenderedChecksumAnnotation, checksum)
+		return o
+	}
+
+	s, changes, err := CheckDrift(local, newTestConfigMap("cm", map[string]interface{}{"a": "1"}, nil), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DriftStatusUnknown, s)
+	assert.Empty(t, changes)
+
+	s, changes, err = CheckDrift(local, stamp(map[string]interface{}{"a": "1"}, "other"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DriftStatusOutdated, s)
+	assert.Empty(t, changes)
+
+	s, changes, err = CheckDrift(local, stamp(map[string]interface{}{"a": "1"}, checksum), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DriftStatusInSync, s)
+	assert.Empty(t, changes)
+
+	// fields only present in the remote object are not managed by us
+	s, changes, err = CheckDrift(local, stamp(map[string]interface{}{"a": "1", "b": "2"}, checksum), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DriftStatusInSync, s)
+	assert.Empty(t, changes)
+
+	s, changes, err = CheckDrift(local, stamp(map[string]interface{}{"a": "x"}, checksum), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DriftStatusDrifted, s)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, "data.a", changes[0].JsonPath)
+	}
+}
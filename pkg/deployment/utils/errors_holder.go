@@ -7,12 +7,14 @@ import (
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"sort"
 	"sync"
 )
 
 type DeploymentErrorsAndWarnings struct {
-	errors   map[k8s.ObjectRef]map[result.DeploymentError]bool
-	warnings map[k8s.ObjectRef]map[result.DeploymentError]bool
+	// errors and warnings map object refs to the set of messages recorded for the object
+	errors   map[k8s.ObjectRef]map[string]bool
+	warnings map[k8s.ObjectRef]map[string]bool
 	mutex    sync.Mutex
 }
 
@@ -25,8 +27,8 @@ func NewDeploymentErrorsAndWarnings() *DeploymentErrorsAndWarnings {
 func (dew *DeploymentErrorsAndWarnings) Init() {
 	dew.mutex.Lock()
 	defer dew.mutex.Unlock()
-	dew.warnings = map[k8s.ObjectRef]map[result.DeploymentError]bool{}
-	dew.errors = map[k8s.ObjectRef]map[result.DeploymentError]bool{}
+	dew.warnings = map[k8s.ObjectRef]map[string]bool{}
+	dew.errors = map[k8s.ObjectRef]map[string]bool{}
 }
 
 func (dew *DeploymentErrorsAndWarnings) Clone() *DeploymentErrorsAndWarnings {
@@ -45,33 +47,25 @@ func (dew *DeploymentErrorsAndWarnings) Clone() *DeploymentErrorsAndWarnings {
 }
 
 func (dew *DeploymentErrorsAndWarnings) AddWarning(ref k8s.ObjectRef, warning error) {
-	de := result.DeploymentError{
-		Ref:     ref,
-		Message: warning.Error(),
-	}
 	dew.mutex.Lock()
 	defer dew.mutex.Unlock()
 	m, ok := dew.warnings[ref]
 	if !ok {
-		m = make(map[result.DeploymentError]bool)
+		m = make(map[string]bool)
 		dew.warnings[ref] = m
 	}
-	m[de] = true
+	m[warning.Error()] = true
 }
 
 func (dew *DeploymentErrorsAndWarnings) AddError(ref k8s.ObjectRef, err error) {
-	de := result.DeploymentError{
-		Ref:     ref,
-		Message: err.Error(),
-	}
 	dew.mutex.Lock()
 	defer dew.mutex.Unlock()
 	m, ok := dew.errors[ref]
 	if !ok {
-		m = make(map[result.DeploymentError]bool)
+		m = make(map[string]bool)
 		dew.errors[ref] = m
 	}
-	m[de] = true
+	m[err.Error()] = true
 }
 
 func (dew *DeploymentErrorsAndWarnings) AddApiWarnings(ref k8s.ObjectRef, warnings []k8s2.ApiWarning) {
@@ -91,9 +85,9 @@ func (dew *DeploymentErrorsAndWarnings) GetErrorsList() []result.DeploymentError
 	dew.mutex.Lock()
 	defer dew.mutex.Unlock()
 	var ret []result.DeploymentError
-	for _, m := range dew.errors {
-		for e := range m {
-			ret = append(ret, e)
+	for ref, m := range dew.errors {
+		for msg := range m {
+			ret = append(ret, result.DeploymentError{Ref: ref, Message: msg})
 		}
 	}
 	return ret
@@ -103,14 +97,73 @@ func (dew *DeploymentErrorsAndWarnings) GetWarningsList() []result.DeploymentErr
 	dew.mutex.Lock()
 	defer dew.mutex.Unlock()
 	var ret []result.DeploymentError
-	for _, m := range dew.warnings {
-		for e := range m {
-			ret = append(ret, e)
+	for ref, m := range dew.warnings {
+		for msg := range m {
+			ret = append(ret, result.DeploymentError{Ref: ref, Message: msg})
 		}
 	}
 	return ret
 }
 
+// GetDeduplicatedWarningsList returns the same warnings as GetWarningsList, but with identical messages reported for
+// multiple objects of the same GVK merged into a single warning. The refs of the affected objects are kept in Refs.
+func (dew *DeploymentErrorsAndWarnings) GetDeduplicatedWarningsList() []result.DeploymentError {
+	return DeduplicateDeploymentErrors(dew.GetWarningsList())
+}
+
+func DeduplicateDeploymentErrors(l []result.DeploymentError) []result.DeploymentError {
+	type key struct {
+		gvk     k8s.ObjectRef
+		message string
+	}
+
+	var keys []key
+	groups := map[key][]result.DeploymentError{}
+	for _, e := range l {
+		k := key{
+			gvk:     k8s.ObjectRef{Group: e.Ref.Group, Version: e.Ref.Version, Kind: e.Ref.Kind},
+			message: e.Message,
+		}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], e)
+	}
+
+	ret := make([]result.DeploymentError, 0, len(keys))
+	for _, k := range keys {
+		g := groups[k]
+		if len(g) == 1 {
+			ret = append(ret, g[0])
+			continue
+		}
+		var refs []k8s.ObjectRef
+		for _, e := range g {
+			if len(e.Refs) != 0 {
+				refs = append(refs, e.Refs...)
+			} else {
+				refs = append(refs, e.Ref)
+			}
+		}
+		sort.SliceStable(refs, func(i, j int) bool {
+			return refs[i].Less(refs[j])
+		})
+		ret = append(ret, result.DeploymentError{
+			Ref:     k.gvk,
+			Message: k.message,
+			Count:   len(refs),
+			Refs:    refs,
+		})
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Ref != ret[j].Ref {
+			return ret[i].Ref.Less(ret[j].Ref)
+		}
+		return ret[i].Message < ret[j].Message
+	})
+	return ret
+}
+
 func (dew *DeploymentErrorsAndWarnings) getPlainErrorsList() []error {
 	var ret []error
 	for _, e := range dew.GetErrorsList() {
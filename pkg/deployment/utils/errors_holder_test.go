@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeduplicateWarnings(t *testing.T) {
+	dew := NewDeploymentErrorsAndWarnings()
+
+	deprecated := fmt.Errorf("policy/v1beta1 PodSecurityPolicy is deprecated")
+	for i := 0; i < 3; i++ {
+		dew.AddWarning(k8s.ObjectRef{Group: "policy", Version: "v1beta1", Kind: "PodSecurityPolicy", Name: fmt.Sprintf("psp-%d", i)}, deprecated)
+	}
+	dew.AddWarning(k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm", Namespace: "ns"}, fmt.Errorf("single warning"))
+
+	assert.Len(t, dew.GetWarningsList(), 4)
+	assert.Equal(t, []result.DeploymentError{
+		{Ref: k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm", Namespace: "ns"}, Message: "single warning"},
+		{Ref: k8s.ObjectRef{Group: "policy", Version: "v1beta1", Kind: "PodSecurityPolicy"}, Message: deprecated.Error(), Count: 3, Refs: []k8s.ObjectRef{
+			{Group: "policy", Version: "v1beta1", Kind: "PodSecurityPolicy", Name: "psp-0"},
+			{Group: "policy", Version: "v1beta1", Kind: "PodSecurityPolicy", Name: "psp-1"},
+			{Group: "policy", Version: "v1beta1", Kind: "PodSecurityPolicy", Name: "psp-2"},
+		}},
+	}, dew.GetDeduplicatedWarningsList())
+}
+
+func TestDeduplicateWarningsTwice(t *testing.T) {
+	dew := NewDeploymentErrorsAndWarnings()
+
+	msg := fmt.Errorf("warning")
+	for i := 0; i < 2; i++ {
+		dew.AddWarning(k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: fmt.Sprintf("cm-%d", i)}, msg)
+	}
+	l := dew.GetDeduplicatedWarningsList()
+	l = append(l, result.DeploymentError{Ref: k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm-2"}, Message: msg.Error()})
+
+	// already deduplicated warnings must keep their refs when deduplicated again
+	l = DeduplicateDeploymentErrors(l)
+	assert.Len(t, l, 1)
+	assert.Equal(t, 3, l[0].Count)
+	assert.Equal(t, []k8s.ObjectRef{
+		{Version: "v1", Kind: "ConfigMap", Name: "cm-0"},
+		{Version: "v1", Kind: "ConfigMap", Name: "cm-1"},
+		{Version: "v1", Kind: "ConfigMap", Name: "cm-2"},
+	}, l[0].Refs)
+}
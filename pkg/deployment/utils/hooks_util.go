@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
@@ -49,6 +50,25 @@ type hook struct {
 	deletePolicies map[string]bool
 	wait           bool
 	timeout        time.Duration
+
+	// runInDryRun is true if the hook is marked as read-only via kluctl.io/hook-dry-run, see ApplyUtilOptions.RunDryRunHooks
+	runInDryRun bool
+
+	// namespaceOverride is true if the namespace of object was overridden via kluctl.io/hook-namespace
+	namespaceOverride bool
+	createNamespace   bool
+}
+
+func (u *HooksUtil) newHookPollBackoff() *pollBackoff {
+	interval := u.a.o.HookPollInterval
+	if interval <= 0 {
+		interval = defaultHookPollInterval
+	}
+	maxInterval := u.a.o.HookPollMaxInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultHookPollMaxInterval
+	}
+	return newPollBackoff(interval, maxInterval)
 }
 
 func (u *HooksUtil) DetermineHooks(d *deployment.DeploymentItem, hooks []string) []*hook {
@@ -64,6 +84,24 @@ func (u *HooksUtil) DetermineHooks(d *deployment.DeploymentItem, hooks []string)
 	return l
 }
 
+// withHookDryRun invokes cb with dry-run mode disabled if the given hook must really be executed in dry-run mode,
+// see ApplyUtilOptions.RunDryRunHooks
+func (u *HooksUtil) withHookDryRun(h *hook, cb func()) {
+	if !u.a.dryRun || !u.a.o.RunDryRunHooks || !h.runInDryRun {
+		cb()
+		return
+	}
+
+	oldK := u.a.k
+	u.a.k = oldK.ReadWrite()
+	u.a.dryRun = false
+	defer func() {
+		u.a.k = oldK
+		u.a.dryRun = true
+	}()
+	cb()
+}
+
 func (u *HooksUtil) RunHooks(hooks []*hook) {
 	var deleteBeforeObjects []*hook
 	var applyObjects []*hook
@@ -85,7 +123,11 @@ func (u *HooksUtil) RunHooks(hooks []*hook) {
 			dpStr = append(dpStr, p)
 		}
 		u.a.sctx.UpdateAndInfoFallbackf("Deleting hook %s due to hook-delete-policy %s (%d of %d)", ref.String(), strings.Join(dpStr, ","), i+1, cnt)
-		return u.a.DeleteObject(ref, true)
+		deleted := false
+		u.withHookDryRun(h, func() {
+			deleted = u.a.DeleteObject(ref, true)
+		})
+		return deleted
 	}
 
 	if len(deleteBeforeObjects) != 0 {
@@ -103,16 +145,25 @@ func (u *HooksUtil) RunHooks(hooks []*hook) {
 		ref := h.object.GetK8sRef()
 		_, replaced := h.deletePolicies["before-hook-creation"]
 		u.a.sctx.UpdateAndInfoFallbackf("Applying hook %s (%d of %d)", ref.String(), i+1, len(applyObjects))
-		u.a.ApplyObject(h.di, h.object, replaced, true)
-		u.a.sctx.Increment()
+		u.withHookDryRun(h, func() {
+			if h.namespaceOverride && !u.ensureHookNamespace(h) {
+				u.a.sctx.Increment()
+				return
+			}
+			u.a.ApplyObject(h.di, h.object, replaced, true)
+			u.a.sctx.Increment()
 
-		if u.a.HadError(ref) {
-			continue
-		}
-		if !h.wait || u.a.o.NoWait {
-			continue
-		}
-		waitResults[ref] = u.a.WaitReadiness(ref, h.timeout)
+			if u.a.HadError(ref) {
+				return
+			}
+			if !h.wait || u.a.o.NoWait {
+				return
+			}
+			u.a.emitEvent(ApplyEventHookWaiting, ref, true, "")
+			startWait := time.Now()
+			waitResults[ref] = u.a.waitReadiness(ref, h.timeout, u.newHookPollBackoff())
+			u.a.o.Metrics.hookWaited(time.Since(startWait), waitResults[ref])
+		})
 	}
 
 	var deleteAfterObjects []*hook
@@ -235,6 +286,27 @@ func (u *HooksUtil) GetHook(di *deployment.DeploymentItem, o *uo.UnstructuredObj
 		}
 	}
 
+	namespaceOverride := false
+	if ns := o.GetK8sAnnotation("kluctl.io/hook-namespace"); ns != nil && len(hooks) != 0 {
+		if ref.Namespace == "" {
+			u.a.HandleError(ref, fmt.Errorf("kluctl.io/hook-namespace can not be used on cluster-scoped objects"))
+		} else if *ns == "" {
+			u.a.HandleError(ref, fmt.Errorf("kluctl.io/hook-namespace must not be empty"))
+		} else if *ns != ref.Namespace {
+			o = o.Clone()
+			o.SetK8sNamespace(*ns)
+			namespaceOverride = true
+		}
+	}
+	createNamespace, err := o.GetK8sAnnotationBool("kluctl.io/hook-create-namespace", false)
+	if err != nil {
+		u.a.HandleError(ref, err)
+	}
+	runInDryRun, err := o.GetK8sAnnotationBool("kluctl.io/hook-dry-run", false)
+	if err != nil {
+		u.a.HandleError(ref, err)
+	}
+
 	if len(hooks) == 0 {
 		return nil
 	}
@@ -247,7 +319,52 @@ func (u *HooksUtil) GetHook(di *deployment.DeploymentItem, o *uo.UnstructuredObj
 		deletePolicies: deletePolicy,
 		wait:           wait,
 		timeout:        timeout,
+		runInDryRun:    runInDryRun,
+
+		namespaceOverride: namespaceOverride,
+		createNamespace:   createNamespace,
+	}
+}
+
+// ensureHookNamespace verifies that the namespace overridden via kluctl.io/hook-namespace exists. If it does not exist
+// and kluctl.io/hook-create-namespace is set, it is created.
+func (u *HooksUtil) ensureHookNamespace(h *hook) bool {
+	ref := h.object.GetK8sRef()
+	if _, ok := u.a.allNamespaces.Load(ref.Namespace); ok {
+		return true
+	}
+
+	remoteNamespace, err := u.a.ru.GetRemoteNamespace(u.a.k, ref.Namespace)
+	if err != nil {
+		u.a.HandleError(ref, err)
+		return false
 	}
+	if remoteNamespace != nil {
+		return true
+	}
+
+	if !h.createNamespace {
+		u.a.HandleError(ref, fmt.Errorf("hook namespace %s does not exist, either create it or set kluctl.io/hook-create-namespace to 'true'", ref.Namespace))
+		return false
+	}
+
+	u.a.sctx.InfoFallbackf("Creating namespace %s for hook %s", ref.Namespace, ref.String())
+
+	ns := uo.New()
+	ns.SetK8sGVKs("", "v1", "Namespace")
+	ns.SetK8sName(ref.Namespace)
+	r, apiWarnings, err := u.a.k.ApplyObject(ns, k8s2.PatchOptions{
+		ForceDryRun: u.a.dryRun,
+		Timeout:     u.a.o.ApplyTimeout,
+	})
+	u.a.handleApiWarnings(ns.GetK8sRef(), apiWarnings)
+	if err != nil {
+		u.a.HandleError(ref, fmt.Errorf("failed to create hook namespace %s: %w", ref.Namespace, err))
+		return false
+	}
+	// this also ensures that dry-run applies of the hook behave as if the namespace existed
+	u.a.allNamespaces.Store(ref.Namespace, r)
+	return true
 }
 
 func (u *HooksUtil) getSortedHooksList(d *deployment.DeploymentItem) []*hook {
@@ -265,6 +382,12 @@ func (u *HooksUtil) getSortedHooksList(d *deployment.DeploymentItem) []*hook {
 	return ret
 }
 
+// GetK8sRef returns the reference of the hook object as it gets applied, which might differ from the rendered object
+// when kluctl.io/hook-namespace is used
+func (h *hook) GetK8sRef() k8s.ObjectRef {
+	return h.object.GetK8sRef()
+}
+
 func (h *hook) IsPersistent() bool {
 	for p := range h.deletePolicies {
 		if p != "before-hook-creation" && p != "hook-failed" {
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDetermineHooksWeightOrder(t *testing.T) {
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{})
+	h := NewHooksUtil(ad.NewApplyUtil(context.TODO(), nil))
+
+	d := &deployment.DeploymentItem{
+		Objects: []*uo.UnstructuredObject{
+			newTestConfigMap("h1", nil, map[string]string{"kluctl.io/hook": "pre-deploy", "kluctl.io/hook-weight": "10"}),
+			newTestConfigMap("h2", nil, map[string]string{"kluctl.io/hook": "pre-deploy"}),
+			newTestConfigMap("h3", nil, map[string]string{"helm.sh/hook": "pre-install", "helm.sh/hook-weight": "-5"}),
+			newTestConfigMap("h4", nil, map[string]string{"kluctl.io/hook": "pre-deploy", "kluctl.io/hook-weight": "10"}),
+			newTestConfigMap("h5", nil, map[string]string{"kluctl.io/hook": "pre-deploy", "kluctl.io/hook-weight": "1", "helm.sh/hook-weight": "100"}),
+			newTestConfigMap("h6", nil, map[string]string{"kluctl.io/hook": "post-deploy", "kluctl.io/hook-weight": "-10"}),
+			newTestConfigMap("not-a-hook", nil, nil),
+		},
+	}
+
+	names := func(hooks []*hook) []string {
+		var ret []string
+		for _, h := range hooks {
+			ret = append(ret, h.object.GetK8sName())
+		}
+		return ret
+	}
+
+	assert.Equal(t, []string{"h3", "h2", "h5", "h1", "h4"}, names(h.DetermineHooks(d, []string{"pre-deploy-initial", "pre-deploy"})))
+	assert.Equal(t, []string{"h2", "h5", "h1", "h4"}, names(h.DetermineHooks(d, []string{"pre-deploy-upgrade", "pre-deploy"})))
+	assert.Equal(t, []string{"h6"}, names(h.DetermineHooks(d, []string{"post-deploy-initial", "post-deploy"})))
+	assert.Empty(t, dew.GetErrorsList())
+}
+
+func TestDetermineHooksInvalidWeight(t *testing.T) {
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{})
+	h := NewHooksUtil(ad.NewApplyUtil(context.TODO(), nil))
+
+	o := newTestConfigMap("h1", nil, map[string]string{"kluctl.io/hook": "pre-deploy", "kluctl.io/hook-weight": "abc"})
+	h.GetHook(&deployment.DeploymentItem{}, o)
+	assert.Len(t, dew.GetErrorsList(), 1)
+	assert.Equal(t, o.GetK8sRef(), dew.GetErrorsList()[0].Ref)
+}
+
+func TestGetHookNamespaceOverride(t *testing.T) {
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{})
+	h := NewHooksUtil(ad.NewApplyUtil(context.TODO(), nil))
+
+	o := newTestConfigMap("h1", nil, map[string]string{"kluctl.io/hook": "pre-deploy", "kluctl.io/hook-namespace": "maintenance"})
+	hook := h.GetHook(&deployment.DeploymentItem{}, o)
+	assert.Equal(t, "maintenance", hook.GetK8sRef().Namespace)
+	assert.True(t, hook.namespaceOverride)
+	assert.False(t, hook.createNamespace)
+	// the rendered object must stay untouched
+	assert.Equal(t, "default", o.GetK8sNamespace())
+
+	o = newTestConfigMap("h2", nil, map[string]string{"kluctl.io/hook": "pre-deploy"})
+	hook = h.GetHook(&deployment.DeploymentItem{}, o)
+	assert.Equal(t, "default", hook.GetK8sRef().Namespace)
+	assert.False(t, hook.namespaceOverride)
+
+	o = newTestConfigMap("h3", nil, map[string]string{"kluctl.io/hook": "pre-deploy", "kluctl.io/hook-namespace": "maintenance", "kluctl.io/hook-create-namespace": "true"})
+	hook = h.GetHook(&deployment.DeploymentItem{}, o)
+	assert.True(t, hook.createNamespace)
+	assert.Empty(t, dew.GetErrorsList())
+
+	o = newTestConfigMap("h4", nil, map[string]string{"kluctl.io/hook": "pre-deploy", "kluctl.io/hook-namespace": "maintenance"})
+	o.SetK8sNamespace("")
+	h.GetHook(&deployment.DeploymentItem{}, o)
+	assert.Len(t, dew.GetErrorsList(), 1)
+}
+
+func TestIsHookObjectMatchesGetHook(t *testing.T) {
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{})
+	h := NewHooksUtil(ad.NewApplyUtil(context.TODO(), nil))
+
+	for i, annotations := range []map[string]string{
+		nil,
+		{"kluctl.io/hook": "pre-deploy"},
+		{"kluctl.io/hook": "pre-deploy, post-deploy"},
+		{"kluctl.io/hook": ""},
+		{"kluctl.io/hook": "pre-deploy", "kluctl.io/delete": "true"},
+		{"helm.sh/hook": "pre-install"},
+		{"helm.sh/hook": "post-rollback"},
+		{"helm.sh/hook": "test"},
+		{"kluctl.io/hook-weight": "1"},
+	} {
+		o := newTestConfigMap(fmt.Sprintf("o%d", i), nil, annotations)
+		assert.Equal(t, h.GetHook(&deployment.DeploymentItem{}, o) != nil, deployment.IsHookObject(o), "annotations: %v", annotations)
+	}
+}
+
+func TestHookDryRun(t *testing.T) {
+	newHooksUtil := func(runDryRunHooks bool) (*HooksUtil, *ApplyUtil) {
+		dew := NewDeploymentErrorsAndWarnings()
+		ru := NewRemoteObjectsUtil(context.TODO(), dew)
+		ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, &k8s.K8sCluster{DryRun: true}, &ApplyUtilOptions{
+			DryRun:         true,
+			RunDryRunHooks: runDryRunHooks,
+		})
+		a := ad.NewApplyUtil(context.TODO(), nil)
+		return NewHooksUtil(a), a
+	}
+
+	h, a := newHooksUtil(true)
+	check := h.GetHook(&deployment.DeploymentItem{}, newTestConfigMap("h1", nil, map[string]string{"kluctl.io/hook": "pre-deploy", "kluctl.io/hook-dry-run": "true"}))
+	other := h.GetHook(&deployment.DeploymentItem{}, newTestConfigMap("h2", nil, map[string]string{"kluctl.io/hook": "pre-deploy"}))
+	assert.True(t, check.runInDryRun)
+	assert.False(t, other.runInDryRun)
+
+	called := false
+	h.withHookDryRun(check, func() {
+		called = true
+		assert.False(t, a.dryRun)
+		assert.False(t, a.k.DryRun)
+	})
+	assert.True(t, called)
+	assert.True(t, a.dryRun)
+	assert.True(t, a.k.DryRun)
+
+	h.withHookDryRun(other, func() {
+		assert.True(t, a.dryRun)
+		assert.True(t, a.k.DryRun)
+	})
+
+	// e.g. the diff performed before deploying, which must never execute hooks
+	h, a = newHooksUtil(false)
+	h.withHookDryRun(check, func() {
+		assert.True(t, a.dryRun)
+		assert.True(t, a.k.DryRun)
+	})
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// ObjectViolation describes a single policy violation found by an ObjectValidator.
+type ObjectViolation struct {
+	Policy  string
+	Message string
+
+	// Warning marks the violation as non-fatal. Such violations are recorded as warnings and the object is applied
+	// anyway.
+	Warning bool
+}
+
+func (v ObjectViolation) Error() string {
+	if v.Policy == "" {
+		return fmt.Sprintf("policy violation: %s", v.Message)
+	}
+	return fmt.Sprintf("policy %s violated: %s", v.Policy, v.Message)
+}
+
+// ObjectValidator is invoked for every rendered object right before it gets applied, allowing to implement local,
+// admission-style policies. Returned violations that are not marked as warnings prevent the object from being applied
+// and are recorded as errors, meaning that AbortOnError is respected as well. Returning an error has the same effect
+// as a single fatal violation.
+type ObjectValidator interface {
+	ValidateObject(ctx context.Context, d *deployment.DeploymentItem, o *uo.UnstructuredObject) ([]ObjectViolation, error)
+}
+
+// NoopObjectValidator is the default ObjectValidator, which accepts all objects.
+type NoopObjectValidator struct{}
+
+func (v NoopObjectValidator) ValidateObject(ctx context.Context, d *deployment.DeploymentItem, o *uo.UnstructuredObject) ([]ObjectViolation, error) {
+	return nil, nil
+}
+
+// ObjectValidators combines multiple validators into one, returning the violations of all validators.
+type ObjectValidators []ObjectValidator
+
+func (l ObjectValidators) ValidateObject(ctx context.Context, d *deployment.DeploymentItem, o *uo.UnstructuredObject) ([]ObjectViolation, error) {
+	var ret []ObjectViolation
+	for _, v := range l {
+		violations, err := v.ValidateObject(ctx, d, o)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, violations...)
+	}
+	return ret, nil
+}
+
+// validateObject runs the configured ObjectValidator and records all violations. It returns false if the object must
+// not be applied.
+func (a *ApplyUtil) validateObject(d *deployment.DeploymentItem, x *uo.UnstructuredObject) bool {
+	if a.o.ObjectValidator == nil {
+		return true
+	}
+
+	ref := x.GetK8sRef()
+	violations, err := a.o.ObjectValidator.ValidateObject(a.ctx, d, x)
+	if err != nil {
+		a.HandleError(ref, fmt.Errorf("failed to validate object: %w", err))
+		return false
+	}
+
+	ok := true
+	for _, v := range violations {
+		if v.Warning {
+			a.HandleWarning(ref, v)
+		} else {
+			a.HandleError(ref, v)
+			ok = false
+		}
+	}
+	return ok
+}
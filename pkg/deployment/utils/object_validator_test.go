@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testObjectValidator struct {
+	violations map[string][]ObjectViolation
+	err        error
+}
+
+func (v *testObjectValidator) ValidateObject(ctx context.Context, d *deployment.DeploymentItem, o *uo.UnstructuredObject) ([]ObjectViolation, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	return v.violations[o.GetK8sName()], nil
+}
+
+func TestValidateObject(t *testing.T) {
+	v := &testObjectValidator{violations: map[string][]ObjectViolation{
+		"bad":  {{Policy: "p1", Message: "m1"}},
+		"warn": {{Policy: "p2", Message: "m2", Warning: true}},
+	}}
+
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{
+		ObjectValidator: ObjectValidators{NoopObjectValidator{}, v},
+	})
+	a := ad.NewApplyUtil(context.TODO(), nil)
+
+	good := newTestConfigMap("good", nil, nil)
+	bad := newTestConfigMap("bad", nil, nil)
+	warn := newTestConfigMap("warn", nil, nil)
+
+	assert.True(t, a.validateObject(nil, good))
+	assert.False(t, a.validateObject(nil, bad))
+	assert.True(t, a.validateObject(nil, warn))
+
+	assert.Len(t, dew.GetErrorsList(), 1)
+	assert.Equal(t, "policy p1 violated: m1", dew.GetErrorsList()[0].Message)
+	assert.Equal(t, bad.GetK8sRef(), dew.GetErrorsList()[0].Ref)
+	assert.Len(t, dew.GetWarningsList(), 1)
+	assert.Equal(t, "policy p2 violated: m2", dew.GetWarningsList()[0].Message)
+
+	// AbortOnError was not set
+	assert.False(t, ad.abortSignal.Load().(bool))
+}
+
+func TestValidateObjectAbortOnError(t *testing.T) {
+	v := &testObjectValidator{err: fmt.Errorf("policy engine failed")}
+
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{
+		AbortOnError:    true,
+		ObjectValidator: v,
+	})
+	a := ad.NewApplyUtil(context.TODO(), nil)
+
+	assert.False(t, a.validateObject(nil, newTestConfigMap("cm", nil, nil)))
+	assert.Equal(t, "failed to validate object: policy engine failed", dew.GetErrorsList()[0].Message)
+	assert.True(t, ad.abortSignal.Load().(bool))
+}
@@ -0,0 +1,29 @@
+package utils
+
+import "time"
+
+// pollBackoff calculates polling intervals, starting with an initial interval which is doubled on every call to Next
+// until the maximum interval is reached.
+type pollBackoff struct {
+	interval    time.Duration
+	maxInterval time.Duration
+}
+
+func newPollBackoff(interval time.Duration, maxInterval time.Duration) *pollBackoff {
+	if maxInterval < interval {
+		maxInterval = interval
+	}
+	return &pollBackoff{
+		interval:    interval,
+		maxInterval: maxInterval,
+	}
+}
+
+func (b *pollBackoff) Next() time.Duration {
+	ret := b.interval
+	b.interval *= 2
+	if b.interval > b.maxInterval {
+		b.interval = b.maxInterval
+	}
+	return ret
+}
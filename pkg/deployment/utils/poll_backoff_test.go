@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPollBackoff(t *testing.T) {
+	b := newPollBackoff(500*time.Millisecond, 5*time.Second)
+
+	var intervals []time.Duration
+	for i := 0; i < 7; i++ {
+		intervals = append(intervals, b.Next())
+	}
+	assert.Equal(t, []time.Duration{
+		500 * time.Millisecond,
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+	}, intervals)
+}
+
+func TestPollBackoffMaxLessThanInitial(t *testing.T) {
+	b := newPollBackoff(2*time.Second, time.Second)
+	assert.Equal(t, 2*time.Second, b.Next())
+	assert.Equal(t, 2*time.Second, b.Next())
+}
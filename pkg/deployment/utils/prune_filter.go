@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"fmt"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DefaultPruneDenyKinds contains kinds that are usually too dangerous to be pruned automatically, as deleting them
+// also deletes everything inside them (namespaces) or all custom resources of that type (CRDs).
+var DefaultPruneDenyKinds = []schema.GroupKind{
+	{Group: "", Kind: "Namespace"},
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+}
+
+// PruneKindFilter restricts pruning to a set of kinds. If Allow is non-empty, only objects with a matching kind are
+// pruned. Objects matching Deny are never pruned, even if they are also matched by Allow.
+type PruneKindFilter struct {
+	Allow []schema.GroupKind
+	Deny  []schema.GroupKind
+}
+
+func containsGroupKind(l []schema.GroupKind, gk schema.GroupKind) bool {
+	for _, x := range l {
+		if x == gk {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter splits refs into the objects that may be pruned and the ones that must be skipped, including the reason
+// why they were skipped.
+func (f *PruneKindFilter) Filter(refs []k8s2.ObjectRef) ([]k8s2.ObjectRef, []result.PruneSkippedObject) {
+	if f == nil || (len(f.Allow) == 0 && len(f.Deny) == 0) {
+		return refs, nil
+	}
+
+	var allowed []k8s2.ObjectRef
+	var skipped []result.PruneSkippedObject
+	for _, ref := range refs {
+		gk := ref.GroupKind()
+		if containsGroupKind(f.Deny, gk) {
+			skipped = append(skipped, result.PruneSkippedObject{
+				Ref:    ref,
+				Reason: fmt.Sprintf("kind %s is in the prune denylist", gk.String()),
+			})
+		} else if len(f.Allow) != 0 && !containsGroupKind(f.Allow, gk) {
+			skipped = append(skipped, result.PruneSkippedObject{
+				Ref:    ref,
+				Reason: fmt.Sprintf("kind %s is not in the prune allowlist", gk.String()),
+			})
+		} else {
+			allowed = append(allowed, ref)
+		}
+	}
+	return allowed, skipped
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+)
+
+func TestPruneKindFilter(t *testing.T) {
+	ns := k8s2.ObjectRef{Version: "v1", Kind: "Namespace", Name: "ns1"}
+	crd := k8s2.ObjectRef{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition", Name: "crd1"}
+	cm := k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm1", Namespace: "ns1"}
+	d := k8s2.ObjectRef{Group: "apps", Version: "v1", Kind: "Deployment", Name: "d1", Namespace: "ns1"}
+	refs := []k8s2.ObjectRef{ns, crd, cm, d}
+
+	var f *PruneKindFilter
+	allowed, skipped := f.Filter(refs)
+	assert.Equal(t, refs, allowed)
+	assert.Empty(t, skipped)
+
+	f = &PruneKindFilter{Deny: DefaultPruneDenyKinds}
+	allowed, skipped = f.Filter(refs)
+	assert.Equal(t, []k8s2.ObjectRef{cm, d}, allowed)
+	assert.Equal(t, []result.PruneSkippedObject{
+		{Ref: ns, Reason: "kind Namespace is in the prune denylist"},
+		{Ref: crd, Reason: "kind CustomResourceDefinition.apiextensions.k8s.io is in the prune denylist"},
+	}, skipped)
+
+	// deny wins over allow
+	f = &PruneKindFilter{
+		Allow: []schema.GroupKind{{Kind: "ConfigMap"}, {Kind: "Namespace"}},
+		Deny:  []schema.GroupKind{{Kind: "Namespace"}},
+	}
+	allowed, skipped = f.Filter(refs)
+	assert.Equal(t, []k8s2.ObjectRef{cm}, allowed)
+	assert.Equal(t, []result.PruneSkippedObject{
+		{Ref: ns, Reason: "kind Namespace is in the prune denylist"},
+		{Ref: crd, Reason: "kind CustomResourceDefinition.apiextensions.k8s.io is not in the prune allowlist"},
+		{Ref: d, Reason: "kind Deployment.apps is not in the prune allowlist"},
+	}, skipped)
+}
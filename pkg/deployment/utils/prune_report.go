@@ -0,0 +1,61 @@
+package utils
+
+import (
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"sort"
+)
+
+func sortRefs(refs []k8s2.ObjectRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Less(refs[j])
+	})
+}
+
+// BuildPruneReport builds the list of objects that got deleted (or would get deleted in dry-run mode), grouped by the
+// deployment item that caused the deletion. ad can be nil, in which case only orphanObjects are reported. Orphan
+// objects are reported as a separate group. Deleted hooks are not reported, as these are re-created on every deploy.
+func BuildPruneReport(ad *ApplyDeploymentsUtil, orphanObjects []k8s2.ObjectRef) []result.PruneCandidates {
+	var ret []result.PruneCandidates
+
+	if ad != nil {
+		ad.resultsMutex.Lock()
+		var byItem utils.OrderedMap[string, map[k8s2.ObjectRef]bool]
+		for _, a := range ad.results {
+			a.mutex.Lock()
+			for ref := range a.deletedObjects {
+				m, ok := byItem.Get(a.deploymentItemName)
+				if !ok {
+					m = map[k8s2.ObjectRef]bool{}
+					byItem.Set(a.deploymentItemName, m)
+				}
+				m[ref] = true
+			}
+			a.mutex.Unlock()
+		}
+		ad.resultsMutex.Unlock()
+
+		byItem.ForEach(func(name string, m map[k8s2.ObjectRef]bool) {
+			pc := result.PruneCandidates{
+				DeploymentItem: name,
+			}
+			for ref := range m {
+				pc.Refs = append(pc.Refs, ref)
+			}
+			sortRefs(pc.Refs)
+			ret = append(ret, pc)
+		})
+	}
+
+	if len(orphanObjects) != 0 {
+		pc := result.PruneCandidates{
+			Orphan: true,
+			Refs:   append([]k8s2.ObjectRef{}, orphanObjects...),
+		}
+		sortRefs(pc.Refs)
+		ret = append(ret, pc)
+	}
+
+	return ret
+}
@@ -280,20 +280,35 @@ func (u *RemoteObjectUtils) ForgetRemoteObject(ref k8s2.ObjectRef) {
 	delete(u.remoteObjects, ref)
 }
 
-func (u *RemoteObjectUtils) GetFilteredRemoteObjects(inclusion *utils.Inclusion) []*uo.UnstructuredObject {
+// GetFilteredRemoteObjects returns all remote objects that are included by all the given inclusions
+func (u *RemoteObjectUtils) GetFilteredRemoteObjects(inclusions ...*utils.Inclusion) []*uo.UnstructuredObject {
+	var objects []*uo.UnstructuredObject
+	for _, o := range u.remoteObjects {
+		objects = append(objects, o)
+	}
+	return filterObjectsByInclusions(objects, inclusions...)
+}
+
+// filterObjectsByInclusions returns all objects that are included by all the given inclusions. nil inclusions include
+// everything.
+func filterObjectsByInclusions(objects []*uo.UnstructuredObject, inclusions ...*utils.Inclusion) []*uo.UnstructuredObject {
 	var ret []*uo.UnstructuredObject
 
-	for _, o := range u.remoteObjects {
-		iv := u.getInclusionEntries(o)
-		if inclusion.CheckIncluded(iv, false) {
-			ret = append(ret, o)
+outer:
+	for _, o := range objects {
+		iv := getInclusionEntries(o)
+		for _, inclusion := range inclusions {
+			if !inclusion.CheckIncluded(iv, false) {
+				continue outer
+			}
 		}
+		ret = append(ret, o)
 	}
 
 	return ret
 }
 
-func (u *RemoteObjectUtils) getInclusionEntries(o *uo.UnstructuredObject) []utils.InclusionEntry {
+func getInclusionEntries(o *uo.UnstructuredObject) []utils.InclusionEntry {
 	var iv []utils.InclusionEntry
 	for _, v := range o.GetK8sLabelsWithRegex("^kluctl.io/tag-\\d+$") {
 		iv = append(iv, utils.InclusionEntry{
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// BuildResumeAppliedObjects builds the map used for ApplyUtilOptions.ResumeAppliedObjects from a previous command
+// result. Only objects that were applied without errors are included.
+func BuildResumeAppliedObjects(cr *result.CommandResult) map[k8s2.ObjectRef]string {
+	erroredRefs := map[k8s2.ObjectRef]bool{}
+	for _, e := range cr.Errors {
+		erroredRefs[e.Ref] = true
+	}
+
+	ret := map[k8s2.ObjectRef]string{}
+	for _, o := range cr.Objects {
+		if o.Applied == nil || o.Deleted || erroredRefs[o.Ref] {
+			continue
+		}
+		checksum := o.Applied.GetK8sAnnotation(RenderedChecksumAnnotation)
+		if checksum == nil || *checksum == "" {
+			continue
+		}
+		ret[o.Ref] = *checksum
+	}
+	return ret
+}
+
+// isResumable returns true if the object was applied successfully before with the same rendered checksum and the
+// remote object still carries this checksum, meaning that applying it again would not change anything.
+func (a *ApplyUtil) isResumable(ref k8s2.ObjectRef, checksum string, remoteObject *uo.UnstructuredObject) bool {
+	if a.o.ResumeAppliedObjects == nil || remoteObject == nil {
+		return false
+	}
+	prevChecksum, ok := a.o.ResumeAppliedObjects[ref]
+	if !ok || prevChecksum != checksum {
+		return false
+	}
+	remoteChecksum := remoteObject.GetK8sAnnotation(RenderedChecksumAnnotation)
+	return remoteChecksum != nil && *remoteChecksum == checksum
+}
+
+// isDeploymentItemResumable returns true if all objects and hooks of the deployment item were applied successfully
+// before. Hooks must only be skipped in this case, as otherwise a hook might be skipped while objects that depend on
+// it are re-applied. Hooks are not required to still exist, as they might have been deleted due to their delete
+// policy.
+func (a *ApplyUtil) isDeploymentItemResumable(d *deployment.DeploymentItem, h *HooksUtil) bool {
+	if a.o.ResumeAppliedObjects == nil {
+		return false
+	}
+	for _, o := range d.Objects {
+		if o.GetK8sAnnotationBoolNoError("kluctl.io/delete", false) {
+			continue
+		}
+		ref := o.GetK8sRef()
+		checksum, err := CalcRenderedChecksum(o)
+		if err != nil {
+			return false
+		}
+		if h.GetHook(d, o) != nil {
+			if prevChecksum, ok := a.o.ResumeAppliedObjects[ref]; !ok || prevChecksum != checksum {
+				return false
+			}
+		} else if !a.isResumable(ref, checksum, a.ru.GetRemoteObject(ref)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"context"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResume(t *testing.T) {
+	cm1 := newTestConfigMap("cm1", map[string]interface{}{"a": "b"}, nil)
+	cm2 := newTestConfigMap("cm2", nil, nil)
+	cm3 := newTestConfigMap("cm3", nil, nil)
+
+	checksum1, err := CalcRenderedChecksum(cm1)
+	assert.NoError(t, err)
+	checksum2, err := CalcRenderedChecksum(cm2)
+	assert.NoError(t, err)
+
+	applied := func(o *uo.UnstructuredObject, checksum string) result.ResultObject {
+		a := o.Clone()
+		a.SetK8sAnnotation(RenderedChecksumAnnotation, checksum)
+		return result.ResultObject{BaseObject: result.BaseObject{Ref: a.GetK8sRef()}, Applied: a}
+	}
+
+	cr := &result.CommandResult{
+		Objects: []result.ResultObject{
+			applied(cm1, checksum1),
+			applied(cm2, checksum2),
+			{BaseObject: result.BaseObject{Ref: cm3.GetK8sRef()}},
+		},
+		Errors: []result.DeploymentError{
+			{Ref: cm2.GetK8sRef(), Message: "failed"},
+		},
+	}
+
+	resumeAppliedObjects := BuildResumeAppliedObjects(cr)
+	assert.Equal(t, map[k8s2.ObjectRef]string{cm1.GetK8sRef(): checksum1}, resumeAppliedObjects)
+
+	dew := NewDeploymentErrorsAndWarnings()
+	ru := NewRemoteObjectsUtil(context.TODO(), dew)
+	ad := NewApplyDeploymentsUtil(context.TODO(), dew, ru, nil, &ApplyUtilOptions{
+		ResumeAppliedObjects: resumeAppliedObjects,
+	})
+	a := ad.NewApplyUtil(context.TODO(), nil)
+
+	remote := cm1.Clone()
+	remote.SetK8sAnnotation(RenderedChecksumAnnotation, checksum1)
+	assert.True(t, a.isResumable(cm1.GetK8sRef(), checksum1, remote))
+
+	// rendered object changed since the previous deployment
+	assert.False(t, a.isResumable(cm1.GetK8sRef(), "other", remote))
+	// remote object was deleted or modified since the previous deployment
+	assert.False(t, a.isResumable(cm1.GetK8sRef(), checksum1, nil))
+	remote.SetK8sAnnotation(RenderedChecksumAnnotation, "other")
+	assert.False(t, a.isResumable(cm1.GetK8sRef(), checksum1, remote))
+	// not applied successfully before
+	assert.False(t, a.isResumable(cm2.GetK8sRef(), checksum2, cm2))
+}
@@ -95,6 +95,8 @@ func normalizeMetadata(o *uo.UnstructuredObject) {
 	// We don't care about managedFields when diffing (they just produce noise)
 	_ = o.RemoveNestedField("metadata", "managedFields")
 	_ = o.RemoveNestedField("metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration")
+	// The rendered checksum changes whenever anything else changes, so it would only duplicate the real changes
+	_ = o.RemoveNestedField("metadata", "annotations", "kluctl.io/rendered-checksum")
 
 	// We don't want to see this in diffs
 	_ = o.RemoveNestedField("metadata", "creationTimestamp")
@@ -0,0 +1,69 @@
+package diff
+
+import (
+	"fmt"
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"strings"
+)
+
+// UnifiedObjectDiff builds a unified diff (in the format used by 'git diff') between the yaml representations of the
+// old and the new object. Both objects are normalized the same way as it is done before calling Diff, meaning that
+// managedFields and other noise is not part of the diff. localObject is the rendered object and is used to honor the
+// ignore-diff annotations. A nil oldObject or newObject results in a diff for a new or deleted object. An empty
+// string is returned if there are no differences.
+func UnifiedObjectDiff(ref k8s.ObjectRef, oldObject *uo.UnstructuredObject, newObject *uo.UnstructuredObject, localObject *uo.UnstructuredObject) (string, error) {
+	toYaml := func(o *uo.UnstructuredObject) (string, error) {
+		if o == nil {
+			return "", nil
+		}
+		lo := localObject
+		if lo == nil {
+			lo = o
+		}
+		no, err := NormalizeObject(o, nil, lo)
+		if err != nil {
+			return "", err
+		}
+		return yaml.WriteYamlString(no.Object)
+	}
+
+	oldStr, err := toYaml(oldObject)
+	if err != nil {
+		return "", err
+	}
+	newStr, err := toYaml(newObject)
+	if err != nil {
+		return "", err
+	}
+	if oldStr == newStr {
+		return "", nil
+	}
+
+	p := ref.String() + ".yaml"
+	oldName := "a/" + p
+	newName := "b/" + p
+	if oldObject == nil {
+		oldName = "/dev/null"
+	}
+	if newObject == nil {
+		newName = "/dev/null"
+	}
+
+	edits := myers.ComputeEdits(span.URIFromPath(p), oldStr, newStr)
+	ud := fmt.Sprint(gotextdiff.ToUnified(oldName, newName, oldStr, edits))
+
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("diff --git a/%s b/%s\n", p, p))
+	if oldObject == nil {
+		buf.WriteString("new file mode 100644\n")
+	} else if newObject == nil {
+		buf.WriteString("deleted file mode 100644\n")
+	}
+	buf.WriteString(ud)
+	return buf.String(), nil
+}
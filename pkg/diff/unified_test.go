@@ -0,0 +1,39 @@
+package diff
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnifiedObjectDiff(t *testing.T) {
+	oldObject := buildObject(`{"metadata": {"managedFields": [{"manager": "kubectl"}]}, "spec": {"replicas": 1}}`)
+	newObject := buildObject(`{"metadata": {"managedFields": [{"manager": "kluctl"}]}, "spec": {"replicas": 2}}`)
+	ref := newObject.GetK8sRef()
+
+	ud, err := UnifiedObjectDiff(ref, oldObject, newObject, newObject)
+	assert.NoError(t, err)
+	assert.Contains(t, ud, "diff --git a/ns/Deployment/test.yaml b/ns/Deployment/test.yaml\n--- a/ns/Deployment/test.yaml\n+++ b/ns/Deployment/test.yaml\n@@ ")
+	assert.Contains(t, ud, "\n-  replicas: 1\n+  replicas: 2\n")
+	assert.NotContains(t, ud, "managedFields")
+	assert.NotContains(t, ud, "new file mode")
+
+	// no changes besides managedFields
+	ud, err = UnifiedObjectDiff(ref, oldObject, buildObject(`{"spec": {"replicas": 1}}`), newObject)
+	assert.NoError(t, err)
+	assert.Equal(t, "", ud)
+
+	ud, err = UnifiedObjectDiff(ref, nil, newObject, newObject)
+	assert.NoError(t, err)
+	assert.Contains(t, ud, "diff --git a/ns/Deployment/test.yaml b/ns/Deployment/test.yaml\nnew file mode 100644\n--- /dev/null\n+++ b/ns/Deployment/test.yaml\n")
+	assert.Contains(t, ud, "\n+  replicas: 2\n")
+
+	ud, err = UnifiedObjectDiff(ref, oldObject, nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, ud, "deleted file mode 100644\n--- a/ns/Deployment/test.yaml\n+++ /dev/null\n")
+	assert.Contains(t, ud, "\n-  replicas: 1\n")
+
+	// ignored via annotation on the rendered object
+	ud, err = UnifiedObjectDiff(ref, oldObject, newObject, buildObject(`{"metadata": {"annotations": {"kluctl.io/ignore-diff-field": "spec.replicas"}}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "", ud)
+}
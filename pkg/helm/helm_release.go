@@ -1,6 +1,7 @@
 package helm
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -28,6 +29,7 @@ import (
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/release"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const InstallNamespaceAnnotation = "kluctl.io/helm-install-namespace"
@@ -37,6 +39,10 @@ type Release struct {
 	Config     *types.HelmChartConfig
 	Chart      *Chart
 
+	// RenderedValues contains the merged values used while rendering, with all values that originate from sops
+	// encrypted values files being redacted. It is only set after Render has been called.
+	RenderedValues *uo.UnstructuredObject
+
 	baseChartsDir string
 }
 
@@ -105,8 +111,8 @@ func (hr *Release) GetFullOutputPath() (string, error) {
 	return securejoin.SecureJoin(dir, hr.GetOutputPath())
 }
 
-func (hr *Release) Render(ctx context.Context, k *k8s.K8sCluster, k8sVersion string, sopsDecrypter *decryptor.Decryptor) error {
-	err := hr.doRender(ctx, k, k8sVersion, sopsDecrypter)
+func (hr *Release) Render(ctx context.Context, k *k8s.K8sCluster, k8sVersion string, offlineApiResources *k8s.OfflineApiResources, sopsDecrypter *decryptor.Decryptor) error {
+	err := hr.doRender(ctx, k, k8sVersion, offlineApiResources, sopsDecrypter)
 	if err != nil {
 		return fmt.Errorf("rendering helm chart %s for release %s has failed: %w", hr.Chart.GetChartName(), hr.Config.ReleaseName, err)
 	}
@@ -171,7 +177,71 @@ func (hr *Release) getPulledChart(ctx context.Context) (*PulledChart, error) {
 	}
 }
 
-func (hr *Release) doRender(ctx context.Context, k *k8s.K8sCluster, k8sVersion string, sopsDecrypter *decryptor.Decryptor) error {
+// decryptValuesFile decrypts the given values file into a temporary file, which must be removed by the caller even in
+// case of an error. It also returns whether the file was actually sops encrypted.
+func decryptValuesFile(ctx context.Context, sopsDecrypter *decryptor.Decryptor, p string) (string, bool, error) {
+	tmpValues, err := sops.MaybeDecryptFileToTmp(ctx, sopsDecrypter, p)
+	if err != nil {
+		return tmpValues, false, err
+	}
+	a, err := os.ReadFile(p)
+	if err != nil {
+		return tmpValues, false, err
+	}
+	b, err := os.ReadFile(tmpValues)
+	if err != nil {
+		return tmpValues, false, err
+	}
+	return tmpValues, !bytes.Equal(a, b), nil
+}
+
+// writeValuesLayers writes all configured values layers into temporary files, in the same order as they are configured.
+// File layers are decrypted if they are sops encrypted, in which case they are also returned as sensitive files.
+// The returned files must be removed by the caller, even in case of an error.
+func (hr *Release) writeValuesLayers(ctx context.Context, sopsDecrypter *decryptor.Decryptor) ([]string, []string, error) {
+	dir := filepath.Dir(hr.ConfigFile)
+
+	var ret []string
+	var sensitive []string
+	for i, l := range hr.Config.Values {
+		if l.File != nil {
+			p, err := securejoin.SecureJoin(dir, *l.File)
+			if err != nil {
+				return ret, sensitive, err
+			}
+			if !utils.Exists(p) {
+				if l.Optional {
+					continue
+				}
+				return ret, sensitive, fmt.Errorf("values file %s does not exist", *l.File)
+			}
+			tmpValues, encrypted, err := decryptValuesFile(ctx, sopsDecrypter, p)
+			if tmpValues != "" {
+				ret = append(ret, tmpValues)
+			}
+			if err != nil {
+				return ret, sensitive, fmt.Errorf("failed to decrypt values file %s: %w", *l.File, err)
+			}
+			if encrypted {
+				sensitive = append(sensitive, tmpValues)
+			}
+		} else {
+			tmp, err := os.CreateTemp(utils.GetTmpBaseDir(ctx), "helm-values-")
+			if err != nil {
+				return ret, sensitive, err
+			}
+			_ = tmp.Close()
+			ret = append(ret, tmp.Name())
+			err = yaml.WriteYamlFile(tmp.Name(), l.Values)
+			if err != nil {
+				return ret, sensitive, fmt.Errorf("failed to write inline values of layer %d: %w", i, err)
+			}
+		}
+	}
+	return ret, sensitive, nil
+}
+
+func (hr *Release) doRender(ctx context.Context, k *k8s.K8sCluster, k8sVersion string, offlineApiResources *k8s.OfflineApiResources, sopsDecrypter *decryptor.Decryptor) error {
 	pc, err := hr.getPulledChart(ctx)
 	if err != nil {
 		return err
@@ -190,16 +260,32 @@ func (hr *Release) doRender(ctx context.Context, k *k8s.K8sCluster, k8sVersion s
 
 	settings := cli.New()
 	valueOpts := values.Options{}
+	var sensitiveValueFiles []string
 
 	if utils.Exists(valuesPath) {
-		tmpValues, err := sops.MaybeDecryptFileToTmp(ctx, sopsDecrypter, valuesPath)
+		tmpValues, encrypted, err := decryptValuesFile(ctx, sopsDecrypter, valuesPath)
+		if tmpValues != "" {
+			defer os.Remove(tmpValues)
+		}
 		if err != nil {
 			return err
 		}
-		defer os.Remove(tmpValues)
 		valueOpts.ValueFiles = append(valueOpts.ValueFiles, tmpValues)
+		if encrypted {
+			sensitiveValueFiles = append(sensitiveValueFiles, tmpValues)
+		}
 	}
 
+	layerFiles, sensitiveLayerFiles, err := hr.writeValuesLayers(ctx, sopsDecrypter)
+	for _, f := range layerFiles {
+		defer os.Remove(f)
+	}
+	if err != nil {
+		return err
+	}
+	valueOpts.ValueFiles = append(valueOpts.ValueFiles, layerFiles...)
+	sensitiveValueFiles = append(sensitiveValueFiles, sensitiveLayerFiles...)
+
 	var kubeVersion *chartutil.KubeVersion
 	if k != nil {
 		kubeVersion, err = chartutil.ParseKubeVersion(k.ServerVersion.String())
@@ -229,7 +315,7 @@ func (hr *Release) doRender(ctx context.Context, k *k8s.K8sCluster, k8sVersion s
 	client.Replace = true
 	client.ClientOnly = true
 	client.KubeVersion = kubeVersion
-	client.APIVersions, err = hr.getApiVersions(k)
+	client.APIVersions, err = hr.getApiVersions(k, offlineApiResources)
 	if err != nil {
 		return err
 	}
@@ -246,6 +332,11 @@ func (hr *Release) doRender(ctx context.Context, k *k8s.K8sCluster, k8sVersion s
 		return err
 	}
 
+	hr.RenderedValues, err = buildRedactedValues(vals, sensitiveValueFiles)
+	if err != nil {
+		return err
+	}
+
 	// Check chart dependencies to make sure all are present in /charts
 	chartRequested, err := loader.Load(pc.dir)
 	if err != nil {
@@ -304,17 +395,21 @@ func (hr *Release) doRender(ctx context.Context, k *k8s.K8sCluster, k8sVersion s
 	return nil
 }
 
-func (hr *Release) getApiVersions(k *k8s.K8sCluster) (chartutil.VersionSet, error) {
-	if k == nil {
+func (hr *Release) getApiVersions(k *k8s.K8sCluster, offlineApiResources *k8s.OfflineApiResources) (chartutil.VersionSet, error) {
+	var ars []v1.APIResource
+	if k != nil {
+		var err error
+		ars, err = k.GetAllAPIResources()
+		if err != nil {
+			return nil, err
+		}
+	} else if offlineApiResources != nil {
+		ars = offlineApiResources.GetAllAPIResources()
+	} else {
 		return nil, nil
 	}
 
 	m := map[string]bool{}
-
-	ars, err := k.GetAllAPIResources()
-	if err != nil {
-		return nil, err
-	}
 	for _, ar := range ars {
 		gvStr := ar.Version
 		if ar.Group != "" {
@@ -355,6 +450,29 @@ func (hr *Release) parseRenderedManifests(s string) ([]*uo.UnstructuredObject, e
 	return parsed, nil
 }
 
+// buildRedactedValues returns a copy of the merged values, with all values that are set by one of the sensitive
+// values files being redacted.
+func buildRedactedValues(vals map[string]any, sensitiveValueFiles []string) (*uo.UnstructuredObject, error) {
+	ret := uo.FromMap(vals).Clone()
+	for _, f := range sensitiveValueFiles {
+		sv, err := uo.FromFile(f)
+		if err != nil {
+			return nil, err
+		}
+		err = sv.NewIterator().IterateLeafs(func(it *uo.ObjectIterator) error {
+			kp := it.KeyPathCopy()
+			if _, found, _ := ret.GetNestedField(kp...); found {
+				return ret.SetNestedField("*****", kp...)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
 func (hr *Release) Save() error {
 	return yaml.WriteYamlFile(hr.ConfigFile, hr.Config)
 }
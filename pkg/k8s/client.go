@@ -8,6 +8,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// defaultQPS and defaultBurst are used for each client in the pool when the rest.Config does not specify rate limits
+const defaultQPS = 10
+const defaultBurst = 20
+
 type k8sClients struct {
 	k          *K8sCluster
 	clientPool chan *parallelClientEntry
@@ -58,8 +62,13 @@ func (kc *k8sClients) newClientEntry() (*parallelClientEntry, error) {
 	p := &parallelClientEntry{}
 
 	p.config = rest.CopyConfig(kc.k.config)
-	p.config.QPS = 10
-	p.config.Burst = 20
+	// only apply our defaults if the rate limits were not explicitly configured (e.g. via --kube-api-qps)
+	if p.config.QPS == 0 {
+		p.config.QPS = defaultQPS
+	}
+	if p.config.Burst == 0 {
+		p.config.Burst = defaultBurst
+	}
 	p.config.WarningHandler = p
 
 	var err error
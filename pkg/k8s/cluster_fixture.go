@@ -0,0 +1,221 @@
+package k8s
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Cluster fixtures are recordings of all requests sent to the Kubernetes API server while running a command. The
+// recorded responses can later be replayed, which allows to run commands against the recorded cluster state without
+// any connectivity. Requests are identified by method, URI and a hash of the request body, so replaying only works
+// when the same requests are sent again, e.g. when diffing the same project against the recorded cluster.
+
+const clusterFixtureInfoFile = "cluster.json"
+
+type clusterFixtureInfo struct {
+	Host        string `json:"host"`
+	ContextName string `json:"contextName"`
+}
+
+type clusterFixtureEntry struct {
+	Method     string      `json:"method"`
+	URI        string      `json:"uri"`
+	BodySha256 string      `json:"bodySha256,omitempty"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+func buildClusterFixtureKey(method string, uri string, body []byte) (string, string) {
+	bodyHash := ""
+	if len(body) != 0 {
+		h := sha256.Sum256(body)
+		bodyHash = hex.EncodeToString(h[:])
+	}
+	h := sha256.Sum256([]byte(method + "\n" + uri + "\n" + bodyHash))
+	return hex.EncodeToString(h[:]) + ".json", bodyHash
+}
+
+type clusterRecorder struct {
+	dir  string
+	next http.RoundTripper
+}
+
+func (r *clusterRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Query().Get("watch") == "true" {
+		// watches are streaming and can't be replayed
+		return r.next.RoundTrip(req)
+	}
+
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fileName, bodyHash := buildClusterFixtureKey(req.Method, req.URL.RequestURI(), body)
+	e := clusterFixtureEntry{
+		Method:     req.Method,
+		URI:        req.URL.RequestURI(),
+		BodySha256: bodyHash,
+		StatusCode: resp.StatusCode,
+		Header:     http.Header{},
+		Body:       respBody,
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		e.Header.Set("Content-Type", ct)
+	}
+	err = writeClusterFixtureFile(filepath.Join(r.dir, fileName), e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record response for %s %s: %w", req.Method, req.URL.RequestURI(), err)
+	}
+	return resp, nil
+}
+
+type clusterReplayer struct {
+	dir string
+}
+
+func (r *clusterReplayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName, _ := buildClusterFixtureKey(req.Method, req.URL.RequestURI(), body)
+	b, err := os.ReadFile(filepath.Join(r.dir, fileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("request %s %s was not recorded in cluster fixture %s", req.Method, req.URL.RequestURI(), r.dir)
+		}
+		return nil, err
+	}
+	var e clusterFixtureEntry
+	err = json.Unmarshal(b, &e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}, nil
+}
+
+func writeClusterFixtureFile(p string, o any) error {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first, as identical requests might be recorded in parallel
+	tmp, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(b)
+	_ = tmp.Close()
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// NewRecordingConfig returns a copy of the given config which records all requests and responses into the given
+// fixture directory. The context name is stored in the fixture so that it can be reported when replaying.
+func NewRecordingConfig(config *rest.Config, dir string, contextName string) (*rest.Config, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	err = writeClusterFixtureFile(filepath.Join(dir, clusterFixtureInfoFile), clusterFixtureInfo{
+		Host:        config.Host,
+		ContextName: contextName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config = rest.CopyConfig(config)
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &clusterRecorder{dir: dir, next: rt}
+	})
+	return config, nil
+}
+
+// NewReplayConfig returns a config that serves all requests from the given fixture directory, without ever connecting
+// to a real cluster. It also returns the context name that was used while recording.
+func NewReplayConfig(dir string) (*rest.Config, string, error) {
+	b, err := os.ReadFile(filepath.Join(dir, clusterFixtureInfoFile))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read cluster fixture: %w", err)
+	}
+	var info clusterFixtureInfo
+	err = json.Unmarshal(b, &info)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read cluster fixture: %w", err)
+	}
+
+	config := &rest.Config{
+		Host: info.Host,
+	}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &clusterReplayer{dir: dir}
+	})
+	return config, info.ContextName, nil
+}
+
+// CreateUncachedDiscoveryAndMapper is like CreateDiscoveryAndMapper, but only caches discovery results in memory. This
+// ensures that discovery requests are actually sent, which is required when recording cluster fixtures.
+func CreateUncachedDiscoveryAndMapper(config *rest.Config) (discovery.CachedDiscoveryInterface, meta.RESTMapper, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	discovery2 := memory.NewMemCacheClient(dc)
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discovery2)
+	return discovery2, mapper, nil
+}
@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+)
+
+// CheckImpersonation verifies that the (non-impersonated) identity of restConfig is allowed to impersonate the user,
+// groups and uid configured in restConfig.Impersonate. Without this check, missing impersonation permissions only show
+// up as generic forbidden errors on the first API call.
+func CheckImpersonation(ctx context.Context, restConfig *rest.Config) error {
+	imp := restConfig.Impersonate
+	if imp.UserName == "" && len(imp.Groups) == 0 && imp.UID == "" {
+		return nil
+	}
+
+	rc := rest.CopyConfig(restConfig)
+	rc.Impersonate = rest.ImpersonationConfig{}
+	c, err := client.New(rc, client.Options{})
+	if err != nil {
+		return err
+	}
+	return checkImpersonation(ctx, c, imp)
+}
+
+// checkImpersonation performs the actual checks of CheckImpersonation via SelfSubjectAccessReviews created by the
+// given client
+func checkImpersonation(ctx context.Context, c client.Client, imp rest.ImpersonationConfig) error {
+	var err error
+	check := func(what string, attrs authorizationv1.ResourceAttributes) error {
+		attrs.Verb = "impersonate"
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &attrs,
+			},
+		}
+		err := c.Create(ctx, review)
+		if err != nil {
+			return fmt.Errorf("failed to check permissions to impersonate %s: %w", what, err)
+		}
+		if review.Status.Allowed {
+			return nil
+		}
+		if review.Status.Reason != "" {
+			return fmt.Errorf("the current identity is not allowed to impersonate %s: %s", what, review.Status.Reason)
+		}
+		return fmt.Errorf("the current identity is not allowed to impersonate %s", what)
+	}
+
+	if imp.UserName != "" {
+		attrs := authorizationv1.ResourceAttributes{Resource: "users", Name: imp.UserName}
+		// service accounts are impersonated via the serviceaccounts resource, see the Kubernetes docs about impersonation
+		if s := strings.Split(imp.UserName, ":"); len(s) == 4 && s[0] == "system" && s[1] == "serviceaccount" {
+			attrs = authorizationv1.ResourceAttributes{Resource: "serviceaccounts", Namespace: s[2], Name: s[3]}
+		}
+		err = check(fmt.Sprintf("user '%s'", imp.UserName), attrs)
+		if err != nil {
+			return err
+		}
+	}
+	for _, g := range imp.Groups {
+		err = check(fmt.Sprintf("group '%s'", g), authorizationv1.ResourceAttributes{Resource: "groups", Name: g})
+		if err != nil {
+			return err
+		}
+	}
+	if imp.UID != "" {
+		err = check(fmt.Sprintf("uid '%s'", imp.UID), authorizationv1.ResourceAttributes{Group: "authentication.k8s.io", Resource: "uids", Name: imp.UID})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
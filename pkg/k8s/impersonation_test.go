@@ -0,0 +1,70 @@
+package k8s
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"testing"
+)
+
+// newTestAccessReviewClient returns a fake client that answers SelfSubjectAccessReviews by looking up the reviewed
+// resource attributes in allowed and records all reviews
+func newTestAccessReviewClient(allowed map[authorizationv1.ResourceAttributes]bool, reviews *[]authorizationv1.ResourceAttributes) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			review, ok := obj.(*authorizationv1.SelfSubjectAccessReview)
+			if !ok {
+				return c.Create(ctx, obj, opts...)
+			}
+			attrs := *review.Spec.ResourceAttributes
+			*reviews = append(*reviews, attrs)
+			review.Status.Allowed = allowed[attrs]
+			if !review.Status.Allowed {
+				review.Status.Reason = "denied by test"
+			}
+			return nil
+		},
+	}).Build()
+}
+
+func TestCheckImpersonationAllowed(t *testing.T) {
+	sa := authorizationv1.ResourceAttributes{Verb: "impersonate", Resource: "serviceaccounts", Namespace: "ns", Name: "sa"}
+	group := authorizationv1.ResourceAttributes{Verb: "impersonate", Resource: "groups", Name: "g1"}
+	uid := authorizationv1.ResourceAttributes{Verb: "impersonate", Group: "authentication.k8s.io", Resource: "uids", Name: "uid"}
+
+	var reviews []authorizationv1.ResourceAttributes
+	c := newTestAccessReviewClient(map[authorizationv1.ResourceAttributes]bool{sa: true, group: true, uid: true}, &reviews)
+
+	err := checkImpersonation(context.TODO(), c, rest.ImpersonationConfig{
+		UserName: "system:serviceaccount:ns:sa",
+		Groups:   []string{"g1"},
+		UID:      "uid",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []authorizationv1.ResourceAttributes{sa, group, uid}, reviews)
+}
+
+func TestCheckImpersonationDenied(t *testing.T) {
+	user := authorizationv1.ResourceAttributes{Verb: "impersonate", Resource: "users", Name: "user"}
+
+	var reviews []authorizationv1.ResourceAttributes
+	c := newTestAccessReviewClient(map[authorizationv1.ResourceAttributes]bool{user: true}, &reviews)
+
+	err := checkImpersonation(context.TODO(), c, rest.ImpersonationConfig{
+		UserName: "user",
+		Groups:   []string{"g1", "g2"},
+	})
+	assert.EqualError(t, err, "the current identity is not allowed to impersonate group 'g1': denied by test")
+	// the check must stop at the first denied review
+	assert.Len(t, reviews, 2)
+}
+
+func TestCheckImpersonationDisabled(t *testing.T) {
+	// no impersonation configured, so no client is created and nothing is checked
+	assert.NoError(t, CheckImpersonation(context.TODO(), &rest.Config{Host: "https://invalid.example.com"}))
+}
@@ -323,6 +323,25 @@ func (k *K8sCluster) FixObjectForPatch(o *uo.UnstructuredObject) *uo.Unstructure
 type PatchOptions struct {
 	ForceDryRun bool
 	ForceApply  bool
+
+	// Timeout limits the time the patch request is allowed to take. 0 means no timeout.
+	Timeout time.Duration
+}
+
+// withRequestTimeout runs fn with a context that is cancelled after the given timeout. If the timeout is hit, the
+// returned error is replaced with a timeout error that does not wrap context.DeadlineExceeded, so that callers can
+// differentiate between a single request timing out and the whole operation being cancelled.
+func (k *K8sCluster) withRequestTimeout(ref k8s.ObjectRef, op string, timeout time.Duration, fn func(ctx context.Context) error) error {
+	if timeout == 0 {
+		return fn(k.ctx)
+	}
+	ctx, cancel := context.WithTimeout(k.ctx, timeout)
+	defer cancel()
+	err := fn(ctx)
+	if err != nil && ctx.Err() != nil && k.ctx.Err() == nil {
+		return fmt.Errorf("timed out after %s while %s %s", timeout.String(), op, ref.String())
+	}
+	return err
 }
 
 func (k *K8sCluster) doPatch(ref k8s.ObjectRef, obj client.Object, patch client.Patch, options PatchOptions) ([]ApiWarning, error) {
@@ -342,11 +361,13 @@ func (k *K8sCluster) doPatch(ref k8s.ObjectRef, obj client.Object, patch client.
 	opts = append(opts, client.FieldOwner("kluctl"))
 
 	apiWarnings, err := k.clients.withCClientFromPool(k.ctx, k.DryRun, func(c client.Client) error {
-		err := c.Patch(k.ctx, obj, patch, opts...)
-		if err != nil {
-			return fmt.Errorf("failed to patch %s: %w", ref.String(), err)
-		}
-		return nil
+		return k.withRequestTimeout(ref, "patching", options.Timeout, func(ctx context.Context) error {
+			err := c.Patch(ctx, obj, patch, opts...)
+			if err != nil {
+				return fmt.Errorf("failed to patch %s: %w", ref.String(), err)
+			}
+			return nil
+		})
 	})
 	return apiWarnings, err
 }
@@ -362,6 +383,9 @@ func (k *K8sCluster) ApplyObject(o *uo.UnstructuredObject, options PatchOptions)
 
 type UpdateOptions struct {
 	ForceDryRun bool
+
+	// Timeout limits the time the update request is allowed to take. 0 means no timeout.
+	Timeout time.Duration
 }
 
 func (k *K8sCluster) UpdateObject(o *uo.UnstructuredObject, options UpdateOptions) (*uo.UnstructuredObject, []ApiWarning, error) {
@@ -381,7 +405,9 @@ func (k *K8sCluster) UpdateObject(o *uo.UnstructuredObject, options UpdateOption
 	opts = append(opts, client.FieldOwner("kluctl"))
 
 	apiWarnings, err := k.clients.withCClientFromPool(k.ctx, k.DryRun, func(c client.Client) error {
-		return c.Update(k.ctx, obj, opts...)
+		return k.withRequestTimeout(ref, "updating", options.Timeout, func(ctx context.Context) error {
+			return c.Update(ctx, obj, opts...)
+		})
 	})
 	if err != nil {
 		return nil, apiWarnings, err
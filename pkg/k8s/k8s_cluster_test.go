@@ -0,0 +1,48 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWithRequestTimeout(t *testing.T) {
+	ref := k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm", Namespace: "default"}
+	k := &K8sCluster{ctx: context.Background()}
+
+	// no timeout, the parent context is passed through
+	err := k.withRequestTimeout(ref, "patching", 0, func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// errors that are not caused by the timeout are passed through
+	testErr := errors.New("test")
+	err = k.withRequestTimeout(ref, "patching", time.Second, func(ctx context.Context) error {
+		return testErr
+	})
+	assert.ErrorIs(t, err, testErr)
+
+	// hitting the timeout results in a timeout error which does not wrap context.DeadlineExceeded
+	err = k.withRequestTimeout(ref, "patching", 10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.EqualError(t, err, "timed out after 10ms while patching "+ref.String())
+	assert.False(t, errors.Is(err, context.DeadlineExceeded))
+
+	// cancellation of the parent context is not reported as a request timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	k = &K8sCluster{ctx: ctx}
+	cancel()
+	err = k.withRequestTimeout(ref, "updating", time.Second, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
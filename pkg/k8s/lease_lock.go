@@ -0,0 +1,278 @@
+package k8s
+
+import (
+	"context"
+	errors2 "errors"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+	"time"
+)
+
+const (
+	leaseLockDuration      = 60 * time.Second
+	leaseLockRetryInterval = 2 * time.Second
+)
+
+// LeaseLock implements a simple cluster-side lock based on coordination.k8s.io Leases. The lease is renewed in the
+// background until the lock is released. Leases that were not renewed in time (e.g. because the holder crashed) are
+// considered expired and can be taken over. If the lock gets lost while being held (it was taken over or could not be
+// renewed within the lease duration), the channel returned by Lost is closed.
+type LeaseLock struct {
+	c         client.Client
+	namespace string
+	name      string
+	identity  string
+
+	leaseDuration time.Duration
+	retryInterval time.Duration
+
+	stopRenew chan struct{}
+	wg        sync.WaitGroup
+
+	lost     chan struct{}
+	lostErr  error
+	lostOnce sync.Once
+}
+
+func NewLeaseLock(c client.Client, namespace string, name string, identity string) *LeaseLock {
+	return &LeaseLock{
+		c:             c,
+		namespace:     namespace,
+		name:          name,
+		identity:      identity,
+		leaseDuration: leaseLockDuration,
+		retryInterval: leaseLockRetryInterval,
+		lost:          make(chan struct{}),
+	}
+}
+
+// Lost returns a channel that is closed when the lock got lost while being held
+func (l *LeaseLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// LostErr returns the reason why the lock got lost or nil if it was not lost
+func (l *LeaseLock) LostErr() error {
+	select {
+	case <-l.lost:
+		return l.lostErr
+	default:
+		return nil
+	}
+}
+
+func (l *LeaseLock) markLost(err error) {
+	l.lostOnce.Do(func() {
+		l.lostErr = fmt.Errorf("lock %s/%s was lost: %w", l.namespace, l.name, err)
+		close(l.lost)
+	})
+}
+
+// Acquire tries to acquire the lock. If the lock is held by someone else, it retries until timeout is exceeded, in
+// which case an error containing the current holder is returned. A timeout of 0 means to fail immediately.
+func (l *LeaseLock) Acquire(ctx context.Context, timeout time.Duration) error {
+	err := l.ensureNamespace(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		holder, err := l.tryAcquire(ctx)
+		if err != nil {
+			return err
+		}
+		if holder == nil {
+			break
+		}
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("lock %s/%s is held by %s", l.namespace, l.name, *holder)
+		}
+		status.Tracef(ctx, "Lock %s/%s is held by %s, retrying", l.namespace, l.name, *holder)
+
+		select {
+		case <-time.After(l.retryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	l.stopRenew = make(chan struct{})
+	l.wg.Add(1)
+	go l.renewLoop(ctx)
+	return nil
+}
+
+// Release stops renewing the lease and deletes it
+func (l *LeaseLock) Release(ctx context.Context) error {
+	if l.stopRenew == nil {
+		return nil
+	}
+	close(l.stopRenew)
+	l.wg.Wait()
+	l.stopRenew = nil
+
+	var lease coordinationv1.Lease
+	err := l.c.Get(ctx, client.ObjectKey{Namespace: l.namespace, Name: l.name}, &lease)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != l.identity {
+		return fmt.Errorf("lock %s/%s was taken over by someone else", l.namespace, l.name)
+	}
+	err = l.c.Delete(ctx, &lease, client.Preconditions{ResourceVersion: &lease.ResourceVersion})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *LeaseLock) ensureNamespace(ctx context.Context) error {
+	var ns corev1.Namespace
+	err := l.c.Get(ctx, client.ObjectKey{Name: l.namespace}, &ns)
+	if err != nil && errors.IsNotFound(err) {
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: l.namespace,
+			},
+		}
+		err = l.c.Create(ctx, ns)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// tryAcquire tries to acquire the lock once. It returns the holder identity if the lock is held by someone else.
+func (l *LeaseLock) tryAcquire(ctx context.Context) (*string, error) {
+	now := metav1.NewMicroTime(time.Now())
+
+	var lease coordinationv1.Lease
+	err := l.c.Get(ctx, client.ObjectKey{Namespace: l.namespace, Name: l.name}, &lease)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		lease = coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: l.namespace,
+				Name:      l.name,
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &l.identity,
+				LeaseDurationSeconds: utils.Ptr(l.leaseDurationSeconds()),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		err = l.c.Create(ctx, &lease)
+		if err != nil {
+			if errors.IsAlreadyExists(err) {
+				// someone else was faster, retry later
+				return utils.Ptr("<unknown>"), nil
+			}
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	if holder := lease.Spec.HolderIdentity; holder != nil && *holder != "" && *holder != l.identity && !isLeaseExpired(&lease) {
+		return holder, nil
+	}
+
+	lease.Spec.HolderIdentity = &l.identity
+	lease.Spec.LeaseDurationSeconds = utils.Ptr(l.leaseDurationSeconds())
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+	err = l.c.Update(ctx, &lease)
+	if err != nil {
+		if errors.IsConflict(err) {
+			// someone else was faster, retry later
+			return utils.Ptr("<unknown>"), nil
+		}
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (l *LeaseLock) leaseDurationSeconds() int32 {
+	return int32(l.leaseDuration.Seconds())
+}
+
+func (l *LeaseLock) renewLoop(ctx context.Context) {
+	defer l.wg.Done()
+
+	lastRenew := time.Now()
+	ticker := time.NewTicker(l.leaseDuration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stopRenew:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := l.renew(ctx)
+		if err == nil {
+			lastRenew = time.Now()
+			continue
+		}
+		if errors2.Is(err, errLeaseTakenOver) {
+			l.markLost(err)
+			return
+		}
+		if time.Since(lastRenew) >= l.leaseDuration {
+			// others consider the lease expired by now
+			l.markLost(fmt.Errorf("failed to renew within the lease duration: %w", err))
+			return
+		}
+		status.Warningf(ctx, "Failed to renew lock %s/%s: %s", l.namespace, l.name, err.Error())
+	}
+}
+
+var errLeaseTakenOver = errors2.New("lock was taken over by someone else")
+
+func (l *LeaseLock) renew(ctx context.Context) error {
+	var lease coordinationv1.Lease
+	err := l.c.Get(ctx, client.ObjectKey{Namespace: l.namespace, Name: l.name}, &lease)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return errLeaseTakenOver
+		}
+		return err
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != l.identity {
+		return errLeaseTakenOver
+	}
+	now := metav1.NewMicroTime(time.Now())
+	lease.Spec.RenewTime = &now
+	err = l.c.Update(ctx, &lease)
+	if err != nil && errors.IsConflict(err) {
+		// someone else modified the lease in the meantime, which can only happen when it was taken over
+		return errLeaseTakenOver
+	}
+	return err
+}
+
+func isLeaseExpired(lease *coordinationv1.Lease) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return time.Now().After(expiry)
+}
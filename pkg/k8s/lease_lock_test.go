@@ -0,0 +1,153 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestLeaseLock(c client.Client, identity string) *LeaseLock {
+	l := NewLeaseLock(c, "kluctl-locks", "lock", identity)
+	l.leaseDuration = 3 * time.Second
+	l.retryInterval = 10 * time.Millisecond
+	return l
+}
+
+func getTestLease(c client.Client) *coordinationv1.Lease {
+	var lease coordinationv1.Lease
+	err := c.Get(context.TODO(), client.ObjectKey{Namespace: "kluctl-locks", Name: "lock"}, &lease)
+	if err != nil {
+		return nil
+	}
+	return &lease
+}
+
+func TestLeaseLockAcquireRelease(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+
+	l1 := newTestLeaseLock(c, "l1")
+	err := l1.Acquire(context.TODO(), 0)
+	assert.NoError(t, err)
+
+	lease := getTestLease(c)
+	if assert.NotNil(t, lease) {
+		assert.Equal(t, "l1", *lease.Spec.HolderIdentity)
+	}
+
+	// the lock is held, so the second one must fail
+	l2 := newTestLeaseLock(c, "l2")
+	err = l2.Acquire(context.TODO(), 50*time.Millisecond)
+	assert.EqualError(t, err, "lock kluctl-locks/lock is held by l1")
+
+	err = l1.Release(context.TODO())
+	assert.NoError(t, err)
+	assert.Nil(t, getTestLease(c))
+	assert.NoError(t, l1.LostErr())
+
+	err = l2.Acquire(context.TODO(), 0)
+	assert.NoError(t, err)
+	err = l2.Release(context.TODO())
+	assert.NoError(t, err)
+}
+
+func TestLeaseLockAcquireWaits(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+
+	l1 := newTestLeaseLock(c, "l1")
+	err := l1.Acquire(context.TODO(), 0)
+	assert.NoError(t, err)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = l1.Release(context.TODO())
+	}()
+
+	l2 := newTestLeaseLock(c, "l2")
+	err = l2.Acquire(context.TODO(), 5*time.Second)
+	assert.NoError(t, err)
+	_ = l2.Release(context.TODO())
+}
+
+func TestLeaseLockExpiredTakeover(t *testing.T) {
+	renewTime := metav1.NewMicroTime(time.Now().Add(-time.Minute))
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kluctl-locks", Name: "lock"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       utils.Ptr("crashed"),
+			LeaseDurationSeconds: utils.Ptr(int32(3)),
+			RenewTime:            &renewTime,
+		},
+	}).Build()
+
+	l := newTestLeaseLock(c, "l1")
+	err := l.Acquire(context.TODO(), 0)
+	assert.NoError(t, err)
+
+	lease := getTestLease(c)
+	if assert.NotNil(t, lease) {
+		assert.Equal(t, "l1", *lease.Spec.HolderIdentity)
+		assert.False(t, isLeaseExpired(lease))
+	}
+	_ = l.Release(context.TODO())
+}
+
+func TestLeaseLockLostOnTakeover(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+
+	l := newTestLeaseLock(c, "l1")
+	err := l.Acquire(context.TODO(), 0)
+	assert.NoError(t, err)
+
+	// simulate a takeover by someone else
+	lease := getTestLease(c)
+	lease.Spec.HolderIdentity = utils.Ptr("other")
+	err = c.Update(context.TODO(), lease)
+	assert.NoError(t, err)
+
+	select {
+	case <-l.Lost():
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "lock was not detected as lost")
+	}
+	assert.ErrorIs(t, l.LostErr(), errLeaseTakenOver)
+
+	err = l.Release(context.TODO())
+	assert.EqualError(t, err, "lock kluctl-locks/lock was taken over by someone else")
+	// the lease of the new holder must not be deleted
+	assert.NotNil(t, getTestLease(c))
+}
+
+func TestLeaseLockLostOnRenewFailures(t *testing.T) {
+	var failUpdates atomic.Bool
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			if failUpdates.Load() {
+				return errors.New("connection refused")
+			}
+			return c.Update(ctx, obj, opts...)
+		},
+	}).Build()
+
+	l := newTestLeaseLock(c, "l1")
+	err := l.Acquire(context.TODO(), 0)
+	assert.NoError(t, err)
+	failUpdates.Store(true)
+
+	select {
+	case <-l.Lost():
+	case <-time.After(10 * time.Second):
+		assert.Fail(t, "lock was not detected as lost")
+	}
+	assert.ErrorContains(t, l.LostErr(), "failed to renew within the lease duration: connection refused")
+	_ = l.Release(context.TODO())
+}
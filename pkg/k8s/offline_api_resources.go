@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"os"
+	"strings"
+)
+
+// OfflineApiResources holds the API resources of a cluster that is not reachable, e.g. when running with
+// --offline-kubernetes. It is loaded from the output of 'kubectl api-resources'.
+type OfflineApiResources struct {
+	resources  []v1.APIResource
+	namespaced map[schema.GroupKind]bool
+}
+
+var offlineApiResourcesColumns = []string{"NAME", "SHORTNAMES", "APIVERSION", "NAMESPACED", "KIND"}
+
+// LoadOfflineApiResources loads the given file, which must contain the table output of 'kubectl api-resources'
+// (optionally with '-o wide').
+func LoadOfflineApiResources(path string) (*OfflineApiResources, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offline api resources: %w", err)
+	}
+	ret, err := ParseOfflineApiResources(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse offline api resources from %s: %w", path, err)
+	}
+	return ret, nil
+}
+
+func ParseOfflineApiResources(b []byte) (*OfflineApiResources, error) {
+	ret := &OfflineApiResources{
+		namespaced: map[schema.GroupKind]bool{},
+	}
+
+	// columns are aligned by kubectl, but SHORTNAMES might be empty, so we need to use the column offsets from the
+	// header instead of simply splitting by whitespace
+	var offsets []int
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if offsets == nil {
+			offsets = make([]int, len(offlineApiResourcesColumns))
+			for i, c := range offlineApiResourcesColumns {
+				offsets[i] = findColumn(line, c)
+				if offsets[i] == -1 {
+					return nil, fmt.Errorf("header is missing the %s column", c)
+				}
+			}
+			continue
+		}
+
+		fields := make([]string, len(offsets))
+		for i, o := range offsets {
+			if o >= len(line) {
+				continue
+			}
+			end := len(line)
+			if i+1 < len(offsets) && offsets[i+1] < end {
+				end = offsets[i+1]
+			}
+			fields[i] = strings.TrimSpace(line[o:end])
+		}
+		// KIND is followed by VERBS and CATEGORIES when using '-o wide'
+		kind, _, _ := strings.Cut(fields[4], " ")
+
+		gv, err := schema.ParseGroupVersion(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		if fields[0] == "" || kind == "" || (fields[3] != "true" && fields[3] != "false") {
+			return nil, fmt.Errorf("line %d: invalid api resource", lineNum)
+		}
+
+		ar := v1.APIResource{
+			Name:       fields[0],
+			Namespaced: fields[3] == "true",
+			Group:      gv.Group,
+			Version:    gv.Version,
+			Kind:       kind,
+		}
+		if fields[1] != "" {
+			ar.ShortNames = strings.Split(fields[1], ",")
+		}
+		ret.resources = append(ret.resources, ar)
+		ret.namespaced[schema.GroupKind{Group: ar.Group, Kind: ar.Kind}] = ar.Namespaced
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if offsets == nil {
+		return nil, fmt.Errorf("no header found")
+	}
+	return ret, nil
+}
+
+func findColumn(header string, name string) int {
+	// the padding ensures that only whole words match, the offset stays the same as in the unpadded header
+	return strings.Index(" "+header+" ", " "+name+" ")
+}
+
+func (r *OfflineApiResources) GetAllAPIResources() []v1.APIResource {
+	return r.resources
+}
+
+// IsNamespaced returns nil if the given GroupKind is not known
+func (r *OfflineApiResources) IsNamespaced(gk schema.GroupKind) *bool {
+	b, ok := r.namespaced[gk]
+	if !ok {
+		return nil
+	}
+	return &b
+}
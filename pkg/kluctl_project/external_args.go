@@ -2,11 +2,14 @@ package kluctl_project
 
 import (
 	"fmt"
+	"github.com/hashicorp/go-multierror"
 	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/xeipuuv/gojsonschema"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -80,6 +83,107 @@ func LoadDefaultArgs(args []types.DeploymentArg, deployArgs *uo.UnstructuredObje
 	return nil
 }
 
+// ValidateArgs validates the given args against the declared args. Unknown args and args with a type that does not
+// match the declared type (or the type of the default value) are reported as errors.
+func ValidateArgs(argsDef []types.DeploymentArg, args *uo.UnstructuredObject) error {
+	schema, err := buildArgsSchema(argsDef)
+	if err != nil {
+		return err
+	}
+
+	r, err := gojsonschema.Validate(gojsonschema.NewGoLoader(schema), gojsonschema.NewGoLoader(args.Object))
+	if err != nil {
+		return fmt.Errorf("failed to validate args: %w", err)
+	}
+	if r.Valid() {
+		return nil
+	}
+
+	var errs []error
+	for _, e := range r.Errors() {
+		switch e.Type() {
+		case "additional_property_not_allowed":
+			name := e.Details()["property"]
+			if e.Field() != "(root)" {
+				name = fmt.Sprintf("%s.%v", e.Field(), name)
+			}
+			errs = append(errs, fmt.Errorf("unknown argument %v", name))
+		case "invalid_type":
+			errs = append(errs, fmt.Errorf("argument %s has the wrong type, expected %v but got %v", e.Field(), e.Details()["expected"], e.Details()["given"]))
+		default:
+			errs = append(errs, fmt.Errorf("argument %s: %s", e.Field(), e.Description()))
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+	return fmt.Errorf("invalid arguments: %w", multierror.Append(nil, errs...))
+}
+
+func buildArgsSchema(argsDef []types.DeploymentArg) (map[string]any, error) {
+	newObjectSchema := func() map[string]any {
+		return map[string]any{
+			"type":                 "object",
+			"properties":           map[string]any{},
+			"additionalProperties": false,
+		}
+	}
+
+	root := newObjectSchema()
+	for _, a := range argsDef {
+		t := a.Type
+		if t == "" && a.Default != nil {
+			var v any
+			err := yaml.ReadYamlBytes(a.Default.Raw, &v)
+			if err != nil {
+				return nil, err
+			}
+			t = jsonSchemaType(v)
+		}
+		argSchema := map[string]any{}
+		if t != "" {
+			argSchema["type"] = t
+		}
+
+		s := root
+		names := strings.Split(a.Name, ".")
+		for i, n := range names {
+			props := s["properties"].(map[string]any)
+			if i == len(names)-1 {
+				props[n] = argSchema
+				break
+			}
+			x, ok := props[n].(map[string]any)
+			if !ok {
+				x = newObjectSchema()
+				props[n] = x
+			} else if _, ok := x["properties"]; !ok {
+				// a parent arg was declared as a whole, so nested args are not restricted any further
+				break
+			}
+			s = x
+		}
+	}
+	return root, nil
+}
+
+func jsonSchemaType(v any) string {
+	switch v.(type) {
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64, int, int64:
+		return "number"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		return ""
+	}
+}
+
 func checkRequiredArgs(argsDef []types.DeploymentArg, args *uo.UnstructuredObject) error {
 	for _, a := range argsDef {
 		var p []interface{}
@@ -20,12 +20,18 @@ type LoadKluctlProjectArgs struct {
 	ProjectConfig string
 	ExternalArgs  *uo.UnstructuredObject
 
+	// StrictArgs causes all args to be validated against the declared args of the project
+	StrictArgs bool
+
 	GitRP *repocache.GitRepoCache
 	OciRP *repocache.OciRepoCache
 
 	OciAuthProvider  auth_provider.OciAuthProvider
 	HelmAuthProvider helm_auth.HelmAuthProvider
 
+	// SopsAgeKeyFiles specifies additional age key files to use for sops decryption
+	SopsAgeKeyFiles []string
+
 	AddKeyServersFunc  func(ctx context.Context, d *decryptor.Decryptor) error
 	ClientConfigGetter func(context *string) (*rest.Config, *api.Config, error)
 }
@@ -2,16 +2,19 @@ package target_context
 
 import (
 	"context"
+	"fmt"
+	"github.com/getsops/sops/v3/age"
 	"github.com/getsops/sops/v3/keyservice"
 	"github.com/getsops/sops/v3/kms"
 	"github.com/kluctl/kluctl/v2/pkg/clouds/aws"
 	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
 	intkeyservice "github.com/kluctl/kluctl/v2/pkg/sops/keyservice"
 	"github.com/kluctl/kluctl/v2/pkg/types"
+	"os"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func buildSopsDecrypter(ctx context.Context, rootDir string, client client.Client, target *types.Target, addKeyServersFunc func(ctx context.Context, d *decryptor.Decryptor) error) (*decryptor.Decryptor, error) {
+func buildSopsDecrypter(ctx context.Context, rootDir string, client client.Client, target *types.Target, ageKeyFiles []string, addKeyServersFunc func(ctx context.Context, d *decryptor.Decryptor) error) (*decryptor.Decryptor, error) {
 	d := decryptor.NewDecryptor(rootDir, decryptor.MaxEncryptedFileSize)
 
 	err := addAwsKeyServers(ctx, client, d, target)
@@ -19,6 +22,11 @@ func buildSopsDecrypter(ctx context.Context, rootDir string, client client.Clien
 		return nil, err
 	}
 
+	err = addAgeKeyFilesKeyServer(d, ageKeyFiles)
+	if err != nil {
+		return nil, err
+	}
+
 	if addKeyServersFunc != nil {
 		err = addKeyServersFunc(ctx, d)
 		if err != nil {
@@ -31,6 +39,30 @@ func buildSopsDecrypter(ctx context.Context, rootDir string, client client.Clien
 	return d, nil
 }
 
+// addAgeKeyFilesKeyServer adds a key service which knows about the age identities found in the given key files. This
+// is done in addition to the local key service, which still honors SOPS_AGE_KEY_FILE and the default age key locations.
+func addAgeKeyFilesKeyServer(d *decryptor.Decryptor, ageKeyFiles []string) error {
+	if len(ageKeyFiles) == 0 {
+		return nil
+	}
+
+	var ageIdentities age.ParsedIdentities
+	for _, p := range ageKeyFiles {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read sops age key file %s: %w", p, err)
+		}
+		if err = ageIdentities.Import(string(b)); err != nil {
+			return fmt.Errorf("failed to parse sops age key file %s: %w", p, err)
+		}
+	}
+
+	server := intkeyservice.NewServer(intkeyservice.WithAgeIdentities(ageIdentities))
+	d.AddKeyServiceClient(keyservice.NewCustomLocalClient(server))
+
+	return nil
+}
+
 func addAwsKeyServers(ctx context.Context, client client.Client, d *decryptor.Decryptor, target *types.Target) error {
 	cfg, err := aws.LoadAwsConfigHelper(ctx, client, target.Aws, nil)
 	if err != nil {
@@ -16,6 +16,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/vars"
 	"path/filepath"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
 )
 
 type TargetContext struct {
@@ -28,46 +29,110 @@ type TargetContext struct {
 	ClusterContext       string
 	DeploymentProject    *deployment.DeploymentProject
 	DeploymentCollection *deployment.DeploymentCollection
+
+	repoRoot      string
+	relProjectDir string
 }
 
 type TargetContextParams struct {
-	TargetName         string
-	TargetNameOverride string
-	ContextOverride    string
-	Discriminator      string
-	OfflineK8s         bool
-	K8sVersion         string
-	DryRun             bool
-	Images             *deployment.Images
-	Inclusion          *utils.Inclusion
-	HelmAuthProvider   auth.HelmAuthProvider
-	OciAuthProvider    auth_provider.OciAuthProvider
-	RenderOutputDir    string
+	TargetName          string
+	TargetNameOverride  string
+	ContextOverride     string
+	Discriminator       string
+	OfflineK8s          bool
+	K8sVersion          string
+	OfflineApiResources *k8s.OfflineApiResources
+	DryRun              bool
+	Images              *deployment.Images
+	Inclusion           *utils.Inclusion
+	HelmAuthProvider    auth.HelmAuthProvider
+	OciAuthProvider     auth_provider.OciAuthProvider
+	RenderOutputDir     string
+	RenderExcludeHooks  bool
+	RenderOnlyHooks     bool
+
+	AllowMissingSopsKeys bool
+	NoIgnoreMissingVars  bool
 }
 
 func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject, contextName string, k *k8s.K8sCluster, params TargetContextParams) (*TargetContext, error) {
-	repoRoot, err := filepath.Abs(p.LoadArgs.RepoRoot)
+	targetCtx, varsCtx, err := PrepareTargetContext(ctx, p, contextName, k, params)
 	if err != nil {
 		return nil, err
 	}
+
+	d, err := deployment.NewDeploymentProject(targetCtx.SharedContext, varsCtx, deployment.NewSource(targetCtx.repoRoot), targetCtx.relProjectDir, nil)
+	if err != nil {
+		return targetCtx, targetCtx.SharedContext.VarsLoader.WrapMissingSopsKeysError(err)
+	}
+	targetCtx.DeploymentProject = d
+
+	inclusion, err := renderInclusionTags(varsCtx, params.Inclusion)
+	if err != nil {
+		return targetCtx, err
+	}
+	targetCtx.Params.Inclusion = inclusion
+
+	c, err := deployment.NewDeploymentCollection(targetCtx.SharedContext, d, params.Images, inclusion)
+	if err != nil {
+		return targetCtx, targetCtx.SharedContext.VarsLoader.WrapMissingSopsKeysError(err)
+	}
+	targetCtx.DeploymentCollection = c
+
+	return targetCtx, nil
+}
+
+// renderInclusionTags renders all included/excluded tags that contain jinja2 templates. This allows to derive tags
+// from args and vars. The rendered result is split by commas, so that a single template can result in multiple tags.
+// Rendering to an empty value is treated as an error, as it would otherwise silently remove the inclusion rule.
+func renderInclusionTags(varsCtx *vars.VarsCtx, inclusion *utils.Inclusion) (*utils.Inclusion, error) {
+	return inclusion.RenderValues("tag", func(v string) ([]string, error) {
+		if !strings.Contains(v, "{{") && !strings.Contains(v, "{%") {
+			return []string{v}, nil
+		}
+		r, err := varsCtx.RenderString(v, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render inclusion tag '%s': %w", v, err)
+		}
+		var ret []string
+		for _, x := range strings.Split(r, ",") {
+			x = strings.TrimSpace(x)
+			if x != "" {
+				ret = append(ret, x)
+			}
+		}
+		if len(ret) == 0 {
+			return nil, fmt.Errorf("inclusion tag '%s' rendered to an empty value", v)
+		}
+		return ret, nil
+	})
+}
+
+// PrepareTargetContext prepares the target context without loading the deployment project. It also returns the vars
+// that are used to load the root deployment project.
+func PrepareTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject, contextName string, k *k8s.K8sCluster, params TargetContextParams) (*TargetContext, *vars.VarsCtx, error) {
+	repoRoot, err := filepath.Abs(p.LoadArgs.RepoRoot)
+	if err != nil {
+		return nil, nil, err
+	}
 	relProjectDir, err := filepath.Rel(repoRoot, p.LoadArgs.ProjectDir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var target *types.Target
 	if params.TargetName != "" {
 		t, err := p.FindTarget(params.TargetName)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		target = &*t
 	} else {
 		if len(p.Targets) != 0 {
 			status.Deprecation(ctx, "no-target", "Warning, tried to use Kluctl without explicitly specifying a target, while the Kluctl project contains target definitions. This was allowed in older version of Kluctl, but is forbidden since v2.23.0. If mixing deployments with and without targets was actually intended, please switch to creating and using a dedicated target that serves as a replacement for no-target deployments.")
-			return nil, fmt.Errorf("a target must be explicitly selected when targets are defined in the Kluctl project")
+			return nil, nil, fmt.Errorf("a target must be explicitly selected when targets are defined in the Kluctl project")
 		} else if p.NoNameTarget == nil {
-			return nil, fmt.Errorf("missing no-name target, which is unexpected")
+			return nil, nil, fmt.Errorf("missing no-name target, which is unexpected")
 		}
 		target = &*p.NoNameTarget
 	}
@@ -84,35 +149,42 @@ func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject
 
 	varsCtx, err := p.BuildVars(target)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var client client.Client
 	if k != nil {
 		client, err = k.ToClient()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	sopsDecryptor, err := buildSopsDecrypter(ctx, p.LoadArgs.ProjectDir, client, target, p.LoadArgs.AddKeyServersFunc)
+	sopsDecryptor, err := buildSopsDecrypter(ctx, p.LoadArgs.ProjectDir, client, target, p.LoadArgs.SopsAgeKeyFiles, p.LoadArgs.AddKeyServersFunc)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	varsLoader := vars.NewVarsLoader(ctx, k, sopsDecryptor, p.GitRP, aws.NewClientFactory(client, target.Aws), gcp.NewClientFactory())
+	varsLoader.SetAllowMissingSopsKeys(params.AllowMissingSopsKeys)
+	varsLoader.SetNoIgnoreMissing(params.NoIgnoreMissingVars)
+	varsLoader.SetContextName(contextName)
+	varsLoader.SetOciRepoCache(p.OciRP)
 
 	dctx := deployment.SharedContext{
-		Ctx:              ctx,
-		K:                k,
-		K8sVersion:       params.K8sVersion,
-		GitRP:            p.GitRP,
-		OciRP:            p.OciRP,
-		SopsDecrypter:    sopsDecryptor,
-		VarsLoader:       varsLoader,
-		HelmAuthProvider: params.HelmAuthProvider,
-		OciAuthProvider:  params.OciAuthProvider,
-		Discriminator:    target.Discriminator,
-		RenderDir:        params.RenderOutputDir,
+		Ctx:                 ctx,
+		K:                   k,
+		K8sVersion:          params.K8sVersion,
+		OfflineApiResources: params.OfflineApiResources,
+		GitRP:               p.GitRP,
+		OciRP:               p.OciRP,
+		SopsDecrypter:       sopsDecryptor,
+		VarsLoader:          varsLoader,
+		HelmAuthProvider:    params.HelmAuthProvider,
+		OciAuthProvider:     params.OciAuthProvider,
+		Discriminator:       target.Discriminator,
+		RenderDir:           params.RenderOutputDir,
+		RenderExcludeHooks:  params.RenderExcludeHooks,
+		RenderOnlyHooks:     params.RenderOnlyHooks,
 	}
 
 	targetCtx := &TargetContext{
@@ -121,19 +193,15 @@ func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject
 		KluctlProject:  p,
 		Target:         *target,
 		ClusterContext: contextName,
+		repoRoot:       repoRoot,
+		relProjectDir:  relProjectDir,
 	}
 
-	d, err := deployment.NewDeploymentProject(dctx, varsCtx, deployment.NewSource(repoRoot), relProjectDir, nil)
-	if err != nil {
-		return targetCtx, err
-	}
-	targetCtx.DeploymentProject = d
-
-	c, err := deployment.NewDeploymentCollection(dctx, d, params.Images, params.Inclusion)
-	if err != nil {
-		return targetCtx, err
-	}
-	targetCtx.DeploymentCollection = c
+	return targetCtx, varsCtx, nil
+}
 
-	return targetCtx, nil
+// LoadRootProjectVarsSources loads the vars sources of the root deployment project without loading the whole
+// project. See deployment.LoadRootProjectVarsSources for details.
+func (tc *TargetContext) LoadRootProjectVarsSources(varsCtx *vars.VarsCtx, onlySource string, withPriorSources bool) ([]deployment.RenderedVarsSource, error) {
+	return deployment.LoadRootProjectVarsSources(tc.SharedContext, varsCtx, deployment.NewSource(tc.repoRoot), tc.relProjectDir, onlySource, withPriorSources)
 }
@@ -24,6 +24,13 @@ func (p *LoadedKluctlProject) BuildVars(target *types.Target) (*vars.VarsCtx, er
 		allArgs.Merge(p.LoadArgs.ExternalArgs)
 	}
 
+	if p.LoadArgs.StrictArgs {
+		err = ValidateArgs(p.Config.Args, allArgs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = LoadDefaultArgs(p.Config.Args, allArgs)
 	if err != nil {
 		return nil, err
@@ -108,6 +108,7 @@ func (c *Client) List(ctx context.Context, url string, opts ListOptions) ([]Meta
 		meta.Revision = manifestMetadata.Revision
 		meta.Source = manifestMetadata.Source
 		meta.Created = manifestMetadata.Created
+		meta.Annotations = manifest.Annotations
 
 		digest, err := crane.Digest(meta.URL, c.optionsWithContext(ctx)...)
 		if err != nil {
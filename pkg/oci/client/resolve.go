@@ -0,0 +1,25 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// Resolve resolves the given artifact URL to its digest without downloading any layers. The returned reference
+// is in the form of '<repository>@<digest>' and can be passed to Pull.
+func (c *Client) Resolve(ctx context.Context, url string) (string, error) {
+	ref, err := name.ParseReference(url)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+
+	digest, err := crane.Digest(url, c.optionsWithContext(ctx)...)
+	if err != nil {
+		return "", err
+	}
+
+	return ref.Context().Digest(digest).String(), nil
+}
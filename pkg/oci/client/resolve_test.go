@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Resolve(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	c := NewClient(DefaultOptions())
+	testRepo := "test-resolve"
+	url := fmt.Sprintf("%s/%s:v0.0.1", dockerReg, testRepo)
+
+	img1, err := random.Image(1024, 1)
+	g.Expect(err).ToNot(HaveOccurred())
+	err = crane.Push(img1, url, c.options...)
+	g.Expect(err).ToNot(HaveOccurred())
+	digest1, err := img1.Digest()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	resolved, err := c.Resolve(ctx, url)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resolved).To(Equal(fmt.Sprintf("%s/%s@%s", dockerReg, testRepo, digest1.String())))
+
+	// moving the tag must be detected
+	img2, err := random.Image(1024, 1)
+	g.Expect(err).ToNot(HaveOccurred())
+	err = crane.Push(img2, url, c.options...)
+	g.Expect(err).ToNot(HaveOccurred())
+	digest2, err := img2.Digest()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	resolved, err = c.Resolve(ctx, url)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resolved).To(Equal(fmt.Sprintf("%s/%s@%s", dockerReg, testRepo, digest2.String())))
+}
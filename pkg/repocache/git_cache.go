@@ -2,6 +2,7 @@ package repocache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
@@ -27,6 +28,8 @@ type GitRepoCache struct {
 	authProviders  *auth.GitAuthProviders
 	sshPool        *ssh_pool.SshPool
 	updateInterval time.Duration
+	gitTimeout     time.Duration
+	offline        bool
 
 	repos      map[types.RepoKey]*GitCacheEntry
 	reposMutex sync.Mutex
@@ -60,17 +63,26 @@ type clonedDir struct {
 	info git.CheckoutInfo
 }
 
-func NewGitRepoCache(ctx context.Context, sshPool *ssh_pool.SshPool, authProviders *auth.GitAuthProviders, repoOverrides sourceoverride.Resolver, updateInterval time.Duration) *GitRepoCache {
+// NewGitRepoCache creates a new GitRepoCache. If gitTimeout is non-zero, each individual git update (clone/fetch) is
+// bound by this timeout. The deadline of ctx still applies as an outer bound.
+func NewGitRepoCache(ctx context.Context, sshPool *ssh_pool.SshPool, authProviders *auth.GitAuthProviders, repoOverrides sourceoverride.Resolver, updateInterval time.Duration, gitTimeout time.Duration) *GitRepoCache {
 	return &GitRepoCache{
 		ctx:            ctx,
 		sshPool:        sshPool,
 		authProviders:  authProviders,
 		updateInterval: updateInterval,
+		gitTimeout:     gitTimeout,
 		repos:          map[types.RepoKey]*GitCacheEntry{},
 		repoOverrides:  repoOverrides,
 	}
 }
 
+// SetOffline enables or disables the offline mode. In offline mode, the cache never fetches from remote repositories
+// and only serves repositories and refs that are already present in the local git cache.
+func (rp *GitRepoCache) SetOffline(offline bool) {
+	rp.offline = offline
+}
+
 func (rp *GitRepoCache) Clear() {
 	rp.cleanupDirsMutex.Lock()
 	defer rp.cleanupDirsMutex.Unlock()
@@ -150,13 +162,19 @@ func (e *GitCacheEntry) Update() error {
 	defer e.mr.Unlock()
 
 	if !e.mr.HasUpdated() {
-		if time.Now().Sub(e.mr.LastUpdateTime()) <= e.rp.updateInterval {
+		if e.rp.offline {
+			if e.mr.LastUpdateTime().IsZero() {
+				url := e.mr.Url()
+				return fmt.Errorf("git repository %s is not present in the local git cache and fetching is disabled (offline mode)", url.String())
+			}
+			e.mr.SetUpdated(true)
+		} else if time.Now().Sub(e.mr.LastUpdateTime()) <= e.rp.updateInterval {
 			e.mr.SetUpdated(true)
 		} else {
 			url := e.mr.Url()
 			s := status.Startf(e.rp.ctx, "Updating git cache for %s", url.String())
 			defer s.Failed()
-			err := e.mr.Update()
+			err := e.updateMirror()
 			if err != nil {
 				s.FailedWithMessage(err.Error())
 				return err
@@ -184,6 +202,22 @@ func (e *GitCacheEntry) Update() error {
 	return nil
 }
 
+func (e *GitCacheEntry) updateMirror() error {
+	if e.rp.gitTimeout == 0 {
+		return e.mr.Update()
+	}
+
+	ctx, cancel := context.WithTimeout(e.rp.ctx, e.rp.gitTimeout)
+	defer cancel()
+
+	err := e.mr.UpdateWithContext(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && e.rp.ctx.Err() == nil {
+		url := e.mr.Url()
+		return fmt.Errorf("git operation for %s timed out after %s: %w", url.String(), e.rp.gitTimeout, err)
+	}
+	return err
+}
+
 func (e *GitCacheEntry) GetRepoInfo() RepoInfo {
 	e.updateMutex.Lock()
 	defer e.updateMutex.Unlock()
@@ -243,6 +277,12 @@ func (e *GitCacheEntry) findRef(ref string) (string, string, error) {
 }
 
 func (e *GitCacheEntry) GetClonedDir(ref *types.GitRef) (string, git.CheckoutInfo, error) {
+	return e.GetClonedDirSparse(ref, nil)
+}
+
+// GetClonedDirSparse is like GetClonedDir, but only checks out the given directories (relative to the repository
+// root). If the sparse checkout fails, it falls back to a full checkout.
+func (e *GitCacheEntry) GetClonedDirSparse(ref *types.GitRef, sparseDirs []string) (string, git.CheckoutInfo, error) {
 	e.updateMutex.Lock()
 	defer e.updateMutex.Unlock()
 
@@ -292,12 +332,21 @@ func (e *GitCacheEntry) GetClonedDir(ref *types.GitRef) (string, git.CheckoutInf
 	var checkoutInfo git.CheckoutInfo
 	if ref.Commit != "" {
 		commit = ref.Commit
+		if e.rp.offline {
+			_, err = e.mr.GetObjectByHash(commit)
+			if err != nil {
+				return "", git.CheckoutInfo{}, fmt.Errorf("commit %s of git repository %s is not present in the local git cache and fetching is disabled (offline mode): %w", commit, url.String(), err)
+			}
+		}
 		checkoutInfo.CheckedOutRef = *ref
 		checkoutInfo.CheckedOutCommit = ref.Commit
 	} else {
 		var ref2 string
 		ref2, commit, err = e.findCommit(ref.String())
 		if err != nil {
+			if e.rp.offline {
+				return "", git.CheckoutInfo{}, fmt.Errorf("%w in the local git cache of %s and fetching is disabled (offline mode)", err, url.String())
+			}
 			return "", git.CheckoutInfo{}, err
 		}
 		checkoutInfo.CheckedOutRef, err = types.ParseGitRef(ref2)
@@ -307,7 +356,19 @@ func (e *GitCacheEntry) GetClonedDir(ref *types.GitRef) (string, git.CheckoutInf
 		checkoutInfo.CheckedOutCommit = commit
 	}
 
-	err = e.mr.CloneProjectByCommit(commit, p)
+	if len(sparseDirs) != 0 {
+		err = e.mr.CloneProjectByCommitSparse(commit, p, sparseDirs)
+		if err != nil {
+			status.Tracef(e.rp.ctx, "sparse checkout of %s failed, falling back to full checkout: %s", url.String(), err.Error())
+			err = os.RemoveAll(p)
+			if err != nil {
+				return "", git.CheckoutInfo{}, err
+			}
+			err = e.mr.CloneProjectByCommit(commit, p)
+		}
+	} else {
+		err = e.mr.CloneProjectByCommit(commit, p)
+	}
 	if err != nil {
 		return "", git.CheckoutInfo{}, err
 	}
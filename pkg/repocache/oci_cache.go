@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/kluctl/kluctl/lib/git"
 	gittypes "github.com/kluctl/kluctl/lib/git/types"
 	"github.com/kluctl/kluctl/lib/status"
@@ -25,6 +26,7 @@ import (
 type OciRepoCache struct {
 	ctx            context.Context
 	updateInterval time.Duration
+	offline        bool
 
 	ociAuthProvider auth_provider.OciAuthProvider
 
@@ -58,6 +60,12 @@ func NewOciRepoCache(ctx context.Context, ociAuthProvider auth_provider.OciAuthP
 	}
 }
 
+// SetOffline enables or disables the offline mode. In offline mode, the cache never pulls from registries and only
+// serves artifacts that were already pulled or that are overridden by local directories.
+func (rp *OciRepoCache) SetOffline(offline bool) {
+	rp.offline = offline
+}
+
 func (rp *OciRepoCache) Clear() {
 	rp.cleanupDirsMutex.Lock()
 	defer rp.cleanupDirsMutex.Unlock()
@@ -182,7 +190,22 @@ func (e *OciCacheEntry) GetExtractedDir(ref *types.OciRef) (string, git.Checkout
 
 	image := strings.TrimPrefix(e.url.String(), "oci://") + ":" + ref.String()
 
-	md, err := e.ociClient.Pull(e.rp.ctx, image, ociDir)
+	var digestRef string
+	if e.rp.offline {
+		// without network access, only pinned digests can be served from the content store
+		if ref.Digest == "" {
+			return "", git.CheckoutInfo{}, fmt.Errorf("oci artifact %s with ref %s is not present locally and pulling is disabled (offline mode)", e.url.String(), ref.String())
+		}
+		digestRef = strings.TrimPrefix(e.url.String(), "oci://") + "@" + ref.Digest
+	} else {
+		// always resolve the digest, so that moved tags are detected
+		digestRef, err = e.ociClient.Resolve(e.rp.ctx, image)
+		if err != nil {
+			return "", git.CheckoutInfo{}, err
+		}
+	}
+
+	md, err := e.pullByDigest(digestRef, ociDir)
 	if err != nil {
 		return "", git.CheckoutInfo{}, err
 	}
@@ -205,3 +228,82 @@ func (e *OciCacheEntry) GetExtractedDir(ref *types.OciRef) (string, git.Checkout
 	e.pulledDirs[*ref] = cd
 	return cd.dir, cd.info, nil
 }
+
+// pullByDigest copies the content of the given artifact into targetDir. Artifacts are stored in a content addressable
+// store inside the cache dir, keyed by the manifest digest, so that identical artifacts are only downloaded once, even
+// if referenced from different repositories or tags.
+func (e *OciCacheEntry) pullByDigest(digestRef string, targetDir string) (*client.Metadata, error) {
+	s := strings.SplitN(digestRef, "@", 2)
+	if len(s) != 2 {
+		return nil, fmt.Errorf("invalid digest reference %s", digestRef)
+	}
+	digest, err := v1.NewHash(s[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest reference %s: %w", digestRef, err)
+	}
+
+	storeDir := filepath.Join(utils.GetCacheDir(e.rp.ctx), "oci-content")
+	contentDir := filepath.Join(storeDir, digest.Algorithm+"-"+digest.Hex)
+
+	md, err := e.loadFromContentStore(contentDir, targetDir)
+	if err == nil {
+		status.Tracef(e.rp.ctx, "Using cached content for oci artifact %s", digestRef)
+		return md, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if e.rp.offline {
+		return nil, fmt.Errorf("oci artifact %s is not present locally and pulling is disabled (offline mode)", digestRef)
+	}
+
+	err = os.MkdirAll(storeDir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	tmpDir, err := os.MkdirTemp(storeDir, "tmp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	err = os.Mkdir(filepath.Join(tmpDir, "content"), 0700)
+	if err != nil {
+		return nil, err
+	}
+	md, err = e.ociClient.Pull(e.rp.ctx, digestRef, filepath.Join(tmpDir, "content"))
+	if err != nil {
+		return nil, err
+	}
+	mdJson, err := json.Marshal(md)
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(filepath.Join(tmpDir, "metadata.json"), mdJson, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	// the rename is atomic, so concurrent processes will either see the full content or nothing. If another process
+	// was faster, the rename fails and we simply use the content from the other process.
+	_ = os.Rename(tmpDir, contentDir)
+
+	return e.loadFromContentStore(contentDir, targetDir)
+}
+
+func (e *OciCacheEntry) loadFromContentStore(contentDir string, targetDir string) (*client.Metadata, error) {
+	mdJson, err := os.ReadFile(filepath.Join(contentDir, "metadata.json"))
+	if err != nil {
+		return nil, err
+	}
+	var md client.Metadata
+	err = json.Unmarshal(mdJson, &md)
+	if err != nil {
+		return nil, err
+	}
+	err = cp.Copy(filepath.Join(contentDir, "content"), targetDir)
+	if err != nil {
+		return nil, err
+	}
+	return &md, nil
+}
@@ -0,0 +1,336 @@
+package results
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/lib/yaml"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	fileStoreCommandResultsDir  = "command-results"
+	fileStoreValidateResultsDir = "validate-results"
+)
+
+// ResultStoreFile is a ResultStore that persists results into a local directory instead of the cluster. Each result
+// is stored in its own directory, containing the summary (summary.json) and the full result (result.json). The full
+// command result is stored in the same compacted form as the ResultStoreSecrets stores it.
+// Watching only sends the results that exist when the watch is started, as changes done by other processes are not
+// detected. KluctlDeployments are not supported by this store.
+type ResultStoreFile struct {
+	ctx context.Context
+
+	dir                      string
+	allowWrite               bool
+	keepCommandResultsCount  int
+	keepValidateResultsCount int
+
+	mutex sync.Mutex
+}
+
+func NewResultStoreFile(ctx context.Context, dir string, allowWrite bool, keepCommandResultsCount int, keepValidateResultsCount int) (*ResultStoreFile, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("missing result store directory")
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ResultStoreFile{
+		ctx:                      ctx,
+		dir:                      dir,
+		allowWrite:               allowWrite,
+		keepCommandResultsCount:  keepCommandResultsCount,
+		keepValidateResultsCount: keepValidateResultsCount,
+	}
+	return s, nil
+}
+
+func (s *ResultStoreFile) resultDir(subDir string, id string) (string, error) {
+	if id == "" || filepath.Base(id) != id || id == "." || id == ".." {
+		return "", fmt.Errorf("invalid result id %s", id)
+	}
+	return filepath.Join(s.dir, subDir, id), nil
+}
+
+func (s *ResultStoreFile) writeResult(subDir string, id string, summary any, r any) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+
+	dir, err := s.resultDir(subDir, id)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	rJson, err := yaml.WriteJsonString(r)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(dir, "result.json"), []byte(rJson), 0o600)
+	if err != nil {
+		return err
+	}
+
+	// the summary is written last, so that listing never sees incomplete results
+	summaryJson, err := yaml.WriteJsonString(summary)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "summary.json"), []byte(summaryJson), 0o600)
+}
+
+func (s *ResultStoreFile) deleteResult(subDir string, id string) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+	dir, err := s.resultDir(subDir, id)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// readSummaries reads all summaries from the given sub directory. Results without a readable summary are skipped.
+func readSummaries[T any](s *ResultStoreFile, subDir string) ([]T, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, subDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ret []T
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		var summary T
+		err = yaml.ReadYamlFile(filepath.Join(s.dir, subDir, e.Name(), "summary.json"), &summary)
+		if err != nil {
+			continue
+		}
+		ret = append(ret, summary)
+	}
+	return ret, nil
+}
+
+func (s *ResultStoreFile) WriteCommandResult(cr *result.CommandResult) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.writeResult(fileStoreCommandResultsDir, cr.Id, cr.BuildSummary(), cr.ToCompacted())
+	if err != nil {
+		return err
+	}
+
+	return s.cleanupOldCommandResults(cr.ProjectKey, cr.TargetKey)
+}
+
+func (s *ResultStoreFile) WriteValidateResult(vr *result.ValidateResult) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.writeResult(fileStoreValidateResultsDir, vr.Id, vr.BuildSummary(), vr)
+	if err != nil {
+		return err
+	}
+
+	return s.cleanupValidateResults(vr.ProjectKey, vr.TargetKey)
+}
+
+func (s *ResultStoreFile) DeleteCommandResult(rsId string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.deleteResult(fileStoreCommandResultsDir, rsId)
+}
+
+func (s *ResultStoreFile) cleanupOldCommandResults(project gittypes.ProjectKey, target result.TargetKey) error {
+	results, err := s.ListCommandResultSummaries(ListResultSummariesOptions{
+		ProjectFilter: &project,
+	})
+	if err != nil {
+		return err
+	}
+
+	cnt := 0
+	for _, rs := range results {
+		if rs.TargetKey != target {
+			continue
+		}
+		cnt++
+
+		if cnt > s.keepCommandResultsCount {
+			err := s.deleteResult(fileStoreCommandResultsDir, rs.Id)
+			if err != nil {
+				status.Warningf(s.ctx, "Failed to delete old command result %s: %s", rs.Id, err)
+			} else {
+				status.Infof(s.ctx, "Deleted old command result %s", rs.Id)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ResultStoreFile) cleanupValidateResults(project gittypes.ProjectKey, target result.TargetKey) error {
+	results, err := s.ListValidateResultSummaries(ListResultSummariesOptions{
+		ProjectFilter: &project,
+	})
+	if err != nil {
+		return err
+	}
+
+	cnt := 0
+	for _, rs := range results {
+		if rs.TargetKey != target {
+			continue
+		}
+		cnt++
+
+		if cnt > s.keepValidateResultsCount {
+			err := s.deleteResult(fileStoreValidateResultsDir, rs.Id)
+			if err != nil {
+				status.Warningf(s.ctx, "Failed to delete old validate result %s: %s", rs.Id, err)
+			} else {
+				status.Infof(s.ctx, "Deleted old validate result %s", rs.Id)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ResultStoreFile) ListCommandResultSummaries(options ListResultSummariesOptions) ([]result.CommandResultSummary, error) {
+	summaries, err := readSummaries[result.CommandResultSummary](s, fileStoreCommandResultsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]result.CommandResultSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if !FilterProject(summary.ProjectKey, options.ProjectFilter) {
+			continue
+		}
+		ret = append(ret, summary)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return lessCommandSummary(&ret[i], &ret[j])
+	})
+	return ret, nil
+}
+
+func (s *ResultStoreFile) WatchCommandResultSummaries(options ListResultSummariesOptions) (<-chan WatchCommandResultSummaryEvent, context.CancelFunc, error) {
+	summaries, err := s.ListCommandResultSummaries(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan WatchCommandResultSummaryEvent, len(summaries))
+	for i := range summaries {
+		ch <- WatchCommandResultSummaryEvent{
+			Summary: &summaries[i],
+		}
+	}
+	return ch, func() {}, nil
+}
+
+func (s *ResultStoreFile) GetCommandResult(options GetCommandResultOptions) (*result.CommandResult, error) {
+	dir, err := s.resultDir(fileStoreCommandResultsDir, options.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var ccr result.CompactedCommandResult
+	err = yaml.ReadYamlFile(filepath.Join(dir, "result.json"), &ccr)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	cr := ccr.ToNonCompacted()
+	if options.Reduced {
+		cr = cr.ToReducedObjects()
+	}
+	return cr, nil
+}
+
+func (s *ResultStoreFile) ListValidateResultSummaries(options ListResultSummariesOptions) ([]result.ValidateResultSummary, error) {
+	summaries, err := readSummaries[result.ValidateResultSummary](s, fileStoreValidateResultsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]result.ValidateResultSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if !FilterProject(summary.ProjectKey, options.ProjectFilter) {
+			continue
+		}
+		ret = append(ret, summary)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return lessValidateSummary(&ret[i], &ret[j])
+	})
+	return ret, nil
+}
+
+func (s *ResultStoreFile) WatchValidateResultSummaries(options ListResultSummariesOptions) (<-chan WatchValidateResultSummaryEvent, context.CancelFunc, error) {
+	summaries, err := s.ListValidateResultSummaries(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan WatchValidateResultSummaryEvent, len(summaries))
+	for i := range summaries {
+		ch <- WatchValidateResultSummaryEvent{
+			Summary: &summaries[i],
+		}
+	}
+	return ch, func() {}, nil
+}
+
+func (s *ResultStoreFile) GetValidateResult(options GetValidateResultOptions) (*result.ValidateResult, error) {
+	dir, err := s.resultDir(fileStoreValidateResultsDir, options.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var vr result.ValidateResult
+	err = yaml.ReadYamlFile(filepath.Join(dir, "result.json"), &vr)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &vr, nil
+}
+
+func (s *ResultStoreFile) ListKluctlDeployments() ([]WatchKluctlDeploymentEvent, error) {
+	return nil, nil
+}
+
+func (s *ResultStoreFile) WatchKluctlDeployments() (<-chan WatchKluctlDeploymentEvent, context.CancelFunc, error) {
+	return make(chan WatchKluctlDeploymentEvent), func() {}, nil
+}
+
+func (s *ResultStoreFile) GetKluctlDeployment(clusterId string, name string, namespace string) (*kluctlv1.KluctlDeployment, error) {
+	return nil, fmt.Errorf("KluctlDeployments are not supported by the file based result store")
+}
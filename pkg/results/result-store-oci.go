@@ -0,0 +1,397 @@
+package results
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/lib/yaml"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/oci"
+	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
+	"github.com/kluctl/kluctl/v2/pkg/oci/client"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	ociCommandResultTagPrefix  = "command-result-"
+	ociValidateResultTagPrefix = "validate-result-"
+
+	ociSummaryAnnotation  = "io.kluctl.result.summary"
+	ociResultMediaTypeExt = "kluctl.result.json"
+)
+
+// ResultStoreOci is a ResultStore that pushes results as OCI artifacts into a single OCI repository. Each result is
+// pushed with a tag that is derived from the result id. The full result is stored as the only layer of the artifact
+// while the summary is stored as an annotation, so that listing results does not require pulling all artifacts.
+// Watching only sends the results that exist when the watch is started. KluctlDeployments are not supported by this
+// store.
+type ResultStoreOci struct {
+	ctx context.Context
+
+	repo                     string
+	client                   *client.Client
+	allowWrite               bool
+	keepCommandResultsCount  int
+	keepValidateResultsCount int
+
+	mutex sync.Mutex
+}
+
+func NewResultStoreOci(ctx context.Context, ociUrl string, authProvider auth_provider.OciAuthProvider, allowWrite bool, keepCommandResultsCount int, keepValidateResultsCount int) (*ResultStoreOci, error) {
+	repo, err := client.ParseRepositoryURL(ociUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []crane.Option
+	if authProvider != nil {
+		auth, err := authProvider.FindAuthEntry(ctx, oci.OCIRepositoryPrefix+repo)
+		if err != nil {
+			return nil, err
+		}
+		opts, err = auth.BuildCraneOptions()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s := &ResultStoreOci{
+		ctx:                      ctx,
+		repo:                     repo,
+		client:                   client.NewClient(opts),
+		allowWrite:               allowWrite,
+		keepCommandResultsCount:  keepCommandResultsCount,
+		keepValidateResultsCount: keepValidateResultsCount,
+	}
+	return s, nil
+}
+
+func isOciNotFound(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
+
+func (s *ResultStoreOci) buildUrl(tagPrefix string, id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, ":/@") {
+		return "", fmt.Errorf("invalid result id %s", id)
+	}
+	return fmt.Sprintf("%s:%s%s", s.repo, tagPrefix, id), nil
+}
+
+func (s *ResultStoreOci) pushResult(tagPrefix string, id string, summary any, r any) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+
+	url, err := s.buildUrl(tagPrefix, id)
+	if err != nil {
+		return err
+	}
+
+	summaryJson, err := yaml.WriteJsonString(summary)
+	if err != nil {
+		return err
+	}
+	rJson, err := yaml.WriteJsonString(r)
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(utils.GetTmpBaseDir(s.ctx), "result-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.WriteString(rJson)
+	_ = tmpFile.Close()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Push(s.ctx, url, tmpFile.Name(),
+		client.WithPushLayerType(client.LayerTypeStatic),
+		client.WithPushMediaTypeExt(ociResultMediaTypeExt),
+		client.WithPushMetadata(client.Metadata{
+			Annotations: map[string]string{
+				ociSummaryAnnotation: summaryJson,
+			},
+		}))
+	if err != nil {
+		return fmt.Errorf("failed to push result to %s: %w", url, err)
+	}
+	return nil
+}
+
+func (s *ResultStoreOci) pullResult(tagPrefix string, id string, r any) (bool, error) {
+	url, err := s.buildUrl(tagPrefix, id)
+	if err != nil {
+		return false, err
+	}
+
+	tmpDir, err := os.MkdirTemp(utils.GetTmpBaseDir(s.ctx), "result-")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(tmpDir)
+	p := filepath.Join(tmpDir, "result.json")
+
+	_, err = s.client.Pull(s.ctx, url, p, client.WithPullLayerType(client.LayerTypeStatic))
+	if err != nil {
+		if isOciNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	err = yaml.ReadYamlFile(p, r)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *ResultStoreOci) deleteResult(tagPrefix string, id string) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+	url, err := s.buildUrl(tagPrefix, id)
+	if err != nil {
+		return err
+	}
+	return s.client.Delete(s.ctx, url)
+}
+
+// listOciSummaries lists all summaries with the given tag prefix. Artifacts without a readable summary are skipped.
+func listOciSummaries[T any](s *ResultStoreOci, tagPrefix string) ([]T, error) {
+	metas, err := s.client.List(s.ctx, s.repo, client.ListOptions{
+		RegexFilter: "^" + tagPrefix,
+	})
+	if err != nil {
+		if isOciNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ret []T
+	for _, m := range metas {
+		summaryJson, ok := m.Annotations[ociSummaryAnnotation]
+		if !ok {
+			continue
+		}
+		var summary T
+		err = yaml.ReadYamlString(summaryJson, &summary)
+		if err != nil {
+			continue
+		}
+		ret = append(ret, summary)
+	}
+	return ret, nil
+}
+
+func (s *ResultStoreOci) WriteCommandResult(cr *result.CommandResult) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.pushResult(ociCommandResultTagPrefix, cr.Id, cr.BuildSummary(), cr.ToCompacted())
+	if err != nil {
+		return err
+	}
+
+	return s.cleanupOldCommandResults(cr.ProjectKey, cr.TargetKey)
+}
+
+func (s *ResultStoreOci) WriteValidateResult(vr *result.ValidateResult) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.pushResult(ociValidateResultTagPrefix, vr.Id, vr.BuildSummary(), vr)
+	if err != nil {
+		return err
+	}
+
+	return s.cleanupValidateResults(vr.ProjectKey, vr.TargetKey)
+}
+
+func (s *ResultStoreOci) DeleteCommandResult(rsId string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.deleteResult(ociCommandResultTagPrefix, rsId)
+}
+
+func (s *ResultStoreOci) cleanupOldCommandResults(project gittypes.ProjectKey, target result.TargetKey) error {
+	results, err := s.ListCommandResultSummaries(ListResultSummariesOptions{
+		ProjectFilter: &project,
+	})
+	if err != nil {
+		return err
+	}
+
+	cnt := 0
+	for _, rs := range results {
+		if rs.TargetKey != target {
+			continue
+		}
+		cnt++
+
+		if cnt > s.keepCommandResultsCount {
+			err := s.deleteResult(ociCommandResultTagPrefix, rs.Id)
+			if err != nil {
+				status.Warningf(s.ctx, "Failed to delete old command result %s: %s", rs.Id, err)
+			} else {
+				status.Infof(s.ctx, "Deleted old command result %s", rs.Id)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ResultStoreOci) cleanupValidateResults(project gittypes.ProjectKey, target result.TargetKey) error {
+	results, err := s.ListValidateResultSummaries(ListResultSummariesOptions{
+		ProjectFilter: &project,
+	})
+	if err != nil {
+		return err
+	}
+
+	cnt := 0
+	for _, rs := range results {
+		if rs.TargetKey != target {
+			continue
+		}
+		cnt++
+
+		if cnt > s.keepValidateResultsCount {
+			err := s.deleteResult(ociValidateResultTagPrefix, rs.Id)
+			if err != nil {
+				status.Warningf(s.ctx, "Failed to delete old validate result %s: %s", rs.Id, err)
+			} else {
+				status.Infof(s.ctx, "Deleted old validate result %s", rs.Id)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ResultStoreOci) ListCommandResultSummaries(options ListResultSummariesOptions) ([]result.CommandResultSummary, error) {
+	summaries, err := listOciSummaries[result.CommandResultSummary](s, ociCommandResultTagPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]result.CommandResultSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if !FilterProject(summary.ProjectKey, options.ProjectFilter) {
+			continue
+		}
+		ret = append(ret, summary)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return lessCommandSummary(&ret[i], &ret[j])
+	})
+	return ret, nil
+}
+
+func (s *ResultStoreOci) WatchCommandResultSummaries(options ListResultSummariesOptions) (<-chan WatchCommandResultSummaryEvent, context.CancelFunc, error) {
+	summaries, err := s.ListCommandResultSummaries(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan WatchCommandResultSummaryEvent, len(summaries))
+	for i := range summaries {
+		ch <- WatchCommandResultSummaryEvent{
+			Summary: &summaries[i],
+		}
+	}
+	return ch, func() {}, nil
+}
+
+func (s *ResultStoreOci) GetCommandResult(options GetCommandResultOptions) (*result.CommandResult, error) {
+	var ccr result.CompactedCommandResult
+	found, err := s.pullResult(ociCommandResultTagPrefix, options.Id, &ccr)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+
+	cr := ccr.ToNonCompacted()
+	if options.Reduced {
+		cr = cr.ToReducedObjects()
+	}
+	return cr, nil
+}
+
+func (s *ResultStoreOci) ListValidateResultSummaries(options ListResultSummariesOptions) ([]result.ValidateResultSummary, error) {
+	summaries, err := listOciSummaries[result.ValidateResultSummary](s, ociValidateResultTagPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]result.ValidateResultSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if !FilterProject(summary.ProjectKey, options.ProjectFilter) {
+			continue
+		}
+		ret = append(ret, summary)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return lessValidateSummary(&ret[i], &ret[j])
+	})
+	return ret, nil
+}
+
+func (s *ResultStoreOci) WatchValidateResultSummaries(options ListResultSummariesOptions) (<-chan WatchValidateResultSummaryEvent, context.CancelFunc, error) {
+	summaries, err := s.ListValidateResultSummaries(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan WatchValidateResultSummaryEvent, len(summaries))
+	for i := range summaries {
+		ch <- WatchValidateResultSummaryEvent{
+			Summary: &summaries[i],
+		}
+	}
+	return ch, func() {}, nil
+}
+
+func (s *ResultStoreOci) GetValidateResult(options GetValidateResultOptions) (*result.ValidateResult, error) {
+	var vr result.ValidateResult
+	found, err := s.pullResult(ociValidateResultTagPrefix, options.Id, &vr)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return &vr, nil
+}
+
+func (s *ResultStoreOci) ListKluctlDeployments() ([]WatchKluctlDeploymentEvent, error) {
+	return nil, nil
+}
+
+func (s *ResultStoreOci) WatchKluctlDeployments() (<-chan WatchKluctlDeploymentEvent, context.CancelFunc, error) {
+	return make(chan WatchKluctlDeploymentEvent), func() {}, nil
+}
+
+func (s *ResultStoreOci) GetKluctlDeployment(clusterId string, name string, namespace string) (*kluctlv1.KluctlDeployment, error) {
+	return nil, fmt.Errorf("KluctlDeployments are not supported by the OCI based result store")
+}
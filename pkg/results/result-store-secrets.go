@@ -45,7 +45,10 @@ type ResultStoreSecrets struct {
 	mutex sync.Mutex
 }
 
-func NewResultStoreSecrets(ctx context.Context, config *rest.Config, client_ client.Client, allowWrite bool, writeNamespace string, keepCommandResultsCount int, keepValidateResultsCount int) (*ResultStoreSecrets, error) {
+// NewResultStoreSecrets creates a result store that writes results into writeNamespace. Reading results is done from
+// all namespaces, unless readNamespaces is non-empty, in which case only the given namespaces are read. The latter is
+// useful when the caller has no cluster-wide permissions to list secrets.
+func NewResultStoreSecrets(ctx context.Context, config *rest.Config, client_ client.Client, allowWrite bool, writeNamespace string, readNamespaces []string, keepCommandResultsCount int, keepValidateResultsCount int) (*ResultStoreSecrets, error) {
 	clusterId, err := k8s.GetClusterId(ctx, client_)
 	if err != nil {
 		return nil, err
@@ -55,10 +58,19 @@ func NewResultStoreSecrets(ctx context.Context, config *rest.Config, client_ cli
 	_ = corev1.AddToScheme(scheme)
 	_ = kluctlv1.AddToScheme(scheme)
 
+	var cacheNamespaces map[string]cache.Config
+	if len(readNamespaces) != 0 {
+		cacheNamespaces = map[string]cache.Config{}
+		for _, ns := range readNamespaces {
+			cacheNamespaces[ns] = cache.Config{}
+		}
+	}
+
 	req1, _ := labels.NewRequirement("kluctl.io/result", selection.Exists, nil)
 	c1, err := cache.New(config, cache.Options{
-		Mapper: client_.RESTMapper(),
-		Scheme: scheme,
+		Mapper:            client_.RESTMapper(),
+		Scheme:            scheme,
+		DefaultNamespaces: cacheNamespaces,
 		ByObject: map[client.Object]cache.ByObject{
 			&corev1.Secret{}: {
 				Label: labels.NewSelector().Add(*req1),
@@ -0,0 +1,146 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kluctl/kluctl/v2/pkg/version"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+// BuildDocument builds an image-level SBOM document for the given target in the given format. The result is JSON.
+func BuildDocument(format string, targetName string, images []*Image, now time.Time) (string, error) {
+	var doc any
+	var err error
+	switch format {
+	case FormatCycloneDX:
+		doc, err = buildCycloneDX(targetName, images, now)
+	case FormatSPDX:
+		doc, err = buildSPDX(targetName, images, now)
+	default:
+		return "", fmt.Errorf("unsupported sbom format %s", format)
+	}
+	if err != nil {
+		return "", err
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+func buildCycloneDX(targetName string, images []*Image, now time.Time) (map[string]any, error) {
+	var components []any
+	for _, image := range images {
+		n, v, err := imageNameAndVersion(image)
+		if err != nil {
+			return nil, err
+		}
+		purl, err := buildPurl(image)
+		if err != nil {
+			return nil, err
+		}
+		c := map[string]any{
+			"type":    "container",
+			"bom-ref": purl,
+			"name":    n,
+			"version": v,
+			"purl":    purl,
+			"properties": []any{map[string]any{
+				"name":  "kluctl:objects",
+				"value": objectsString(image.Objects),
+			}},
+		}
+		if image.Digest != "" {
+			c["hashes"] = []any{map[string]any{
+				"alg":     "SHA-256",
+				"content": strings.TrimPrefix(image.Digest, "sha256:"),
+			}}
+		}
+		components = append(components, c)
+	}
+
+	return map[string]any{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  "1.5",
+		"serialNumber": "urn:uuid:" + uuid.NewString(),
+		"version":      1,
+		"metadata": map[string]any{
+			"timestamp": now.UTC().Format(time.RFC3339),
+			"tools": map[string]any{
+				"components": []any{map[string]any{
+					"type":    "application",
+					"name":    "kluctl",
+					"version": version.GetVersion(),
+				}},
+			},
+			"component": map[string]any{
+				"type": "application",
+				"name": targetName,
+			},
+		},
+		"components": components,
+	}, nil
+}
+
+func buildSPDX(targetName string, images []*Image, now time.Time) (map[string]any, error) {
+	var packages []any
+	var relationships []any
+	for i, image := range images {
+		n, v, err := imageNameAndVersion(image)
+		if err != nil {
+			return nil, err
+		}
+		purl, err := buildPurl(image)
+		if err != nil {
+			return nil, err
+		}
+		spdxId := fmt.Sprintf("SPDXRef-Image-%d", i)
+		p := map[string]any{
+			"name":             n,
+			"SPDXID":           spdxId,
+			"versionInfo":      v,
+			"downloadLocation": "NOASSERTION",
+			"filesAnalyzed":    false,
+			"comment":          "Used by " + objectsString(image.Objects),
+			"externalRefs": []any{map[string]any{
+				"referenceCategory": "PACKAGE-MANAGER",
+				"referenceType":     "purl",
+				"referenceLocator":  purl,
+			}},
+		}
+		if image.Digest != "" {
+			p["checksums"] = []any{map[string]any{
+				"algorithm":     "SHA256",
+				"checksumValue": strings.TrimPrefix(image.Digest, "sha256:"),
+			}}
+		}
+		packages = append(packages, p)
+		relationships = append(relationships, map[string]any{
+			"spdxElementId":      "SPDXRef-DOCUMENT",
+			"relationshipType":   "DESCRIBES",
+			"relatedSpdxElement": spdxId,
+		})
+	}
+
+	return map[string]any{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              targetName,
+		"documentNamespace": fmt.Sprintf("https://kluctl.io/spdx/%s-%s", targetName, uuid.NewString()),
+		"creationInfo": map[string]any{
+			"created":  now.UTC().Format(time.RFC3339),
+			"creators": []any{"Tool: kluctl-" + version.GetVersion()},
+		},
+		"packages":      packages,
+		"relationships": relationships,
+	}, nil
+}
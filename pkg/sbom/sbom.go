@@ -0,0 +1,179 @@
+package sbom
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// podSpecPaths contains all known locations of pod specs inside of workload objects
+var podSpecPaths = []uo.KeyPath{
+	{"spec"},
+	{"spec", "template", "spec"},
+	{"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+var containerFields = []string{"containers", "initContainers", "ephemeralContainers"}
+
+type Image struct {
+	Image   string           `json:"image"`
+	Digest  string           `json:"digest,omitempty"`
+	Objects []k8s2.ObjectRef `json:"objects"`
+}
+
+// CollectImages returns all unique container images referenced by the given objects, sorted by image.
+func CollectImages(objects []*uo.UnstructuredObject) []*Image {
+	m := map[string]*Image{}
+	for _, o := range objects {
+		ref := o.GetK8sRef()
+		for _, p := range podSpecPaths {
+			for _, f := range containerFields {
+				containers, _, _ := o.GetNestedObjectList(append(append(uo.KeyPath{}, p...), f)...)
+				for _, c := range containers {
+					image, ok, _ := c.GetNestedString("image")
+					if !ok || image == "" {
+						continue
+					}
+					e, ok := m[image]
+					if !ok {
+						e = &Image{Image: image}
+						m[image] = e
+					}
+					if len(e.Objects) == 0 || e.Objects[len(e.Objects)-1] != ref {
+						e.Objects = append(e.Objects, ref)
+					}
+				}
+			}
+		}
+	}
+
+	ret := make([]*Image, 0, len(m))
+	for _, e := range m {
+		ret = append(ret, e)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Image < ret[j].Image
+	})
+	return ret
+}
+
+// ResolveDigests resolves the digests of all images by querying the registries. Images which already reference a
+// digest are not queried.
+func ResolveDigests(ctx context.Context, images []*Image, authProvider auth_provider.OciAuthProvider) error {
+	for _, image := range images {
+		digest, err := ResolveDigest(ctx, image.Image, authProvider)
+		if err != nil {
+			return err
+		}
+		image.Digest = digest
+	}
+	return nil
+}
+
+// ResolveDigest resolves the digest of a single image by querying the registry. If the image already references a
+// digest, the registry is not queried.
+func ResolveDigest(ctx context.Context, image string, authProvider auth_provider.OciAuthProvider) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image %s: %w", image, err)
+	}
+	if d, ok := ref.(name.Digest); ok {
+		return d.DigestStr(), nil
+	}
+
+	opts := []crane.Option{crane.WithContext(ctx)}
+	if authProvider != nil {
+		auth, err := authProvider.FindAuthEntry(ctx, "oci://"+ref.Context().String())
+		if err != nil {
+			return "", err
+		}
+		authOpts, err := auth.BuildCraneOptions()
+		if err != nil {
+			return "", err
+		}
+		opts = append(opts, authOpts...)
+	}
+
+	digest, err := crane.Digest(image, opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest of image %s: %w", image, err)
+	}
+	return digest, nil
+}
+
+// PinDigests rewrites all container images of the given objects which have an entry in digests (mapping images to
+// digests) to reference the digest, e.g. 'nginx:1.25' becomes 'nginx:1.25@sha256:...'. Images which already reference
+// a digest are left untouched.
+func PinDigests(objects []*uo.UnstructuredObject, digests map[string]string) error {
+	for _, o := range objects {
+		for _, p := range podSpecPaths {
+			for _, f := range containerFields {
+				// the returned containers share their underlying maps with the object, so modifying them modifies the object
+				containers, _, _ := o.GetNestedObjectList(append(append(uo.KeyPath{}, p...), f)...)
+				for _, c := range containers {
+					image, ok, _ := c.GetNestedString("image")
+					if !ok || strings.Contains(image, "@") {
+						continue
+					}
+					digest, ok := digests[image]
+					if !ok {
+						continue
+					}
+					err := c.SetNestedField(image+"@"+digest, "image")
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// buildPurl builds a package url for the given image, following https://github.com/package-url/purl-spec
+func buildPurl(image *Image) (string, error) {
+	ref, err := name.ParseReference(image.Image)
+	if err != nil {
+		return "", err
+	}
+	repo := ref.Context()
+	s := strings.Split(repo.RepositoryStr(), "/")
+
+	purl := "pkg:oci/" + url.PathEscape(s[len(s)-1])
+	if image.Digest != "" {
+		purl += "@" + url.PathEscape(image.Digest)
+	}
+	q := url.Values{}
+	q.Set("repository_url", repo.Name())
+	if t, ok := ref.(name.Tag); ok {
+		q.Set("tag", t.TagStr())
+	}
+	return purl + "?" + q.Encode(), nil
+}
+
+func imageNameAndVersion(image *Image) (string, string, error) {
+	ref, err := name.ParseReference(image.Image)
+	if err != nil {
+		return "", "", err
+	}
+	version := ref.Identifier()
+	if image.Digest != "" {
+		version = image.Digest
+	}
+	return ref.Context().Name(), version, nil
+}
+
+func objectsString(objects []k8s2.ObjectRef) string {
+	var l []string
+	for _, o := range objects {
+		l = append(l, o.String())
+	}
+	return strings.Join(l, ", ")
+}
@@ -0,0 +1,102 @@
+package sbom
+
+import (
+	"encoding/json"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestCollectImages(t *testing.T) {
+	objects := []*uo.UnstructuredObject{
+		uo.FromStringMust(`{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "d1", "namespace": "ns"},
+			"spec": {"template": {"spec": {
+				"initContainers": [{"name": "init", "image": "busybox:1.36"}],
+				"containers": [{"name": "c1", "image": "nginx:1.25"}, {"name": "c2", "image": "nginx:1.25"}]}}}}`),
+		uo.FromStringMust(`{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "p1", "namespace": "ns"},
+			"spec": {"containers": [{"name": "c1", "image": "nginx:1.25"}]}}`),
+		uo.FromStringMust(`{"apiVersion": "batch/v1", "kind": "CronJob", "metadata": {"name": "cj1", "namespace": "ns"},
+			"spec": {"jobTemplate": {"spec": {"template": {"spec": {"containers": [{"name": "c1", "image": "ghcr.io/org/job@` + testDigest + `"}]}}}}}}`),
+		uo.FromStringMust(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm1", "namespace": "ns"}}`),
+	}
+
+	images := CollectImages(objects)
+	assert.Equal(t, []*Image{
+		{Image: "busybox:1.36", Objects: []k8s2.ObjectRef{objects[0].GetK8sRef()}},
+		{Image: "ghcr.io/org/job@" + testDigest, Objects: []k8s2.ObjectRef{objects[2].GetK8sRef()}},
+		{Image: "nginx:1.25", Objects: []k8s2.ObjectRef{objects[0].GetK8sRef(), objects[1].GetK8sRef()}},
+	}, images)
+}
+
+func TestPinDigests(t *testing.T) {
+	objects := []*uo.UnstructuredObject{
+		uo.FromStringMust(`{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "d1", "namespace": "ns"},
+			"spec": {"template": {"spec": {
+				"initContainers": [{"name": "init", "image": "busybox:1.36"}],
+				"containers": [{"name": "c1", "image": "nginx:1.25"}, {"name": "c2", "image": "ghcr.io/org/app@` + testDigest + `"}]}}}}`),
+		uo.FromStringMust(`{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "p1", "namespace": "ns"},
+			"spec": {"containers": [{"name": "c1", "image": "nginx:1.25"}, {"name": "c2", "image": "redis:7"}]}}`),
+	}
+
+	err := PinDigests(objects, map[string]string{
+		"busybox:1.36": testDigest,
+		"nginx:1.25":   testDigest,
+	})
+	assert.NoError(t, err)
+
+	getImage := func(o *uo.UnstructuredObject, path ...interface{}) string {
+		s, _, _ := o.GetNestedString(path...)
+		return s
+	}
+	assert.Equal(t, "busybox:1.36@"+testDigest, getImage(objects[0], "spec", "template", "spec", "initContainers", 0, "image"))
+	assert.Equal(t, "nginx:1.25@"+testDigest, getImage(objects[0], "spec", "template", "spec", "containers", 0, "image"))
+	assert.Equal(t, "ghcr.io/org/app@"+testDigest, getImage(objects[0], "spec", "template", "spec", "containers", 1, "image"))
+	assert.Equal(t, "nginx:1.25@"+testDigest, getImage(objects[1], "spec", "containers", 0, "image"))
+	assert.Equal(t, "redis:7", getImage(objects[1], "spec", "containers", 1, "image"))
+}
+
+func TestBuildPurl(t *testing.T) {
+	purl, err := buildPurl(&Image{Image: "ghcr.io/org/app:v1", Digest: testDigest})
+	assert.NoError(t, err)
+	assert.Equal(t, "pkg:oci/app@sha256:"+testDigest[7:]+"?repository_url=ghcr.io%2Forg%2Fapp&tag=v1", purl)
+
+	purl, err = buildPurl(&Image{Image: "nginx"})
+	assert.NoError(t, err)
+	assert.Equal(t, "pkg:oci/nginx?repository_url=index.docker.io%2Flibrary%2Fnginx&tag=latest", purl)
+}
+
+func TestBuildDocument(t *testing.T) {
+	images := []*Image{
+		{Image: "ghcr.io/org/app:v1", Digest: testDigest, Objects: []k8s2.ObjectRef{{Group: "apps", Version: "v1", Kind: "Deployment", Name: "d1", Namespace: "ns"}}},
+	}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	s, err := BuildDocument(FormatCycloneDX, "prod", images, now)
+	assert.NoError(t, err)
+	var doc map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(s), &doc))
+	assert.Equal(t, "CycloneDX", doc["bomFormat"])
+	components := doc["components"].([]any)
+	assert.Len(t, components, 1)
+	c := components[0].(map[string]any)
+	assert.Equal(t, "ghcr.io/org/app", c["name"])
+	assert.Equal(t, testDigest, c["version"])
+	assert.Equal(t, testDigest[7:], c["hashes"].([]any)[0].(map[string]any)["content"])
+
+	s, err = BuildDocument(FormatSPDX, "prod", images, now)
+	assert.NoError(t, err)
+	doc = nil
+	assert.NoError(t, json.Unmarshal([]byte(s), &doc))
+	assert.Equal(t, "SPDX-2.3", doc["spdxVersion"])
+	assert.Equal(t, "2024-01-02T03:04:05Z", doc["creationInfo"].(map[string]any)["created"])
+	packages := doc["packages"].([]any)
+	assert.Len(t, packages, 1)
+	assert.Equal(t, "SPDXRef-Image-0", packages[0].(map[string]any)["SPDXID"])
+
+	_, err = BuildDocument("invalid", "prod", images, now)
+	assert.EqualError(t, err, "unsupported sbom format invalid")
+}
@@ -100,6 +100,12 @@ type Decryptor struct {
 	keyServices []keyservice.KeyServiceClient
 }
 
+// ErrDataKeyNotFound is returned (wrapped) when none of the available keys is able to decrypt the sops data key and
+// the data is only encrypted with offline keys (age, pgp), which means that the required keys are not available.
+// Failures of online key services (e.g. network or permission errors of KMS) never wrap ErrDataKeyNotFound, as
+// these don't tell anything about the availability of keys.
+var ErrDataKeyNotFound = errors.New("cannot get sops data key")
+
 // NewDecryptor creates a new Decryptor for the given kluctlDeployment.
 // gnuPGHome can be empty, in which case the systems' keyring is used.
 func NewDecryptor(root string, maxFileSize int64) *Decryptor {
@@ -138,6 +144,19 @@ func IsOfflineMethod(mk keys.MasterKey) bool {
 	}
 }
 
+// hasOnlyOfflineKeys returns true if all master keys of all key groups are offline keys, meaning that failing to get
+// the data key can only be caused by missing keys
+func hasOnlyOfflineKeys(keyGroups []sops.KeyGroup) bool {
+	for _, group := range keyGroups {
+		for _, mk := range group {
+			if !IsOfflineMethod(mk) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // SopsDecryptWithFormat attempts to load a SOPS encrypted file using the store
 // for the input format, gathers the data key for it from the key service,
 // and then decrypts the file data with the retrieved data key.
@@ -175,7 +194,13 @@ func (d *Decryptor) SopsDecryptWithFormat(data []byte, inputFormat, outputFormat
 
 	metadataKey, err := tree.Metadata.GetDataKeyWithKeyServices(d.keyServices, sops.DefaultDecryptionOrder)
 	if err != nil {
-		return nil, sopsUserErr("cannot get sops data key", err)
+		if userErr, ok := err.(sops.UserError); ok {
+			err = fmt.Errorf(userErr.UserError())
+		}
+		if !hasOnlyOfflineKeys(tree.Metadata.KeyGroups) {
+			return nil, fmt.Errorf("cannot get sops data key: %w", err)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrDataKeyNotFound, err)
 	}
 
 	cipher := aes.NewCipher()
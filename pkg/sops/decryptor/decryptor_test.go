@@ -26,6 +26,8 @@ import (
 	"github.com/getsops/sops/v3"
 	sopsage "github.com/getsops/sops/v3/age"
 	"github.com/getsops/sops/v3/cmd/sops/formats"
+	awskms "github.com/getsops/sops/v3/kms"
+	"github.com/getsops/sops/v3/pgp"
 	. "github.com/onsi/gomega"
 	gt "github.com/onsi/gomega/types"
 	"io/fs"
@@ -192,6 +194,20 @@ func TestDecryptor_SopsDecryptWithFormat(t *testing.T) {
 	})
 }
 
+func TestDecryptor_hasOnlyOfflineKeys(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(hasOnlyOfflineKeys(nil)).To(BeTrue())
+	g.Expect(hasOnlyOfflineKeys([]sops.KeyGroup{
+		{&sopsage.MasterKey{}, &pgp.MasterKey{}},
+		{&sopsage.MasterKey{}},
+	})).To(BeTrue())
+	g.Expect(hasOnlyOfflineKeys([]sops.KeyGroup{
+		{&sopsage.MasterKey{}},
+		{&sopsage.MasterKey{}, &awskms.MasterKey{}},
+	})).To(BeFalse())
+}
+
 func TestDecryptor_DecryptResource(t *testing.T) {
 	var (
 		resourceFactory  = provider.NewDefaultDepProvider().GetResourceFactory()
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"github.com/kluctl/kluctl/lib/git/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"math"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -31,7 +33,11 @@ func (ro *RepoOverride) Match(repoKey types.RepoKey) (string, bool) {
 
 	var overridePath string
 	if ro.IsGroup {
-		prefix := ro.RepoKey.Path + "/"
+		// an empty path means that all repositories of the host are matched
+		prefix := ""
+		if ro.RepoKey.Path != "" {
+			prefix = ro.RepoKey.Path + "/"
+		}
 		if !strings.HasPrefix(repoKey.Path, prefix) {
 			return "", false
 		}
@@ -57,12 +63,39 @@ func NewManager(overrides []RepoOverride) *Manager {
 	}
 }
 
+// specificity returns a value that is higher for more specific overrides. Exact overrides are always more specific
+// than group overrides, and group overrides with longer prefixes are more specific than those with shorter prefixes.
+func (ro *RepoOverride) specificity() int {
+	if !ro.IsGroup {
+		return math.MaxInt
+	}
+	if ro.RepoKey.Path == "" {
+		return 0
+	}
+	return strings.Count(ro.RepoKey.Path, "/") + 1
+}
+
+// ResolveOverride returns the override path for the given repo key. If multiple overrides match, the most specific one
+// that actually exists locally is used. Overrides with the same specificity are tried in the order they were given.
 func (m *Manager) ResolveOverride(ctx context.Context, repoKey types.RepoKey) (string, error) {
+	type match struct {
+		ro           RepoOverride
+		overridePath string
+	}
+	var matches []match
 	for _, ro := range m.Overrides {
 		overridePath, ok := ro.Match(repoKey)
-		if !ok {
-			continue
+		if ok {
+			matches = append(matches, match{ro: ro, overridePath: overridePath})
 		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].ro.specificity() > matches[j].ro.specificity()
+	})
+
+	for _, x := range matches {
+		ro := x.ro
+		overridePath := x.overridePath
 
 		overridePath = filepath.FromSlash(overridePath)
 
@@ -0,0 +1,61 @@
+package sourceoverride
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/lib/git/types"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveOverrideSpecificity(t *testing.T) {
+	hostDir := t.TempDir()
+	orgDir := t.TempDir()
+	exactDir := t.TempDir()
+
+	for _, p := range []string{
+		filepath.Join(hostDir, "org1", "repo1"),
+		filepath.Join(hostDir, "org1", "repo2"),
+		filepath.Join(hostDir, "org2", "repo1"),
+		filepath.Join(orgDir, "repo1"),
+	} {
+		assert.NoError(t, os.MkdirAll(p, 0o700))
+	}
+
+	key := func(s string) types.RepoKey {
+		k, err := types.ParseRepoKey(s, "git")
+		assert.NoError(t, err)
+		return k
+	}
+
+	// the least specific overrides come first, to ensure that order does not matter
+	m := NewManager([]RepoOverride{
+		{RepoKey: types.NewRepoKey("git", "example.com", ""), IsGroup: true, Override: hostDir},
+		{RepoKey: key("example.com/org1"), IsGroup: true, Override: orgDir},
+		{RepoKey: key("example.com/org1/repo3"), Override: exactDir},
+	})
+
+	resolve := func(s string) string {
+		p, err := m.ResolveOverride(context.Background(), key(s))
+		assert.NoError(t, err)
+		return p
+	}
+
+	// exact override wins over both group overrides
+	assert.Equal(t, exactDir, resolve("example.com/org1/repo3"))
+	// the org group is more specific than the host group
+	assert.Equal(t, filepath.Join(orgDir, "repo1"), resolve("example.com/org1/repo1"))
+	// not present in the org group, so it falls back to the host group
+	assert.Equal(t, filepath.Join(hostDir, "org1", "repo2"), resolve("example.com/org1/repo2"))
+	// only the host group matches
+	assert.Equal(t, filepath.Join(hostDir, "org2", "repo1"), resolve("example.com/org2/repo1"))
+	// nothing exists locally
+	assert.Equal(t, "", resolve("example.com/org3/repo1"))
+	// different host
+	assert.Equal(t, "", resolve("other.com/org1/repo1"))
+	// different type
+	p, err := m.ResolveOverride(context.Background(), types.NewRepoKey("oci", "example.com", "org1/repo1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "", p)
+}
@@ -30,10 +30,15 @@ type DeploymentItemConfig struct {
 	AlwaysDeploy     bool   `json:"alwaysDeploy,omitempty"`
 	When             string `json:"when,omitempty"`
 
+	// DependsOn lists the paths/includes of other deployment items of the same deployment project that must be
+	// deployed before this item
+	DependsOn []string `json:"dependsOn,omitempty"`
+
 	// these are only allowed when writing the command result
-	RenderedHelmChartConfig *HelmChartConfig         `json:"renderedHelmChartConfig,omitempty"`
-	RenderedObjects         []k8s.ObjectRef          `json:"renderedObjects,omitempty"`
-	RenderedInclude         *DeploymentProjectConfig `json:"renderedInclude,omitempty"`
+	RenderedHelmChartConfig *HelmChartConfig         `json:"renderedHelmChartConfig,omitempty" jsonschema:"-"`
+	RenderedObjects         []k8s.ObjectRef          `json:"renderedObjects,omitempty" jsonschema:"-"`
+	RenderedInclude         *DeploymentProjectConfig `json:"renderedInclude,omitempty" jsonschema:"-"`
+	RenderedHelmValues      *uo.UnstructuredObject   `json:"renderedHelmValues,omitempty" jsonschema:"-"`
 }
 
 func ValidateDeploymentItemConfig(sl validator.StructLevel) {
@@ -157,8 +162,62 @@ func ValidateConflictResolutionConfig(sl validator.StructLevel) {
 	}
 }
 
+// ApplyOrderItemConfig matches objects to define an explicit apply order. All fields support glob patterns
+// (e.g. "*-config"). Unset fields match all objects.
+type ApplyOrderItemConfig struct {
+	Group     *string `json:"group,omitempty"`
+	Kind      *string `json:"kind,omitempty"`
+	Name      *string `json:"name,omitempty"`
+	Namespace *string `json:"namespace,omitempty"`
+}
+
+func ValidateApplyOrderItemConfig(sl validator.StructLevel) {
+	s := sl.Current().Interface().(ApplyOrderItemConfig)
+	if s.Group == nil && s.Kind == nil && s.Name == nil && s.Namespace == nil {
+		sl.ReportError(s, "self", "self", "at least one of group, kind, name or namespace must be set", "")
+	}
+}
+
+// ReadinessCheckConfig declares how readiness is determined for objects of the given kind. Exactly one of condition or
+// field must be set.
+type ReadinessCheckConfig struct {
+	Group *string `json:"group,omitempty"`
+	Kind  string  `json:"kind" validate:"required"`
+
+	// Condition is the type of the status condition that must have the status "True"
+	Condition *string `json:"condition,omitempty"`
+
+	// Field is a dot separated path inside the status that must be equal to Value
+	Field *string `json:"field,omitempty"`
+	Value *string `json:"value,omitempty"`
+}
+
+func ValidateReadinessCheckConfig(sl validator.StructLevel) {
+	s := sl.Current().Interface().(ReadinessCheckConfig)
+	if (s.Condition == nil) == (s.Field == nil) {
+		sl.ReportError(s, "self", "self", "exactly one of condition or field must be set", "")
+	}
+	if (s.Field == nil) != (s.Value == nil) {
+		sl.ReportError(s, "self", "self", "field and value must be set together", "")
+	}
+}
+
+// VarsSchemaConfig specifies a JSON schema that the merged vars of a deployment project are validated against.
+type VarsSchemaConfig struct {
+	File   *string                `json:"file,omitempty"`
+	Schema *uo.UnstructuredObject `json:"schema,omitempty"`
+}
+
+func ValidateVarsSchemaConfig(sl validator.StructLevel) {
+	s := sl.Current().Interface().(VarsSchemaConfig)
+	if (s.File == nil) == (s.Schema == nil) {
+		sl.ReportError(s, "self", "self", "exactly one of file or schema must be set", "")
+	}
+}
+
 type DeploymentProjectConfig struct {
-	Vars []VarsSource `json:"vars,omitempty"`
+	Vars       []VarsSource      `json:"vars,omitempty"`
+	VarsSchema *VarsSchemaConfig `json:"varsSchema,omitempty"`
 
 	When string `json:"when,omitempty"`
 
@@ -171,6 +230,8 @@ type DeploymentProjectConfig struct {
 
 	IgnoreForDiff      []IgnoreForDiffItemConfig  `json:"ignoreForDiff,omitempty"`
 	ConflictResolution []ConflictResolutionConfig `json:"conflictResolution,omitempty"`
+	ApplyOrder         []ApplyOrderItemConfig     `json:"applyOrder,omitempty"`
+	ReadinessChecks    []ReadinessCheckConfig     `json:"readinessChecks,omitempty"`
 }
 
 func init() {
@@ -179,4 +240,7 @@ func init() {
 	yaml2.Validator.RegisterStructValidation(ValidateWaitReadinessObjectItemConfig, WaitReadinessObjectItemConfig{})
 	yaml2.Validator.RegisterStructValidation(ValidateIgnoreForDiffItemConfig, IgnoreForDiffItemConfig{})
 	yaml2.Validator.RegisterStructValidation(ValidateConflictResolutionConfig, ConflictResolutionConfig{})
+	yaml2.Validator.RegisterStructValidation(ValidateApplyOrderItemConfig, ApplyOrderItemConfig{})
+	yaml2.Validator.RegisterStructValidation(ValidateVarsSchemaConfig, VarsSchemaConfig{})
+	yaml2.Validator.RegisterStructValidation(ValidateReadinessCheckConfig, ReadinessCheckConfig{})
 }
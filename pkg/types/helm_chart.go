@@ -3,6 +3,7 @@ package types
 import (
 	"github.com/go-playground/validator/v10"
 	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"helm.sh/helm/v3/pkg/registry"
 )
 
@@ -20,6 +21,25 @@ type HelmChartConfig2 struct {
 	SkipCRDs          bool        `json:"skipCRDs,omitempty"`
 	SkipUpdate        bool        `json:"skipUpdate,omitempty"`
 	SkipPrePull       bool        `json:"skipPrePull,omitempty"`
+
+	Values []HelmValuesLayer `json:"values,omitempty"`
+}
+
+// HelmValuesLayer is a single layer of Helm values. Layers are merged in order, with later layers taking precedence.
+type HelmValuesLayer struct {
+	File     *string                `json:"file,omitempty"`
+	Values   *uo.UnstructuredObject `json:"values,omitempty"`
+	Optional bool                   `json:"optional,omitempty"`
+}
+
+func ValidateHelmValuesLayer(sl validator.StructLevel) {
+	s := sl.Current().Interface().(HelmValuesLayer)
+	if (s.File == nil) == (s.Values == nil) {
+		sl.ReportError(s, "self", "self", "exactly one of file or values must be set", "")
+	}
+	if s.Optional && s.File == nil {
+		sl.ReportError(s, "optional", "optional", "optional can only be set for file layers", "")
+	}
 }
 
 func ValidateHelmChartConfig2(sl validator.StructLevel) {
@@ -77,4 +97,5 @@ type HelmChartConfig struct {
 
 func init() {
 	yaml.Validator.RegisterStructValidation(ValidateHelmChartConfig2, HelmChartConfig2{})
+	yaml.Validator.RegisterStructValidation(ValidateHelmValuesLayer, HelmValuesLayer{})
 }
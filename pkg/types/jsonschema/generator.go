@@ -0,0 +1,201 @@
+package jsonschema
+
+import (
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const draft07 = "http://json-schema.org/draft-07/schema#"
+
+// Generator generates JSON schemas from Go types via reflection. Struct fields are mapped by their json tags, fields
+// tagged with `validate:"required"` become required properties and `validate:"oneof=a b"` becomes an enum. Unknown
+// properties are not allowed, so that typos are caught by the schema.
+type Generator struct {
+	overrides     map[reflect.Type]map[string]any
+	stringOrTypes map[reflect.Type]bool
+	oneOfFields   map[reflect.Type][]string
+
+	definitions map[string]any
+	names       map[reflect.Type]string
+	usedNames   map[string]reflect.Type
+}
+
+func NewGenerator() *Generator {
+	return &Generator{
+		overrides:     map[reflect.Type]map[string]any{},
+		stringOrTypes: map[reflect.Type]bool{},
+		oneOfFields:   map[reflect.Type][]string{},
+	}
+}
+
+// AddOverride replaces the generated schema of the given type with the given schema. This is required for types that
+// implement custom (un)marshalling.
+func (g *Generator) AddOverride(t reflect.Type, s map[string]any) {
+	g.overrides[t] = s
+}
+
+// AddStringOr allows the given struct type to be also specified as a simple string, which is the case for types that
+// implement UnmarshalJSON with a shorthand string form.
+func (g *Generator) AddStringOr(t reflect.Type) {
+	g.stringOrTypes[t] = true
+}
+
+// AddOneOf requires exactly one of the given properties (json names) to be set on the given struct type.
+func (g *Generator) AddOneOf(t reflect.Type, fields []string) {
+	g.oneOfFields[t] = fields
+}
+
+// Generate returns the JSON schema for the given root type.
+func (g *Generator) Generate(t reflect.Type) map[string]any {
+	g.definitions = map[string]any{}
+	g.names = map[reflect.Type]string{}
+	g.usedNames = map[string]reflect.Type{}
+
+	ret := g.typeSchema(t)
+	ret["$schema"] = draft07
+	ret["definitions"] = g.definitions
+	return ret
+}
+
+func (g *Generator) typeSchema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if s, ok := g.overrides[t]; ok {
+		return copyMap(s)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string"}
+		}
+		return map[string]any{
+			"type":  "array",
+			"items": g.typeSchema(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": g.typeSchema(t.Elem()),
+		}
+	case reflect.Struct:
+		ref := map[string]any{"$ref": "#/definitions/" + g.definitionName(t)}
+		if g.stringOrTypes[t] {
+			return map[string]any{
+				"anyOf": []any{map[string]any{"type": "string"}, ref},
+			}
+		}
+		return ref
+	default:
+		// interfaces and everything else can be anything
+		return map[string]any{}
+	}
+}
+
+func (g *Generator) definitionName(t reflect.Type) string {
+	if n, ok := g.names[t]; ok {
+		return n
+	}
+
+	n := t.Name()
+	if t2, ok := g.usedNames[n]; ok && t2 != t {
+		n = path.Base(t.PkgPath()) + "." + n
+	}
+	g.names[t] = n
+	g.usedNames[n] = t
+
+	// register a placeholder first so that recursive types terminate
+	g.definitions[n] = map[string]any{}
+	g.definitions[n] = g.structSchema(t)
+	return n
+}
+
+func (g *Generator) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	g.addStructFields(t, properties, &required)
+
+	ret := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) != 0 {
+		sort.Strings(required)
+		ret["required"] = required
+	}
+	if fields, ok := g.oneOfFields[t]; ok {
+		var oneOf []any
+		for _, f := range fields {
+			oneOf = append(oneOf, map[string]any{"required": []string{f}})
+		}
+		ret["oneOf"] = oneOf
+	}
+	return ret
+}
+
+func (g *Generator) addStructFields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Tag.Get("jsonschema") == "-" {
+			continue
+		}
+
+		jsonTag := f.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(jsonTag, ",")
+		if f.Anonymous && (name == "" || strings.Contains(opts, "inline")) {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addStructFields(ft, properties, required)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		s := g.typeSchema(f.Type)
+		for _, v := range strings.Split(f.Tag.Get("validate"), ",") {
+			if v == "required" {
+				*required = append(*required, name)
+			} else if strings.HasPrefix(v, "oneof=") {
+				var enum []any
+				for _, e := range strings.Fields(strings.TrimPrefix(v, "oneof=")) {
+					enum = append(enum, e)
+				}
+				s["enum"] = enum
+			}
+		}
+		properties[name] = s
+	}
+}
+
+func copyMap(m map[string]any) map[string]any {
+	ret := make(map[string]any, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	return ret
+}
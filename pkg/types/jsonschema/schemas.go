@@ -0,0 +1,97 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Schemas contains the root types of all config files for which schemas are available, keyed by the schema name.
+var Schemas = map[string]reflect.Type{
+	"kluctl-project":    reflect.TypeOf(types.KluctlProject{}),
+	"kluctl-library":    reflect.TypeOf(types.KluctlLibraryProject{}),
+	"deployment":        reflect.TypeOf(types.DeploymentProjectConfig{}),
+	"vars-source":       reflect.TypeOf(types.VarsSource{}),
+	"fixed-images":      reflect.TypeOf(types.FixedImagesConfig{}),
+	"helm-chart-config": reflect.TypeOf(types.HelmChartConfig{}),
+}
+
+// SchemaNames returns the sorted names of all available schemas.
+func SchemaNames() []string {
+	var ret []string
+	for n := range Schemas {
+		ret = append(ret, n)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// VarsSourceFields returns the json names of all vars source variants, as marked by the isVarsSource tag.
+func VarsSourceFields() []string {
+	var ret []string
+	t := reflect.TypeOf(types.VarsSource{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("isVarsSource") == "true" {
+			ret = append(ret, jsonName(f))
+		}
+	}
+	return ret
+}
+
+func jsonName(f reflect.StructField) string {
+	n, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return n
+}
+
+func newKluctlGenerator() *Generator {
+	g := NewGenerator()
+
+	anyObject := map[string]any{"type": "object"}
+	g.AddOverride(reflect.TypeOf(uo.UnstructuredObject{}), anyObject)
+	g.AddOverride(reflect.TypeOf(runtime.RawExtension{}), map[string]any{})
+	g.AddOverride(reflect.TypeOf(apiextensionsv1.JSON{}), map[string]any{})
+	g.AddOverride(reflect.TypeOf(gittypes.GitUrl{}), map[string]any{"type": "string"})
+	g.AddOverride(reflect.TypeOf(types.YamlUrl{}), map[string]any{"type": "string"})
+	g.AddOverride(reflect.TypeOf(types.SingleStringOrList{}), map[string]any{
+		"anyOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	})
+
+	// these implement UnmarshalJSON with a shorthand string form
+	g.AddStringOr(reflect.TypeOf(gittypes.GitRef{}))
+	g.AddStringOr(reflect.TypeOf(types.GitProject{}))
+
+	g.AddOneOf(reflect.TypeOf(types.VarsSource{}), VarsSourceFields())
+
+	return g
+}
+
+// GetSchema generates the JSON schema with the given name.
+func GetSchema(name string) (map[string]any, error) {
+	t, ok := Schemas[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema %s, must be one of %v", name, SchemaNames())
+	}
+	s := newKluctlGenerator().Generate(t)
+	s["title"] = name
+	return s, nil
+}
+
+// GetSchemaJson generates the JSON schema with the given name and returns it as indented JSON.
+func GetSchemaJson(name string) ([]byte, error) {
+	s, err := GetSchema(name)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(s, "", "  ")
+}
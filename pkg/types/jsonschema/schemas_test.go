@@ -0,0 +1,114 @@
+package jsonschema
+
+import (
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+	"reflect"
+	"testing"
+)
+
+func validateYaml(t *testing.T, schemaName string, y string) *gojsonschema.Result {
+	s, err := GetSchema(schemaName)
+	assert.NoError(t, err)
+
+	var doc any
+	err = yaml.ReadYamlString(y, &doc)
+	assert.NoError(t, err)
+
+	r, err := gojsonschema.Validate(gojsonschema.NewGoLoader(s), gojsonschema.NewGoLoader(doc))
+	assert.NoError(t, err)
+	return r
+}
+
+func TestAllSchemasCompile(t *testing.T) {
+	for _, n := range SchemaNames() {
+		s, err := GetSchema(n)
+		assert.NoError(t, err)
+		_, err = gojsonschema.NewSchema(gojsonschema.NewGoLoader(s))
+		assert.NoError(t, err, n)
+	}
+
+	_, err := GetSchema("unknown")
+	assert.ErrorContains(t, err, "unknown schema unknown")
+}
+
+func TestVarsSourceSchemaCoversAllFields(t *testing.T) {
+	s, err := GetSchema("vars-source")
+	assert.NoError(t, err)
+
+	def := s["definitions"].(map[string]any)["VarsSource"].(map[string]any)
+	props := def["properties"].(map[string]any)
+
+	rt := Schemas["vars-source"]
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		_, ok := props[jsonName(f)]
+		if f.Tag.Get("jsonschema") == "-" {
+			assert.False(t, ok, f.Name)
+		} else {
+			assert.True(t, ok, f.Name)
+		}
+	}
+
+	assert.Len(t, def["oneOf"], len(VarsSourceFields()))
+}
+
+func TestDeploymentSchema(t *testing.T) {
+	r := validateYaml(t, "deployment", `
+vars:
+  - file: vars.yaml
+    ignoreMissing: true
+  - git:
+      url: https://github.com/example/repo.git
+      ref:
+        tag: v1.0.0
+      path: vars.yaml
+  - clusterConfigMap:
+      name: cm
+      namespace: ns
+      key: vars
+deployments:
+  - path: app
+    tags: [app]
+  - git: https://github.com/example/other.git
+  - barrier: true
+conflictResolution:
+  - fieldPath: spec.replicas
+    action: ignore
+`)
+	assert.True(t, r.Valid(), r.Errors())
+
+	r = validateYaml(t, "deployment", `
+vars:
+  - file: vars.yaml
+    ignoreMisssing: true
+`)
+	assert.False(t, r.Valid())
+
+	r = validateYaml(t, "deployment", `
+conflictResolution:
+  - fieldPath: spec.replicas
+    action: invalid
+`)
+	assert.False(t, r.Valid())
+}
+
+func TestVarsSourceSchemaOneOf(t *testing.T) {
+	r := validateYaml(t, "vars-source", `
+file: vars.yaml
+`)
+	assert.True(t, r.Valid(), r.Errors())
+
+	r = validateYaml(t, "vars-source", `
+ignoreMissing: true
+`)
+	assert.False(t, r.Valid())
+
+	r = validateYaml(t, "vars-source", `
+file: vars.yaml
+values:
+  a: b
+`)
+	assert.False(t, r.Valid())
+}
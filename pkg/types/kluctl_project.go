@@ -27,6 +27,9 @@ type Target struct {
 type DeploymentArg struct {
 	Name    string                `json:"name" validate:"required"`
 	Default *apiextensionsv1.JSON `json:"default,omitempty"`
+	// Type is the JSON schema type of the arg, which is validated when --strict-args is used. If omitted, the type is
+	// inferred from the default value
+	Type string `json:"type,omitempty" validate:"omitempty,oneof=string boolean integer number object array"`
 }
 
 type KluctlProject struct {
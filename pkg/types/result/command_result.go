@@ -22,9 +22,58 @@ type ChangedObject struct {
 	Changes []Change      `json:"changes,omitempty"`
 }
 
+// HelmValuesChange describes the changes of the rendered values of a single Helm release, compared to the values of
+// the previous command result.
+type HelmValuesChange struct {
+	ReleaseName string   `json:"releaseName"`
+	Namespace   string   `json:"namespace,omitempty"`
+	Changes     []Change `json:"changes,omitempty"`
+}
+
+// PruneCandidates lists objects that are slated for deletion, grouped by the deployment item that causes the deletion.
+// Orphan objects are not associated with any deployment item and are instead marked via Orphan.
+type PruneCandidates struct {
+	DeploymentItem string          `json:"deploymentItem,omitempty"`
+	Orphan         bool            `json:"orphan,omitempty"`
+	Refs           []k8s.ObjectRef `json:"refs"`
+}
+
+// PruneSkippedObject is an orphan object that was excluded from pruning by the prune allow/deny lists
+type PruneSkippedObject struct {
+	Ref    k8s.ObjectRef `json:"ref"`
+	Reason string        `json:"reason"`
+}
+
+// AppliedKindCounts counts the applied objects of a single GroupKind. Objects that did not exist before applying are
+// counted as created, all others as updated, even if the apply did not cause any actual changes.
+type AppliedKindCounts struct {
+	Group   string `json:"group,omitempty"`
+	Kind    string `json:"kind"`
+	Created int    `json:"created"`
+	Updated int    `json:"updated"`
+}
+
+// AppliedObjectsSummary is a compact summary of all applied objects, grouped by GroupKind. Hooks are counted separately
+// from normal objects.
+type AppliedObjectsSummary struct {
+	Objects []AppliedKindCounts `json:"objects,omitempty"`
+	Hooks   []AppliedKindCounts `json:"hooks,omitempty"`
+}
+
+// PinnedImage records the digest an image tag got resolved to when pinning image digests
+type PinnedImage struct {
+	Image  string `json:"image"`
+	Digest string `json:"digest"`
+}
+
 type DeploymentError struct {
 	Ref     k8s.ObjectRef `json:"ref"`
 	Message string        `json:"message"`
+
+	// Count is set when identical messages of multiple objects got deduplicated. Ref then only contains the GVK and
+	// Refs contains the refs of all affected objects.
+	Count int             `json:"count,omitempty"`
+	Refs  []k8s.ObjectRef `json:"refs,omitempty"`
 }
 
 type KluctlDeploymentInfo struct {
@@ -122,6 +171,19 @@ type CommandResult struct {
 	Errors     []DeploymentError  `json:"errors,omitempty"`
 	Warnings   []DeploymentError  `json:"warnings,omitempty"`
 	SeenImages []types.FixedImage `json:"seenImages,omitempty"`
+
+	HelmValuesChanges []HelmValuesChange `json:"helmValuesChanges,omitempty"`
+
+	// PruneReport contains all objects that are (or would be in dry-run mode) deleted by the command
+	PruneReport []PruneCandidates `json:"pruneReport,omitempty"`
+	// PruneSkipped contains all orphan objects that were not pruned due to the prune allow/deny lists
+	PruneSkipped []PruneSkippedObject `json:"pruneSkipped,omitempty"`
+
+	// AppliedObjectsSummary is only set for commands that apply objects
+	AppliedObjectsSummary *AppliedObjectsSummary `json:"appliedObjectsSummary,omitempty"`
+
+	// PinnedImages contains all images that got pinned to their digests before applying
+	PinnedImages []PinnedImage `json:"pinnedImages,omitempty"`
 }
 
 func (cr *CommandResult) ToCompacted() *CompactedCommandResult {
@@ -32,6 +32,8 @@ type CommandResultSummary struct {
 	Warnings []DeploymentError `json:"warnings"`
 
 	TotalChanges int `json:"totalChanges"`
+
+	AppliedObjectsSummary *AppliedObjectsSummary `json:"appliedObjectsSummary,omitempty"`
 }
 
 func (cr *CommandResult) BuildSummary() *CommandResultSummary {
@@ -70,6 +72,8 @@ func (cr *CommandResult) BuildSummary() *CommandResultSummary {
 		DeletedObjects:      count(func(o ResultObject) bool { return o.Deleted }),
 		Errors:              cr.Errors,
 		Warnings:            cr.Warnings,
+
+		AppliedObjectsSummary: cr.AppliedObjectsSummary,
 	}
 	for _, o := range cr.Objects {
 		ret.TotalChanges += len(o.Changes)
@@ -22,9 +22,50 @@ package result
 
 import (
 	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppliedKindCounts) DeepCopyInto(out *AppliedKindCounts) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppliedKindCounts.
+func (in *AppliedKindCounts) DeepCopy() *AppliedKindCounts {
+	if in == nil {
+		return nil
+	}
+	out := new(AppliedKindCounts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppliedObjectsSummary) DeepCopyInto(out *AppliedObjectsSummary) {
+	*out = *in
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]AppliedKindCounts, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]AppliedKindCounts, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppliedObjectsSummary.
+func (in *AppliedObjectsSummary) DeepCopy() *AppliedObjectsSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(AppliedObjectsSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BaseObject) DeepCopyInto(out *BaseObject) {
 	*out = *in
@@ -192,12 +233,16 @@ func (in *CommandResult) DeepCopyInto(out *CommandResult) {
 	if in.Errors != nil {
 		in, out := &in.Errors, &out.Errors
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Warnings != nil {
 		in, out := &in.Warnings, &out.Warnings
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.SeenImages != nil {
 		in, out := &in.SeenImages, &out.SeenImages
@@ -206,6 +251,35 @@ func (in *CommandResult) DeepCopyInto(out *CommandResult) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.HelmValuesChanges != nil {
+		in, out := &in.HelmValuesChanges, &out.HelmValuesChanges
+		*out = make([]HelmValuesChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PruneReport != nil {
+		in, out := &in.PruneReport, &out.PruneReport
+		*out = make([]PruneCandidates, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PruneSkipped != nil {
+		in, out := &in.PruneSkipped, &out.PruneSkipped
+		*out = make([]PruneSkippedObject, len(*in))
+		copy(*out, *in)
+	}
+	if in.AppliedObjectsSummary != nil {
+		in, out := &in.AppliedObjectsSummary, &out.AppliedObjectsSummary
+		*out = new(AppliedObjectsSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PinnedImages != nil {
+		in, out := &in.PinnedImages, &out.PinnedImages
+		*out = make([]PinnedImage, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandResult.
@@ -235,12 +309,21 @@ func (in *CommandResultSummary) DeepCopyInto(out *CommandResultSummary) {
 	if in.Errors != nil {
 		in, out := &in.Errors, &out.Errors
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Warnings != nil {
 		in, out := &in.Warnings, &out.Warnings
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AppliedObjectsSummary != nil {
+		in, out := &in.AppliedObjectsSummary, &out.AppliedObjectsSummary
+		*out = new(AppliedObjectsSummary)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -318,6 +401,11 @@ func (in CompactedObjects) DeepCopy() CompactedObjects {
 func (in *DeploymentError) DeepCopyInto(out *DeploymentError) {
 	*out = *in
 	out.Ref = in.Ref
+	if in.Refs != nil {
+		in, out := &in.Refs, &out.Refs
+		*out = make([]k8s.ObjectRef, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentError.
@@ -345,12 +433,16 @@ func (in *DriftDetectionResult) DeepCopyInto(out *DriftDetectionResult) {
 	if in.Warnings != nil {
 		in, out := &in.Warnings, &out.Warnings
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Errors != nil {
 		in, out := &in.Errors, &out.Errors
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Objects != nil {
 		in, out := &in.Objects, &out.Objects
@@ -387,6 +479,28 @@ func (in *DriftedObject) DeepCopy() *DriftedObject {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmValuesChange) DeepCopyInto(out *HelmValuesChange) {
+	*out = *in
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]Change, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmValuesChange.
+func (in *HelmValuesChange) DeepCopy() *HelmValuesChange {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmValuesChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KluctlDeploymentInfo) DeepCopyInto(out *KluctlDeploymentInfo) {
 	*out = *in
@@ -402,6 +516,56 @@ func (in *KluctlDeploymentInfo) DeepCopy() *KluctlDeploymentInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PinnedImage) DeepCopyInto(out *PinnedImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PinnedImage.
+func (in *PinnedImage) DeepCopy() *PinnedImage {
+	if in == nil {
+		return nil
+	}
+	out := new(PinnedImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PruneCandidates) DeepCopyInto(out *PruneCandidates) {
+	*out = *in
+	if in.Refs != nil {
+		in, out := &in.Refs, &out.Refs
+		*out = make([]k8s.ObjectRef, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PruneCandidates.
+func (in *PruneCandidates) DeepCopy() *PruneCandidates {
+	if in == nil {
+		return nil
+	}
+	out := new(PruneCandidates)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PruneSkippedObject) DeepCopyInto(out *PruneSkippedObject) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PruneSkippedObject.
+func (in *PruneSkippedObject) DeepCopy() *PruneSkippedObject {
+	if in == nil {
+		return nil
+	}
+	out := new(PruneSkippedObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResultObject) DeepCopyInto(out *ResultObject) {
 	*out = *in
@@ -464,12 +628,16 @@ func (in *ValidateResult) DeepCopyInto(out *ValidateResult) {
 	if in.Warnings != nil {
 		in, out := &in.Warnings, &out.Warnings
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Errors != nil {
 		in, out := &in.Errors, &out.Errors
 		*out = make([]DeploymentError, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Results != nil {
 		in, out := &in.Results, &out.Results
@@ -19,6 +19,13 @@ type FixedImage struct {
 	DeploymentDir *string        `json:"deploymentDir,omitempty"`
 }
 
+// ImageOverride rewrites all images matching Regex to Replacement, which can reference capture groups of Regex via
+// $1 or ${1} (see regexp.Regexp.Expand). Fixed images always take precedence over image overrides.
+type ImageOverride struct {
+	Regex       string `json:"regex" validate:"required"`
+	Replacement string `json:"replacement"`
+}
+
 type FixedImagesConfig struct {
 	Images []FixedImage `json:"images,omitempty"`
 }
@@ -7,12 +7,22 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"k8s.io/apimachinery/pkg/runtime"
 	"reflect"
+	"strings"
 )
 
 type VarsSourceGit struct {
 	Url  types.GitUrl  `json:"url" validate:"required"`
 	Ref  *types.GitRef `json:"ref,omitempty"`
 	Path string        `json:"path" validate:"required"`
+
+	// Sparse enables a sparse checkout, limited to the directory containing Path and the directories of included templates
+	Sparse bool `json:"sparse,omitempty"`
+}
+
+type VarsSourceOci struct {
+	Url  string  `json:"url" validate:"required"`
+	Ref  *OciRef `json:"ref,omitempty"`
+	Path string  `json:"path" validate:"required"`
 }
 
 type VarsSourceGitFiles struct {
@@ -52,6 +62,12 @@ type VarsSourceClusterConfigMapOrSecret struct {
 	Namespace  string            `json:"namespace" validate:"required"`
 	Key        string            `json:"key" validate:"required"`
 	TargetPath string            `json:"targetPath,omitempty"`
+
+	// FieldManager restricts the loaded key to keys owned by the given field manager (see metadata.managedFields)
+	FieldManager string `json:"fieldManager,omitempty"`
+
+	// MergeMultiple allows labels to match multiple objects, which are then merged in the order of their names
+	MergeMultiple bool `json:"mergeMultiple,omitempty"`
 }
 
 func ValidateVarsSourceClusterConfigMapOrSecret(sl validator.StructLevel) {
@@ -62,6 +78,9 @@ func ValidateVarsSourceClusterConfigMapOrSecret(sl validator.StructLevel) {
 	} else if s.Name != "" && len(s.Labels) != 0 {
 		sl.ReportError(s, "self", "self", "only one of name or labels can be set", "")
 	}
+	if s.MergeMultiple && len(s.Labels) == 0 {
+		sl.ReportError(s, "mergeMultiple", "mergeMultiple", "mergeMultiple can only be used with labels", "")
+	}
 }
 
 type VarsSourceClusterObject struct {
@@ -95,6 +114,24 @@ type VarsSourceHttp struct {
 	Body     *string           `json:"body,omitempty"`
 	Headers  map[string]string `json:"headers,omitempty"`
 	JsonPath *string           `json:"jsonPath,omitempty"`
+
+	// HeadersFromEnv maps header names to the names of environment variables that contain the header values. This
+	// avoids putting tokens into the project.
+	HeadersFromEnv map[string]string `json:"headersFromEnv,omitempty"`
+
+	// TlsClientCert, TlsClientKey and TlsCaBundle are either file pathes or references to environment variables in the
+	// form of 'env:MY_ENV_VAR'. Relative file pathes are resolved against the project directory.
+	TlsClientCert      *string `json:"tlsClientCert,omitempty"`
+	TlsClientKey       *string `json:"tlsClientKey,omitempty"`
+	TlsCaBundle        *string `json:"tlsCaBundle,omitempty"`
+	InsecureSkipVerify bool    `json:"insecureSkipVerify,omitempty"`
+}
+
+func ValidateVarsSourceHttp(sl validator.StructLevel) {
+	s := sl.Current().Interface().(VarsSourceHttp)
+	if (s.TlsClientCert == nil) != (s.TlsClientKey == nil) {
+		sl.ReportError(s, "self", "self", "tlsClientCert and tlsClientKey must be set together", "")
+	}
 }
 
 type VarsSourceAwsSecretsManager struct {
@@ -106,6 +143,30 @@ type VarsSourceAwsSecretsManager struct {
 	Profile *string `json:"profile,omitempty"`
 }
 
+type VarsSourceAwsSsm struct {
+	// Name or ARN of the parameter. In case recursive is true, this is the parameter path to read
+	Name string `json:"name" validate:"required"`
+	// Read all parameters below the path given in name and build a nested object from the parameter hierarchy
+	Recursive bool `json:"recursive,omitempty"`
+	// The aws region
+	Region *string `json:"region,omitempty"`
+	// AWS credentials profile to use. The AWS_PROFILE environemnt variables will take precedence in case it is also set
+	Profile *string `json:"profile,omitempty"`
+}
+
+type VarsSourceAwsAppConfig struct {
+	// Name or ID of the AppConfig application
+	Application string `json:"application" validate:"required"`
+	// Name or ID of the environment
+	Environment string `json:"environment" validate:"required"`
+	// Name or ID of the configuration profile. The currently deployed configuration of this profile is read
+	ConfigurationProfile string `json:"configurationProfile" validate:"required"`
+	// The aws region
+	Region *string `json:"region,omitempty"`
+	// AWS credentials profile to use. The AWS_PROFILE environemnt variables will take precedence in case it is also set
+	Profile *string `json:"profile,omitempty"`
+}
+
 type VarSourceAzureKeyVault struct {
 	// Name or ARN of the secret. In case a name is given, the region must be specified as well
 	VaultUri string `json:"vaultUri" validate:"required"`
@@ -121,6 +182,113 @@ type VarsSourceGcpSecretManager struct {
 type VarsSourceVault struct {
 	Address string `json:"address" validate:"required"`
 	Path    string `json:"path" validate:"required"`
+	// Version specifies the secret version to read. Only supported for KV v2 mounts. Defaults to the latest version.
+	Version *int `json:"version,omitempty"`
+	// KvVersion specifies the KV secrets engine version (1 or 2) of the mount. If omitted, the version is detected via
+	// the mount info, which requires read access to sys/internal/ui/mounts.
+	KvVersion int `json:"kvVersion,omitempty" validate:"omitempty,oneof=1 2"`
+
+	// Auth specifies the auth method to use. If omitted, the default token (e.g. from VAULT_TOKEN) is used.
+	Auth *VarsSourceVaultAuth `json:"auth,omitempty"`
+}
+
+type VarsSourceVaultAuth struct {
+	AppRole    *VarsSourceVaultAppRoleAuth    `json:"appRole,omitempty"`
+	Kubernetes *VarsSourceVaultKubernetesAuth `json:"kubernetes,omitempty"`
+}
+
+type VarsSourceVaultAppRoleAuth struct {
+	// MountPath is the path where the auth method is mounted. Defaults to "approle"
+	MountPath string `json:"mountPath,omitempty"`
+	RoleId    string `json:"roleId" validate:"required"`
+	SecretId  string `json:"secretId" validate:"required"`
+}
+
+type VarsSourceVaultKubernetesAuth struct {
+	// MountPath is the path where the auth method is mounted. Defaults to "kubernetes"
+	MountPath string `json:"mountPath,omitempty"`
+	Role      string `json:"role" validate:"required"`
+	// TokenPath is the path to the service account token. Defaults to the in-cluster service account token
+	TokenPath string `json:"tokenPath,omitempty"`
+}
+
+func ValidateVarsSourceVaultAuth(sl validator.StructLevel) {
+	s := sl.Current().Interface().(VarsSourceVaultAuth)
+	if s.AppRole == nil && s.Kubernetes == nil {
+		sl.ReportError(s, "self", "self", "one of appRole or kubernetes must be set", "")
+	} else if s.AppRole != nil && s.Kubernetes != nil {
+		sl.ReportError(s, "self", "self", "only one of appRole or kubernetes can be set", "")
+	}
+}
+
+type VarsSourceEtcd struct {
+	Endpoints []string `json:"endpoints" validate:"required,min=1"`
+	Key       string   `json:"key" validate:"required"`
+	// Prefix causes all keys with the given prefix to be loaded
+	Prefix bool               `json:"prefix,omitempty"`
+	Tls    *VarsSourceEtcdTls `json:"tls,omitempty"`
+}
+
+type VarsSourceEtcdTls struct {
+	CaFile                string `json:"caFile,omitempty"`
+	CertFile              string `json:"certFile,omitempty"`
+	KeyFile               string `json:"keyFile,omitempty"`
+	InsecureSkipTlsVerify bool   `json:"insecureSkipTlsVerify,omitempty"`
+}
+
+type VarsSource1Password struct {
+	ConnectHost string `json:"connectHost" validate:"required"`
+	// Token is the 1Password Connect access token. If omitted, the token is read from the environment variable
+	// specified via TokenEnv, which defaults to OP_CONNECT_TOKEN
+	Token    string `json:"token,omitempty"`
+	TokenEnv string `json:"tokenEnv,omitempty"`
+
+	// Vault and Item can either be names/titles or ids
+	Vault string `json:"vault" validate:"required"`
+	Item  string `json:"item" validate:"required"`
+
+	// Field specifies a single field which is loaded as variables YAML. Fields specifies a list of fields which are
+	// loaded as a dictionary, with the field labels as keys
+	Field  string   `json:"field,omitempty"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+func ValidateVarsSource1Password(sl validator.StructLevel) {
+	s := sl.Current().Interface().(VarsSource1Password)
+	if s.Token != "" && s.TokenEnv != "" {
+		sl.ReportError(s, "self", "self", "only one of token or tokenEnv can be set", "")
+	}
+	if (s.Field == "") == (len(s.Fields) == 0) {
+		sl.ReportError(s, "self", "self", "exactly one of field or fields must be set", "")
+	}
+}
+
+type VarsSourceGitlab struct {
+	// BaseUrl is the URL of the GitLab instance. Defaults to https://gitlab.com
+	BaseUrl string `json:"baseUrl,omitempty"`
+	// ProjectId can either be the numeric project id or the full path of the project, e.g. "my-group/my-project"
+	ProjectId string `json:"projectId" validate:"required"`
+
+	// Token is the GitLab access token. If omitted, the token is read from the environment variable specified via
+	// TokenEnv, which defaults to GITLAB_TOKEN
+	Token    string `json:"token,omitempty"`
+	TokenEnv string `json:"tokenEnv,omitempty"`
+
+	// EnvironmentScope causes variables with the given environment scope to be loaded in addition to variables
+	// defined for all environments ("*"). Variables with a matching scope take precedence.
+	EnvironmentScope string `json:"environmentScope,omitempty"`
+}
+
+func ValidateVarsSourceGitlab(sl validator.StructLevel) {
+	s := sl.Current().Interface().(VarsSourceGitlab)
+	if s.Token != "" && s.TokenEnv != "" {
+		sl.ReportError(s, "self", "self", "only one of token or tokenEnv can be set", "")
+	}
+}
+
+type VarsSourceClusterInfo struct {
+	// Fallback specifies the values to use when no cluster is available, e.g. in offline mode
+	Fallback *uo.UnstructuredObject `json:"fallback,omitempty"`
 }
 
 type VarsSource struct {
@@ -132,23 +300,47 @@ type VarsSource struct {
 	File              *string                             `json:"file,omitempty" isVarsSource:"true"`
 	Git               *VarsSourceGit                      `json:"git,omitempty" isVarsSource:"true"`
 	GitFiles          *VarsSourceGitFiles                 `json:"gitFiles,omitempty" isVarsSource:"true"`
+	Oci               *VarsSourceOci                      `json:"oci,omitempty" isVarsSource:"true"`
 	ClusterConfigMap  *VarsSourceClusterConfigMapOrSecret `json:"clusterConfigMap,omitempty" isVarsSource:"true"`
 	ClusterSecret     *VarsSourceClusterConfigMapOrSecret `json:"clusterSecret,omitempty" isVarsSource:"true"`
 	ClusterObject     *VarsSourceClusterObject            `json:"clusterObject,omitempty" isVarsSource:"true"`
 	SystemEnvVars     *uo.UnstructuredObject              `json:"systemEnvVars,omitempty" isVarsSource:"true"`
+	EnvFile           *string                             `json:"envFile,omitempty"`
 	Http              *VarsSourceHttp                     `json:"http,omitempty" isVarsSource:"true" isVarsSource:"true"`
 	AwsSecretsManager *VarsSourceAwsSecretsManager        `json:"awsSecretsManager,omitempty" isVarsSource:"true"`
+	AwsSsm            *VarsSourceAwsSsm                   `json:"awsSsm,omitempty" isVarsSource:"true"`
+	AwsAppConfig      *VarsSourceAwsAppConfig             `json:"awsAppConfig,omitempty" isVarsSource:"true"`
 	GcpSecretManager  *VarsSourceGcpSecretManager         `json:"gcpSecretManager,omitempty" isVarsSource:"true"`
 	Vault             *VarsSourceVault                    `json:"vault,omitempty" isVarsSource:"true"`
 	AzureKeyVault     *VarSourceAzureKeyVault             `json:"azureKeyVault,omitempty" isVarsSource:"true"`
+	Etcd              *VarsSourceEtcd                     `json:"etcd,omitempty" isVarsSource:"true"`
+	OnePassword       *VarsSource1Password                `json:"onePassword,omitempty" isVarsSource:"true"`
+	Gitlab            *VarsSourceGitlab                   `json:"gitlab,omitempty" isVarsSource:"true"`
+	ClusterInfo       *VarsSourceClusterInfo              `json:"clusterInfo,omitempty" isVarsSource:"true"`
 
+	// SourcePath specifies a JSON path to a dictionary inside the loaded variables. Only this dictionary is then used
+	// as the new variables
+	SourcePath string `json:"sourcePath,omitempty"`
 	TargetPath string `json:"targetPath,omitempty"`
 
 	When string `json:"when,omitempty"`
 
 	// these are only allowed when writing the command result
-	RenderedSensitive bool                   `json:"renderedSensitive,omitempty"`
-	RenderedVars      *uo.UnstructuredObject `json:"renderedVars,omitempty"`
+	RenderedSensitive bool                   `json:"renderedSensitive,omitempty" jsonschema:"-"`
+	RenderedVars      *uo.UnstructuredObject `json:"renderedVars,omitempty" jsonschema:"-"`
+}
+
+// TypeName returns the json name of the vars source variant that is set, e.g. "git" or "vault". It returns an empty
+// string if no variant is set.
+func (vs *VarsSource) TypeName() string {
+	v := reflect.ValueOf(vs).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.Tag.Get("isVarsSource") == "true" && !v.Field(i).IsNil() {
+			return strings.Split(f.Tag.Get("json"), ",")[0]
+		}
+	}
+	return ""
 }
 
 func ValidateVarsSource(sl validator.StructLevel) {
@@ -168,10 +360,17 @@ func ValidateVarsSource(sl validator.StructLevel) {
 	} else if count != 1 {
 		sl.ReportError(s, "self", "self", "more then one vars source type", "")
 	}
+	if s.EnvFile != nil && s.SystemEnvVars == nil {
+		sl.ReportError(s.EnvFile, "envFile", "EnvFile", "envFile is only allowed for systemEnvVars", "")
+	}
 }
 
 func init() {
 	yaml.Validator.RegisterStructValidation(ValidateVarsSourceClusterConfigMapOrSecret, VarsSourceClusterConfigMapOrSecret{})
 	yaml.Validator.RegisterStructValidation(ValidateVarsSourceClusterObject, VarsSourceClusterObject{})
 	yaml.Validator.RegisterStructValidation(ValidateVarsSource, VarsSource{})
+	yaml.Validator.RegisterStructValidation(ValidateVarsSourceVaultAuth, VarsSourceVaultAuth{})
+	yaml.Validator.RegisterStructValidation(ValidateVarsSource1Password, VarsSource1Password{})
+	yaml.Validator.RegisterStructValidation(ValidateVarsSourceGitlab, VarsSourceGitlab{})
+	yaml.Validator.RegisterStructValidation(ValidateVarsSourceHttp, VarsSourceHttp{})
 }
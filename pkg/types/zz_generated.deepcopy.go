@@ -27,6 +27,41 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplyOrderItemConfig) DeepCopyInto(out *ApplyOrderItemConfig) {
+	*out = *in
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplyOrderItemConfig.
+func (in *ApplyOrderItemConfig) DeepCopy() *ApplyOrderItemConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplyOrderItemConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AwsConfig) DeepCopyInto(out *AwsConfig) {
 	*out = *in
@@ -196,6 +231,11 @@ func (in *DeploymentItemConfig) DeepCopyInto(out *DeploymentItemConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.RenderedHelmChartConfig != nil {
 		in, out := &in.RenderedHelmChartConfig, &out.RenderedHelmChartConfig
 		*out = new(HelmChartConfig)
@@ -211,6 +251,10 @@ func (in *DeploymentItemConfig) DeepCopyInto(out *DeploymentItemConfig) {
 		*out = new(DeploymentProjectConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RenderedHelmValues != nil {
+		in, out := &in.RenderedHelmValues, &out.RenderedHelmValues
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentItemConfig.
@@ -233,6 +277,11 @@ func (in *DeploymentProjectConfig) DeepCopyInto(out *DeploymentProjectConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.VarsSchema != nil {
+		in, out := &in.VarsSchema, &out.VarsSchema
+		*out = new(VarsSchemaConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Deployments != nil {
 		in, out := &in.Deployments, &out.Deployments
 		*out = make([]DeploymentItemConfig, len(*in))
@@ -278,6 +327,20 @@ func (in *DeploymentProjectConfig) DeepCopyInto(out *DeploymentProjectConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ApplyOrder != nil {
+		in, out := &in.ApplyOrder, &out.ApplyOrder
+		*out = make([]ApplyOrderItemConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReadinessChecks != nil {
+		in, out := &in.ReadinessChecks, &out.ReadinessChecks
+		*out = make([]ReadinessCheckConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentProjectConfig.
@@ -512,6 +575,13 @@ func (in *HelmChartConfig2) DeepCopyInto(out *HelmChartConfig2) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]HelmValuesLayer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartConfig2.
@@ -524,6 +594,30 @@ func (in *HelmChartConfig2) DeepCopy() *HelmChartConfig2 {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmValuesLayer) DeepCopyInto(out *HelmValuesLayer) {
+	*out = *in
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(string)
+		**out = **in
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmValuesLayer.
+func (in *HelmValuesLayer) DeepCopy() *HelmValuesLayer {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmValuesLayer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IgnoreForDiffItemConfig) DeepCopyInto(out *IgnoreForDiffItemConfig) {
 	*out = *in
@@ -569,6 +663,21 @@ func (in *IgnoreForDiffItemConfig) DeepCopy() *IgnoreForDiffItemConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageOverride) DeepCopyInto(out *ImageOverride) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageOverride.
+func (in *ImageOverride) DeepCopy() *ImageOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KluctlLibraryProject) DeepCopyInto(out *KluctlLibraryProject) {
 	*out = *in
@@ -685,6 +794,41 @@ func (in *OciRef) DeepCopy() *OciRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessCheckConfig) DeepCopyInto(out *ReadinessCheckConfig) {
+	*out = *in
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Condition != nil {
+		in, out := &in.Condition, &out.Condition
+		*out = new(string)
+		**out = **in
+	}
+	if in.Field != nil {
+		in, out := &in.Field, &out.Field
+		*out = new(string)
+		**out = **in
+	}
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessCheckConfig.
+func (in *ReadinessCheckConfig) DeepCopy() *ReadinessCheckConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessCheckConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceAccountRef) DeepCopyInto(out *ServiceAccountRef) {
 	*out = *in
@@ -770,6 +914,30 @@ func (in *VarSourceAzureKeyVault) DeepCopy() *VarSourceAzureKeyVault {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSchemaConfig) DeepCopyInto(out *VarsSchemaConfig) {
+	*out = *in
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(string)
+		**out = **in
+	}
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSchemaConfig.
+func (in *VarsSchemaConfig) DeepCopy() *VarsSchemaConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSchemaConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSource) DeepCopyInto(out *VarsSource) {
 	*out = *in
@@ -807,6 +975,11 @@ func (in *VarsSource) DeepCopyInto(out *VarsSource) {
 		*out = new(VarsSourceGitFiles)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Oci != nil {
+		in, out := &in.Oci, &out.Oci
+		*out = new(VarsSourceOci)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ClusterConfigMap != nil {
 		in, out := &in.ClusterConfigMap, &out.ClusterConfigMap
 		*out = new(VarsSourceClusterConfigMapOrSecret)
@@ -826,6 +999,11 @@ func (in *VarsSource) DeepCopyInto(out *VarsSource) {
 		in, out := &in.SystemEnvVars, &out.SystemEnvVars
 		*out = (*in).DeepCopy()
 	}
+	if in.EnvFile != nil {
+		in, out := &in.EnvFile, &out.EnvFile
+		*out = new(string)
+		**out = **in
+	}
 	if in.Http != nil {
 		in, out := &in.Http, &out.Http
 		*out = new(VarsSourceHttp)
@@ -836,6 +1014,16 @@ func (in *VarsSource) DeepCopyInto(out *VarsSource) {
 		*out = new(VarsSourceAwsSecretsManager)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AwsSsm != nil {
+		in, out := &in.AwsSsm, &out.AwsSsm
+		*out = new(VarsSourceAwsSsm)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AwsAppConfig != nil {
+		in, out := &in.AwsAppConfig, &out.AwsAppConfig
+		*out = new(VarsSourceAwsAppConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.GcpSecretManager != nil {
 		in, out := &in.GcpSecretManager, &out.GcpSecretManager
 		*out = new(VarsSourceGcpSecretManager)
@@ -844,13 +1032,33 @@ func (in *VarsSource) DeepCopyInto(out *VarsSource) {
 	if in.Vault != nil {
 		in, out := &in.Vault, &out.Vault
 		*out = new(VarsSourceVault)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.AzureKeyVault != nil {
 		in, out := &in.AzureKeyVault, &out.AzureKeyVault
 		*out = new(VarSourceAzureKeyVault)
 		**out = **in
 	}
+	if in.Etcd != nil {
+		in, out := &in.Etcd, &out.Etcd
+		*out = new(VarsSourceEtcd)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OnePassword != nil {
+		in, out := &in.OnePassword, &out.OnePassword
+		*out = new(VarsSource1Password)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gitlab != nil {
+		in, out := &in.Gitlab, &out.Gitlab
+		*out = new(VarsSourceGitlab)
+		**out = **in
+	}
+	if in.ClusterInfo != nil {
+		in, out := &in.ClusterInfo, &out.ClusterInfo
+		*out = new(VarsSourceClusterInfo)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.RenderedVars != nil {
 		in, out := &in.RenderedVars, &out.RenderedVars
 		*out = (*in).DeepCopy()
@@ -867,6 +1075,51 @@ func (in *VarsSource) DeepCopy() *VarsSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSource1Password) DeepCopyInto(out *VarsSource1Password) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSource1Password.
+func (in *VarsSource1Password) DeepCopy() *VarsSource1Password {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSource1Password)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceAwsAppConfig) DeepCopyInto(out *VarsSourceAwsAppConfig) {
+	*out = *in
+	if in.Region != nil {
+		in, out := &in.Region, &out.Region
+		*out = new(string)
+		**out = **in
+	}
+	if in.Profile != nil {
+		in, out := &in.Profile, &out.Profile
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceAwsAppConfig.
+func (in *VarsSourceAwsAppConfig) DeepCopy() *VarsSourceAwsAppConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceAwsAppConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSourceAwsSecretsManager) DeepCopyInto(out *VarsSourceAwsSecretsManager) {
 	*out = *in
@@ -892,6 +1145,31 @@ func (in *VarsSourceAwsSecretsManager) DeepCopy() *VarsSourceAwsSecretsManager {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceAwsSsm) DeepCopyInto(out *VarsSourceAwsSsm) {
+	*out = *in
+	if in.Region != nil {
+		in, out := &in.Region, &out.Region
+		*out = new(string)
+		**out = **in
+	}
+	if in.Profile != nil {
+		in, out := &in.Profile, &out.Profile
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceAwsSsm.
+func (in *VarsSourceAwsSsm) DeepCopy() *VarsSourceAwsSsm {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceAwsSsm)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSourceClusterConfigMapOrSecret) DeepCopyInto(out *VarsSourceClusterConfigMapOrSecret) {
 	*out = *in
@@ -914,6 +1192,25 @@ func (in *VarsSourceClusterConfigMapOrSecret) DeepCopy() *VarsSourceClusterConfi
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceClusterInfo) DeepCopyInto(out *VarsSourceClusterInfo) {
+	*out = *in
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceClusterInfo.
+func (in *VarsSourceClusterInfo) DeepCopy() *VarsSourceClusterInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceClusterInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSourceClusterObject) DeepCopyInto(out *VarsSourceClusterObject) {
 	*out = *in
@@ -936,6 +1233,46 @@ func (in *VarsSourceClusterObject) DeepCopy() *VarsSourceClusterObject {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceEtcd) DeepCopyInto(out *VarsSourceEtcd) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tls != nil {
+		in, out := &in.Tls, &out.Tls
+		*out = new(VarsSourceEtcdTls)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceEtcd.
+func (in *VarsSourceEtcd) DeepCopy() *VarsSourceEtcd {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceEtcd)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceEtcdTls) DeepCopyInto(out *VarsSourceEtcdTls) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceEtcdTls.
+func (in *VarsSourceEtcdTls) DeepCopy() *VarsSourceEtcdTls {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceEtcdTls)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSourceGcpSecretManager) DeepCopyInto(out *VarsSourceGcpSecretManager) {
 	*out = *in
@@ -998,6 +1335,21 @@ func (in *VarsSourceGitFiles) DeepCopy() *VarsSourceGitFiles {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceGitlab) DeepCopyInto(out *VarsSourceGitlab) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceGitlab.
+func (in *VarsSourceGitlab) DeepCopy() *VarsSourceGitlab {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceGitlab)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSourceHttp) DeepCopyInto(out *VarsSourceHttp) {
 	*out = *in
@@ -1024,6 +1376,28 @@ func (in *VarsSourceHttp) DeepCopyInto(out *VarsSourceHttp) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.HeadersFromEnv != nil {
+		in, out := &in.HeadersFromEnv, &out.HeadersFromEnv
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TlsClientCert != nil {
+		in, out := &in.TlsClientCert, &out.TlsClientCert
+		*out = new(string)
+		**out = **in
+	}
+	if in.TlsClientKey != nil {
+		in, out := &in.TlsClientKey, &out.TlsClientKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.TlsCaBundle != nil {
+		in, out := &in.TlsCaBundle, &out.TlsCaBundle
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceHttp.
@@ -1036,9 +1410,39 @@ func (in *VarsSourceHttp) DeepCopy() *VarsSourceHttp {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceOci) DeepCopyInto(out *VarsSourceOci) {
+	*out = *in
+	if in.Ref != nil {
+		in, out := &in.Ref, &out.Ref
+		*out = new(OciRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceOci.
+func (in *VarsSourceOci) DeepCopy() *VarsSourceOci {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceOci)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSourceVault) DeepCopyInto(out *VarsSourceVault) {
 	*out = *in
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(int)
+		**out = **in
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(VarsSourceVaultAuth)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceVault.
@@ -1051,6 +1455,61 @@ func (in *VarsSourceVault) DeepCopy() *VarsSourceVault {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceVaultAppRoleAuth) DeepCopyInto(out *VarsSourceVaultAppRoleAuth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceVaultAppRoleAuth.
+func (in *VarsSourceVaultAppRoleAuth) DeepCopy() *VarsSourceVaultAppRoleAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceVaultAppRoleAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceVaultAuth) DeepCopyInto(out *VarsSourceVaultAuth) {
+	*out = *in
+	if in.AppRole != nil {
+		in, out := &in.AppRole, &out.AppRole
+		*out = new(VarsSourceVaultAppRoleAuth)
+		**out = **in
+	}
+	if in.Kubernetes != nil {
+		in, out := &in.Kubernetes, &out.Kubernetes
+		*out = new(VarsSourceVaultKubernetesAuth)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceVaultAuth.
+func (in *VarsSourceVaultAuth) DeepCopy() *VarsSourceVaultAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceVaultAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceVaultKubernetesAuth) DeepCopyInto(out *VarsSourceVaultKubernetesAuth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceVaultKubernetesAuth.
+func (in *VarsSourceVaultKubernetesAuth) DeepCopy() *VarsSourceVaultKubernetesAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceVaultKubernetesAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WaitReadinessObjectItemConfig) DeepCopyInto(out *WaitReadinessObjectItemConfig) {
 	*out = *in
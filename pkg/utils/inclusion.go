@@ -5,18 +5,37 @@ type InclusionEntry struct {
 	Value string
 }
 
+// InclusionOrder controls the precedence of includes and excludes
+type InclusionOrder string
+
+const (
+	// InclusionOrderIncludeExclude evaluates includes first and then excludes, meaning that excludes have precedence
+	// over includes. This is the default.
+	InclusionOrderIncludeExclude InclusionOrder = "include-exclude"
+	// InclusionOrderExcludeInclude evaluates excludes first and then includes, meaning that includes can re-include
+	// entries that were excluded before. Entries not matched by any include or exclude are included if any exclude
+	// was specified.
+	InclusionOrderExcludeInclude InclusionOrder = "exclude-include"
+)
+
 type Inclusion struct {
 	includes map[InclusionEntry]bool
 	excludes map[InclusionEntry]bool
+	order    InclusionOrder
 }
 
 func NewInclusion() *Inclusion {
 	return &Inclusion{
 		includes: map[InclusionEntry]bool{},
 		excludes: map[InclusionEntry]bool{},
+		order:    InclusionOrderIncludeExclude,
 	}
 }
 
+func (inc *Inclusion) SetOrder(order InclusionOrder) {
+	inc.order = order
+}
+
 func (inc *Inclusion) AddInclude(typ string, value string) {
 	inc.includes[InclusionEntry{typ, value}] = true
 }
@@ -93,8 +112,55 @@ func (inc *Inclusion) CheckIncluded(l []InclusionEntry, excludeIfNotIncluded boo
 			return false
 		}
 	}
+	if inc.order == InclusionOrderExcludeInclude {
+		if isIncluded {
+			return true
+		}
+		if isExcluded {
+			return false
+		}
+		return len(inc.excludes) != 0
+	}
 	if isExcluded {
 		return false
 	}
 	return len(inc.includes) == 0 || isIncluded
 }
+
+// RenderValues returns a copy of the inclusion where all values of the given type are replaced by the values returned
+// from render. render may return multiple values for a single input value, in which case all of them are added.
+func (inc *Inclusion) RenderValues(typ string, render func(v string) ([]string, error)) (*Inclusion, error) {
+	if inc == nil {
+		return nil, nil
+	}
+
+	ret := NewInclusion()
+	ret.order = inc.order
+
+	doRender := func(m map[InclusionEntry]bool, add func(typ string, value string)) error {
+		for e := range m {
+			if e.Type != typ {
+				add(e.Type, e.Value)
+				continue
+			}
+			values, err := render(e.Value)
+			if err != nil {
+				return err
+			}
+			for _, v := range values {
+				add(e.Type, v)
+			}
+		}
+		return nil
+	}
+
+	err := doRender(inc.includes, ret.AddInclude)
+	if err != nil {
+		return nil, err
+	}
+	err = doRender(inc.excludes, ret.AddExclude)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"sort"
+	"strings"
+)
+
+// ValidateRenderedObject performs local validation of a rendered object, meaning that it does not require the object
+// to exist on the target cluster. It checks that the basic fields (apiVersion, kind and metadata.name) are set and,
+// where a CRD schema can be retrieved (which is not the case in offline mode), that all required fields are present.
+// Objects annotated with kluctl.io/validate-ignore are skipped.
+func ValidateRenderedObject(ctx context.Context, k *k8s.K8sCluster, o *uo.UnstructuredObject) (ret result.ValidateResult) {
+	ref := o.GetK8sRef()
+
+	ret.Ready = true
+
+	if o.GetK8sAnnotationBoolNoError("kluctl.io/validate-ignore", false) {
+		return
+	}
+
+	addError := func(message string) {
+		ret.Errors = append(ret.Errors, result.DeploymentError{
+			Ref:     ref,
+			Message: message,
+		})
+		ret.Ready = false
+	}
+	addWarning := func(message string) {
+		ret.Warnings = append(ret.Warnings, result.DeploymentError{
+			Ref:     ref,
+			Message: message,
+		})
+	}
+
+	gvk := o.GetK8sGVK()
+	if gvk.Version == "" {
+		addError("apiVersion is missing")
+	}
+	if gvk.Kind == "" {
+		addError("kind is missing")
+	}
+	if o.GetK8sName() == "" {
+		generateName, _, _ := o.GetNestedString("metadata", "generateName")
+		if generateName == "" {
+			addError("metadata.name is missing")
+		}
+	}
+	if len(ret.Errors) != 0 || k == nil {
+		return
+	}
+
+	namespaced := k.IsNamespaced(gvk)
+	if namespaced != nil && !*namespaced && o.GetK8sNamespace() != "" {
+		addWarning(fmt.Sprintf("%s is cluster-scoped but has namespace %s set", gvk.Kind, o.GetK8sNamespace()))
+	}
+
+	s, err := k.GetSchemaForGVK(gvk)
+	if err != nil {
+		// no schema available (e.g. built-in kind or offline mode), so we can't perform any further checks
+		return
+	}
+	for _, m := range checkRequiredFields(s.Object, o.Object, "") {
+		addError(m)
+	}
+	return
+}
+
+// checkRequiredFields recursively checks the given value against the "required" lists found in the OpenAPI schema
+func checkRequiredFields(schema map[string]any, v any, path string) []string {
+	var ret []string
+
+	switch x := v.(type) {
+	case map[string]any:
+		required, _ := schema["required"].([]any)
+		for _, r := range required {
+			name, ok := r.(string)
+			if !ok {
+				continue
+			}
+			if _, ok := x[name]; !ok {
+				ret = append(ret, fmt.Sprintf("required field %s is missing", joinFieldPath(path, name)))
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]any)
+		var keys []string
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ps, ok := properties[k].(map[string]any)
+			if !ok {
+				continue
+			}
+			ret = append(ret, checkRequiredFields(ps, x[k], joinFieldPath(path, k))...)
+		}
+	case []any:
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			break
+		}
+		for i, e := range x {
+			ret = append(ret, checkRequiredFields(items, e, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return ret
+}
+
+func joinFieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return strings.Join([]string{path, name}, ".")
+}
@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidateRenderedObject(t *testing.T) {
+	r := ValidateRenderedObject(context.TODO(), nil, newTestDatabase(nil))
+	assert.True(t, r.Ready)
+	assert.Empty(t, r.Errors)
+
+	o := uo.FromMap(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{},
+	})
+	r = ValidateRenderedObject(context.TODO(), nil, o)
+	assert.False(t, r.Ready)
+	assert.Len(t, r.Errors, 1)
+	assert.Equal(t, "metadata.name is missing", r.Errors[0].Message)
+
+	_ = o.SetNestedField("cm-", "metadata", "generateName")
+	r = ValidateRenderedObject(context.TODO(), nil, o)
+	assert.True(t, r.Ready)
+
+	o = uo.FromMap(map[string]any{
+		"metadata": map[string]any{
+			"name": "x",
+			"annotations": map[string]any{
+				"kluctl.io/validate-ignore": "true",
+			},
+		},
+	})
+	r = ValidateRenderedObject(context.TODO(), nil, o)
+	assert.True(t, r.Ready)
+	assert.Empty(t, r.Errors)
+}
+
+func TestCheckRequiredFields(t *testing.T) {
+	schema := map[string]any{
+		"required": []any{"spec"},
+		"properties": map[string]any{
+			"spec": map[string]any{
+				"required": []any{"engine"},
+				"properties": map[string]any{
+					"users": map[string]any{
+						"items": map[string]any{
+							"required": []any{"name"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"required field spec is missing"}, checkRequiredFields(schema, map[string]any{}, ""))
+	assert.Empty(t, checkRequiredFields(schema, map[string]any{
+		"spec": map[string]any{"engine": "postgres"},
+	}, ""))
+	assert.Equal(t, []string{
+		"required field spec.engine is missing",
+		"required field spec.users[1].name is missing",
+	}, checkRequiredFields(schema, map[string]any{
+		"spec": map[string]any{
+			"users": []any{
+				map[string]any{"name": "a"},
+				map[string]any{},
+			},
+		},
+	}, ""))
+}
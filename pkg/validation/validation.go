@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
@@ -48,7 +49,23 @@ const (
 	reactNotReady
 )
 
-func ValidateObject(ctx context.Context, k *k8s.K8sCluster, o *uo.UnstructuredObject, notReadyIsError bool, forceStatusRequired bool) (ret result.ValidateResult) {
+// findReadinessCheck returns the first readiness check matching the given GroupKind or nil if none matches
+func findReadinessCheck(readinessChecks []types.ReadinessCheckConfig, gk schema.GroupKind) *types.ReadinessCheckConfig {
+	for i, rc := range readinessChecks {
+		if rc.Kind != gk.Kind {
+			continue
+		}
+		if rc.Group != nil && *rc.Group != gk.Group {
+			continue
+		}
+		return &readinessChecks[i]
+	}
+	return nil
+}
+
+// ValidateObject validates the given object and determines its readiness. readinessChecks allows to declare custom
+// readiness rules for custom resources, which take precedence over the built-in rules. The first matching check is used.
+func ValidateObject(ctx context.Context, k *k8s.K8sCluster, o *uo.UnstructuredObject, readinessChecks []types.ReadinessCheckConfig, notReadyIsError bool, forceStatusRequired bool) (ret result.ValidateResult) {
 	ref := o.GetK8sRef()
 
 	// We assume all is good in case no validation is performed
@@ -129,9 +146,11 @@ func ValidateObject(ctx context.Context, k *k8s.K8sCluster, o *uo.UnstructuredOb
 		return
 	}
 
+	readinessCheck := findReadinessCheck(readinessChecks, o.GetK8sGVK().GroupKind())
+
 	status, _, _ := o.GetNestedObject("status")
 	if status == nil {
-		if forceStatusRequired {
+		if forceStatusRequired || readinessCheck != nil {
 			addNotReady("no status available yet")
 			return
 		}
@@ -265,6 +284,27 @@ func ValidateObject(ctx context.Context, k *k8s.K8sCluster, o *uo.UnstructuredOb
 		return
 	}
 
+	if readinessCheck != nil {
+		if readinessCheck.Condition != nil {
+			c := getCondition(*readinessCheck.Condition, reactNotReady, true)
+			if c.status != "True" {
+				addNotReady(c.getMessage(fmt.Sprintf("%s condition is not True", *readinessCheck.Condition)))
+			}
+		} else {
+			var keys []interface{}
+			for _, k := range strings.Split(*readinessCheck.Field, ".") {
+				keys = append(keys, k)
+			}
+			v, found, _ := status.GetNestedField(keys...)
+			if !found {
+				addNotReady(fmt.Sprintf("%s field not in status", *readinessCheck.Field))
+			} else if fmt.Sprintf("%v", v) != *readinessCheck.Value {
+				addNotReady(fmt.Sprintf("%s field is %v instead of %s", *readinessCheck.Field, v, *readinessCheck.Value))
+			}
+		}
+		return
+	}
+
 	switch o.GetK8sGVK().GroupKind() {
 	case schema.GroupKind{Group: "", Kind: "Pod"}:
 		containerStatuses, _, err := status.GetNestedObjectList("containerStatuses")
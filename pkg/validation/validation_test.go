@@ -0,0 +1,82 @@
+package validation
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newTestDatabase(status map[string]any) *uo.UnstructuredObject {
+	o := uo.FromMap(map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Database",
+		"metadata": map[string]any{
+			"name":      "db",
+			"namespace": "default",
+		},
+	})
+	if status != nil {
+		_ = o.SetNestedField(status, "status")
+	}
+	return o
+}
+
+func readyCondition(status string) map[string]any {
+	return map[string]any{
+		"conditions": []any{
+			map[string]any{"type": "Synced", "status": "True"},
+			map[string]any{"type": "Ready", "status": status, "message": "database is " + status},
+		},
+	}
+}
+
+func TestReadinessCheckCondition(t *testing.T) {
+	checks := []types.ReadinessCheckConfig{
+		{Group: utils.Ptr("other.com"), Kind: "Database", Field: utils.Ptr("phase"), Value: utils.Ptr("Running")},
+		{Group: utils.Ptr("example.com"), Kind: "Database", Condition: utils.Ptr("Ready")},
+	}
+
+	r := ValidateObject(context.TODO(), nil, newTestDatabase(readyCondition("True")), checks, true, false)
+	assert.True(t, r.Ready)
+	assert.Empty(t, r.Errors)
+
+	r = ValidateObject(context.TODO(), nil, newTestDatabase(readyCondition("False")), checks, true, false)
+	assert.False(t, r.Ready)
+	assert.Len(t, r.Errors, 1)
+	assert.Equal(t, "database is False", r.Errors[0].Message)
+
+	r = ValidateObject(context.TODO(), nil, newTestDatabase(map[string]any{}), checks, true, false)
+	assert.False(t, r.Ready)
+	assert.Equal(t, "Ready condition not in status", r.Errors[0].Message)
+
+	// no status at all
+	r = ValidateObject(context.TODO(), nil, newTestDatabase(nil), checks, true, false)
+	assert.False(t, r.Ready)
+	assert.Equal(t, "no status available yet", r.Errors[0].Message)
+
+	// without a check, the status is not interpreted
+	r = ValidateObject(context.TODO(), nil, newTestDatabase(readyCondition("False")), nil, true, false)
+	assert.True(t, r.Ready)
+	assert.Empty(t, r.Errors)
+}
+
+func TestReadinessCheckField(t *testing.T) {
+	checks := []types.ReadinessCheckConfig{
+		{Kind: "Database", Field: utils.Ptr("state.phase"), Value: utils.Ptr("Running")},
+	}
+
+	r := ValidateObject(context.TODO(), nil, newTestDatabase(map[string]any{
+		"state": map[string]any{"phase": "Running"},
+	}), checks, true, false)
+	assert.True(t, r.Ready)
+
+	r = ValidateObject(context.TODO(), nil, newTestDatabase(map[string]any{
+		"state": map[string]any{"phase": "Pending"},
+	}), checks, false, false)
+	assert.False(t, r.Ready)
+	assert.Empty(t, r.Errors)
+	assert.Equal(t, "state.phase field is Pending instead of Running", r.Warnings[0].Message)
+}
@@ -0,0 +1,157 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+)
+
+// KeyValue is a single key/value pair as returned by etcd
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+type rangeRequest struct {
+	Key      string `json:"key"`
+	RangeEnd string `json:"range_end,omitempty"`
+}
+
+type rangeResponse struct {
+	Kvs []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"kvs"`
+}
+
+// GetKeys reads the given key from etcd. If prefix is true, all keys with the given prefix are returned. Reading is
+// performed via the JSON gRPC gateway of the etcd v3 API, trying all endpoints in order until one succeeds.
+func GetKeys(ctx context.Context, config *types.VarsSourceEtcd) ([]KeyValue, error) {
+	httpClient, err := buildHttpClient(config.Tls)
+	if err != nil {
+		return nil, err
+	}
+
+	req := rangeRequest{
+		Key: base64.StdEncoding.EncodeToString([]byte(config.Key)),
+	}
+	if config.Prefix {
+		req.RangeEnd = base64.StdEncoding.EncodeToString(getPrefixRangeEnd([]byte(config.Key)))
+	}
+	body, err := json.Marshal(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs *multierror.Error
+	for _, endpoint := range config.Endpoints {
+		resp, err := doRangeRequest(ctx, httpClient, endpoint, body)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("%s: %w", endpoint, err))
+			continue
+		}
+
+		ret := make([]KeyValue, 0, len(resp.Kvs))
+		for _, kv := range resp.Kvs {
+			k, err := base64.StdEncoding.DecodeString(kv.Key)
+			if err != nil {
+				return nil, err
+			}
+			v, err := base64.StdEncoding.DecodeString(kv.Value)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, KeyValue{Key: string(k), Value: string(v)})
+		}
+		return ret, nil
+	}
+	return nil, fmt.Errorf("failed to read from etcd: %w", errs.ErrorOrNil())
+}
+
+func doRangeRequest(ctx context.Context, httpClient *http.Client, endpoint string, body []byte) (*rangeResponse, error) {
+	url := strings.TrimSuffix(endpoint, "/") + "/v3/kv/range"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var rr rangeResponse
+	err = json.Unmarshal(respBody, &rr)
+	if err != nil {
+		return nil, err
+	}
+	return &rr, nil
+}
+
+func buildHttpClient(tlsConfig *types.VarsSourceEtcdTls) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		c := &tls.Config{
+			InsecureSkipVerify: tlsConfig.InsecureSkipTlsVerify,
+		}
+		if tlsConfig.CaFile != "" {
+			ca, err := os.ReadFile(tlsConfig.CaFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read etcd CA file: %w", err)
+			}
+			c.RootCAs = x509.NewCertPool()
+			if !c.RootCAs.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("failed to parse etcd CA file %s", tlsConfig.CaFile)
+			}
+		}
+		if tlsConfig.CertFile != "" || tlsConfig.KeyFile != "" {
+			cert, err := tls.LoadX509KeyPair(tlsConfig.CertFile, tlsConfig.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load etcd client certificate: %w", err)
+			}
+			c.Certificates = []tls.Certificate{cert}
+		}
+		transport.TLSClientConfig = c
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   15 * time.Second,
+	}, nil
+}
+
+// getPrefixRangeEnd returns the range end for a prefix query, which is the prefix with the last byte incremented. See
+// clientv3.GetPrefixRangeEnd
+func getPrefixRangeEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i] = end[i] + 1
+			return end[:i+1]
+		}
+	}
+	// next prefix does not exist (e.g., 0xffff), so use the special "\0" key to read all keys
+	return []byte{0}
+}
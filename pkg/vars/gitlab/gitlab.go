@@ -0,0 +1,139 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+)
+
+const (
+	defaultBaseUrl  = "https://gitlab.com"
+	defaultTokenEnv = "GITLAB_TOKEN"
+	allEnvironments = "*"
+)
+
+// ErrNotFound is returned when the project does not exist or is not accessible with the given token
+var ErrNotFound = errors.New("not found")
+
+var httpClient = &http.Client{
+	Timeout: 15 * time.Second,
+}
+
+type variable struct {
+	Key              string `json:"key"`
+	Value            string `json:"value"`
+	EnvironmentScope string `json:"environment_scope"`
+}
+
+// GetVariables reads all CI/CD variables of the configured project and returns their values, indexed by the variable
+// key. Only variables defined for all environments and, if configured, for the given environment scope are returned.
+func GetVariables(ctx context.Context, config *types.VarsSourceGitlab) (map[string]string, error) {
+	token, err := getToken(config)
+	if err != nil {
+		return nil, err
+	}
+
+	baseUrl := config.BaseUrl
+	if baseUrl == "" {
+		baseUrl = defaultBaseUrl
+	}
+	baseUrl = strings.TrimSuffix(baseUrl, "/")
+
+	vars, err := listVariables(ctx, baseUrl, config.ProjectId, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CI/CD variables of GitLab project %s: %w", config.ProjectId, err)
+	}
+
+	ret := map[string]string{}
+	for _, v := range vars {
+		if v.EnvironmentScope == allEnvironments {
+			if _, ok := ret[v.Key]; !ok {
+				ret[v.Key] = v.Value
+			}
+		}
+	}
+	if config.EnvironmentScope != "" && config.EnvironmentScope != allEnvironments {
+		for _, v := range vars {
+			if v.EnvironmentScope == config.EnvironmentScope {
+				ret[v.Key] = v.Value
+			}
+		}
+	}
+	return ret, nil
+}
+
+func getToken(config *types.VarsSourceGitlab) (string, error) {
+	if config.Token != "" {
+		return config.Token, nil
+	}
+	env := config.TokenEnv
+	if env == "" {
+		env = defaultTokenEnv
+	}
+	token := os.Getenv(env)
+	if token == "" {
+		return "", fmt.Errorf("no GitLab token specified and the environment variable %s is not set", env)
+	}
+	return token, nil
+}
+
+func listVariables(ctx context.Context, baseUrl string, projectId string, token string) ([]variable, error) {
+	var ret []variable
+	page := "1"
+	for page != "" {
+		q := url.Values{}
+		q.Set("per_page", "100")
+		q.Set("page", page)
+		u := fmt.Sprintf("%s/api/v4/projects/%s/variables?%s", baseUrl, url.PathEscape(projectId), q.Encode())
+
+		var l []variable
+		nextPage, err := get(ctx, u, token, &l)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, l...)
+		page = nextPage
+	}
+	return ret, nil
+}
+
+func get(ctx context.Context, u string, token string, out any) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("PRIVATE-TOKEN", token)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		// make sure the token never ends up in errors, even if the server echoes it back
+		msg := strings.ReplaceAll(string(body), token, "<redacted>")
+		return "", fmt.Errorf("request failed with status %d: %s", resp.StatusCode, msg)
+	}
+	err = json.Unmarshal(body, out)
+	if err != nil {
+		return "", err
+	}
+	return resp.Header.Get("X-Next-Page"), nil
+}
@@ -0,0 +1,90 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testToken = "secret-token"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"401 Unauthorized: ` + r.Header.Get("PRIVATE-TOKEN") + `"}`))
+			return
+		}
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/my-group%2Fmy-project/variables", "/api/v4/projects/42/variables":
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				_, _ = w.Write([]byte(`[
+					{"key": "a", "value": "a-all", "environment_scope": "*"},
+					{"key": "b", "value": "b-prod", "environment_scope": "prod"}
+				]`))
+			} else {
+				_, _ = w.Write([]byte(`[
+					{"key": "b", "value": "b-all", "environment_scope": "*"},
+					{"key": "c", "value": "c-staging", "environment_scope": "staging"},
+					{"key": "masked", "value": "masked-value", "masked": true, "environment_scope": "*"}
+				]`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"404 Project Not Found"}`))
+		}
+	}))
+}
+
+func TestGetVariables(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	vars, err := GetVariables(context.TODO(), &types.VarsSourceGitlab{
+		BaseUrl:   s.URL,
+		ProjectId: "my-group/my-project",
+		Token:     testToken,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "a-all", "b": "b-all", "masked": "masked-value"}, vars)
+
+	vars, err = GetVariables(context.TODO(), &types.VarsSourceGitlab{
+		BaseUrl:          s.URL,
+		ProjectId:        "42",
+		Token:            testToken,
+		EnvironmentScope: "prod",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "a-all", "b": "b-prod", "masked": "masked-value"}, vars)
+}
+
+func TestGetVariablesNotFound(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	_, err := GetVariables(context.TODO(), &types.VarsSourceGitlab{
+		BaseUrl:   s.URL,
+		ProjectId: "missing",
+		Token:     testToken,
+	})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGetVariablesTokenNotInError(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	t.Setenv("MY_GITLAB_TOKEN", "wrong-token")
+	_, err := GetVariables(context.TODO(), &types.VarsSourceGitlab{
+		BaseUrl:   s.URL,
+		ProjectId: "42",
+		TokenEnv:  "MY_GITLAB_TOKEN",
+	})
+	assert.ErrorContains(t, err, "status 401")
+	assert.NotContains(t, err.Error(), "wrong-token")
+}
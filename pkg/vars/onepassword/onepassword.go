@@ -0,0 +1,148 @@
+package onepassword
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+)
+
+const defaultTokenEnv = "OP_CONNECT_TOKEN"
+
+// ErrNotFound is returned when the vault, the item or one of the requested fields does not exist
+var ErrNotFound = errors.New("not found")
+
+var httpClient = &http.Client{
+	Timeout: 15 * time.Second,
+}
+
+type vaultOrItem struct {
+	Id string `json:"id"`
+}
+
+type item struct {
+	Fields []struct {
+		Id    string `json:"id"`
+		Label string `json:"label"`
+		Value string `json:"value"`
+	} `json:"fields"`
+}
+
+type client struct {
+	ctx   context.Context
+	host  string
+	token string
+}
+
+// GetItemFields reads the configured item from the 1Password Connect server and returns the values of all fields,
+// indexed by the field label.
+func GetItemFields(ctx context.Context, config *types.VarsSource1Password) (map[string]string, error) {
+	token, err := getToken(config)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{
+		ctx:   ctx,
+		host:  strings.TrimSuffix(config.ConnectHost, "/"),
+		token: token,
+	}
+
+	vaultId, err := c.resolveId("/v1/vaults", config.Vault, "name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find 1Password vault %s: %w", config.Vault, err)
+	}
+	itemId, err := c.resolveId(fmt.Sprintf("/v1/vaults/%s/items", url.PathEscape(vaultId)), config.Item, "title")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find 1Password item %s: %w", config.Item, err)
+	}
+
+	var i item
+	err = c.get(fmt.Sprintf("/v1/vaults/%s/items/%s", url.PathEscape(vaultId), url.PathEscape(itemId)), nil, &i)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read 1Password item %s: %w", config.Item, err)
+	}
+
+	ret := make(map[string]string, len(i.Fields))
+	for _, f := range i.Fields {
+		key := f.Label
+		if key == "" {
+			key = f.Id
+		}
+		ret[key] = f.Value
+	}
+	return ret, nil
+}
+
+func getToken(config *types.VarsSource1Password) (string, error) {
+	if config.Token != "" {
+		return config.Token, nil
+	}
+	env := config.TokenEnv
+	if env == "" {
+		env = defaultTokenEnv
+	}
+	token := os.Getenv(env)
+	if token == "" {
+		return "", fmt.Errorf("no 1Password Connect token specified and the environment variable %s is not set", env)
+	}
+	return token, nil
+}
+
+// resolveId looks up the vault or item by its name/title. If nothing is found, nameOrId is treated as the id.
+func (c *client) resolveId(path string, nameOrId string, filterField string) (string, error) {
+	var l []vaultOrItem
+	q := url.Values{}
+	q.Set("filter", fmt.Sprintf("%s eq %q", filterField, nameOrId))
+	err := c.get(path, q, &l)
+	if err != nil {
+		return "", err
+	}
+	if len(l) > 1 {
+		return "", fmt.Errorf("found more than one match")
+	}
+	if len(l) == 1 {
+		return l[0].Id, nil
+	}
+	return nameOrId, nil
+}
+
+func (c *client) get(path string, query url.Values, out any) error {
+	u := c.host + path
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		// make sure the token never ends up in errors, even if the server echoes it back
+		msg := strings.ReplaceAll(string(body), c.token, "<redacted>")
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, msg)
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,93 @@
+package onepassword
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testToken = "secret-token"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"status":401,"message":"Invalid token: ` + r.Header.Get("Authorization") + `"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/vaults":
+			if r.URL.Query().Get("filter") == `name eq "ci"` {
+				_, _ = w.Write([]byte(`[{"id": "vault1"}]`))
+			} else {
+				_, _ = w.Write([]byte(`[]`))
+			}
+		case "/v1/vaults/vault1/items":
+			if r.URL.Query().Get("filter") == `title eq "db"` {
+				_, _ = w.Write([]byte(`[{"id": "item1"}]`))
+			} else {
+				_, _ = w.Write([]byte(`[]`))
+			}
+		case "/v1/vaults/vault1/items/item1":
+			_, _ = w.Write([]byte(`{"fields": [{"id": "password", "label": "password", "value": "pw"}, {"id": "f2", "label": "username", "value": "user"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGetItemFields(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	fields, err := GetItemFields(context.TODO(), &types.VarsSource1Password{
+		ConnectHost: s.URL,
+		Token:       testToken,
+		Vault:       "ci",
+		Item:        "db",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"password": "pw", "username": "user"}, fields)
+
+	// ids can be used as well
+	fields, err = GetItemFields(context.TODO(), &types.VarsSource1Password{
+		ConnectHost: s.URL,
+		Token:       testToken,
+		Vault:       "vault1",
+		Item:        "item1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "pw", fields["password"])
+}
+
+func TestGetItemFieldsNotFound(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	_, err := GetItemFields(context.TODO(), &types.VarsSource1Password{
+		ConnectHost: s.URL,
+		Token:       testToken,
+		Vault:       "ci",
+		Item:        "missing",
+	})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGetItemFieldsTokenNotInError(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	t.Setenv("MY_OP_TOKEN", "wrong-token")
+	_, err := GetItemFields(context.TODO(), &types.VarsSource1Password{
+		ConnectHost: s.URL,
+		TokenEnv:    "MY_OP_TOKEN",
+		Vault:       "ci",
+		Item:        "db",
+	})
+	assert.ErrorContains(t, err, "status 401")
+	assert.NotContains(t, err.Error(), "wrong-token")
+}
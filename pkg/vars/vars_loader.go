@@ -5,7 +5,9 @@ import (
 	"encoding/base64"
 	errors2 "errors"
 	"fmt"
+	appconfigdatatypes "github.com/aws/aws-sdk-go-v2/service/appconfigdata/types"
 	types2 "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
 	"github.com/getsops/sops/v3/cmd/sops/formats"
 	"github.com/kluctl/kluctl/lib/go-jinja2"
 	"github.com/kluctl/kluctl/lib/status"
@@ -21,11 +23,18 @@ import (
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/vars/etcd"
+	"github.com/kluctl/kluctl/v2/pkg/vars/gitlab"
+	"github.com/kluctl/kluctl/v2/pkg/vars/onepassword"
 	"github.com/kluctl/kluctl/v2/pkg/vars/vault"
+	"io/fs"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 )
@@ -43,7 +52,46 @@ type VarsLoader struct {
 	aws  aws.AwsClientFactory
 	gcp  gcp.GcpClientFactory
 
+	// ociRP is only used for the oci vars source
+	ociRP *repocache.OciRepoCache
+
 	credentialsCache map[string]usernamePassword
+	gitVarsCache     map[gitVarsCacheKey]gitVarsCacheEntry
+	gitClonedDirs    map[gitClonedDirKey]string
+
+	allowMissingSopsKeys bool
+	skippedSopsFiles     []string
+	noIgnoreMissing      bool
+
+	// contextName is only used for the clusterInfo vars source
+	contextName string
+
+	// provenance maps the json path of each leaf value to the vars source that wrote it. It is nil unless enabled via
+	// SetTrackProvenance
+	provenance map[string]string
+}
+
+// gitVarsCacheKey identifies a rendered git vars file. As the file is rendered with the current vars as globals,
+// a hash of the vars referenced by the file is part of the key, so that changed vars result in the file being rendered
+// again. Vars which are not referenced by the file do not influence the key.
+type gitVarsCacheKey struct {
+	url           string
+	ref           string
+	path          string
+	rootKey       string
+	ignoreMissing bool
+	varsHash      string
+}
+
+type gitClonedDirKey struct {
+	url    string
+	ref    string
+	sparse string
+}
+
+type gitVarsCacheEntry struct {
+	vars      *uo.UnstructuredObject
+	sensitive bool
 }
 
 func NewVarsLoader(ctx context.Context, k *k8s.K8sCluster, sops *decryptor.Decryptor, rp *repocache.GitRepoCache, aws aws.AwsClientFactory, gcp gcp.GcpClientFactory) *VarsLoader {
@@ -55,9 +103,57 @@ func NewVarsLoader(ctx context.Context, k *k8s.K8sCluster, sops *decryptor.Decry
 		aws:              aws,
 		gcp:              gcp,
 		credentialsCache: map[string]usernamePassword{},
+		gitVarsCache:     map[gitVarsCacheKey]gitVarsCacheEntry{},
+		gitClonedDirs:    map[gitClonedDirKey]string{},
 	}
 }
 
+// SetAllowMissingSopsKeys controls if sops encrypted vars files that can't be decrypted due to missing keys are
+// skipped (with a warning) instead of failing.
+func (v *VarsLoader) SetAllowMissingSopsKeys(allow bool) {
+	v.allowMissingSopsKeys = allow
+}
+
+// SetNoIgnoreMissing causes all vars sources to be treated as required, overriding `ignoreMissing: true` of
+// individual sources.
+func (v *VarsLoader) SetNoIgnoreMissing(noIgnoreMissing bool) {
+	v.noIgnoreMissing = noIgnoreMissing
+}
+
+// SetOciRepoCache sets the OCI repo cache used by the oci vars source. The same cache (and thus the same repo
+// overrides) as for OCI includes should be used.
+func (v *VarsLoader) SetOciRepoCache(ociRP *repocache.OciRepoCache) {
+	v.ociRP = ociRP
+}
+
+// SetContextName sets the name of the kubeconfig context of the target cluster, which is then available via the
+// clusterInfo vars source.
+func (v *VarsLoader) SetContextName(contextName string) {
+	v.contextName = contextName
+}
+
+// isSkippableSopsError returns true if the given decryption error was caused by missing keys and such errors
+// should be ignored
+func (v *VarsLoader) isSkippableSopsError(err error) bool {
+	return v.allowMissingSopsKeys && errors2.Is(err, decryptor.ErrDataKeyNotFound)
+}
+
+// skipSopsFile prints a warning about the skipped file and remembers it for WrapMissingSopsKeysError
+func (v *VarsLoader) skipSopsFile(ctx context.Context, kind string, path string) {
+	status.Warningf(ctx, "Skipping %s %s as it can't be decrypted due to missing sops keys. Vars from this file will be missing!", kind, path)
+	v.skippedSopsFiles = append(v.skippedSopsFiles, path)
+}
+
+// WrapMissingSopsKeysError adds a hint about skipped sops encrypted files to the given error, as templates that
+// reference vars from skipped files fail with errors about undefined variables, which are otherwise hard to
+// understand. It returns the error unmodified if no files were skipped.
+func (v *VarsLoader) WrapMissingSopsKeysError(err error) error {
+	if err == nil || len(v.skippedSopsFiles) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w (vars from the following files are missing as they could not be decrypted due to missing sops keys, which might be the cause of this error: %s)", err, strings.Join(v.skippedSopsFiles, ", "))
+}
+
 func (v *VarsLoader) LoadVarsList(ctx context.Context, varsCtx *VarsCtx, varsList []types.VarsSource, searchDirs []string, rootKey string) error {
 	for i, _ := range varsList {
 		source := &varsList[i]
@@ -69,27 +165,44 @@ func (v *VarsLoader) LoadVarsList(ctx context.Context, varsCtx *VarsCtx, varsLis
 	return nil
 }
 
-func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *types.VarsSource, searchDirs []string, rootKey string) error {
-	if sourceIn.RenderedVars != nil && len(sourceIn.RenderedVars.Object) != 0 {
-		return fmt.Errorf("renderedVars is not allowed here")
-	}
-
+// RenderVarsSource returns a copy of the given source with all templates being rendered with the current vars.
+func (v *VarsLoader) RenderVarsSource(varsCtx *VarsCtx, sourceIn *types.VarsSource) (*types.VarsSource, error) {
 	var source types.VarsSource
 	err := utils.DeepCopy(&source, sourceIn)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	globals, err := varsCtx.Vars.ToMap()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	_, err = varsCtx.J2.RenderStruct(&source, jinja2.WithGlobals(globals))
+	if err != nil {
+		return nil, err
+	}
+	return &source, nil
+}
+
+func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *types.VarsSource, searchDirs []string, rootKey string) error {
+	if sourceIn.RenderedVars != nil && len(sourceIn.RenderedVars.Object) != 0 {
+		return fmt.Errorf("renderedVars is not allowed here")
+	}
+
+	source, err := v.RenderVarsSource(varsCtx, sourceIn)
 	if err != nil {
 		return err
 	}
 
+	if sourceIn.TargetPath != "" {
+		if source.TargetPath == "" {
+			return fmt.Errorf("targetPath '%s' rendered to an empty path", sourceIn.TargetPath)
+		}
+		// the (possibly dynamically computed) targetPath takes precedence over the static rootKey
+		rootKey = ""
+	}
+
 	whenTrue, err := varsCtx.CheckConditional(source.When)
 	if err != nil {
 		return err
@@ -102,6 +215,11 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 	if source.IgnoreMissing != nil {
 		ignoreMissing = *source.IgnoreMissing
 	}
+	ignoreMissingOverridden := false
+	if ignoreMissing && v.noIgnoreMissing {
+		ignoreMissing = false
+		ignoreMissingOverridden = true
+	}
 
 	var newValue any
 	var sensitive bool
@@ -116,41 +234,70 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 	} else if source.File != nil {
 		newValue, sensitive, err = v.loadFile(varsCtx, *source.File, ignoreMissing, searchDirs)
 	} else if source.Git != nil {
-		newValue, sensitive, err = v.loadGit(ctx, varsCtx, source.Git, ignoreMissing)
+		newValue, sensitive, err = v.loadGit(ctx, varsCtx, source.Git, ignoreMissing, rootKey)
 	} else if source.GitFiles != nil {
 		newValue, sensitive, err = v.loadGitFiles(ctx, varsCtx, source.GitFiles, ignoreMissing)
+	} else if source.Oci != nil {
+		newValue, sensitive, err = v.loadOci(varsCtx, source.Oci, ignoreMissing)
 	} else if source.ClusterConfigMap != nil {
-		newValue, err = v.loadFromK8sConfigMapOrSecret(varsCtx, *source.ClusterConfigMap, "ConfigMap", ignoreMissing, false)
+		newValue, sensitive, err = v.loadFromK8sConfigMapOrSecret(varsCtx, *source.ClusterConfigMap, "ConfigMap", ignoreMissing, false)
 	} else if source.ClusterSecret != nil {
-		newValue, err = v.loadFromK8sConfigMapOrSecret(varsCtx, *source.ClusterSecret, "Secret", ignoreMissing, true)
+		newValue, _, err = v.loadFromK8sConfigMapOrSecret(varsCtx, *source.ClusterSecret, "Secret", ignoreMissing, true)
 		sensitive = true
 	} else if source.ClusterObject != nil {
 		newValue, err = v.loadFromK8sObject(varsCtx, *source.ClusterObject, ignoreMissing)
 		sensitive = true
 	} else if source.SystemEnvVars != nil {
-		newValue, err = v.loadSystemEnvs(varsCtx, &source, ignoreMissing, rootKey)
+		newValue, err = v.loadSystemEnvs(varsCtx, source, ignoreMissing, rootKey, searchDirs)
 		sensitive = true
 	} else if source.Http != nil {
-		newValue, sensitive, err = v.loadHttp(varsCtx, &source, ignoreMissing)
+		newValue, sensitive, err = v.loadHttp(varsCtx, source, ignoreMissing, searchDirs)
 	} else if source.AwsSecretsManager != nil {
-		newValue, err = v.loadAwsSecretsManager(varsCtx, &source, ignoreMissing)
+		newValue, err = v.loadAwsSecretsManager(varsCtx, source, ignoreMissing)
+		sensitive = true
+	} else if source.AwsSsm != nil {
+		newValue, err = v.loadAwsSsm(varsCtx, source, ignoreMissing)
+		sensitive = true
+	} else if source.AwsAppConfig != nil {
+		newValue, err = v.loadAwsAppConfig(varsCtx, source, ignoreMissing)
 		sensitive = true
 	} else if source.GcpSecretManager != nil {
-		newValue, err = v.loadGcpSecretManager(varsCtx, &source, ignoreMissing)
+		newValue, err = v.loadGcpSecretManager(varsCtx, source, ignoreMissing)
 		sensitive = true
 	} else if source.Vault != nil {
-		newValue, err = v.loadVault(varsCtx, &source, ignoreMissing)
+		newValue, err = v.loadVault(varsCtx, source, ignoreMissing)
 		sensitive = true
 	} else if source.AzureKeyVault != nil {
-		newValue, err = v.loadAzureKeyVault(varsCtx, &source, ignoreMissing)
+		newValue, err = v.loadAzureKeyVault(varsCtx, source, ignoreMissing)
 		sensitive = true
+	} else if source.Etcd != nil {
+		newValue, err = v.loadEtcd(varsCtx, source, ignoreMissing)
+		sensitive = true
+	} else if source.OnePassword != nil {
+		newValue, err = v.load1Password(varsCtx, source, ignoreMissing)
+		sensitive = true
+	} else if source.Gitlab != nil {
+		newValue, err = v.loadGitlab(source, ignoreMissing)
+		sensitive = true
+	} else if source.ClusterInfo != nil {
+		newValue, err = v.loadClusterInfo(source)
 	} else {
 		return fmt.Errorf("invalid vars source")
 	}
 	if err != nil {
+		if ignoreMissingOverridden {
+			return fmt.Errorf("%w (ignoreMissing of this vars source is overridden by --no-ignore-missing-vars)", err)
+		}
 		return err
 	}
 
+	if source.SourcePath != "" {
+		newValue, err = v.extractSourcePath(newValue, source.SourcePath, ignoreMissing)
+		if err != nil {
+			return err
+		}
+	}
+
 	if sourceIn.Sensitive != nil {
 		// override the default
 		sensitive = *sourceIn.Sensitive
@@ -178,6 +325,8 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 	sourceIn.RenderedSensitive = sensitive
 	sourceIn.RenderedVars = newVars.Clone()
 
+	v.recordProvenance(varsCtx, newVars, source, source.NoOverride != nil && *source.NoOverride)
+
 	if source.NoOverride == nil || !*source.NoOverride {
 		varsCtx.Vars.Merge(newVars)
 	} else {
@@ -188,6 +337,37 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 	return nil
 }
 
+// extractSourcePath returns the dictionary found at the given JSON path. If nothing is found at the path, an empty
+// dictionary is returned in case ignoreMissing is true.
+func (v *VarsLoader) extractSourcePath(value any, sourcePath string, ignoreMissing bool) (*uo.UnstructuredObject, error) {
+	p, err := uo.NewMyJsonPath(sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sourcePath: %w", err)
+	}
+	x, found := p.GetFirstFromAny(unwrapUnstructured(value))
+	if !found {
+		if ignoreMissing {
+			return uo.New(), nil
+		}
+		return nil, fmt.Errorf("sourcePath %s not found in loaded variables", sourcePath)
+	}
+	switch m := x.(type) {
+	case map[string]any:
+		return uo.FromMap(m), nil
+	case *uo.UnstructuredObject:
+		return m, nil
+	default:
+		return nil, fmt.Errorf("sourcePath %s does not point to a dictionary, but to a value of type %T", sourcePath, x)
+	}
+}
+
+func unwrapUnstructured(value any) any {
+	if x, ok := value.(*uo.UnstructuredObject); ok {
+		return x.Object
+	}
+	return value
+}
+
 func (v *VarsLoader) mergeVars(varsCtx *VarsCtx, newVars *uo.UnstructuredObject, rootKey string) {
 	if rootKey == "" {
 		varsCtx.Update(newVars)
@@ -197,6 +377,63 @@ func (v *VarsLoader) mergeVars(varsCtx *VarsCtx, newVars *uo.UnstructuredObject,
 }
 
 func (v *VarsLoader) loadFile(varsCtx *VarsCtx, path string, ignoreMissing bool, searchDirs []string) (*uo.UnstructuredObject, bool, error) {
+	if strings.ContainsAny(path, "*?[") {
+		return v.loadFileGlob(varsCtx, path, ignoreMissing, searchDirs)
+	}
+	return v.loadSingleFile(varsCtx, path, ignoreMissing, searchDirs)
+}
+
+// loadFileGlob resolves the glob pattern against all search dirs and loads all matched files in lexical order. If the
+// same relative path is matched in multiple search dirs, it is only loaded once, as the template loader would only
+// find the file in the first search dir anyway.
+func (v *VarsLoader) loadFileGlob(varsCtx *VarsCtx, pattern string, ignoreMissing bool, searchDirs []string) (*uo.UnstructuredObject, bool, error) {
+	matches := map[string]bool{}
+	for _, d := range searchDirs {
+		l, err := filepath.Glob(filepath.Join(d, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid glob pattern %s: %w", pattern, err)
+		}
+		for _, m := range l {
+			if !utils.IsFile(m) {
+				continue
+			}
+			rel, err := filepath.Rel(d, m)
+			if err != nil {
+				return nil, false, err
+			}
+			if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return nil, false, fmt.Errorf("glob pattern %s matched %s, which is outside of the project", pattern, m)
+			}
+			matches[filepath.ToSlash(rel)] = true
+		}
+	}
+	if len(matches) == 0 {
+		if ignoreMissing {
+			return uo.New(), false, nil
+		}
+		return nil, false, fmt.Errorf("glob pattern %s did not match any vars files", pattern)
+	}
+
+	var pathes []string
+	for p := range matches {
+		pathes = append(pathes, p)
+	}
+	sort.Strings(pathes)
+
+	ret := uo.New()
+	sensitive := false
+	for _, p := range pathes {
+		newVars, s, err := v.loadSingleFile(varsCtx, p, false, searchDirs)
+		if err != nil {
+			return nil, false, err
+		}
+		ret.Merge(newVars)
+		sensitive = sensitive || s
+	}
+	return ret, sensitive, nil
+}
+
+func (v *VarsLoader) loadSingleFile(varsCtx *VarsCtx, path string, ignoreMissing bool, searchDirs []string) (*uo.UnstructuredObject, bool, error) {
 	rendered, err := varsCtx.RenderFile(path, searchDirs)
 	if err != nil {
 		// TODO the Jinja2 renderer should be able to better report this error
@@ -210,6 +447,10 @@ func (v *VarsLoader) loadFile(varsCtx *VarsCtx, path string, ignoreMissing bool,
 	format := formats.FormatForPath(path)
 	decrypted, sensitive, err := sops.MaybeDecrypt(v.sops, []byte(rendered), format, format)
 	if err != nil {
+		if v.isSkippableSopsError(err) {
+			v.skipSopsFile(v.ctx, "vars file", path)
+			return uo.New(), true, nil
+		}
 		return nil, false, fmt.Errorf("failed to decrypt vars file %s: %w", path, err)
 	}
 	rendered = string(decrypted)
@@ -225,7 +466,16 @@ func (v *VarsLoader) loadFile(varsCtx *VarsCtx, path string, ignoreMissing bool,
 	return newVars, sensitive, nil
 }
 
-func (v *VarsLoader) loadSystemEnvs(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool, rootKey string) (*uo.UnstructuredObject, error) {
+func (v *VarsLoader) loadSystemEnvs(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool, rootKey string, searchDirs []string) (*uo.UnstructuredObject, error) {
+	var fileEnvs map[string]string
+	if source.EnvFile != nil {
+		var err error
+		fileEnvs, err = v.loadEnvFile(*source.EnvFile, ignoreMissing, searchDirs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	newVars := uo.New()
 	err := source.SystemEnvVars.NewIterator().IterateLeafs(func(it *uo.ObjectIterator) error {
 		envName, ok := it.Value().(string)
@@ -240,9 +490,16 @@ func (v *VarsLoader) loadSystemEnvs(varsCtx *VarsCtx, source *types.VarsSource,
 			defaultValue = s[1]
 			hasDefaultValue = true
 		}
+		if envName == "" {
+			// this usually happens when the name is templated and renders to an empty string, which should never be
+			// silently ignored
+			return fmt.Errorf("empty environment variable name for %s", it.KeyPath().ToJsonPath())
+		}
 		envValueStr := ""
 		if v, ok := os.LookupEnv(envName); ok {
 			envValueStr = v
+		} else if v, ok := fileEnvs[envName]; ok {
+			envValueStr = v
 		} else if hasDefaultValue {
 			envValueStr = defaultValue
 			if envValueStr == "" {
@@ -297,6 +554,80 @@ func (v *VarsLoader) loadAwsSecretsManager(varsCtx *VarsCtx, source *types.VarsS
 	return v.loadFromString(varsCtx, secret)
 }
 
+func (v *VarsLoader) loadAwsSsm(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool) (*uo.UnstructuredObject, error) {
+	if v.aws == nil {
+		return uo.New(), fmt.Errorf("no AWS client factory provided")
+	}
+
+	s := source.AwsSsm
+	handleErr := func(err error) (*uo.UnstructuredObject, error) {
+		var aerr *ssmtypes.ParameterNotFound
+		if errors2.As(err, &aerr) {
+			if ignoreMissing {
+				return uo.New(), nil
+			}
+		}
+		return nil, err
+	}
+
+	if !s.Recursive {
+		value, err := aws.GetAwsSsmParameter(v.ctx, v.aws, s.Profile, s.Region, s.Name)
+		if err != nil {
+			return handleErr(err)
+		}
+		return v.loadFromString(varsCtx, value)
+	}
+
+	params, err := aws.GetAwsSsmParametersByPath(v.ctx, v.aws, s.Profile, s.Region, s.Name)
+	if err != nil {
+		return handleErr(err)
+	}
+
+	names := make([]string, 0, len(params))
+	for n := range params {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	prefix := strings.TrimSuffix(s.Name, "/") + "/"
+	ret := uo.New()
+	for _, n := range names {
+		var keys []any
+		for _, k := range strings.Split(strings.TrimPrefix(n, prefix), "/") {
+			if k != "" {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		err = ret.SetNestedField(params[n], keys...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build object from AWS SSM parameter %s: %w", n, err)
+		}
+	}
+	return ret, nil
+}
+
+func (v *VarsLoader) loadAwsAppConfig(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool) (*uo.UnstructuredObject, error) {
+	if v.aws == nil {
+		return uo.New(), fmt.Errorf("no AWS client factory provided")
+	}
+
+	s := source.AwsAppConfig
+	c, err := aws.GetAwsAppConfigConfiguration(v.ctx, v.aws, s.Profile, s.Region, s.Application, s.Environment, s.ConfigurationProfile)
+	if err != nil {
+		var aerr *appconfigdatatypes.ResourceNotFoundException
+		if errors2.As(err, &aerr) {
+			if ignoreMissing {
+				return uo.New(), nil
+			}
+		}
+		return nil, err
+	}
+	return v.loadFromString(varsCtx, c)
+}
+
 func (v *VarsLoader) loadGcpSecretManager(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool) (*uo.UnstructuredObject, error) {
 	if v.gcp == nil {
 		return uo.New(), fmt.Errorf("no GCP client factory provided")
@@ -330,7 +661,7 @@ func (v *VarsLoader) loadAzureKeyVault(varsCtx *VarsCtx, source *types.VarsSourc
 }
 
 func (v *VarsLoader) loadVault(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool) (*uo.UnstructuredObject, error) {
-	secret, err := vault.GetSecret(source.Vault.Address, source.Vault.Path)
+	secret, err := vault.GetSecret(source.Vault.Address, source.Vault.Path, source.Vault.Version, source.Vault.KvVersion, source.Vault.Auth)
 	if err != nil {
 		return nil, err
 	}
@@ -343,7 +674,105 @@ func (v *VarsLoader) loadVault(varsCtx *VarsCtx, source *types.VarsSource, ignor
 	return v.loadFromString(varsCtx, *secret)
 }
 
-func (v *VarsLoader) loadGit(ctx context.Context, varsCtx *VarsCtx, gitFile *types.VarsSourceGit, ignoreMissing bool) (*uo.UnstructuredObject, bool, error) {
+func (v *VarsLoader) loadEtcd(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool) (*uo.UnstructuredObject, error) {
+	kvs, err := etcd.GetKeys(v.ctx, source.Etcd)
+	if err != nil {
+		return nil, err
+	}
+	if len(kvs) == 0 {
+		if ignoreMissing {
+			return uo.New(), nil
+		}
+		return nil, fmt.Errorf("the specified etcd key %s was not found", source.Etcd.Key)
+	}
+
+	if !source.Etcd.Prefix {
+		return v.loadFromString(varsCtx, kvs[0].Value)
+	}
+
+	ret := uo.New()
+	for _, kv := range kvs {
+		var fields []interface{}
+		for _, f := range strings.Split(strings.TrimPrefix(kv.Key, source.Etcd.Key), "/") {
+			if f != "" {
+				fields = append(fields, f)
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		var value any
+		err = yaml.ReadYamlString(kv.Value, &value)
+		if err != nil {
+			// not valid yaml, so use the raw value
+			value = kv.Value
+		}
+		err = ret.SetNestedField(value, fields...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set value for etcd key %s: %w", kv.Key, err)
+		}
+	}
+	return ret, nil
+}
+
+func (v *VarsLoader) load1Password(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool) (*uo.UnstructuredObject, error) {
+	fields, err := onepassword.GetItemFields(v.ctx, source.OnePassword)
+	if err != nil {
+		if ignoreMissing && errors2.Is(err, onepassword.ErrNotFound) {
+			return uo.New(), nil
+		}
+		return nil, err
+	}
+
+	if source.OnePassword.Field != "" {
+		value, ok := fields[source.OnePassword.Field]
+		if !ok {
+			if ignoreMissing {
+				return uo.New(), nil
+			}
+			return nil, fmt.Errorf("field %s not found in 1Password item %s", source.OnePassword.Field, source.OnePassword.Item)
+		}
+		return v.loadFromString(varsCtx, value)
+	}
+
+	ret := uo.New()
+	for _, f := range source.OnePassword.Fields {
+		value, ok := fields[f]
+		if !ok {
+			if ignoreMissing {
+				continue
+			}
+			return nil, fmt.Errorf("field %s not found in 1Password item %s", f, source.OnePassword.Item)
+		}
+		err = ret.SetNestedField(value, f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func (v *VarsLoader) loadGitlab(source *types.VarsSource, ignoreMissing bool) (*uo.UnstructuredObject, error) {
+	variables, err := gitlab.GetVariables(v.ctx, source.Gitlab)
+	if err != nil {
+		if ignoreMissing && errors2.Is(err, gitlab.ErrNotFound) {
+			return uo.New(), nil
+		}
+		return nil, err
+	}
+
+	ret := uo.New()
+	for k, value := range variables {
+		err = ret.SetNestedField(value, k)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func (v *VarsLoader) loadGit(ctx context.Context, varsCtx *VarsCtx, gitFile *types.VarsSourceGit, ignoreMissing bool, rootKey string) (*uo.UnstructuredObject, bool, error) {
 	ge, err := v.rp.GetEntry(gitFile.Url.String())
 	if err != nil {
 		return nil, false, err
@@ -355,59 +784,287 @@ func (v *VarsLoader) loadGit(ctx context.Context, varsCtx *VarsCtx, gitFile *typ
 			"documentation for details: https://kluctl.io/docs/kluctl/reference/templating/variable-sources/#git")
 	}
 
-	clonedDir, _, err := ge.GetClonedDir(gitFile.Ref)
+	var clonedDir string
+	if gitFile.Sparse {
+		clonedDir, err = v.getSparseGitClonedDir(ge, gitFile)
+	} else {
+		clonedDir, err = v.getGitClonedDir(ge, gitFile, nil)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	varsHash, err := buildUsedVarsHash(varsCtx.Vars, clonedDir, gitFile.Path)
+	if err != nil {
+		return nil, false, err
+	}
+	cacheKey := gitVarsCacheKey{
+		url:           gitFile.Url.String(),
+		path:          gitFile.Path,
+		rootKey:       rootKey,
+		ignoreMissing: ignoreMissing,
+		varsHash:      varsHash,
+	}
+	if gitFile.Ref != nil {
+		cacheKey.ref = gitFile.Ref.String()
+	}
+	if e, ok := v.gitVarsCache[cacheKey]; ok {
+		return e.vars.Clone(), e.sensitive, nil
+	}
+
+	newVars, sensitive, err := v.loadFile(varsCtx, gitFile.Path, ignoreMissing, []string{clonedDir})
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to load vars from git repository %s: %w", gitFile.Url.String(), err)
+		return nil, false, err
 	}
 
-	return v.loadFile(varsCtx, gitFile.Path, ignoreMissing, []string{clonedDir})
+	v.gitVarsCache[cacheKey] = gitVarsCacheEntry{
+		vars:      newVars.Clone(),
+		sensitive: sensitive,
+	}
+	return newVars, sensitive, nil
 }
 
-func (v *VarsLoader) loadFromK8sConfigMapOrSecret(varsCtx *VarsCtx, varsSource types.VarsSourceClusterConfigMapOrSecret, kind string, ignoreMissing bool, base64Decode bool) (*uo.UnstructuredObject, error) {
-	if v.k == nil {
-		return nil, fmt.Errorf("loading vars from cluster is disabled")
+// getGitClonedDir returns a checkout of the given git vars source. Checkouts are reused for the lifetime of the
+// VarsLoader, so that repeated loading of the same file does not cause repeated checkouts.
+func (v *VarsLoader) getGitClonedDir(ge *repocache.GitCacheEntry, gitFile *types.VarsSourceGit, sparseDirs []string) (string, error) {
+	key := gitClonedDirKey{
+		url:    gitFile.Url.String(),
+		sparse: strings.Join(sparseDirs, ":"),
+	}
+	if gitFile.Ref != nil {
+		key.ref = gitFile.Ref.String()
+	}
+	if d, ok := v.gitClonedDirs[key]; ok {
+		return d, nil
 	}
 
-	var err error
-	var o *uo.UnstructuredObject
+	clonedDir, _, err := ge.GetClonedDirSparse(gitFile.Ref, sparseDirs)
+	if err != nil {
+		return "", fmt.Errorf("failed to load vars from git repository %s: %w", gitFile.Url.String(), err)
+	}
+	v.gitClonedDirs[key] = clonedDir
+	return clonedDir, nil
+}
+
+// getSparseGitClonedDir returns a sparse checkout that contains the directory of the vars file and the directories of
+// all templates that are (transitively) included by it. The set of directories is extended until it does not change
+// anymore. If an include can't be resolved statically, a full checkout is returned.
+func (v *VarsLoader) getSparseGitClonedDir(ge *repocache.GitCacheEntry, gitFile *types.VarsSourceGit) (string, error) {
+	dirs := map[string]bool{
+		path.Dir(gitFile.Path): true,
+	}
+	for {
+		if dirs["."] {
+			return v.getGitClonedDir(ge, gitFile, nil)
+		}
+
+		var sparseDirs []string
+		for d := range dirs {
+			sparseDirs = append(sparseDirs, d)
+		}
+		sort.Strings(sparseDirs)
+
+		clonedDir, err := v.getGitClonedDir(ge, gitFile, sparseDirs)
+		if err != nil {
+			return "", err
+		}
+
+		includeDirs, ok, err := collectTemplateIncludeDirs(clonedDir, sparseDirs)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return v.getGitClonedDir(ge, gitFile, nil)
+		}
+
+		changed := false
+		for _, d := range includeDirs {
+			if !dirs[d] {
+				dirs[d] = true
+				changed = true
+			}
+		}
+		if !changed {
+			return clonedDir, nil
+		}
+	}
+}
+
+var templateIncludeRegex = regexp.MustCompile(`(?:\{%-?\s*(?:include|import|from|extends)|load_template\()\s*(?:"([^"]*)"|'([^']*)')?`)
+
+// collectTemplateIncludeDirs scans all files found in the given directories for include statements and returns the
+// directories of the included templates, relative to the repository root. It returns false if any of the includes
+// does not use a literal path or points outside the repository.
+func collectTemplateIncludeDirs(root string, dirs []string) ([]string, bool, error) {
+	var ret []string
+	ok := true
+	for _, d := range dirs {
+		err := filepath.WalkDir(filepath.Join(root, filepath.FromSlash(d)), func(p string, de fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if de.IsDir() {
+				if de.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			b, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			for _, m := range templateIncludeRegex.FindAllStringSubmatch(string(b), -1) {
+				include := m[1] + m[2]
+				if include == "" {
+					ok = false
+					continue
+				}
+				if strings.HasPrefix(include, "./") || strings.HasPrefix(include, "../") {
+					include = path.Join(path.Dir(filepath.ToSlash(rel)), include)
+				}
+				include = path.Clean(include)
+				if include == ".." || strings.HasPrefix(include, "../") {
+					ok = false
+					continue
+				}
+				ret = append(ret, path.Dir(include))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return ret, ok, nil
+}
+
+var templateIdentifierRegex = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// buildUsedVarsHash builds a hash of all top-level vars that are referenced by the vars file found at p. If the file
+// can't be analyzed (e.g. because it is a glob pattern, does not exist or includes other templates), all vars are
+// hashed, which means that the cache is only hit when all vars are equal.
+func buildUsedVarsHash(vars *uo.UnstructuredObject, dir string, p string) (string, error) {
+	used := vars
+	if !strings.ContainsAny(p, "*?[") {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+		if err == nil && !templateMightIncludeOthers(string(b)) {
+			identifiers := map[string]bool{}
+			for _, id := range templateIdentifierRegex.FindAllString(string(b), -1) {
+				identifiers[id] = true
+			}
+			used = uo.New()
+			for k, x := range vars.Object {
+				if identifiers[k] {
+					used.Object[k] = x
+				}
+			}
+		}
+	}
+
+	j, err := yaml.WriteJsonString(used)
+	if err != nil {
+		return "", err
+	}
+	return utils.Sha256String(j), nil
+}
+
+// templateMightIncludeOthers returns true if the template might pull in other templates, which might then reference
+// vars not referenced by the template itself
+func templateMightIncludeOthers(s string) bool {
+	for _, x := range []string{"include", "import", "extends", "load_template", "load_sha256"} {
+		if strings.Contains(s, x) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *VarsLoader) loadFromK8sConfigMapOrSecret(varsCtx *VarsCtx, varsSource types.VarsSourceClusterConfigMapOrSecret, kind string, ignoreMissing bool, base64Decode bool) (*uo.UnstructuredObject, bool, error) {
+	if v.k == nil {
+		return nil, false, fmt.Errorf("loading vars from cluster is disabled")
+	}
 
 	if varsSource.Name != "" {
-		o, _, err = v.k.GetSingleObject(k8s2.NewObjectRef("", "v1", kind, varsSource.Name, varsSource.Namespace))
+		o, _, err := v.k.GetSingleObject(k8s2.NewObjectRef("", "v1", kind, varsSource.Name, varsSource.Namespace))
 		if err != nil {
 			if ignoreMissing && errors.IsNotFound(err) {
-				return uo.New(), nil
+				return uo.New(), false, nil
 			}
-			return nil, err
+			return nil, false, err
 		}
-	} else {
-		objs, _, err := v.k.ListObjects(schema.GroupVersionKind{
-			Group:   "",
-			Version: "v1",
-			Kind:    kind,
-		}, varsSource.Namespace, varsSource.Labels)
+		return v.loadFromK8sConfigMapOrSecretObject(varsCtx, varsSource, o, ignoreMissing, base64Decode)
+	}
+
+	objs, _, err := v.k.ListObjects(schema.GroupVersionKind{
+		Group:   "",
+		Version: "v1",
+		Kind:    kind,
+	}, varsSource.Namespace, varsSource.Labels)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(objs) == 0 {
+		if ignoreMissing {
+			return uo.New(), false, nil
+		}
+		return nil, false, fmt.Errorf("no object found with labels %v", varsSource.Labels)
+	}
+	if len(objs) > 1 && !varsSource.MergeMultiple {
+		return nil, false, fmt.Errorf("found more than one objects with labels %v", varsSource.Labels)
+	}
+
+	// sort by name so that the merge order is stable, meaning that the last object (by name) wins on duplicate keys
+	sort.Slice(objs, func(i, j int) bool {
+		return objs[i].GetK8sName() < objs[j].GetK8sName()
+	})
+
+	ret := uo.New()
+	encrypted := false
+	for _, o := range objs {
+		newVars, e, err := v.loadFromK8sConfigMapOrSecretObject(varsCtx, varsSource, o, ignoreMissing, base64Decode)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
-		if len(objs) == 0 {
+		ret.Merge(newVars)
+		encrypted = encrypted || e
+	}
+	return ret, encrypted, nil
+}
+
+func (v *VarsLoader) loadFromK8sConfigMapOrSecretObject(varsCtx *VarsCtx, varsSource types.VarsSourceClusterConfigMapOrSecret, o *uo.UnstructuredObject, ignoreMissing bool, base64Decode bool) (*uo.UnstructuredObject, bool, error) {
+	ref := o.GetK8sRef()
+
+	if varsSource.FieldManager != "" {
+		ownedKeys, err := getDataKeysOwnedByManager(o, varsSource.FieldManager)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ownedKeys[varsSource.Key] {
 			if ignoreMissing {
-				return uo.New(), nil
+				return uo.New(), false, nil
 			}
-			return nil, fmt.Errorf("no object found with labels %v", varsSource.Labels)
+			return nil, false, fmt.Errorf("key %s in %s on cluster is not managed by field manager %s", varsSource.Key, ref.String(), varsSource.FieldManager)
 		}
-		if len(objs) > 1 {
-			return nil, fmt.Errorf("found more than one objects with labels %v", varsSource.Labels)
-		}
-		o = objs[0]
 	}
 
-	ref := o.GetK8sRef()
+	dockerConfig := base64Decode && isDockerConfigJsonSecret(o, varsSource.Key)
 
 	f, found, err := o.GetNestedField("data", varsSource.Key)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	if !found {
-		return nil, fmt.Errorf("key %s not found in %s on cluster", varsSource.Key, ref.String())
+		if dockerConfig && ignoreMissing {
+			return uo.New(), false, nil
+		}
+		return nil, false, fmt.Errorf("key %s not found in %s on cluster", varsSource.Key, ref.String())
 	}
 
 	var value string
@@ -417,7 +1074,7 @@ func (v *VarsLoader) loadFromK8sConfigMapOrSecret(varsCtx *VarsCtx, varsSource t
 		if base64Decode {
 			b, err := base64.StdEncoding.DecodeString(s)
 			if err != nil {
-				return nil, err
+				return nil, false, err
 			}
 			value = string(b)
 		} else {
@@ -425,12 +1082,18 @@ func (v *VarsLoader) loadFromK8sConfigMapOrSecret(varsCtx *VarsCtx, varsSource t
 		}
 	}
 
-	doError := func(err error) (*uo.UnstructuredObject, error) {
-		return nil, fmt.Errorf("failed to load vars from kubernetes object %s and key %s: %w", ref.String(), varsSource.Key, err)
+	doError := func(err error) (*uo.UnstructuredObject, bool, error) {
+		return nil, false, fmt.Errorf("failed to load vars from kubernetes object %s and key %s: %w", ref.String(), varsSource.Key, err)
 	}
 
 	var parsed any
-	err = v.renderYamlString(varsCtx, value, &parsed)
+	var encrypted bool
+	if dockerConfig {
+		// registry credentials must not be rendered, as passwords might contain anything
+		parsed, err = parseDockerConfigJson(value)
+	} else {
+		encrypted, err = v.renderYamlString(varsCtx, value, &parsed)
+	}
 	if err != nil {
 		return doError(err)
 	}
@@ -440,7 +1103,7 @@ func (v *VarsLoader) loadFromK8sConfigMapOrSecret(varsCtx *VarsCtx, varsSource t
 		if !ok {
 			return doError(fmt.Errorf("value is not a YAML dictionary"))
 		}
-		return uo.FromMap(m), nil
+		return uo.FromMap(m), encrypted, nil
 	} else {
 		status.Deprecation(v.ctx, "cm-or-secret-target-path", "'targetPath' in clusterConfigMap and clusterSecret is deprecated, use the common 'targetPath' property from one level above instead.")
 
@@ -453,8 +1116,35 @@ func (v *VarsLoader) loadFromK8sConfigMapOrSecret(varsCtx *VarsCtx, varsSource t
 		if err != nil {
 			return doError(err)
 		}
-		return newVars, nil
+		return newVars, encrypted, nil
+	}
+}
+
+// getDataKeysOwnedByManager returns all keys of the data field that are owned by the given field manager
+func getDataKeysOwnedByManager(o *uo.UnstructuredObject, manager string) (map[string]bool, error) {
+	ret := map[string]bool{}
+	for _, mf := range o.GetK8sManagedFields() {
+		mgr, _, err := mf.GetNestedString("manager")
+		if err != nil {
+			return nil, err
+		}
+		if mgr != manager {
+			continue
+		}
+		dataFields, ok, err := mf.GetNestedObject("fieldsV1", "f:data")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		for k := range dataFields.Object {
+			if strings.HasPrefix(k, "f:") {
+				ret[strings.TrimPrefix(k, "f:")] = true
+			}
+		}
 	}
+	return ret, nil
 }
 
 func (v *VarsLoader) loadFromK8sObject(varsCtx *VarsCtx, varsSource types.VarsSourceClusterObject, ignoreMissing bool) (any, error) {
@@ -571,6 +1261,10 @@ func (v *VarsLoader) loadFromK8sObject(varsCtx *VarsCtx, varsSource types.VarsSo
 			if !ok {
 				return doError(fmt.Errorf("value is not a string, but parsing YAML was requested"))
 			}
+			s, _, err := v.maybeDecryptString(s)
+			if err != nil {
+				return doError(err)
+			}
 			x, err := uo.FromString(s)
 			if err != nil {
 				return doError(err)
@@ -588,25 +1282,72 @@ func (v *VarsLoader) loadFromK8sObject(varsCtx *VarsCtx, varsSource types.VarsSo
 	}
 }
 
+// loadFromString renders and (if needed) decrypts the given string and then parses it as YAML. All callers are
+// treating the result as sensitive already, so there is no need to return whether it was encrypted.
 func (v *VarsLoader) loadFromString(varsCtx *VarsCtx, s string) (*uo.UnstructuredObject, error) {
 	newVars := uo.New()
-	err := v.renderYamlString(varsCtx, s, newVars)
+	_, err := v.renderYamlString(varsCtx, s, newVars)
 	if err != nil {
 		return nil, err
 	}
 	return newVars, nil
 }
 
-func (v *VarsLoader) renderYamlString(varsCtx *VarsCtx, s string, out interface{}) error {
+// renderYamlString renders the given string, decrypts it in case it is sops encrypted and then parses it as YAML.
+// It returns true if the string was encrypted.
+func (v *VarsLoader) renderYamlString(varsCtx *VarsCtx, s string, out interface{}) (bool, error) {
 	ret, err := varsCtx.RenderString(s, nil)
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	ret, encrypted, err := v.maybeDecryptString(ret)
+	if err != nil {
+		return false, err
 	}
 
 	err = yaml.ReadYamlString(ret, out)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return encrypted, nil
+}
+
+// isSopsEncryptedString is stricter than sops.IsMaybeSopsFile, as strings from non-file sources are often plain
+// values which might contain the word "sops" without being encrypted.
+func isSopsEncryptedString(s string) bool {
+	if !sops.IsMaybeSopsFile([]byte(s)) {
+		return false
+	}
+	var m map[string]any
+	err := yaml.ReadYamlString(s, &m)
+	if err != nil {
+		return false
+	}
+	md, ok := m["sops"].(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = md["mac"]
+	return ok
+}
+
+// maybeDecryptString decrypts the given string in case it is sops encrypted. As there is no file name to derive the
+// format from, strings that look like a JSON object are treated as JSON and everything else as YAML. Strings that are
+// not sops encrypted are returned unmodified.
+func (v *VarsLoader) maybeDecryptString(s string) (string, bool, error) {
+	if !isSopsEncryptedString(s) {
+		return s, false, nil
+	}
+
+	format := formats.Yaml
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		format = formats.Json
+	}
+	decrypted, encrypted, err := sops.MaybeDecrypt(v.sops, []byte(s), format, format)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to decrypt vars: %w", err)
+	}
+	return string(decrypted), encrypted, nil
 }
@@ -0,0 +1,58 @@
+package vars
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"strings"
+)
+
+// loadClusterInfo builds the clusterInfo variables from the target cluster. If no cluster is available (e.g. in
+// offline mode), all values are null unless a fallback is provided.
+func (v *VarsLoader) loadClusterInfo(source *types.VarsSource) (*uo.UnstructuredObject, error) {
+	ret := uo.FromMap(map[string]interface{}{
+		"kubernetesVersion": nil,
+		"contextName":       nil,
+		"serverUrl":         nil,
+		"cloudProvider":     nil,
+	})
+
+	if v.k == nil {
+		if source.ClusterInfo.Fallback != nil {
+			ret.Merge(source.ClusterInfo.Fallback)
+		}
+		return ret, nil
+	}
+
+	if v.contextName != "" {
+		ret.Object["contextName"] = v.contextName
+	}
+	if v.k.ServerVersion != nil {
+		ret.Object["kubernetesVersion"] = v.k.ServerVersion.GitVersion
+		if p := detectCloudProvider(v.k.ServerVersion.GitVersion); p != "" {
+			ret.Object["cloudProvider"] = p
+		}
+	}
+	restConfig, err := v.k.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	if restConfig != nil && restConfig.Host != "" {
+		ret.Object["serverUrl"] = restConfig.Host
+	}
+	return ret, nil
+}
+
+// detectCloudProvider detects managed Kubernetes offerings by looking at the vendor specific suffixes of the server
+// version, e.g. v1.27.3-eks-a5565ad or v1.27.3-gke.100
+func detectCloudProvider(gitVersion string) string {
+	switch {
+	case strings.Contains(gitVersion, "-eks-"):
+		return "aws"
+	case strings.Contains(gitVersion, "-gke."):
+		return "gcp"
+	case strings.Contains(gitVersion, "+IKS"):
+		return "ibm"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,69 @@
+package vars
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	corev1 "k8s.io/api/core/v1"
+	"strings"
+)
+
+type dockerConfigJsonAuth struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+type dockerConfigJson struct {
+	Auths map[string]dockerConfigJsonAuth `json:"auths"`
+}
+
+func isDockerConfigJsonSecret(o *uo.UnstructuredObject, key string) bool {
+	if o.GetK8sGVK().Kind != "Secret" || key != corev1.DockerConfigJsonKey {
+		return false
+	}
+	t, _, _ := o.GetNestedString("type")
+	return t == string(corev1.SecretTypeDockerConfigJson)
+}
+
+// parseDockerConfigJson parses the content of a kubernetes.io/dockerconfigjson secret into a structured object with
+// the registry credentials found in 'auths'. If only 'auth' is set for a registry, username and password are decoded
+// from it. The content is neither rendered nor included in errors, as it contains plain credentials.
+func parseDockerConfigJson(s string) (map[string]any, error) {
+	var dc dockerConfigJson
+	err := json.Unmarshal([]byte(s), &dc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: invalid JSON", corev1.DockerConfigJsonKey)
+	}
+
+	auths := map[string]any{}
+	for registry, a := range dc.Auths {
+		if a.Auth != "" && a.Username == "" && a.Password == "" {
+			b, err := base64.StdEncoding.DecodeString(a.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode auth for registry %s", registry)
+			}
+			username, password, ok := strings.Cut(string(b), ":")
+			if !ok {
+				return nil, fmt.Errorf("failed to decode auth for registry %s: expected 'username:password'", registry)
+			}
+			a.Username = username
+			a.Password = password
+		}
+		m := map[string]any{
+			"username": a.Username,
+			"password": a.Password,
+			"auth":     a.Auth,
+		}
+		if a.Email != "" {
+			m["email"] = a.Email
+		}
+		auths[registry] = m
+	}
+
+	return map[string]any{
+		"auths": auths,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package vars
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/kluctl/kluctl/v2/pkg/sops"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// loadEnvFile loads the given dotenv file from the first search dir that contains it. The file may be encrypted
+// with sops. If the file can't be found and ignoreMissing is true, nil is returned.
+func (v *VarsLoader) loadEnvFile(path string, ignoreMissing bool, searchDirs []string) (map[string]string, error) {
+	var found string
+	for _, d := range searchDirs {
+		p, err := securejoin.SecureJoin(d, filepath.FromSlash(path))
+		if err != nil {
+			return nil, err
+		}
+		if utils.IsFile(p) {
+			found = p
+			break
+		}
+	}
+	if found == "" {
+		if ignoreMissing {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("env file %s not found", path)
+	}
+
+	b, err := os.ReadFile(found)
+	if err != nil {
+		return nil, err
+	}
+	decrypted, _, err := sops.MaybeDecrypt(v.sops, b, formats.Dotenv, formats.Dotenv)
+	if err != nil {
+		if v.isSkippableSopsError(err) {
+			v.skipSopsFile(v.ctx, "env file", path)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to decrypt env file %s: %w", path, err)
+	}
+
+	envs, err := parseEnvFile(decrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse env file %s: %w", path, err)
+	}
+	return envs, nil
+}
+
+// parseEnvFile parses the content of a dotenv file. It supports comments, empty lines, the optional 'export' prefix
+// and single or double-quoted values.
+func parseEnvFile(b []byte) (map[string]string, error) {
+	ret := map[string]string{}
+	s := bufio.NewScanner(bytes.NewReader(b))
+	lineNum := 0
+	for s.Scan() {
+		lineNum++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		k, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid line %d, expected KEY=VALUE", lineNum)
+		}
+		k = strings.TrimSpace(k)
+		if k == "" {
+			return nil, fmt.Errorf("invalid line %d, empty key", lineNum)
+		}
+		value = strings.TrimSpace(value)
+
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			x, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value in line %d: %w", lineNum, err)
+			}
+			value = x
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		} else if i := strings.Index(value, " #"); i != -1 {
+			value = strings.TrimSpace(value[:i])
+		}
+		ret[k] = value
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
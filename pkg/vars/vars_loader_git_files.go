@@ -99,6 +99,10 @@ func (v *VarsLoader) loadGitFiles(ctx context.Context, varsCtx *VarsCtx, gitFile
 			format := formats.FormatForPath(path)
 			decrypted, isEncrypted, err := sops.MaybeDecrypt(v.sops, []byte(content), format, format)
 			if err != nil {
+				if v.isSkippableSopsError(err) {
+					v.skipSopsFile(ctx, "git file", relPath)
+					return nil
+				}
 				return err
 			}
 			if isEncrypted {
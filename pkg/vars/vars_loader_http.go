@@ -2,24 +2,105 @@ package vars
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"github.com/Azure/go-ntlmssp"
+	securejoin "github.com/cyphar/filepath-securejoin"
 	"github.com/docker/distribution/registry/client/auth/challenge"
+	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/pkg/prompts"
 	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
-func (v *VarsLoader) doHttp(httpSource *types.VarsSourceHttp, ignoreMissing bool, username string, password string) (*http.Response, string, error) {
+// buildHttpTlsConfig builds the TLS config for the given http source. It returns nil if no TLS related settings are
+// specified, in which case the defaults are used.
+func (v *VarsLoader) buildHttpTlsConfig(httpSource *types.VarsSourceHttp, searchDirs []string) (*tls.Config, error) {
+	if httpSource.TlsClientCert == nil && httpSource.TlsClientKey == nil && httpSource.TlsCaBundle == nil && !httpSource.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{}
+
+	if httpSource.TlsClientCert != nil && httpSource.TlsClientKey != nil {
+		certPem, err := loadHttpPem(*httpSource.TlsClientCert, searchDirs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tlsClientCert: %w", err)
+		}
+		keyPem, err := loadHttpPem(*httpSource.TlsClientKey, searchDirs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tlsClientKey: %w", err)
+		}
+		cert, err := tls.X509KeyPair(certPem, keyPem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if httpSource.TlsCaBundle != nil {
+		caPem, err := loadHttpPem(*httpSource.TlsCaBundle, searchDirs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tlsCaBundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caPem) {
+			return nil, fmt.Errorf("tlsCaBundle does not contain any valid PEM encoded certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if httpSource.InsecureSkipVerify {
+		status.WarningOncef(v.ctx, "http-insecure-"+httpSource.Url.Host, "TLS certificate verification is disabled for http vars source %s (insecureSkipVerify). Never use this in production!", httpSource.Url.Host)
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return tlsConfig, nil
+}
+
+// loadHttpPem loads PEM data either from an environment variable (when ref is in the form 'env:MY_ENV_VAR') or from
+// a file. Relative file pathes are searched in the given search dirs.
+func loadHttpPem(ref string, searchDirs []string) ([]byte, error) {
+	if envName, ok := strings.CutPrefix(ref, "env:"); ok {
+		s, ok := os.LookupEnv(envName)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s not found", envName)
+		}
+		return []byte(s), nil
+	}
+
+	if filepath.IsAbs(ref) {
+		return os.ReadFile(ref)
+	}
+	for _, d := range searchDirs {
+		p, err := securejoin.SecureJoin(d, filepath.FromSlash(ref))
+		if err != nil {
+			return nil, err
+		}
+		if utils.IsFile(p) {
+			return os.ReadFile(p)
+		}
+	}
+	return nil, fmt.Errorf("file %s not found", ref)
+}
+
+func (v *VarsLoader) doHttp(httpSource *types.VarsSourceHttp, tlsConfig *tls.Config, username string, password string) (*http.Response, string, error) {
 	client := &http.Client{
 		Transport: ntlmssp.Negotiator{
 			RoundTripper: &http.Transport{
 				// This disables HTTP2.0 support, as it does not play well together with NTLM
-				TLSNextProto: make(map[string]func(string, *tls.Conn) http.RoundTripper),
+				TLSNextProto:    make(map[string]func(string, *tls.Conn) http.RoundTripper),
+				TLSClientConfig: tlsConfig,
 			},
 		},
 	}
@@ -46,6 +127,14 @@ func (v *VarsLoader) doHttp(httpSource *types.VarsSourceHttp, ignoreMissing bool
 	for k, v := range httpSource.Headers {
 		req.Header.Set(k, v)
 	}
+	for k, envName := range httpSource.HeadersFromEnv {
+		// never include the value in errors, as it usually contains tokens
+		v, ok := os.LookupEnv(envName)
+		if !ok {
+			return nil, "", fmt.Errorf("environment variable %s for header %s of http request to %s not found", envName, k, httpSource.Url.Redacted())
+		}
+		req.Header.Set(k, v)
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, "", err
@@ -58,15 +147,19 @@ func (v *VarsLoader) doHttp(httpSource *types.VarsSourceHttp, ignoreMissing bool
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return resp, string(respBody), fmt.Errorf("http request to %s failed with status code %d", httpSource.Url.String(), resp.StatusCode)
+		return resp, string(respBody), fmt.Errorf("http request to %s failed with status code %d", httpSource.Url.Redacted(), resp.StatusCode)
 	}
 
 	return resp, string(respBody), nil
 }
 
-func (v *VarsLoader) loadHttp(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool) (*uo.UnstructuredObject, bool, error) {
+func (v *VarsLoader) loadHttp(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool, searchDirs []string) (*uo.UnstructuredObject, bool, error) {
 	sensitive := false
-	resp, respBody, err := v.doHttp(source.Http, ignoreMissing, "", "")
+	tlsConfig, err := v.buildHttpTlsConfig(source.Http, searchDirs)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, respBody, err := v.doHttp(source.Http, tlsConfig, "", "")
 	if err != nil && resp != nil && resp.StatusCode == http.StatusUnauthorized {
 		chgs := challenge.ResponseChallenges(resp)
 		if len(chgs) == 0 {
@@ -96,7 +189,7 @@ func (v *VarsLoader) loadHttp(varsCtx *VarsCtx, source *types.VarsSource, ignore
 			v.credentialsCache[credsKey] = creds
 		}
 
-		resp, respBody, err = v.doHttp(source.Http, ignoreMissing, creds.username, creds.password)
+		resp, respBody, err = v.doHttp(source.Http, tlsConfig, creds.username, creds.password)
 		if err != nil {
 			return nil, false, err
 		}
@@ -111,6 +204,12 @@ func (v *VarsLoader) loadHttp(varsCtx *VarsCtx, source *types.VarsSource, ignore
 	var respObj interface{}
 	var newVars *uo.UnstructuredObject
 
+	respBody, encrypted, err := v.maybeDecryptString(respBody)
+	if err != nil {
+		return nil, false, err
+	}
+	sensitive = sensitive || encrypted
+
 	err = yaml.ReadYamlString(respBody, &respObj)
 	if err != nil {
 		return nil, false, err
@@ -125,12 +224,17 @@ func (v *VarsLoader) loadHttp(varsCtx *VarsCtx, source *types.VarsSource, ignore
 		}
 		x, ok := p.GetFirstFromAny(respObj)
 		if !ok {
-			return nil, false, fmt.Errorf("%s not found in result from http request %s", *source.Http.JsonPath, source.Http.Url.String())
+			return nil, false, fmt.Errorf("%s not found in result from http request %s", *source.Http.JsonPath, source.Http.Url.Redacted())
 		}
 		s, ok := x.(string)
 		if !ok {
-			return nil, false, fmt.Errorf("%s in result of http request %s is not a string", *source.Http.JsonPath, source.Http.Url.String())
+			return nil, false, fmt.Errorf("%s in result of http request %s is not a string", *source.Http.JsonPath, source.Http.Url.Redacted())
+		}
+		s, encrypted, err := v.maybeDecryptString(s)
+		if err != nil {
+			return nil, false, err
 		}
+		sensitive = sensitive || encrypted
 		newVars, err = uo.FromString(s)
 		if err != nil {
 			return nil, false, err
@@ -138,7 +242,7 @@ func (v *VarsLoader) loadHttp(varsCtx *VarsCtx, source *types.VarsSource, ignore
 	} else {
 		x, ok := respObj.(map[string]interface{})
 		if !ok {
-			return nil, false, fmt.Errorf("result of http request %s is not an object", source.Http.Url.String())
+			return nil, false, fmt.Errorf("result of http request %s is not an object", source.Http.Url.Redacted())
 		}
 		newVars = uo.FromMap(x)
 	}
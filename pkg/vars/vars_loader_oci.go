@@ -0,0 +1,38 @@
+package vars
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"net/http"
+)
+
+func isOciNotFound(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
+
+// loadOci pulls the given OCI artifact (via the OCI repo cache, so that repo overrides are honored) and loads the vars
+// file found at the given path the same way as the file vars source does.
+func (v *VarsLoader) loadOci(varsCtx *VarsCtx, ociFile *types.VarsSourceOci, ignoreMissing bool) (*uo.UnstructuredObject, bool, error) {
+	if v.ociRP == nil {
+		return nil, false, fmt.Errorf("loading vars from OCI artifacts is not supported in this context")
+	}
+
+	oe, err := v.ociRP.GetEntry(ociFile.Url)
+	if err != nil {
+		return nil, false, err
+	}
+
+	extractedDir, _, err := oe.GetExtractedDir(ociFile.Ref)
+	if err != nil {
+		if ignoreMissing && isOciNotFound(err) {
+			return uo.New(), false, nil
+		}
+		return nil, false, fmt.Errorf("failed to load vars from oci artifact %s: %w", ociFile.Url, err)
+	}
+
+	return v.loadFile(varsCtx, ociFile.Path, ignoreMissing, []string{extractedDir})
+}
@@ -2,6 +2,8 @@ package vars
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"github.com/huandu/xstrings"
 	gittypes "github.com/kluctl/kluctl/lib/git/types"
@@ -16,6 +18,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
@@ -26,6 +29,7 @@ import (
 	ssh_pool "github.com/kluctl/kluctl/lib/git/ssh-pool"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
+	"github.com/kluctl/kluctl/v2/pkg/sourceoverride"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
@@ -33,6 +37,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type VarsLoaderTestSuite struct {
@@ -76,7 +81,7 @@ func TestVarsLoader(t *testing.T) {
 }
 
 func (s *VarsLoaderTestSuite) newRP() *repocache.GitRepoCache {
-	grc := repocache.NewGitRepoCache(context.TODO(), &ssh_pool.SshPool{}, auth.NewDefaultAuthProviders("KLUCTL_GIT", nil), nil, 0)
+	grc := repocache.NewGitRepoCache(context.TODO(), &ssh_pool.SshPool{}, auth.NewDefaultAuthProviders("KLUCTL_GIT", nil), nil, 0, 0)
 	s.T().Cleanup(func() {
 		grc.Clear()
 	})
@@ -141,6 +146,50 @@ func (s *VarsLoaderTestSuite) TestValuesTargetPath() {
 	})
 }
 
+func (s *VarsLoaderTestSuite) TestValuesDynamicTargetPath() {
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		for _, region := range []string{"eu-central-1", "us-east-1"} {
+			_ = vc.Vars.SetNestedField(region, "region")
+			err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+				Values:     uo.FromStringMust(`{"name": "{{ region }}", "a": 1}`),
+				TargetPath: `regions["{{ region }}"].config`,
+			}, nil, "rootKey")
+			assert.NoError(s.T(), err)
+		}
+
+		v, _, _ := vc.Vars.GetNestedString("regions", "eu-central-1", "config", "name")
+		assert.Equal(s.T(), "eu-central-1", v)
+		v, _, _ = vc.Vars.GetNestedString("regions", "us-east-1", "config", "name")
+		assert.Equal(s.T(), "us-east-1", v)
+
+		// same computed path, merged according to noOverride
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Values:     uo.FromStringMust(`{"a": 2, "b": 2}`),
+			TargetPath: `regions["{{ region }}"].config`,
+			NoOverride: utils.Ptr(true),
+		}, nil, "")
+		assert.NoError(s.T(), err)
+		i, _, _ := vc.Vars.GetNestedInt("regions", "us-east-1", "config", "a")
+		assert.Equal(s.T(), int64(1), i)
+		i, _, _ = vc.Vars.GetNestedInt("regions", "us-east-1", "config", "b")
+		assert.Equal(s.T(), int64(2), i)
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Values:     uo.FromStringMust(`{"a": 3}`),
+			TargetPath: `regions["{{ region }}"].config`,
+		}, nil, "")
+		assert.NoError(s.T(), err)
+		i, _, _ = vc.Vars.GetNestedInt("regions", "us-east-1", "config", "a")
+		assert.Equal(s.T(), int64(3), i)
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Values:     uo.FromStringMust(`{"a": 3}`),
+			TargetPath: `{{ missing | default("") }}`,
+		}, nil, "")
+		assert.ErrorContains(s.T(), err, "rendered to an empty path")
+	})
+}
+
 func (s *VarsLoaderTestSuite) TestWhen() {
 	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
 		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
@@ -224,6 +273,80 @@ func (s *VarsLoaderTestSuite) TestSopsFile() {
 	})
 }
 
+func (s *VarsLoaderTestSuite) TestSopsFileMissingKey() {
+	d := s.T().TempDir()
+	f, _ := sops_test_resources.TestResources.ReadFile("test.yaml")
+	_ = os.WriteFile(filepath.Join(d, "test.yaml"), f, 0o600)
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			File: utils.Ptr("test.yaml"),
+		}, []string{d}, "")
+		assert.ErrorIs(s.T(), err, decryptor.ErrDataKeyNotFound)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		vl.SetAllowMissingSopsKeys(true)
+		vs := &types.VarsSource{
+			File: utils.Ptr("test.yaml"),
+		}
+		err := vl.LoadVars(context.TODO(), vc, vs, []string{d}, "")
+		assert.NoError(s.T(), err)
+
+		_, ok, _ := vc.Vars.GetNestedField("test1")
+		assert.False(s.T(), ok)
+		assert.True(s.T(), vs.RenderedSensitive)
+
+		// errors that might be caused by the missing vars must mention the skipped file
+		err = vl.WrapMissingSopsKeysError(fmt.Errorf("'test1' is undefined"))
+		assert.EqualError(s.T(), err, "'test1' is undefined (vars from the following files are missing as they could not be decrypted due to missing sops keys, which might be the cause of this error: test.yaml)")
+		assert.NoError(s.T(), vl.WrapMissingSopsKeysError(nil))
+	})
+}
+
+func (s *VarsLoaderTestSuite) TestFileGlob() {
+	d := s.T().TempDir()
+	f, _ := sops_test_resources.TestResources.ReadFile("test.yaml")
+	key, _ := sops_test_resources.TestResources.ReadFile("test-key.txt")
+	_ = os.MkdirAll(filepath.Join(d, "vars"), 0o700)
+	_ = os.WriteFile(filepath.Join(d, "vars", "02.yaml"), []byte(`{"b": 2}`), 0o600)
+	_ = os.WriteFile(filepath.Join(d, "vars", "01.yaml"), []byte(`{"a": 1, "b": 1}`), 0o600)
+	_ = os.WriteFile(filepath.Join(d, "vars", "03.yaml"), f, 0o600)
+	_ = os.WriteFile(filepath.Join(d, "vars", "04.txt"), []byte(`{"b": 4}`), 0o600)
+
+	s.T().Setenv(age.SopsAgeKeyEnv, string(key))
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		vs := &types.VarsSource{
+			File: utils.Ptr("vars/*.yaml"),
+		}
+		err := vl.LoadVars(context.TODO(), vc, vs, []string{d}, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("a")
+		assert.Equal(s.T(), int64(1), v)
+		v, _, _ = vc.Vars.GetNestedInt("b")
+		assert.Equal(s.T(), int64(2), v)
+		v, _, _ = vc.Vars.GetNestedInt("test1", "test2")
+		assert.Equal(s.T(), int64(42), v)
+		assert.True(s.T(), vs.RenderedSensitive)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			File: utils.Ptr("missing/*.yaml"),
+		}, []string{d}, "")
+		assert.EqualError(s.T(), err, "glob pattern missing/*.yaml did not match any vars files")
+
+		b := true
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			IgnoreMissing: &b,
+			File:          utils.Ptr("missing/*.yaml"),
+		}, []string{d}, "")
+		assert.NoError(s.T(), err)
+	})
+}
+
 func (s *VarsLoaderTestSuite) TestFileWithLoad() {
 	d := s.T().TempDir()
 	_ = os.WriteFile(filepath.Join(d, "test.yaml"), []byte(`{"test1": {"test2": {{ load_template("test2.txt") }}}}`), 0o600)
@@ -307,6 +430,125 @@ func (s *VarsLoaderTestSuite) TestGit() {
 	})
 }
 
+func (s *VarsLoaderTestSuite) TestOci() {
+	d := s.T().TempDir()
+	_ = os.WriteFile(filepath.Join(d, "test.yaml"), []byte(`{"test1": {"test2": 42}}`), 0o600)
+
+	ociRP := repocache.NewOciRepoCache(context.TODO(), nil, sourceoverride.NewManager([]sourceoverride.RepoOverride{
+		{RepoKey: gittypes.NewRepoKey("oci", "registry.example.com", "org/vars"), Override: d},
+	}), 0)
+	s.T().Cleanup(ociRP.Clear)
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Oci: &types.VarsSourceOci{
+				Url:  "oci://registry.example.com/org/vars",
+				Path: "test.yaml",
+			},
+		}, nil, "")
+		assert.ErrorContains(s.T(), err, "not supported")
+
+		vl.SetOciRepoCache(ociRP)
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Oci: &types.VarsSourceOci{
+				Url:  "oci://registry.example.com/org/vars",
+				Ref:  &types.OciRef{Tag: "v1"},
+				Path: "test.yaml",
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
+		assert.Equal(s.T(), int64(42), v)
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			IgnoreMissing: utils.Ptr(true),
+			Oci: &types.VarsSourceOci{
+				Url:  "oci://registry.example.com/org/vars",
+				Path: "test-missing.yaml",
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+	})
+}
+
+func (s *VarsLoaderTestSuite) TestGitSparse() {
+	gs := test_utils.NewTestGitServer(s.T())
+	gs.GitInit("repo")
+	gs.UpdateYaml("repo", "dir/test.yaml", func(o map[string]any) error {
+		o["test1"] = map[string]any{
+			"test2": 42,
+		}
+		return nil
+	}, "")
+	gs.UpdateYaml("repo", "other/test.yaml", func(o map[string]any) error {
+		o["test1"] = 43
+		return nil
+	}, "")
+	gs.UpdateFile("repo", "dir/include.yaml", func(f string) (string, error) {
+		return `{% include "../included/a.yaml" %}`, nil
+	}, "")
+	gs.UpdateFile("repo", "included/a.yaml", func(f string) (string, error) {
+		return `{% include "included2/b.yaml" %}`, nil
+	}, "")
+	gs.UpdateFile("repo", "included2/b.yaml", func(f string) (string, error) {
+		return `test3: 44`, nil
+	}, "")
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		url, _ := gittypes.ParseGitUrl(gs.GitRepoUrl("repo"))
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Git: &types.VarsSourceGit{
+				Url:    *url,
+				Path:   "dir/test.yaml",
+				Sparse: true,
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
+		assert.Equal(s.T(), int64(42), v)
+
+		// includes (also transitive ones) from other directories must be part of the sparse checkout
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Git: &types.VarsSourceGit{
+				Url:    *url,
+				Path:   "dir/include.yaml",
+				Sparse: true,
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ = vc.Vars.GetNestedInt("test3")
+		assert.Equal(s.T(), int64(44), v)
+	})
+}
+
+func TestCollectTemplateIncludeDirs(t *testing.T) {
+	root := t.TempDir()
+	write := func(p string, content string) {
+		p = filepath.Join(root, filepath.FromSlash(p))
+		_ = os.MkdirAll(filepath.Dir(p), 0o700)
+		_ = os.WriteFile(p, []byte(content), 0o600)
+	}
+	write("dir/a.yaml", `{% include "x/a.yaml" %}{%- import './y/b.yaml' as b %}{% from "../z/c.yaml" import c %}{{ load_template("w/d.yaml") }}`)
+
+	dirs, ok, err := collectTemplateIncludeDirs(root, []string{"dir"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"x", "dir/y", "z", "w"}, dirs)
+
+	write("dir2/a.yaml", `{% include some_var %}`)
+	_, ok, err = collectTemplateIncludeDirs(root, []string{"dir2"})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	write("dir3/a.yaml", `{% include "../../outside.yaml" %}`)
+	_, ok, err = collectTemplateIncludeDirs(root, []string{"dir3"})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
 func (s *VarsLoaderTestSuite) TestGitBranch() {
 	gs := test_utils.NewTestGitServer(s.T())
 	gs.GitInit("repo")
@@ -434,6 +676,64 @@ func (s *VarsLoaderTestSuite) TestClusterConfigMap() {
 	})
 }
 
+func (s *VarsLoaderTestSuite) TestClusterConfigMapFieldManager() {
+	s.createNamespace()
+
+	cm := corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{Name: "cm", Namespace: s.namespace()},
+		Data: map[string]string{
+			"vars": `{"test1": {"test2": 42}}`,
+		},
+	}
+
+	err := s.k.Client.Create(context.TODO(), &cm, client.FieldOwner("manager1"))
+	assert.NoError(s.T(), err)
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			ClusterConfigMap: &types.VarsSourceClusterConfigMapOrSecret{
+				Name:         "cm",
+				Namespace:    s.namespace(),
+				Key:          "vars",
+				FieldManager: "manager1",
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
+		assert.Equal(s.T(), int64(42), v)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			ClusterConfigMap: &types.VarsSourceClusterConfigMapOrSecret{
+				Name:         "cm",
+				Namespace:    s.namespace(),
+				Key:          "vars",
+				FieldManager: "manager2",
+			},
+		}, nil, "")
+		assert.EqualError(s.T(), err, fmt.Sprintf("key vars in %s/ConfigMap/cm on cluster is not managed by field manager manager2", s.namespace()))
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		b := true
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			IgnoreMissing: &b,
+			ClusterConfigMap: &types.VarsSourceClusterConfigMapOrSecret{
+				Name:         "cm",
+				Namespace:    s.namespace(),
+				Key:          "vars",
+				FieldManager: "manager2",
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		_, ok, _ := vc.Vars.GetNestedField("test1")
+		assert.False(s.T(), ok)
+	})
+}
+
 func (s *VarsLoaderTestSuite) TestClusterSecret() {
 	s.createNamespace()
 
@@ -497,6 +797,47 @@ func (s *VarsLoaderTestSuite) TestClusterSecret() {
 	})
 }
 
+func (s *VarsLoaderTestSuite) TestClusterSecretDockerConfigJson() {
+	s.createNamespace()
+
+	secret := corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "pull-secret", Namespace: s.namespace()},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths": {
+				"ghcr.io": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("user1:pass{{1")) + `"},
+				"registry.example.com": {"username": "user2", "password": "pass2", "email": "user2@example.com"}
+			}}`),
+		},
+	}
+
+	err := s.k.Client.Create(context.TODO(), &secret)
+	assert.NoError(s.T(), err)
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		vs := &types.VarsSource{
+			ClusterSecret: &types.VarsSourceClusterConfigMapOrSecret{
+				Name:      "pull-secret",
+				Namespace: s.namespace(),
+				Key:       corev1.DockerConfigJsonKey,
+			},
+			TargetPath: "registries",
+		}
+		err := vl.LoadVars(context.TODO(), vc, vs, nil, "")
+		assert.NoError(s.T(), err)
+		assert.True(s.T(), vs.RenderedSensitive)
+
+		v, _, _ := vc.Vars.GetNestedString("registries", "auths", "ghcr.io", "username")
+		assert.Equal(s.T(), "user1", v)
+		v, _, _ = vc.Vars.GetNestedString("registries", "auths", "ghcr.io", "password")
+		assert.Equal(s.T(), "pass{{1", v)
+		v, _, _ = vc.Vars.GetNestedString("registries", "auths", "registry.example.com", "password")
+		assert.Equal(s.T(), "pass2", v)
+		v, _, _ = vc.Vars.GetNestedString("registries", "auths", "registry.example.com", "email")
+		assert.Equal(s.T(), "user2@example.com", v)
+	})
+}
+
 func (s *VarsLoaderTestSuite) TestK8sObjectLabels() {
 	s.createNamespace()
 
@@ -560,6 +901,57 @@ func (s *VarsLoaderTestSuite) TestK8sObjectLabels() {
 	})
 }
 
+func (s *VarsLoaderTestSuite) TestK8sObjectLabelsMergeMultiple() {
+	s.createNamespace()
+
+	for _, x := range []struct {
+		name string
+		vars string
+	}{
+		{name: "cm-b", vars: `{"shared": "b", "b": {"x": 2}}`},
+		{name: "cm-a", vars: `{"shared": "a", "a": {"x": 1}}`},
+	} {
+		cm := corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: x.name, Namespace: s.namespace(), Labels: map[string]string{"shard": "true"}},
+			Data: map[string]string{
+				"vars": x.vars,
+			},
+		}
+		err := s.k.Client.Create(context.TODO(), &cm)
+		assert.NoError(s.T(), err)
+	}
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			ClusterConfigMap: &types.VarsSourceClusterConfigMapOrSecret{
+				Labels:    map[string]string{"shard": "true"},
+				Namespace: s.namespace(),
+				Key:       "vars",
+			},
+		}, nil, "")
+		assert.EqualError(s.T(), err, "found more than one objects with labels map[shard:true]")
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			ClusterConfigMap: &types.VarsSourceClusterConfigMapOrSecret{
+				Labels:        map[string]string{"shard": "true"},
+				Namespace:     s.namespace(),
+				Key:           "vars",
+				MergeMultiple: true,
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("a", "x")
+		assert.Equal(s.T(), int64(1), v)
+		v, _, _ = vc.Vars.GetNestedInt("b", "x")
+		assert.Equal(s.T(), int64(2), v)
+		shared, _, _ := vc.Vars.GetNestedString("shared")
+		assert.Equal(s.T(), "b", shared)
+	})
+}
+
 func (s *VarsLoaderTestSuite) TestClusterObject() {
 	s.createNamespace()
 
@@ -984,43 +1376,145 @@ func (s *VarsLoaderTestSuite) TestSystemEnv() {
 	})
 }
 
-func (s *VarsLoaderTestSuite) TestHttp_GET() {
-	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if strings.HasSuffix(r.URL.Path, "/ok") {
-			_, _ = w.Write([]byte(`{"test1": {"test2": 42}}`))
-		} else if strings.HasSuffix(r.URL.Path, "/error") {
-			http.Error(w, "error", http.StatusInternalServerError)
-		} else {
-			http.NotFound(w, r)
-		}
-	}))
-	defer ts.Close()
+func (s *VarsLoaderTestSuite) TestSystemEnvTemplatedNames() {
+	s.T().Setenv("DB_PASSWORD_PROD", "secret")
 
 	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
-		u, _ := url.Parse(ts.URL)
-		u.Path += "/ok"
+		vc.Update(uo.FromMap(map[string]interface{}{
+			"target": map[string]interface{}{
+				"name": "prod",
+			},
+		}))
 
 		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
-			Http: &types.VarsSourceHttp{
-				Url: types.YamlUrl{URL: *u},
-			},
+			SystemEnvVars: uo.FromMap(map[string]interface{}{
+				"test1": "DB_PASSWORD_{{ target.name | upper }}",
+				"test2": "DB_USER_{{ target.name | upper }}:def-{{ target.name }}",
+			}),
 		}, nil, "")
 		assert.NoError(s.T(), err)
 
-		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
-		assert.Equal(s.T(), int64(42), v)
+		v, _, _ := vc.Vars.GetNestedField("test1")
+		assert.Equal(s.T(), "secret", v)
+
+		v, _, _ = vc.Vars.GetNestedField("test2")
+		assert.Equal(s.T(), "def-prod", v)
 	})
 
 	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
 		b := true
-		u, _ := url.Parse(ts.URL)
-		u.Path += "/missing"
 		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
 			IgnoreMissing: &b,
-			Http: &types.VarsSourceHttp{
-				Url: types.YamlUrl{URL: *u},
-			},
-		}, nil, "")
+			SystemEnvVars: uo.FromMap(map[string]interface{}{
+				"test1": "{{ '' }}:def",
+			}),
+		}, nil, "")
+		assert.EqualError(s.T(), err, "empty environment variable name for test1")
+	})
+}
+
+func (s *VarsLoaderTestSuite) TestSystemEnvFile() {
+	s.T().Setenv("TEST_ENV_FILE1", "42")
+
+	d := s.T().TempDir()
+	_ = os.WriteFile(filepath.Join(d, ".env"), []byte(`# comment
+TEST_ENV_FILE1=1
+export TEST_ENV_FILE2="a b"
+TEST_ENV_FILE3='43' # comment
+TEST_ENV_FILE4=
+`), 0o600)
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			EnvFile: utils.Ptr(".env"),
+			SystemEnvVars: uo.FromMap(map[string]interface{}{
+				"test1": "TEST_ENV_FILE1",
+				"test2": "TEST_ENV_FILE2",
+				"test3": "TEST_ENV_FILE3",
+				"test4": "TEST_ENV_FILE4:def",
+				"test5": "TEST_ENV_FILE5:def",
+			}),
+		}, []string{d}, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedField("test1")
+		assert.Equal(s.T(), 42., v)
+
+		v, _, _ = vc.Vars.GetNestedField("test2")
+		assert.Equal(s.T(), "a b", v)
+
+		v, _, _ = vc.Vars.GetNestedField("test3")
+		assert.Equal(s.T(), 43., v)
+
+		v, _, _ = vc.Vars.GetNestedField("test4")
+		assert.Nil(s.T(), v)
+
+		v, _, _ = vc.Vars.GetNestedField("test5")
+		assert.Equal(s.T(), "def", v)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			EnvFile: utils.Ptr("missing.env"),
+			SystemEnvVars: uo.FromMap(map[string]interface{}{
+				"test1": "TEST_ENV_FILE1",
+			}),
+		}, []string{d}, "")
+		assert.EqualError(s.T(), err, "env file missing.env not found")
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			IgnoreMissing: utils.Ptr(true),
+			EnvFile:       utils.Ptr("missing.env"),
+			SystemEnvVars: uo.FromMap(map[string]interface{}{
+				"test1": "TEST_ENV_FILE1",
+			}),
+		}, []string{d}, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedField("test1")
+		assert.Equal(s.T(), 42., v)
+	})
+}
+
+func (s *VarsLoaderTestSuite) TestHttp_GET() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/ok") {
+			_, _ = w.Write([]byte(`{"test1": {"test2": 42}}`))
+		} else if strings.HasSuffix(r.URL.Path, "/error") {
+			http.Error(w, "error", http.StatusInternalServerError)
+		} else {
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		u, _ := url.Parse(ts.URL)
+		u.Path += "/ok"
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Http: &types.VarsSourceHttp{
+				Url: types.YamlUrl{URL: *u},
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
+		assert.Equal(s.T(), int64(42), v)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		b := true
+		u, _ := url.Parse(ts.URL)
+		u.Path += "/missing"
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			IgnoreMissing: &b,
+			Http: &types.VarsSourceHttp{
+				Url: types.YamlUrl{URL: *u},
+			},
+		}, nil, "")
 		assert.NoError(s.T(), err)
 	})
 
@@ -1039,6 +1533,133 @@ func (s *VarsLoaderTestSuite) TestHttp_GET() {
 	})
 }
 
+func (s *VarsLoaderTestSuite) TestHttp_MTLS() {
+	ts := &test_utils.TestHttpServer{
+		TLSEnabled:           true,
+		TLSClientCertEnabled: true,
+	}
+	ts.Start(s.T(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"test1": {"test2": 42}}`))
+	}))
+
+	d := s.T().TempDir()
+	_ = os.WriteFile(filepath.Join(d, "ca.pem"), ts.ServerCAs, 0o600)
+	_ = os.WriteFile(filepath.Join(d, "client.pem"), ts.ClientCert, 0o600)
+	s.T().Setenv("TEST_CLIENT_KEY", string(ts.ClientKey))
+
+	u, _ := url.Parse(ts.Server.URL)
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		// no CA and no client cert
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Http: &types.VarsSourceHttp{
+				Url: types.YamlUrl{URL: *u},
+			},
+		}, []string{d}, "")
+		assert.ErrorContains(s.T(), err, "certificate")
+
+		// no client cert
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Http: &types.VarsSourceHttp{
+				Url:         types.YamlUrl{URL: *u},
+				TlsCaBundle: utils.Ptr("ca.pem"),
+			},
+		}, []string{d}, "")
+		assert.Error(s.T(), err)
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Http: &types.VarsSourceHttp{
+				Url:           types.YamlUrl{URL: *u},
+				TlsCaBundle:   utils.Ptr(filepath.Join(d, "ca.pem")),
+				TlsClientCert: utils.Ptr("client.pem"),
+				TlsClientKey:  utils.Ptr("env:TEST_CLIENT_KEY"),
+			},
+		}, []string{d}, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
+		assert.Equal(s.T(), int64(42), v)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Http: &types.VarsSourceHttp{
+				Url:                types.YamlUrl{URL: *u},
+				TlsClientCert:      utils.Ptr("client.pem"),
+				TlsClientKey:       utils.Ptr("env:TEST_CLIENT_KEY"),
+				InsecureSkipVerify: true,
+			},
+		}, []string{d}, "")
+		assert.NoError(s.T(), err)
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Http: &types.VarsSourceHttp{
+				Url:           types.YamlUrl{URL: *u},
+				TlsClientCert: utils.Ptr("missing.pem"),
+				TlsClientKey:  utils.Ptr("env:TEST_MISSING_KEY"),
+			},
+		}, []string{d}, "")
+		assert.EqualError(s.T(), err, "failed to load tlsClientCert: file missing.pem not found")
+	})
+}
+
+func (s *VarsLoaderTestSuite) TestHttp_HeadersFromEnv() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" || r.Header.Get("X-Env") != "prod" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"test1": {"test2": 42}}`))
+	}))
+	defer ts.Close()
+
+	s.T().Setenv("TEST_HTTP_TOKEN", "Bearer secret-token")
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		u, _ := url.Parse(ts.URL)
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Http: &types.VarsSourceHttp{
+				Url:     types.YamlUrl{URL: *u},
+				Headers: map[string]string{"X-Env": "prod"},
+				HeadersFromEnv: map[string]string{
+					"Authorization": "TEST_HTTP_TOKEN",
+				},
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
+		assert.Equal(s.T(), int64(42), v)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		u, _ := url.Parse(ts.URL)
+		u.User = url.UserPassword("user", "password")
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Http: &types.VarsSourceHttp{
+				Url:     types.YamlUrl{URL: *u},
+				Headers: map[string]string{"X-Env": "dev"},
+				HeadersFromEnv: map[string]string{
+					"Authorization": "TEST_HTTP_TOKEN",
+				},
+			},
+		}, nil, "")
+		assert.ErrorContains(s.T(), err, "failed with status code 403")
+		assert.NotContains(s.T(), err.Error(), "secret-token")
+		assert.NotContains(s.T(), err.Error(), "password")
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Http: &types.VarsSourceHttp{
+				Url: types.YamlUrl{URL: *u},
+				HeadersFromEnv: map[string]string{
+					"Authorization": "TEST_HTTP_MISSING_TOKEN",
+				},
+			},
+		}, nil, "")
+		assert.ErrorContains(s.T(), err, "environment variable TEST_HTTP_MISSING_TOKEN for header Authorization")
+	})
+}
+
 func (s *VarsLoaderTestSuite) TestHttp_POST() {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
@@ -1121,6 +1742,307 @@ func (s *VarsLoaderTestSuite) TestHttp_JsonPath() {
 	})
 }
 
+func (s *VarsLoaderTestSuite) TestHttp_Sops() {
+	f, _ := sops_test_resources.TestResources.ReadFile("test.yaml")
+	key, _ := sops_test_resources.TestResources.ReadFile("test-key.txt")
+
+	s.T().Setenv(age.SopsAgeKeyEnv, string(key))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain" {
+			// not encrypted, but mentions sops
+			_, _ = w.Write([]byte("sops: plain\ntest3: 43\n"))
+			return
+		}
+		_, _ = w.Write(f)
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		vs := &types.VarsSource{
+			Http: &types.VarsSourceHttp{
+				Url: types.YamlUrl{URL: *u},
+			},
+		}
+		err := vl.LoadVars(context.TODO(), vc, vs, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
+		assert.Equal(s.T(), int64(42), v)
+		assert.True(s.T(), vs.RenderedSensitive)
+	})
+
+	u2 := *u
+	u2.Path = "/plain"
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		vs := &types.VarsSource{
+			Http: &types.VarsSourceHttp{
+				Url: types.YamlUrl{URL: u2},
+			},
+		}
+		err := vl.LoadVars(context.TODO(), vc, vs, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedString("sops")
+		assert.Equal(s.T(), "plain", v)
+		v2, _, _ := vc.Vars.GetNestedInt("test3")
+		assert.Equal(s.T(), int64(43), v2)
+		assert.False(s.T(), vs.RenderedSensitive)
+	})
+}
+
+func (s *VarsLoaderTestSuite) TestEtcd() {
+	kvs := map[string]string{
+		"config/app":       `{"test1": {"test2": 42}}`,
+		"prefix/a/b":       `{"x": 1}`,
+		"prefix/c":         "not: [valid yaml",
+		"prefix2/excluded": "1",
+	}
+
+	// fake of the JSON gRPC gateway of the etcd v3 API
+	var requestedEndpoints []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedEndpoints = append(requestedEndpoints, r.URL.Path)
+		if r.URL.Path != "/v3/kv/range" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req struct {
+			Key      string `json:"key"`
+			RangeEnd string `json:"range_end"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		assert.NoError(s.T(), err)
+		key, _ := base64.StdEncoding.DecodeString(req.Key)
+		rangeEnd, _ := base64.StdEncoding.DecodeString(req.RangeEnd)
+
+		var keys []string
+		for k := range kvs {
+			if k == string(key) || (len(rangeEnd) != 0 && k >= string(key) && k < string(rangeEnd)) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		var resp struct {
+			Kvs []map[string]string `json:"kvs,omitempty"`
+		}
+		for _, k := range keys {
+			resp.Kvs = append(resp.Kvs, map[string]string{
+				"key":   base64.StdEncoding.EncodeToString([]byte(k)),
+				"value": base64.StdEncoding.EncodeToString([]byte(kvs[k])),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(&resp)
+	}))
+	defer ts.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		// the first endpoint fails, so the second one must be used
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Etcd: &types.VarsSourceEtcd{
+				Endpoints: []string{broken.URL, ts.URL},
+				Key:       "config/app",
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
+		assert.Equal(s.T(), int64(42), v)
+		assert.Equal(s.T(), []string{"/v3/kv/range"}, requestedEndpoints)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Etcd: &types.VarsSourceEtcd{
+				Endpoints: []string{ts.URL},
+				Key:       "prefix/",
+				Prefix:    true,
+			},
+			TargetPath: "etcd",
+		}, nil, "")
+		assert.NoError(s.T(), err)
+		v, _, _ := vc.Vars.GetNestedInt("etcd", "a", "b", "x")
+		assert.Equal(s.T(), int64(1), v)
+		// not valid yaml, so the raw value is used
+		c, _, _ := vc.Vars.GetNestedString("etcd", "c")
+		assert.Equal(s.T(), "not: [valid yaml", c)
+		// keys of prefix2/ must not be included
+		o, _, _ := vc.Vars.GetNestedObject("etcd")
+		assert.Len(s.T(), o.Object, 2)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Etcd: &types.VarsSourceEtcd{
+				Endpoints: []string{ts.URL},
+				Key:       "missing",
+			},
+		}, nil, "")
+		assert.ErrorContains(s.T(), err, "the specified etcd key missing was not found")
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Etcd: &types.VarsSourceEtcd{
+				Endpoints: []string{ts.URL},
+				Key:       "missing",
+			},
+			IgnoreMissing: utils.Ptr(true),
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Etcd: &types.VarsSourceEtcd{
+				Endpoints: []string{broken.URL},
+				Key:       "config/app",
+			},
+		}, nil, "")
+		assert.ErrorContains(s.T(), err, "request failed with status 503")
+	})
+}
+
+func (s *VarsLoaderTestSuite) TestAwsSsm() {
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		aws.Parameters = map[string]string{
+			"/app/config": `{"test1": {"test2": 42}}`,
+		}
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsSsm: &types.VarsSourceAwsSsm{
+				Name:   "/app/config",
+				Region: utils.Ptr("eu-central-1"),
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
+		assert.Equal(s.T(), int64(42), v)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		aws.Parameters = map[string]string{
+			"/app/config/db/host": "db.example.com",
+			"/app/config/db/port": "5432",
+			"/app/config/name":    "app",
+			"/other/name":         "other",
+		}
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsSsm: &types.VarsSourceAwsSsm{
+				Name:      "/app/config",
+				Recursive: true,
+			},
+			TargetPath: "ssm",
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		assert.Equal(s.T(), map[string]any{
+			"ssm": map[string]any{
+				"db": map[string]any{
+					"host": "db.example.com",
+					"port": "5432",
+				},
+				"name": "app",
+			},
+		}, vc.Vars.Object)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		aws.Parameters = map[string]string{
+			"/app/config": `{"test1": {"test2": 42}}`,
+		}
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsSsm: &types.VarsSourceAwsSsm{
+				Name: "/app/missing",
+			},
+		}, nil, "")
+		assert.ErrorContains(s.T(), err, "parameter /app/missing not found")
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsSsm: &types.VarsSourceAwsSsm{
+				Name:      "/missing",
+				Recursive: true,
+			},
+		}, nil, "")
+		assert.ErrorContains(s.T(), err, "no parameters found below path /missing")
+
+		b := true
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			IgnoreMissing: &b,
+			AwsSsm: &types.VarsSourceAwsSsm{
+				Name: "/app/missing",
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			IgnoreMissing: &b,
+			AwsSsm: &types.VarsSourceAwsSsm{
+				Name:      "/missing",
+				Recursive: true,
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), map[string]any{}, vc.Vars.Object)
+	})
+}
+
+func (s *VarsLoaderTestSuite) TestAwsAppConfig() {
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		aws.AppConfigs = map[string]string{
+			"my-app/prod/features": "features:\n  newUi: true\n  maxItems: 10",
+		}
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsAppConfig: &types.VarsSourceAwsAppConfig{
+				Application:          "my-app",
+				Environment:          "prod",
+				ConfigurationProfile: "features",
+				Region:               utils.Ptr("eu-central-1"),
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("features", "maxItems")
+		assert.Equal(s.T(), int64(10), v)
+		b, _, _ := vc.Vars.GetNestedBool("features", "newUi")
+		assert.True(s.T(), b)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		aws.AppConfigs = map[string]string{
+			"my-app/prod/features": `{"test1": 42}`,
+		}
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsAppConfig: &types.VarsSourceAwsAppConfig{
+				Application:          "my-app",
+				Environment:          "staging",
+				ConfigurationProfile: "features",
+			},
+		}, nil, "")
+		assert.ErrorContains(s.T(), err, "configuration my-app/staging/features not found")
+
+		b := true
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			IgnoreMissing: &b,
+			AwsAppConfig: &types.VarsSourceAwsAppConfig{
+				Application:          "my-app",
+				Environment:          "staging",
+				ConfigurationProfile: "features",
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), map[string]any{}, vc.Vars.Object)
+	})
+}
+
 func (s *VarsLoaderTestSuite) TestAwsSecretsManager() {
 	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
 		aws.Secrets = map[string]string{
@@ -1225,3 +2147,302 @@ func (s *VarsLoaderTestSuite) TestGcpSecretManager() {
 		assert.NoError(s.T(), err)
 	})
 }
+
+func (s *VarsLoaderTestSuite) TestGitCache() {
+	gs := test_utils.NewTestGitServer(s.T())
+	gs.GitInit("repo")
+	gs.UpdateFile("repo", "test.yaml", func(f string) (string, error) {
+		return `test1: {{ a }}`, nil
+	}, "")
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		url, _ := gittypes.ParseGitUrl(gs.GitRepoUrl("repo"))
+		load := func(a int, b int) int64 {
+			vc.Vars = uo.FromMap(map[string]any{"a": a, "b": b})
+			err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+				Git: &types.VarsSourceGit{
+					Url:  *url,
+					Path: "test.yaml",
+				},
+			}, nil, "")
+			assert.NoError(s.T(), err)
+			v, _, _ := vc.Vars.GetNestedInt("test1")
+			return v
+		}
+
+		assert.Equal(s.T(), int64(1), load(1, 1))
+		assert.Len(s.T(), vl.gitVarsCache, 1)
+		assert.Equal(s.T(), int64(1), load(1, 1))
+		assert.Len(s.T(), vl.gitVarsCache, 1)
+
+		// changed globals which are not referenced by the file must not cause re-rendering
+		assert.Equal(s.T(), int64(1), load(1, 2))
+		assert.Len(s.T(), vl.gitVarsCache, 1)
+
+		// changed globals must cause re-rendering
+		assert.Equal(s.T(), int64(2), load(2, 2))
+		assert.Len(s.T(), vl.gitVarsCache, 2)
+	})
+}
+
+func (s *VarsLoaderTestSuite) TestGitOffline() {
+	gs := test_utils.NewTestGitServer(s.T())
+	gs.GitInit("repo")
+	gs.UpdateYaml("repo", "test.yaml", func(o map[string]any) error {
+		o["test1"] = 42
+		return nil
+	}, "")
+
+	url, _ := gittypes.ParseGitUrl(gs.GitRepoUrl("repo"))
+
+	load := func(offline bool, ref *gittypes.GitRef) (*VarsCtx, error) {
+		grc := s.newRP()
+		grc.SetOffline(offline)
+		d := decryptor.NewDecryptor("", decryptor.MaxEncryptedFileSize)
+		vl := NewVarsLoader(context.TODO(), s.k2, d, grc, nil, nil)
+		vc := NewVarsCtx(newJinja2Must(s.T()))
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			Git: &types.VarsSourceGit{
+				Url:  *url,
+				Path: "test.yaml",
+				Ref:  ref,
+			},
+		}, nil, "")
+		return vc, err
+	}
+
+	// nothing is cached yet
+	_, err := load(true, nil)
+	assert.ErrorContains(s.T(), err, "is not present in the local git cache and fetching is disabled (offline mode)")
+
+	// populate the cache
+	_, err = load(false, nil)
+	assert.NoError(s.T(), err)
+
+	wt := gs.GetWorktree("repo")
+	err = wt.Checkout(&git2.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName("testbranch"),
+		Create: true,
+	})
+	assert.NoError(s.T(), err)
+	gs.UpdateYaml("repo", "test.yaml", func(o map[string]any) error {
+		o["test1"] = 43
+		return nil
+	}, "")
+
+	vc, err := load(true, nil)
+	assert.NoError(s.T(), err)
+	v, _, _ := vc.Vars.GetNestedInt("test1")
+	assert.Equal(s.T(), int64(42), v)
+
+	// the new branch was never fetched
+	_, err = load(true, &gittypes.GitRef{Branch: "testbranch"})
+	assert.ErrorContains(s.T(), err, "ref refs/heads/testbranch not found in the local git cache")
+
+	_, err = load(false, &gittypes.GitRef{Branch: "testbranch"})
+	assert.NoError(s.T(), err)
+
+	vc, err = load(true, &gittypes.GitRef{Branch: "testbranch"})
+	assert.NoError(s.T(), err)
+	v, _, _ = vc.Vars.GetNestedInt("test1")
+	assert.Equal(s.T(), int64(43), v)
+}
+
+func (s *VarsLoaderTestSuite) TestClusterInfo() {
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		vl.SetContextName("my-context")
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			ClusterInfo: &types.VarsSourceClusterInfo{},
+			TargetPath:  "cluster",
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedString("cluster", "kubernetesVersion")
+		assert.Equal(s.T(), s.k2.ServerVersion.GitVersion, v)
+		v, _, _ = vc.Vars.GetNestedString("cluster", "contextName")
+		assert.Equal(s.T(), "my-context", v)
+		v, _, _ = vc.Vars.GetNestedString("cluster", "serverUrl")
+		assert.Equal(s.T(), s.k.RESTConfig().Host, v)
+		x, found, _ := vc.Vars.GetNestedField("cluster", "cloudProvider")
+		assert.True(s.T(), found)
+		assert.Nil(s.T(), x)
+	})
+}
+
+func (s *VarsLoaderTestSuite) TestClusterInfoOffline() {
+	d := decryptor.NewDecryptor("", decryptor.MaxEncryptedFileSize)
+	vl := NewVarsLoader(context.TODO(), nil, d, s.newRP(), nil, nil)
+	vl.SetContextName("my-context")
+	vc := NewVarsCtx(newJinja2Must(s.T()))
+
+	err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+		ClusterInfo: &types.VarsSourceClusterInfo{
+			Fallback: uo.FromStringMust(`{"kubernetesVersion": "v1.28.0"}`),
+		},
+		TargetPath: "cluster",
+	}, nil, "")
+	assert.NoError(s.T(), err)
+
+	v, _, _ := vc.Vars.GetNestedString("cluster", "kubernetesVersion")
+	assert.Equal(s.T(), "v1.28.0", v)
+	x, found, _ := vc.Vars.GetNestedField("cluster", "serverUrl")
+	assert.True(s.T(), found)
+	assert.Nil(s.T(), x)
+}
+
+func (s *VarsLoaderTestSuite) TestProvenance() {
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		assert.Nil(s.T(), vl.GetProvenance())
+
+		vl.SetTrackProvenance(true)
+
+		s.T().Setenv("TEST_PROVENANCE", "env")
+
+		b := true
+		sources := []types.VarsSource{
+			{Values: uo.FromStringMust(`{"app": {"replicas": 1, "image": "x"}, "db": {"host": "y"}}`)},
+			{SystemEnvVars: uo.FromStringMust(`{"app": {"image": "TEST_PROVENANCE"}}`)},
+			{Values: uo.FromStringMust(`{"db": "z"}`)},
+			{Values: uo.FromStringMust(`{"app": {"replicas": 2, "port": 80}}`), NoOverride: &b},
+		}
+		err := vl.LoadVarsList(context.TODO(), vc, sources, nil, "")
+		assert.NoError(s.T(), err)
+
+		assert.Equal(s.T(), []VarsProvenance{
+			{Path: "app.image", Source: "systemEnvVars"},
+			{Path: "app.port", Source: "values"},
+			{Path: "app.replicas", Source: "values"},
+			{Path: "db", Source: "values"},
+		}, vl.GetProvenance())
+	})
+}
+
+func TestDetectCloudProvider(t *testing.T) {
+	assert.Equal(t, "aws", detectCloudProvider("v1.27.3-eks-a5565ad"))
+	assert.Equal(t, "gcp", detectCloudProvider("v1.27.3-gke.100"))
+	assert.Equal(t, "", detectCloudProvider("v1.27.3"))
+}
+
+func (s *VarsLoaderTestSuite) TestNoIgnoreMissing() {
+	s.createNamespace()
+
+	d := s.T().TempDir()
+
+	gs := test_utils.NewTestGitServer(s.T())
+	gs.GitInit("repo")
+	gs.UpdateYaml("repo", "test.yaml", func(o map[string]any) error {
+		o["test1"] = 42
+		return nil
+	}, "")
+	gitUrl, _ := gittypes.ParseGitUrl(gs.GitRepoUrl("repo"))
+
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	httpUrl, _ := url.Parse(ts.URL)
+	httpUrl.Path += "/missing"
+
+	sources := map[string]types.VarsSource{
+		"file": {
+			File: utils.Ptr("test-missing.yaml"),
+		},
+		"git": {
+			Git: &types.VarsSourceGit{
+				Url:  *gitUrl,
+				Path: "test-missing.yaml",
+			},
+		},
+		"http": {
+			Http: &types.VarsSourceHttp{
+				Url: types.YamlUrl{URL: *httpUrl},
+			},
+		},
+		"clusterConfigMap": {
+			ClusterConfigMap: &types.VarsSourceClusterConfigMapOrSecret{
+				Name:      "cm-missing",
+				Namespace: s.namespace(),
+				Key:       "vars",
+			},
+		},
+	}
+
+	for name, source := range sources {
+		s.Run(name, func() {
+			s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+				vs := source
+				vs.IgnoreMissing = utils.Ptr(true)
+				err := vl.LoadVars(context.TODO(), vc, &vs, []string{d}, "")
+				assert.NoError(s.T(), err)
+
+				vl.SetNoIgnoreMissing(true)
+				vs = source
+				vs.IgnoreMissing = utils.Ptr(true)
+				err = vl.LoadVars(context.TODO(), vc, &vs, []string{d}, "")
+				assert.ErrorContains(s.T(), err, "overridden by --no-ignore-missing-vars")
+			})
+		})
+	}
+}
+
+func (s *VarsLoaderTestSuite) TestSourcePath() {
+	s.createNamespace()
+
+	d := s.T().TempDir()
+	_ = os.WriteFile(filepath.Join(d, "test.yaml"), []byte(`{"app": {"config": {"test1": 42}, "value": 43}}`), 0o600)
+
+	cm := corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{Name: "cm", Namespace: s.namespace()},
+		Data: map[string]string{
+			"vars": `{"app": {"config": {"test2": 44}}}`,
+		},
+	}
+	err := s.k.Client.Create(context.TODO(), &cm)
+	assert.NoError(s.T(), err)
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			File:       utils.Ptr("test.yaml"),
+			SourcePath: "app.config",
+		}, []string{d}, "")
+		assert.NoError(s.T(), err)
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			ClusterConfigMap: &types.VarsSourceClusterConfigMapOrSecret{
+				Name:      "cm",
+				Namespace: s.namespace(),
+				Key:       "vars",
+			},
+			SourcePath: "app.config",
+			TargetPath: "cm",
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		assert.Equal(s.T(), map[string]any{
+			"test1": int64(42),
+			"cm": map[string]any{
+				"test2": int64(44),
+			},
+		}, vc.Vars.Object)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			File:       utils.Ptr("test.yaml"),
+			SourcePath: "app.value",
+		}, []string{d}, "")
+		assert.ErrorContains(s.T(), err, "sourcePath app.value does not point to a dictionary")
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			File:       utils.Ptr("test.yaml"),
+			SourcePath: "app.missing",
+		}, []string{d}, "")
+		assert.EqualError(s.T(), err, "sourcePath app.missing not found in loaded variables")
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			File:          utils.Ptr("test.yaml"),
+			SourcePath:    "app.missing",
+			IgnoreMissing: utils.Ptr(true),
+		}, []string{d}, "")
+		assert.NoError(s.T(), err)
+	})
+}
@@ -0,0 +1,119 @@
+package vars
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"sort"
+	"strings"
+)
+
+// VarsProvenance describes which vars source last wrote the leaf value at Path.
+type VarsProvenance struct {
+	Path   string `json:"path"`
+	Source string `json:"source"`
+}
+
+// SetTrackProvenance enables tracking of which vars source wrote which leaf value. This is disabled by default, as
+// iterating all loaded vars has a performance impact. Use GetProvenance to retrieve the tracked provenance.
+func (v *VarsLoader) SetTrackProvenance(track bool) {
+	if track {
+		v.provenance = map[string]string{}
+	} else {
+		v.provenance = nil
+	}
+}
+
+// GetProvenance returns the tracked provenance of all leaf values, sorted by path. It returns nil if tracking was not
+// enabled via SetTrackProvenance.
+func (v *VarsLoader) GetProvenance() []VarsProvenance {
+	if v.provenance == nil {
+		return nil
+	}
+	ret := make([]VarsProvenance, 0, len(v.provenance))
+	for p, s := range v.provenance {
+		ret = append(ret, VarsProvenance{Path: p, Source: s})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Path < ret[j].Path
+	})
+	return ret
+}
+
+// recordProvenance records the given source as the writer of all leafs found in newVars. In case of noOverride,
+// leafs that already exist in the current vars are not recorded, as these are not overwritten.
+func (v *VarsLoader) recordProvenance(varsCtx *VarsCtx, newVars *uo.UnstructuredObject, source *types.VarsSource, noOverride bool) {
+	if v.provenance == nil {
+		return
+	}
+
+	desc := describeVarsSource(source)
+	_ = newVars.NewIterator().IterateLeafs(func(it *uo.ObjectIterator) error {
+		if noOverride {
+			if _, found, _ := varsCtx.Vars.GetNestedField(it.KeyPath()...); found {
+				return nil
+			}
+		}
+
+		p := it.KeyPath().ToJsonPath()
+		// a previously written dictionary or list might have been replaced by this leaf
+		for x := range v.provenance {
+			if strings.HasPrefix(x, p+".") || strings.HasPrefix(x, p+"[") {
+				delete(v.provenance, x)
+			}
+		}
+		v.provenance[p] = desc
+		return nil
+	})
+}
+
+// describeVarsSource returns a short human readable description of the given (rendered) vars source. It must not
+// contain any sensitive information.
+func describeVarsSource(source *types.VarsSource) string {
+	switch {
+	case source.Values != nil:
+		return "values"
+	case source.File != nil:
+		return fmt.Sprintf("file: %s", *source.File)
+	case source.Git != nil:
+		return fmt.Sprintf("git: %s, path=%s", source.Git.Url.Redacted(), source.Git.Path)
+	case source.GitFiles != nil:
+		return fmt.Sprintf("gitFiles: %s", source.GitFiles.Url.Redacted())
+	case source.Oci != nil:
+		return fmt.Sprintf("oci: %s, path=%s", source.Oci.Url, source.Oci.Path)
+	case source.ClusterConfigMap != nil:
+		return fmt.Sprintf("clusterConfigMap: %s/%s, key=%s", source.ClusterConfigMap.Namespace, source.ClusterConfigMap.Name, source.ClusterConfigMap.Key)
+	case source.ClusterSecret != nil:
+		return fmt.Sprintf("clusterSecret: %s/%s, key=%s", source.ClusterSecret.Namespace, source.ClusterSecret.Name, source.ClusterSecret.Key)
+	case source.ClusterObject != nil:
+		return fmt.Sprintf("clusterObject: %s %s/%s, path=%s", source.ClusterObject.Kind, source.ClusterObject.Namespace, source.ClusterObject.Name, source.ClusterObject.Path)
+	case source.SystemEnvVars != nil:
+		if source.EnvFile != nil {
+			return fmt.Sprintf("systemEnvVars: envFile=%s", *source.EnvFile)
+		}
+		return "systemEnvVars"
+	case source.Http != nil:
+		return fmt.Sprintf("http: %s", source.Http.Url.Redacted())
+	case source.AwsSecretsManager != nil:
+		return fmt.Sprintf("awsSecretsManager: %s", source.AwsSecretsManager.SecretName)
+	case source.AwsSsm != nil:
+		return fmt.Sprintf("awsSsm: %s", source.AwsSsm.Name)
+	case source.AwsAppConfig != nil:
+		return fmt.Sprintf("awsAppConfig: %s/%s/%s", source.AwsAppConfig.Application, source.AwsAppConfig.Environment, source.AwsAppConfig.ConfigurationProfile)
+	case source.GcpSecretManager != nil:
+		return fmt.Sprintf("gcpSecretManager: %s", source.GcpSecretManager.SecretName)
+	case source.Vault != nil:
+		return fmt.Sprintf("vault: %s, path=%s", source.Vault.Address, source.Vault.Path)
+	case source.AzureKeyVault != nil:
+		return fmt.Sprintf("azureKeyVault: %s, secretName=%s", source.AzureKeyVault.VaultUri, source.AzureKeyVault.SecretName)
+	case source.Etcd != nil:
+		return fmt.Sprintf("etcd: %s", source.Etcd.Key)
+	case source.OnePassword != nil:
+		return fmt.Sprintf("onePassword: %s/%s", source.OnePassword.Vault, source.OnePassword.Item)
+	case source.Gitlab != nil:
+		return fmt.Sprintf("gitlab: project=%s", source.Gitlab.ProjectId)
+	case source.ClusterInfo != nil:
+		return "clusterInfo"
+	}
+	return "unknown"
+}
@@ -0,0 +1,30 @@
+package vars
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/xeipuuv/gojsonschema"
+	"sort"
+)
+
+// ValidateVarsSchema validates the given vars against the given JSON schema. All violations are reported with the
+// path of the offending field.
+func ValidateVarsSchema(vars *uo.UnstructuredObject, schema *uo.UnstructuredObject) error {
+	r, err := gojsonschema.Validate(gojsonschema.NewGoLoader(schema.Object), gojsonschema.NewGoLoader(vars.Object))
+	if err != nil {
+		return fmt.Errorf("failed to validate vars against schema: %w", err)
+	}
+	if r.Valid() {
+		return nil
+	}
+
+	var errs []error
+	for _, e := range r.Errors() {
+		errs = append(errs, fmt.Errorf("%s: %s", e.Field(), e.Description()))
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+	return fmt.Errorf("vars do not match schema: %w", multierror.Append(nil, errs...))
+}
@@ -0,0 +1,34 @@
+package vars
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidateVarsSchema(t *testing.T) {
+	schema := uo.FromStringMust(`
+type: object
+required: [app]
+properties:
+  app:
+    type: object
+    required: [replicas]
+    properties:
+      replicas:
+        type: integer
+      env:
+        type: string
+        enum: [dev, prod]
+`)
+
+	err := ValidateVarsSchema(uo.FromStringMust(`{"app": {"replicas": 3, "env": "dev"}}`), schema)
+	assert.NoError(t, err)
+
+	err = ValidateVarsSchema(uo.FromStringMust(`{"other": 1}`), schema)
+	assert.ErrorContains(t, err, "(root): app is required")
+
+	err = ValidateVarsSchema(uo.FromStringMust(`{"app": {"replicas": "3", "env": "staging"}}`), schema)
+	assert.ErrorContains(t, err, "app.replicas: Invalid type. Expected: integer, given: string")
+	assert.ErrorContains(t, err, "app.env: app.env must be one of the following")
+}
@@ -4,29 +4,189 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/vault/api"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 )
 
+const defaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
 var httpClient = &http.Client{
 	Timeout: 15 * time.Second,
 }
 
-func GetSecret(server string, path string) (*string, error) {
+// GetSecret reads the secret at the given path. kvVersion specifies the KV secrets engine version of the mount, 0 means
+// to detect it via the mount info. If the mount info can't be read either, the path is read as-is and, if nothing is
+// found, read again as KV v2 path.
+func GetSecret(server string, path string, version *int, kvVersion int, auth *types.VarsSourceVaultAuth) (*string, error) {
 	client, err := api.NewClient(&api.Config{Address: server, HttpClient: httpClient})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create vault %s client", server)
 	}
-	secret, err := client.Logical().Read(path)
+	if auth != nil {
+		err = login(client, auth)
+		if err != nil {
+			return nil, fmt.Errorf("vault authentication failed: %w", err)
+		}
+	}
+
+	mountPath, detectedKvVersion := getKvMountInfo(client, path)
+	if kvVersion == 0 {
+		kvVersion = detectedKvVersion
+	}
+	if kvVersion != 2 && version != nil {
+		return nil, fmt.Errorf("a secret version can only be specified for KV v2 secrets")
+	}
+
+	var queryData map[string][]string
+	if kvVersion == 2 {
+		if mountPath != "" {
+			path = addPrefixToKvV2Path(path, mountPath, "data")
+		} else {
+			path = guessKvV2Path(path)
+		}
+		if version != nil {
+			queryData = map[string][]string{
+				"version": {strconv.Itoa(*version)},
+			}
+		}
+	}
+
+	secret, err := client.Logical().ReadWithData(path, queryData)
 	if err != nil {
 		return nil, fmt.Errorf("reading from vault failed: %v", err)
 	}
+	if secret == nil && kvVersion == 0 {
+		// we don't know the mount type, so probe for a KV v2 secret where the data/ prefix was omitted
+		if v2Path := guessKvV2Path(path); v2Path != strings.TrimPrefix(path, "/") {
+			secret, err = client.Logical().Read(v2Path)
+			if err != nil {
+				return nil, fmt.Errorf("reading from vault failed: %v", err)
+			}
+		}
+	}
 	if secret == nil || secret.Data == nil {
 		return nil, nil
 	}
-	data, _ := secret.Data["data"].(map[string]interface{})
+
+	var data map[string]interface{}
+	switch kvVersion {
+	case 1:
+		data = secret.Data
+	case 2:
+		data, _ = secret.Data["data"].(map[string]interface{})
+		if data == nil {
+			// deleted or destroyed versions have no data
+			return nil, nil
+		}
+	default:
+		// unknown mount type, try to detect the KV v2 envelope
+		data = secret.Data
+		if d, ok := secret.Data["data"].(map[string]interface{}); ok {
+			if _, ok := secret.Data["metadata"]; ok {
+				data = d
+			}
+		}
+	}
 	jsonData, _ := json.Marshal(data)
 	ret := string(jsonData)
 	return &ret, nil
 }
+
+// getKvMountInfo returns the mount path and the KV version of the mount containing path. If the mount info can't be
+// determined (e.g. due to missing permissions), 0 is returned as version.
+func getKvMountInfo(client *api.Client, path string) (string, int) {
+	secret, err := client.Logical().Read("sys/internal/ui/mounts/" + strings.TrimPrefix(path, "/"))
+	if err != nil || secret == nil || secret.Data == nil {
+		return "", 0
+	}
+	mountPath, _ := secret.Data["path"].(string)
+	if t, _ := secret.Data["type"].(string); t != "kv" && t != "generic" {
+		return mountPath, 0
+	}
+	options, _ := secret.Data["options"].(map[string]interface{})
+	if v, _ := options["version"].(string); v == "2" {
+		return mountPath, 2
+	}
+	return mountPath, 1
+}
+
+// addPrefixToKvV2Path converts a path relative to the mount into the API path of a KV v2 mount, e.g. "secret/foo" into
+// "secret/data/foo". Paths that already contain the prefix are returned unmodified.
+func addPrefixToKvV2Path(path string, mountPath string, prefix string) string {
+	path = strings.TrimPrefix(path, "/")
+	mountPath = strings.TrimSuffix(strings.TrimPrefix(mountPath, "/"), "/")
+	if mountPath == "" {
+		return path
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(path, mountPath), "/")
+	if rel == prefix || strings.HasPrefix(rel, prefix+"/") {
+		return path
+	}
+	return mountPath + "/" + prefix + "/" + rel
+}
+
+// guessKvV2Path is used when the mount path is unknown. It assumes that the mount is the first path element, e.g.
+// "secret/foo" is converted into "secret/data/foo". Paths that already contain a data/ element are returned unmodified.
+func guessKvV2Path(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	s := strings.Split(path, "/")
+	if len(s) < 2 {
+		return path
+	}
+	for _, x := range s {
+		if x == "data" {
+			return path
+		}
+	}
+	return addPrefixToKvV2Path(path, s[0], "data")
+}
+
+func login(client *api.Client, auth *types.VarsSourceVaultAuth) error {
+	var mountPath string
+	var data map[string]interface{}
+	switch {
+	case auth.AppRole != nil:
+		mountPath = auth.AppRole.MountPath
+		if mountPath == "" {
+			mountPath = "approle"
+		}
+		data = map[string]interface{}{
+			"role_id":   auth.AppRole.RoleId,
+			"secret_id": auth.AppRole.SecretId,
+		}
+	case auth.Kubernetes != nil:
+		mountPath = auth.Kubernetes.MountPath
+		if mountPath == "" {
+			mountPath = "kubernetes"
+		}
+		tokenPath := auth.Kubernetes.TokenPath
+		if tokenPath == "" {
+			tokenPath = defaultServiceAccountTokenPath
+		}
+		jwt, err := os.ReadFile(tokenPath)
+		if err != nil {
+			return fmt.Errorf("failed to read service account token: %w", err)
+		}
+		data = map[string]interface{}{
+			"role": auth.Kubernetes.Role,
+			"jwt":  strings.TrimSpace(string(jwt)),
+		}
+	default:
+		return fmt.Errorf("no auth method specified")
+	}
+
+	secret, err := client.Logical().Write(fmt.Sprintf("auth/%s/login", strings.Trim(mountPath, "/")), data)
+	if err != nil {
+		return err
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return fmt.Errorf("login did not return a client token")
+	}
+	client.SetToken(secret.Auth.ClientToken)
+	return nil
+}
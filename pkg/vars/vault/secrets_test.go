@@ -0,0 +1,94 @@
+package vault
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAddPrefixToKvV2Path(t *testing.T) {
+	testCases := []struct {
+		path      string
+		mountPath string
+		result    string
+	}{
+		{path: "secret/simple", mountPath: "secret/", result: "secret/data/simple"},
+		{path: "secret/data/simple", mountPath: "secret/", result: "secret/data/simple"},
+		{path: "/secret/a/b", mountPath: "secret/", result: "secret/data/a/b"},
+		{path: "kv/team/simple", mountPath: "kv/team/", result: "kv/team/data/simple"},
+		{path: "secret/simple", mountPath: "", result: "secret/simple"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			assert.Equal(t, tc.result, addPrefixToKvV2Path(tc.path, tc.mountPath, "data"))
+		})
+	}
+}
+
+func TestGuessKvV2Path(t *testing.T) {
+	assert.Equal(t, "secret/data/simple", guessKvV2Path("secret/simple"))
+	assert.Equal(t, "secret/data/a/b", guessKvV2Path("/secret/a/b"))
+	assert.Equal(t, "kv/team/data/simple", guessKvV2Path("kv/team/data/simple"))
+	assert.Equal(t, "secret", guessKvV2Path("secret"))
+}
+
+func newTestVaultServer(t *testing.T, allowMounts bool) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/sys/internal/ui/mounts/secret/simple", "/v1/sys/internal/ui/mounts/secret/data/simple":
+			if !allowMounts {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"errors": ["permission denied"]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data": {"path": "secret/", "type": "kv", "options": {"version": "2"}}}`))
+		case "/v1/secret/data/simple":
+			v := r.URL.Query().Get("version")
+			if v == "" {
+				v = "2"
+			}
+			_, _ = w.Write([]byte(fmt.Sprintf(`{"data": {"data": {"version": "%s"}, "metadata": {"version": %s}}}`, v, v)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": []}`))
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestGetSecretKvV2(t *testing.T) {
+	t.Setenv("VAULT_TOKEN", "test")
+
+	version := 1
+	for _, allowMounts := range []bool{true, false} {
+		t.Run(fmt.Sprintf("allowMounts=%v", allowMounts), func(t *testing.T) {
+			s := newTestVaultServer(t, allowMounts)
+
+			// auto-detected or probed
+			secret, err := GetSecret(s.URL, "secret/simple", nil, 0, nil)
+			assert.NoError(t, err)
+			if assert.NotNil(t, secret) {
+				assert.Equal(t, `{"version":"2"}`, *secret)
+			}
+
+			// explicit KV version
+			secret, err = GetSecret(s.URL, "secret/simple", &version, 2, nil)
+			assert.NoError(t, err)
+			if assert.NotNil(t, secret) {
+				assert.Equal(t, `{"version":"1"}`, *secret)
+			}
+
+			secret, err = GetSecret(s.URL, "secret/missing", nil, 0, nil)
+			assert.NoError(t, err)
+			assert.Nil(t, secret)
+		})
+	}
+
+	s := newTestVaultServer(t, false)
+	// a version can't be specified when the KV version is unknown
+	_, err := GetSecret(s.URL, "secret/simple", &version, 0, nil)
+	assert.EqualError(t, err, "a secret version can only be specified for KV v2 secrets")
+}
@@ -5,11 +5,15 @@ import { GitRef } from './models-static'
 export class DeploymentError {
     ref: ObjectRef;
     message: string;
+    count?: number;
+    refs?: ObjectRef[];
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
         this.ref = this.convertValues(source["ref"], ObjectRef);
         this.message = source["message"];
+        this.count = source["count"];
+        this.refs = this.convertValues(source["refs"], ObjectRef);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -110,6 +114,20 @@ export class ConflictResolutionConfig {
         this.action = source["action"];
     }
 }
+export class ApplyOrderItemConfig {
+    group?: string;
+    kind?: string;
+    name?: string;
+    namespace?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.group = source["group"];
+        this.kind = source["kind"];
+        this.name = source["name"];
+        this.namespace = source["namespace"];
+    }
+}
 export class IgnoreForDiffItemConfig {
     fieldPath?: string[];
     fieldPathRegex?: string[];
@@ -128,6 +146,18 @@ export class IgnoreForDiffItemConfig {
         this.namespace = source["namespace"];
     }
 }
+export class HelmValuesLayer {
+    file?: string;
+    values?: any;
+    optional?: boolean;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.file = source["file"];
+        this.values = source["values"];
+        this.optional = source["optional"];
+    }
+}
 export class HelmChartConfig {
     repo?: string;
     git?: GitProject;
@@ -142,6 +172,7 @@ export class HelmChartConfig {
     skipCRDs?: boolean;
     skipUpdate?: boolean;
     skipPrePull?: boolean;
+    values?: HelmValuesLayer[];
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
@@ -158,6 +189,7 @@ export class HelmChartConfig {
         this.skipCRDs = source["skipCRDs"];
         this.skipUpdate = source["skipUpdate"];
         this.skipPrePull = source["skipPrePull"];
+        this.values = this.convertValues(source["values"], HelmValuesLayer);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -276,9 +308,11 @@ export class DeploymentItemConfig {
     onlyRender?: boolean;
     alwaysDeploy?: boolean;
     when?: string;
+    dependsOn?: string[];
     renderedHelmChartConfig?: HelmChartConfig;
     renderedObjects?: ObjectRef[];
     renderedInclude?: DeploymentProjectConfig;
+    renderedHelmValues?: any;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
@@ -299,9 +333,11 @@ export class DeploymentItemConfig {
         this.onlyRender = source["onlyRender"];
         this.alwaysDeploy = source["alwaysDeploy"];
         this.when = source["when"];
+        this.dependsOn = source["dependsOn"];
         this.renderedHelmChartConfig = this.convertValues(source["renderedHelmChartConfig"], HelmChartConfig);
         this.renderedObjects = this.convertValues(source["renderedObjects"], ObjectRef);
         this.renderedInclude = this.convertValues(source["renderedInclude"], DeploymentProjectConfig);
+        this.renderedHelmValues = source["renderedHelmValues"];
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -332,15 +368,157 @@ export class VarSourceAzureKeyVault {
         this.secretName = source["secretName"];
     }
 }
+export class VarsSource1Password {
+    connectHost: string;
+    token?: string;
+    tokenEnv?: string;
+    vault: string;
+    item: string;
+    field?: string;
+    fields?: string[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.connectHost = source["connectHost"];
+        this.token = source["token"];
+        this.tokenEnv = source["tokenEnv"];
+        this.vault = source["vault"];
+        this.item = source["item"];
+        this.field = source["field"];
+        this.fields = source["fields"];
+    }
+}
+export class VarsSourceEtcdTls {
+    caFile?: string;
+    certFile?: string;
+    keyFile?: string;
+    insecureSkipTlsVerify?: boolean;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.caFile = source["caFile"];
+        this.certFile = source["certFile"];
+        this.keyFile = source["keyFile"];
+        this.insecureSkipTlsVerify = source["insecureSkipTlsVerify"];
+    }
+}
+export class VarsSourceEtcd {
+    endpoints: string[];
+    key: string;
+    prefix?: boolean;
+    tls?: VarsSourceEtcdTls;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.endpoints = source["endpoints"];
+        this.key = source["key"];
+        this.prefix = source["prefix"];
+        this.tls = this.convertValues(source["tls"], VarsSourceEtcdTls);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class VarsSourceVaultKubernetesAuth {
+    mountPath?: string;
+    role: string;
+    tokenPath?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.mountPath = source["mountPath"];
+        this.role = source["role"];
+        this.tokenPath = source["tokenPath"];
+    }
+}
+export class VarsSourceVaultAppRoleAuth {
+    mountPath?: string;
+    roleId: string;
+    secretId: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.mountPath = source["mountPath"];
+        this.roleId = source["roleId"];
+        this.secretId = source["secretId"];
+    }
+}
+export class VarsSourceVaultAuth {
+    appRole?: VarsSourceVaultAppRoleAuth;
+    kubernetes?: VarsSourceVaultKubernetesAuth;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.appRole = this.convertValues(source["appRole"], VarsSourceVaultAppRoleAuth);
+        this.kubernetes = this.convertValues(source["kubernetes"], VarsSourceVaultKubernetesAuth);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class VarsSourceVault {
     address: string;
     path: string;
+    version?: number;
+    kvVersion?: number;
+    auth?: VarsSourceVaultAuth;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
         this.address = source["address"];
         this.path = source["path"];
+        this.version = source["version"];
+        this.kvVersion = source["kvVersion"];
+        this.auth = this.convertValues(source["auth"], VarsSourceVaultAuth);
     }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
 }
 export class VarsSourceGcpSecretManager {
     secretName: string;
@@ -408,6 +586,8 @@ export class VarsSourceClusterConfigMapOrSecret {
     namespace: string;
     key: string;
     targetPath?: string;
+    fieldManager?: string;
+    mergeMultiple?: boolean;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
@@ -416,6 +596,8 @@ export class VarsSourceClusterConfigMapOrSecret {
         this.namespace = source["namespace"];
         this.key = source["key"];
         this.targetPath = source["targetPath"];
+        this.fieldManager = source["fieldManager"];
+        this.mergeMultiple = source["mergeMultiple"];
     }
 }
 export class GitFile {
@@ -466,14 +648,46 @@ export class VarsSourceGit {
     url: string;
     ref?: GitRef;
     path: string;
+    sparse?: boolean;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
         this.url = source["url"];
         this.ref = new GitRef(source["ref"]);
         this.path = source["path"];
+        this.sparse = source["sparse"];
     }
 }
+export class VarsSourceOci {
+    url: string;
+    ref?: OciRef;
+    path: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.url = source["url"];
+        this.ref = this.convertValues(source["ref"], OciRef);
+        this.path = source["path"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class VarsSource {
     ignoreMissing?: boolean;
     noOverride?: boolean;
@@ -482,15 +696,19 @@ export class VarsSource {
     file?: string;
     git?: VarsSourceGit;
     gitFiles?: VarsSourceGitFiles;
+    oci?: VarsSourceOci;
     clusterConfigMap?: VarsSourceClusterConfigMapOrSecret;
     clusterSecret?: VarsSourceClusterConfigMapOrSecret;
     clusterObject?: VarsSourceClusterObject;
     systemEnvVars?: any;
+    envFile?: string;
     http?: VarsSourceHttp;
     awsSecretsManager?: VarsSourceAwsSecretsManager;
     gcpSecretManager?: VarsSourceGcpSecretManager;
     vault?: VarsSourceVault;
     azureKeyVault?: VarSourceAzureKeyVault;
+    etcd?: VarsSourceEtcd;
+    onePassword?: VarsSource1Password;
     targetPath?: string;
     when?: string;
     renderedSensitive?: boolean;
@@ -505,15 +723,19 @@ export class VarsSource {
         this.file = source["file"];
         this.git = this.convertValues(source["git"], VarsSourceGit);
         this.gitFiles = this.convertValues(source["gitFiles"], VarsSourceGitFiles);
+        this.oci = this.convertValues(source["oci"], VarsSourceOci);
         this.clusterConfigMap = this.convertValues(source["clusterConfigMap"], VarsSourceClusterConfigMapOrSecret);
         this.clusterSecret = this.convertValues(source["clusterSecret"], VarsSourceClusterConfigMapOrSecret);
         this.clusterObject = this.convertValues(source["clusterObject"], VarsSourceClusterObject);
         this.systemEnvVars = source["systemEnvVars"];
+        this.envFile = source["envFile"];
         this.http = this.convertValues(source["http"], VarsSourceHttp);
         this.awsSecretsManager = this.convertValues(source["awsSecretsManager"], VarsSourceAwsSecretsManager);
         this.gcpSecretManager = this.convertValues(source["gcpSecretManager"], VarsSourceGcpSecretManager);
         this.vault = this.convertValues(source["vault"], VarsSourceVault);
         this.azureKeyVault = this.convertValues(source["azureKeyVault"], VarSourceAzureKeyVault);
+        this.etcd = this.convertValues(source["etcd"], VarsSourceEtcd);
+        this.onePassword = this.convertValues(source["onePassword"], VarsSource1Password);
         this.targetPath = source["targetPath"];
         this.when = source["when"];
         this.renderedSensitive = source["renderedSensitive"];
@@ -538,8 +760,19 @@ export class VarsSource {
 	    return a;
 	}
 }
+export class VarsSchemaConfig {
+    file?: string;
+    schema?: any;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.file = source["file"];
+        this.schema = source["schema"];
+    }
+}
 export class DeploymentProjectConfig {
     vars?: VarsSource[];
+    varsSchema?: VarsSchemaConfig;
     when?: string;
     deployments?: DeploymentItemConfig[];
     commonLabels?: {[key: string]: string};
@@ -548,10 +781,12 @@ export class DeploymentProjectConfig {
     tags?: string[];
     ignoreForDiff?: IgnoreForDiffItemConfig[];
     conflictResolution?: ConflictResolutionConfig[];
+    applyOrder?: ApplyOrderItemConfig[];
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
         this.vars = this.convertValues(source["vars"], VarsSource);
+        this.varsSchema = this.convertValues(source["varsSchema"], VarsSchemaConfig);
         this.when = source["when"];
         this.deployments = this.convertValues(source["deployments"], DeploymentItemConfig);
         this.commonLabels = source["commonLabels"];
@@ -560,6 +795,7 @@ export class DeploymentProjectConfig {
         this.tags = source["tags"];
         this.ignoreForDiff = this.convertValues(source["ignoreForDiff"], IgnoreForDiffItemConfig);
         this.conflictResolution = this.convertValues(source["conflictResolution"], ConflictResolutionConfig);
+        this.applyOrder = this.convertValues(source["applyOrder"], ApplyOrderItemConfig);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -834,6 +1070,86 @@ export class ProjectKey {
         this.subDir = source["subDir"];
     }
 }
+export class AppliedKindCounts {
+    group?: string;
+    kind: string;
+    created: number;
+    updated: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.group = source["group"];
+        this.kind = source["kind"];
+        this.created = source["created"];
+        this.updated = source["updated"];
+    }
+}
+export class AppliedObjectsSummary {
+    objects?: AppliedKindCounts[];
+    hooks?: AppliedKindCounts[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.objects = this.convertValues(source["objects"], AppliedKindCounts);
+        this.hooks = this.convertValues(source["hooks"], AppliedKindCounts);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class PinnedImage {
+    image: string;
+    digest: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.image = source["image"];
+        this.digest = source["digest"];
+    }
+}
+export class PruneSkippedObject {
+    ref: ObjectRef;
+    reason: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.ref = this.convertValues(source["ref"], ObjectRef);
+        this.reason = source["reason"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class CommandResult {
     id: string;
     reconcileId: string;
@@ -851,6 +1167,11 @@ export class CommandResult {
     errors?: DeploymentError[];
     warnings?: DeploymentError[];
     seenImages?: FixedImage[];
+    helmValuesChanges?: HelmValuesChange[];
+    pruneReport?: PruneCandidates[];
+    pruneSkipped?: PruneSkippedObject[];
+    appliedObjectsSummary?: AppliedObjectsSummary;
+    pinnedImages?: PinnedImage[];
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
@@ -870,6 +1191,11 @@ export class CommandResult {
         this.errors = this.convertValues(source["errors"], DeploymentError);
         this.warnings = this.convertValues(source["warnings"], DeploymentError);
         this.seenImages = this.convertValues(source["seenImages"], FixedImage);
+        this.helmValuesChanges = this.convertValues(source["helmValuesChanges"], HelmValuesChange);
+        this.pruneReport = this.convertValues(source["pruneReport"], PruneCandidates);
+        this.pruneSkipped = this.convertValues(source["pruneSkipped"], PruneSkippedObject);
+        this.appliedObjectsSummary = this.convertValues(source["appliedObjectsSummary"], AppliedObjectsSummary);
+        this.pinnedImages = this.convertValues(source["pinnedImages"], PinnedImage);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -912,6 +1238,7 @@ export class CommandResultSummary {
     errors: DeploymentError[];
     warnings: DeploymentError[];
     totalChanges: number;
+    appliedObjectsSummary?: AppliedObjectsSummary;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
@@ -936,6 +1263,7 @@ export class CommandResultSummary {
         this.errors = this.convertValues(source["errors"], DeploymentError);
         this.warnings = this.convertValues(source["warnings"], DeploymentError);
         this.totalChanges = source["totalChanges"];
+        this.appliedObjectsSummary = this.convertValues(source["appliedObjectsSummary"], AppliedObjectsSummary);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -1168,6 +1496,66 @@ export class DriftDetectionResult {
 	    return a;
 	}
 }
+export class HelmValuesChange {
+    releaseName: string;
+    namespace?: string;
+    changes?: Change[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.releaseName = source["releaseName"];
+        this.namespace = source["namespace"];
+        this.changes = this.convertValues(source["changes"], Change);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class PruneCandidates {
+    deploymentItem?: string;
+    orphan?: boolean;
+    refs: ObjectRef[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.deploymentItem = source["deploymentItem"];
+        this.orphan = source["orphan"];
+        this.refs = this.convertValues(source["refs"], ObjectRef);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (Array.isArray(a)) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class ChangedObject {
     ref: ObjectRef;
     changes?: Change[];